@@ -0,0 +1,266 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha512" // SHA-384 and SHA-512 of the supported algorithms
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clockSkew is the tolerance applied when checking token expiry.
+const clockSkew = time.Minute
+
+// keyRefreshInterval is the minimum time between two fetches of the
+// provider's keys triggered by unknown key IDs.
+const keyRefreshInterval = time.Minute
+
+// signingAlgorithms maps the supported JWS algorithms to their hash.
+var signingAlgorithms = map[string]crypto.Hash{
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+}
+
+// keySet caches the public keys of the provider, keyed by key ID.
+type keySet struct {
+	uri      string
+	provider *Provider
+
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+// jsonWebKey holds the fields of a JWK used for RSA and EC keys.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// key returns the public key with the given ID, fetching the key set if
+// the ID is unknown, which happens when the provider rotates its keys.
+func (s *keySet) key(kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	if !s.fetched.IsZero() && time.Since(s.fetched) < keyRefreshInterval {
+		return nil, loginError(fmt.Errorf("oidc: unknown signing key %q", kid))
+	}
+
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := s.provider.getJSON(s.uri, &doc); err != nil {
+		return nil, providerError(err)
+	}
+	s.fetched = time.Now()
+	s.keys = make(map[string]crypto.PublicKey, len(doc.Keys))
+	for _, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := jwk.publicKey(); err == nil {
+			s.keys[jwk.Kid] = key
+		}
+	}
+
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, loginError(fmt.Errorf("oidc: unknown signing key %q", kid))
+}
+
+// publicKey decodes an RSA or EC public key.
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("oidc: invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("oidc: unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("oidc: EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("oidc: unsupported key type %q", k.Kty)
+}
+
+// decodeBigInt decodes a base64url-encoded big-endian integer.
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("oidc: invalid key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// verify checks the signature, issuer, audience and expiry of an ID token
+// and returns its claims.
+func (p *Provider) verify(token string, d *discovery) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, loginError(errors.New("oidc: malformed ID token"))
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, loginError(err)
+	}
+	hash, ok := signingAlgorithms[header.Alg]
+	if !ok {
+		return nil, loginError(fmt.Errorf("oidc: unsupported signing algorithm %q", header.Alg))
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, loginError(errors.New("oidc: malformed ID token signature"))
+	}
+
+	key, err := p.keys.key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	h := hash.New()
+	h.Write([]byte(parts[0] + "." + parts[1]))
+	if err := verifySignature(header.Alg, key, hash, h.Sum(nil), signature); err != nil {
+		return nil, loginError(err)
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, loginError(err)
+	}
+	if err := p.checkClaims(claims, d); err != nil {
+		return nil, loginError(err)
+	}
+	return claims, nil
+}
+
+// verifySignature checks a JWS signature of the given algorithm over
+// digest.
+func verifySignature(alg string, key crypto.PublicKey, hash crypto.Hash, digest, signature []byte) error {
+	invalid := errors.New("oidc: invalid ID token signature")
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		switch alg[:2] {
+		case "RS":
+			if rsa.VerifyPKCS1v15(key, hash, digest, signature) != nil {
+				return invalid
+			}
+			return nil
+		case "PS":
+			if rsa.VerifyPSS(key, hash, digest, signature, nil) != nil {
+				return invalid
+			}
+			return nil
+		}
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if alg[:2] != "ES" || len(signature) != 2*size {
+			return invalid
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return invalid
+		}
+		return nil
+	}
+	return fmt.Errorf("oidc: key does not match algorithm %s", alg)
+}
+
+// checkClaims validates the standard claims of an ID token.
+func (p *Provider) checkClaims(claims map[string]interface{}, d *discovery) error {
+	if iss, _ := claims["iss"].(string); iss != d.Issuer {
+		return fmt.Errorf("oidc: ID token issued by %q, want %q", iss, d.Issuer)
+	}
+
+	var audiences []string
+	switch aud := claims["aud"].(type) {
+	case string:
+		audiences = []string{aud}
+	case []interface{}:
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				audiences = append(audiences, s)
+			}
+		}
+	}
+	found := false
+	for _, aud := range audiences {
+		found = found || aud == p.config.ClientID
+	}
+	if !found {
+		return fmt.Errorf("oidc: ID token not issued for client %q", p.config.ClientID)
+	}
+	if azp, ok := claims["azp"].(string); ok && len(audiences) > 1 && azp != p.config.ClientID {
+		return fmt.Errorf("oidc: ID token authorized for party %q", azp)
+	}
+
+	exp, ok := claims["exp"].(float64)
+	if !ok || time.Unix(int64(exp), 0).Add(clockSkew).Before(time.Now()) {
+		return errors.New("oidc: ID token expired")
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return errors.New("oidc: ID token has no subject")
+	}
+	return nil
+}
+
+// decodeSegment decodes a base64url-encoded JSON segment of a token.
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errors.New("oidc: malformed ID token")
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.New("oidc: malformed ID token")
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signES256 returns an ES256 token with the given header and claims.
+func signES256(t *testing.T, key *ecdsa.PrivateKey, header, claims map[string]interface{}) string {
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	digest := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerify(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &discovery{Issuer: "https://id.example.com"}
+	p := New(Config{Issuer: d.Issuer, ClientID: "app", RedirectURL: "https://app.example.com/cb"})
+	p.keys = &keySet{
+		provider: p,
+		keys:     map[string]crypto.PublicKey{"ec": &key.PublicKey},
+		fetched:  time.Now(),
+	}
+
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss": d.Issuer, "aud": []string{"app", "api"}, "azp": "app", "sub": "u-1",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+	header := map[string]interface{}{"alg": "ES256", "kid": "ec"}
+
+	claims, err := p.verify(signES256(t, key, header, valid()), d)
+	if err != nil || claims["sub"] != "u-1" {
+		t.Fatalf("Expected a valid token, got %v %v", claims, err)
+	}
+
+	token := signES256(t, key, header, valid())
+	tampered := valid()
+	tampered["sub"] = "admin"
+	payload, _ := json.Marshal(tampered)
+	parts := strings.Split(token, ".")
+	forged := parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + parts[2]
+
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+
+	wrongAzp := valid()
+	wrongAzp["azp"] = "api"
+	otherIssuer := valid()
+	otherIssuer["iss"] = "https://evil.example.com"
+
+	tests := map[string]string{
+		"Tampered":     forged,
+		"None":         unsigned,
+		"UnknownKey":   signES256(t, key, map[string]interface{}{"alg": "ES256", "kid": "other"}, valid()),
+		"WrongKeyType": signES256(t, key, map[string]interface{}{"alg": "RS256", "kid": "ec"}, valid()),
+		"WrongAzp":     signES256(t, key, header, wrongAzp),
+		"OtherIssuer":  signES256(t, key, header, otherIssuer),
+		"Malformed":    "not-a-token",
+	}
+	for name, token := range tests {
+		if _, err := p.verify(token, d); err == nil {
+			t.Errorf("%s: expected the token to be rejected", name)
+		} else if !strings.Contains(err.Error(), "login failed") {
+			t.Errorf("%s: expected ErrLoginFailed, got %v", name, err)
+		}
+	}
+}
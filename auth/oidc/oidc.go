@@ -0,0 +1,447 @@
+// Package oidc provides goxpress handlers for signing users in with an
+// OpenID Connect provider, such as Google, Microsoft Entra ID, Okta,
+// Auth0 or Keycloak, using the authorization code flow with PKCE.
+//
+// The provider's endpoints and signing keys are discovered from the
+// issuer. Login redirects the browser to the provider, Callback verifies
+// the returned ID token and stores the signed-in Identity in the goxpress
+// session, and RequireLogin protects routes. The goxpress Sessions
+// middleware must be installed:
+//
+//	auth := oidc.New(oidc.Config{
+//		Issuer:       "https://accounts.google.com",
+//		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+//		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+//		RedirectURL:  "https://app.example.com/auth/callback",
+//	})
+//
+//	app.Use(goxpress.Sessions(store))
+//	app.GET("/auth/login", auth.Login())
+//	app.GET("/auth/callback", auth.Callback())
+//	app.POST("/auth/logout", auth.Logout())
+//
+//	account := app.Route("/account")
+//	account.Use(auth.RequireLogin())
+//	account.GET("", func(c *goxpress.Context) {
+//		identity, _ := oidc.CurrentIdentity(c)
+//		c.String(200, "Hello "+identity.Name)
+//	})
+//
+// The package only depends on the standard library.
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minorcell/goxpress"
+)
+
+// Errors passed to the error handlers when a login cannot be completed.
+// They are goxpress.HTTPErrors, so by default they produce the matching
+// response.
+var (
+	ErrInvalidState = goxpress.NewHTTPError(http.StatusBadRequest, "invalid or expired login state")
+	ErrLoginFailed  = goxpress.NewHTTPError(http.StatusUnauthorized, "login failed")
+	ErrProvider     = goxpress.NewHTTPError(http.StatusBadGateway, "identity provider unavailable")
+)
+
+// Session keys used by the handlers.
+const (
+	identityKey = "oidc.identity" // JSON-encoded Identity of the signed-in user
+	loginKey    = "oidc.login"    // JSON-encoded pendingLogin between Login and Callback
+)
+
+// Config defines configuration options for a Provider.
+type Config struct {
+	// Issuer is the URL identifying the provider, e.g.
+	// "https://accounts.google.com". The provider's configuration is
+	// discovered from Issuer + "/.well-known/openid-configuration". Required.
+	Issuer string
+
+	// ClientID and ClientSecret are the credentials of the application
+	// registered with the provider. ClientID is required; ClientSecret may
+	// be empty for public clients, which rely on PKCE alone.
+	ClientID     string
+	ClientSecret string
+
+	// RedirectURL is the absolute URL of the Callback route, as registered
+	// with the provider. Required.
+	RedirectURL string
+
+	// Scopes requested in addition to "openid".
+	// If nil, defaults to "profile" and "email".
+	Scopes []string
+
+	// LoginPath is the path of the Login route RequireLogin redirects to.
+	// If empty, defaults to "/auth/login".
+	LoginPath string
+
+	// DefaultRedirect is where users land after signing in or out when no
+	// other page was requested. If empty, defaults to "/".
+	DefaultRedirect string
+
+	// HTTPClient is used to reach the provider.
+	// If nil, a client with a 10 second timeout is used.
+	HTTPClient *http.Client
+}
+
+// Identity describes a signed-in user, as asserted by the provider's ID
+// token.
+type Identity struct {
+	Subject       string                 `json:"sub"` // Stable identifier of the user at the provider
+	Issuer        string                 `json:"iss"` // Provider that signed the user in
+	Email         string                 `json:"email,omitempty"`
+	EmailVerified bool                   `json:"email_verified,omitempty"`
+	Name          string                 `json:"name,omitempty"`
+	Picture       string                 `json:"picture,omitempty"`
+	Claims        map[string]interface{} `json:"claims"`   // All claims of the ID token
+	LoginAt       time.Time              `json:"login_at"` // Time the user signed in
+}
+
+// CurrentIdentity returns the identity of the signed-in user stored in
+// the session by Callback.
+//
+// Example:
+//
+//	if identity, ok := oidc.CurrentIdentity(c); ok {
+//		c.Set("user", identity.Subject)
+//	}
+func CurrentIdentity(c *goxpress.Context) (*Identity, bool) {
+	data, ok := c.Session().Get(identityKey).(string)
+	if !ok {
+		return nil, false
+	}
+	var identity Identity
+	if err := json.Unmarshal([]byte(data), &identity); err != nil {
+		return nil, false
+	}
+	return &identity, true
+}
+
+// pendingLogin holds the secrets of a login between Login and Callback.
+type pendingLogin struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"` // PKCE code verifier
+	ReturnTo string `json:"return_to"`
+}
+
+// Provider signs users in with an OpenID Connect provider. It is safe for
+// concurrent use.
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discovery // Discovered configuration, nil until fetched
+	keys      *keySet
+}
+
+// discovery holds the fields of the provider configuration used by the
+// authorization code flow.
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// New returns a Provider with the given configuration. The provider's
+// configuration is discovered on first use. It panics if Issuer,
+// ClientID or RedirectURL is empty.
+func New(config Config) *Provider {
+	if config.Issuer == "" || config.ClientID == "" || config.RedirectURL == "" {
+		panic("goxpress: oidc.Config requires Issuer, ClientID and RedirectURL")
+	}
+
+	// Set defaults
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	if config.Scopes == nil {
+		config.Scopes = []string{"profile", "email"}
+	}
+	if config.LoginPath == "" {
+		config.LoginPath = "/auth/login"
+	}
+	if config.DefaultRedirect == "" {
+		config.DefaultRedirect = "/"
+	}
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &Provider{config: config, client: client}
+}
+
+// Login returns a handler redirecting the browser to the provider's
+// sign-in page. The page to return to afterwards may be passed in the
+// return_to query parameter; only paths on the same site are accepted.
+func (p *Provider) Login() goxpress.HandlerFunc {
+	return func(c *goxpress.Context) {
+		d, err := p.discover()
+		if err != nil {
+			c.Next(err)
+			return
+		}
+
+		login := pendingLogin{
+			State:    randomString(),
+			Nonce:    randomString(),
+			Verifier: randomString(),
+			ReturnTo: p.safeReturnTo(c.Query("return_to")),
+		}
+		data, _ := json.Marshal(login)
+		c.Session().Set(loginKey, string(data))
+
+		challenge := sha256.Sum256([]byte(login.Verifier))
+		query := url.Values{
+			"response_type":         {"code"},
+			"client_id":             {p.config.ClientID},
+			"redirect_uri":          {p.config.RedirectURL},
+			"scope":                 {strings.Join(append([]string{"openid"}, p.config.Scopes...), " ")},
+			"state":                 {login.State},
+			"nonce":                 {login.Nonce},
+			"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+			"code_challenge_method": {"S256"},
+		}
+		separator := "?"
+		if strings.Contains(d.AuthorizationEndpoint, "?") {
+			separator = "&"
+		}
+		c.Redirect(http.StatusFound, d.AuthorizationEndpoint+separator+query.Encode())
+	}
+}
+
+// Callback returns the handler of the RedirectURL route. It checks the
+// state, exchanges the authorization code for tokens, verifies the ID
+// token and stores the Identity in a regenerated session before
+// redirecting to the page the login started from. Failures are passed to
+// the error handlers as ErrInvalidState, ErrLoginFailed or ErrProvider.
+func (p *Provider) Callback() goxpress.HandlerFunc {
+	return func(c *goxpress.Context) {
+		session := c.Session()
+		data, _ := session.Get(loginKey).(string)
+		session.Delete(loginKey)
+
+		var login pendingLogin
+		if err := json.Unmarshal([]byte(data), &login); err != nil || login.State == "" || c.Query("state") != login.State {
+			c.Next(ErrInvalidState)
+			return
+		}
+		if reason := c.Query("error"); reason != "" {
+			description := c.Query("error_description")
+			c.Next(goxpress.NewHTTPError(http.StatusUnauthorized, ErrLoginFailed.Message,
+				goxpress.WithInternal(fmt.Errorf("oidc: provider returned %s: %s", reason, description))))
+			return
+		}
+
+		claims, err := p.exchange(c, c.Query("code"), login)
+		if err != nil {
+			c.Next(err)
+			return
+		}
+
+		identity := Identity{Claims: claims, LoginAt: time.Now()}
+		identity.Subject, _ = claims["sub"].(string)
+		identity.Issuer, _ = claims["iss"].(string)
+		identity.Email, _ = claims["email"].(string)
+		identity.EmailVerified, _ = claims["email_verified"].(bool)
+		identity.Name, _ = claims["name"].(string)
+		identity.Picture, _ = claims["picture"].(string)
+		encoded, _ := json.Marshal(identity)
+
+		// A new session ID prevents session fixation
+		session.Regenerate()
+		session.Set(identityKey, string(encoded))
+
+		returnTo := login.ReturnTo
+		if returnTo == "" {
+			returnTo = p.config.DefaultRedirect
+		}
+		c.Redirect(http.StatusFound, returnTo)
+	}
+}
+
+// Logout returns a handler removing the identity from the session and
+// redirecting to DefaultRedirect. It does not sign the user out of the
+// provider itself. Register it for POST so other sites cannot sign users
+// out with a link.
+func (p *Provider) Logout() goxpress.HandlerFunc {
+	return func(c *goxpress.Context) {
+		session := c.Session()
+		session.Delete(identityKey)
+		session.Regenerate()
+		c.Redirect(http.StatusSeeOther, p.config.DefaultRedirect)
+	}
+}
+
+// RequireLogin returns a middleware letting only signed-in users through.
+// Browsers navigating to a page are redirected to LoginPath and brought
+// back afterwards; other requests, such as API calls, are aborted with
+// goxpress.ErrUnauthorized.
+func (p *Provider) RequireLogin() goxpress.HandlerFunc {
+	return func(c *goxpress.Context) {
+		if _, ok := CurrentIdentity(c); ok {
+			c.Next()
+			return
+		}
+
+		c.Abort()
+		if (c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead) &&
+			strings.Contains(c.Request.Header.Get("Accept"), goxpress.MIMEHTML) {
+			c.Redirect(http.StatusFound, p.config.LoginPath+"?return_to="+url.QueryEscape(c.Request.URL.RequestURI()))
+			return
+		}
+		c.Error(goxpress.ErrUnauthorized)
+	}
+}
+
+// safeReturnTo returns returnTo if it is a path on this site, and an
+// empty string otherwise, so logins can't redirect to other sites.
+// Control characters and backslashes are rejected, as browsers strip or
+// rewrite them and could resolve the redirect to another host.
+func (p *Provider) safeReturnTo(returnTo string) string {
+	for i := 0; i < len(returnTo); i++ {
+		if b := returnTo[i]; b < 0x20 || b == 0x7f || b == '\\' {
+			return ""
+		}
+	}
+	u, err := url.Parse(returnTo)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.User != nil ||
+		!strings.HasPrefix(u.Path, "/") || strings.HasPrefix(u.Path, "//") {
+		return ""
+	}
+	return returnTo
+}
+
+// tokenResponse holds the fields of a token endpoint response used to
+// sign the user in.
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// exchange redeems an authorization code at the token endpoint and
+// returns the claims of the verified ID token.
+func (p *Provider) exchange(c *goxpress.Context, code string, login pendingLogin) (map[string]interface{}, error) {
+	if code == "" {
+		return nil, ErrInvalidState
+	}
+	d, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {login.Verifier},
+	}
+	if p.config.ClientSecret == "" {
+		form.Set("client_id", p.config.ClientID)
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, providerError(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, providerError(err)
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&token); err != nil {
+		return nil, providerError(fmt.Errorf("oidc: invalid token response with status %d: %v", resp.StatusCode, err))
+	}
+	if resp.StatusCode != http.StatusOK || token.IDToken == "" {
+		return nil, loginError(fmt.Errorf("oidc: token request failed with status %d: %s %s", resp.StatusCode, token.Error, token.ErrorDescription))
+	}
+
+	claims, err := p.verify(token.IDToken, d)
+	if err != nil {
+		return nil, err
+	}
+	if nonce, _ := claims["nonce"].(string); nonce != login.Nonce {
+		return nil, loginError(fmt.Errorf("oidc: ID token nonce does not match"))
+	}
+	return claims, nil
+}
+
+// discover returns the provider configuration, fetching it on first use.
+// Failed attempts are retried on the next call.
+func (p *Provider) discover() (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var d discovery
+	if err := p.getJSON(p.config.Issuer+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, providerError(err)
+	}
+	if strings.TrimSuffix(d.Issuer, "/") != p.config.Issuer {
+		return nil, providerError(fmt.Errorf("oidc: discovered issuer %q does not match %q", d.Issuer, p.config.Issuer))
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, providerError(fmt.Errorf("oidc: incomplete provider configuration at %s", p.config.Issuer))
+	}
+	p.discovery = &d
+	p.keys = &keySet{uri: d.JWKSURI, provider: p}
+	return p.discovery, nil
+}
+
+// getJSON fetches a JSON document from the provider.
+func (p *Provider) getJSON(target string, v interface{}) error {
+	resp, err := p.client.Get(target)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("oidc: GET %s returned status %d: %s", target, resp.StatusCode, body)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// providerError wraps a failure to reach the provider in ErrProvider.
+func providerError(err error) error {
+	return goxpress.NewHTTPError(ErrProvider.Code, ErrProvider.Message, goxpress.WithInternal(err))
+}
+
+// loginError wraps a rejected login in ErrLoginFailed.
+func loginError(err error) error {
+	return goxpress.NewHTTPError(ErrLoginFailed.Code, ErrLoginFailed.Message, goxpress.WithInternal(err))
+}
+
+// randomString returns a random, URL-safe string suitable as state,
+// nonce or PKCE code verifier.
+func randomString() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic("goxpress: cannot generate random value: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,242 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minorcell/goxpress"
+)
+
+// fakeProvider is an OpenID Connect provider issuing RS256 ID tokens.
+type fakeProvider struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	challenge string                 // PKCE challenge of the pending authorization
+	claims    map[string]interface{} // Claims of the next ID token
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &fakeProvider{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"jwks_uri":               p.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "k1", "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if id != "app" || secret != "s3cret" || r.PostFormValue("code") != "good-code" ||
+			base64.RawURLEncoding.EncodeToString(verifier[:]) != p.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": p.sign(t, p.claims)})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// sign returns an RS256 token with the given claims.
+func (p *fakeProvider) sign(t *testing.T, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k1", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// browser sends requests to app with a cookie jar.
+type browser struct {
+	app     *goxpress.Engine
+	cookies map[string]*http.Cookie
+}
+
+func (b *browser) get(target string, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", target, nil)
+	req.Header.Set("Accept", accept)
+	for _, cookie := range b.cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	b.app.ServeHTTP(w, req)
+	for _, cookie := range w.Result().Cookies() {
+		b.cookies[cookie.Name] = cookie
+	}
+	return w
+}
+
+func newTestApp(provider *fakeProvider) (*goxpress.Engine, *Provider) {
+	auth := New(Config{
+		Issuer:       provider.URL,
+		ClientID:     "app",
+		ClientSecret: "s3cret",
+		RedirectURL:  "https://app.example.com/auth/callback",
+	})
+
+	app := goxpress.New()
+	app.Use(goxpress.Sessions(goxpress.NewMemorySessionStore()))
+	app.GET("/auth/login", auth.Login())
+	app.GET("/auth/callback", auth.Callback())
+	app.POST("/auth/logout", auth.Logout())
+	app.GET("/account", auth.RequireLogin(), func(c *goxpress.Context) {
+		identity, _ := CurrentIdentity(c)
+		c.String(200, "Hello "+identity.Name+" <"+identity.Email+">")
+	})
+	return app, auth
+}
+
+func TestLoginFlow(t *testing.T) {
+	provider := newFakeProvider(t)
+	app, _ := newTestApp(provider)
+	b := &browser{app: app, cookies: make(map[string]*http.Cookie)}
+	const html = "text/html,application/xhtml+xml"
+
+	// Unauthenticated requests are sent to the login page or rejected
+	w := b.get("/account?tab=1", html)
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/auth/login?return_to=%2Faccount%3Ftab%3D1" {
+		t.Fatalf("Expected redirect to login, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	if w := b.get("/account", "application/json"); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for API requests, got %d", w.Code)
+	}
+
+	// Login redirects to the provider with state, nonce and PKCE challenge
+	w = b.get("/auth/login?return_to=%2Faccount%3Ftab%3D1", html)
+	location, err := url.Parse(w.Header().Get("Location"))
+	if w.Code != http.StatusFound || err != nil || !strings.HasPrefix(location.String(), provider.URL+"/authorize?") {
+		t.Fatalf("Expected redirect to the provider, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	query := location.Query()
+	if query.Get("client_id") != "app" || query.Get("scope") != "openid profile email" ||
+		query.Get("code_challenge_method") != "S256" || query.Get("state") == "" {
+		t.Fatalf("Unexpected authorization request %v", query)
+	}
+	provider.challenge = query.Get("code_challenge")
+	provider.claims = map[string]interface{}{
+		"iss": provider.URL, "aud": "app", "sub": "u-42", "nonce": query.Get("nonce"),
+		"exp": time.Now().Add(time.Hour).Unix(), "name": "Ada", "email": "ada@example.com",
+	}
+	sessionBefore := b.cookies["goxpress_session"].Value
+
+	// A callback with another state is rejected
+	if w := b.get("/auth/callback?code=good-code&state=forged", html); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a forged state, got %d", w.Code)
+	}
+
+	// The pending login was consumed, so start again
+	w = b.get("/auth/login?return_to=%2Faccount%3Ftab%3D1", html)
+	location, _ = url.Parse(w.Header().Get("Location"))
+	query = location.Query()
+	provider.challenge = query.Get("code_challenge")
+	provider.claims["nonce"] = query.Get("nonce")
+
+	w = b.get("/auth/callback?code=good-code&state="+url.QueryEscape(query.Get("state")), html)
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/account?tab=1" {
+		t.Fatalf("Expected redirect back to the account, got %d %q %q", w.Code, w.Header().Get("Location"), w.Body.String())
+	}
+	if b.cookies["goxpress_session"].Value == sessionBefore {
+		t.Error("Expected the session to be regenerated after login")
+	}
+
+	w = b.get("/account", html)
+	if w.Code != 200 || w.Body.String() != "Hello Ada <ada@example.com>" {
+		t.Errorf("Expected the signed-in page, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestCallbackRejections(t *testing.T) {
+	provider := newFakeProvider(t)
+	app, _ := newTestApp(provider)
+
+	tests := []struct {
+		name   string
+		code   string
+		claims func(nonce string) map[string]interface{}
+		status int
+	}{
+		{"BadCode", "bad-code", nil, http.StatusUnauthorized},
+		{"WrongNonce", "good-code", func(nonce string) map[string]interface{} {
+			return map[string]interface{}{"iss": provider.URL, "aud": "app", "sub": "u", "nonce": "other", "exp": time.Now().Add(time.Hour).Unix()}
+		}, http.StatusUnauthorized},
+		{"WrongAudience", "good-code", func(nonce string) map[string]interface{} {
+			return map[string]interface{}{"iss": provider.URL, "aud": "other", "sub": "u", "nonce": nonce, "exp": time.Now().Add(time.Hour).Unix()}
+		}, http.StatusUnauthorized},
+		{"Expired", "good-code", func(nonce string) map[string]interface{} {
+			return map[string]interface{}{"iss": provider.URL, "aud": "app", "sub": "u", "nonce": nonce, "exp": time.Now().Add(-time.Hour).Unix()}
+		}, http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := &browser{app: app, cookies: make(map[string]*http.Cookie)}
+			location, _ := url.Parse(b.get("/auth/login", "text/html").Header().Get("Location"))
+			query := location.Query()
+			provider.challenge = query.Get("code_challenge")
+			if test.claims != nil {
+				provider.claims = test.claims(query.Get("nonce"))
+			}
+
+			w := b.get("/auth/callback?code="+test.code+"&state="+url.QueryEscape(query.Get("state")), "text/html")
+			if w.Code != test.status {
+				t.Errorf("Expected %d, got %d %q", test.status, w.Code, w.Body.String())
+			}
+			if w := b.get("/account", "application/json"); w.Code != http.StatusUnauthorized {
+				t.Errorf("Expected no identity after a rejected login, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestSafeReturnTo(t *testing.T) {
+	p := New(Config{Issuer: "https://id.example.com", ClientID: "app", RedirectURL: "https://app.example.com/cb"})
+	for returnTo, want := range map[string]string{
+		"/account?tab=1":       "/account?tab=1",
+		"https://evil.example": "",
+		"//evil.example/path":  "",
+		"/\\evil.example":      "",
+		"javascript:alert(1)":  "",
+		"/\t/evil.example":     "",
+		"/\n/evil.example":     "",
+		"/\r/evil.example":     "",
+		"/%09/evil.example":    "/%09/evil.example",
+		"":                     "",
+		"account":              "",
+	} {
+		if got := p.safeReturnTo(returnTo); got != want {
+			t.Errorf("Expected %q for %q, got %q", want, returnTo, got)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains bandwidth throttling of response bodies.
+package goxpress
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxThrottledChunk is the largest number of bytes a throttled writer
+// sends at once, which keeps the transfer rate smooth.
+const maxThrottledChunk = 32 << 10
+
+// BandwidthConfig defines configuration options for the bandwidth
+// limiting middleware.
+type BandwidthConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper Skipper
+
+	// Rate is the number of response body bytes per second. Required.
+	Rate int64
+
+	// Burst is the number of bytes that may be sent at once before the
+	// transfer is slowed down to Rate. If zero, defaults to Rate.
+	Burst int64
+
+	// Shared makes all responses of the middleware share one budget, which
+	// caps their total bandwidth instead of the bandwidth of each response.
+	Shared bool
+}
+
+// BandwidthLimit returns a middleware limiting the response body of each
+// request to bytesPerSec bytes per second, so large downloads don't
+// starve other traffic. It is meant for the routes serving them.
+//
+// Example:
+//
+//	app.GET("/downloads/*file", goxpress.BandwidthLimit(512<<10), func(c *goxpress.Context) {
+//		c.File(filepath.Join("downloads", filepath.Clean("/"+c.Param("file"))))
+//	})
+func BandwidthLimit(bytesPerSec int64) HandlerFunc {
+	return BandwidthLimitWithConfig(BandwidthConfig{Rate: bytesPerSec})
+}
+
+// BandwidthLimitWithConfig returns a bandwidth limiting middleware with
+// custom configuration. Writes wait until the budget allows them, and
+// stop with an error once the client goes away. It panics if config.Rate
+// is not positive.
+//
+// Example:
+//
+//	// At most 10 MB/s for all downloads together
+//	downloads := app.Group("/downloads")
+//	downloads.Use(goxpress.BandwidthLimitWithConfig(goxpress.BandwidthConfig{
+//		Rate:   10 << 20,
+//		Shared: true,
+//	}))
+func BandwidthLimitWithConfig(config BandwidthConfig) HandlerFunc {
+	if config.Rate <= 0 {
+		panic("goxpress: BandwidthConfig.Rate must be positive")
+	}
+
+	// Set defaults
+	if config.Burst <= 0 {
+		config.Burst = config.Rate
+	}
+
+	var shared *byteBucket
+	if config.Shared {
+		shared = newByteBucket(config.Rate, config.Burst)
+	}
+
+	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		bucket := shared
+		if bucket == nil {
+			bucket = newByteBucket(config.Rate, config.Burst)
+		}
+
+		// Throttle below c.Writer, so writes through c.Response and
+		// c.Writer are both paced
+		original := c.writer.ResponseWriter
+		c.writer.ResponseWriter = &throttledResponseWriter{
+			ResponseWriter: original,
+			throttled:      newThrottledWriter(c, original, bucket),
+		}
+		defer func() {
+			c.writer.ResponseWriter = original
+		}()
+
+		c.Next()
+	}
+}
+
+// ThrottledWriter returns a writer sending to the response at most
+// bytesPerSec bytes per second, with bursts of up to one second worth of
+// bytes. Writes wait until the budget allows them, and stop with an error
+// once the client goes away. It panics if bytesPerSec is not positive.
+//
+// Example:
+//
+//	app.GET("/export", func(c *goxpress.Context) {
+//		c.Response.Header().Set("Content-Type", "text/csv")
+//		io.Copy(c.ThrottledWriter(256<<10), export)
+//	})
+func (c *Context) ThrottledWriter(bytesPerSec int64) io.Writer {
+	if bytesPerSec <= 0 {
+		panic("goxpress: ThrottledWriter rate must be positive")
+	}
+	return newThrottledWriter(c, c.Response, newByteBucket(bytesPerSec, bytesPerSec))
+}
+
+// byteBucket is a token bucket of bytes. Reservations may overdraw it, so
+// concurrent writers sharing a bucket queue up fairly.
+type byteBucket struct {
+	mu     sync.Mutex
+	rate   float64   // Bytes added per second
+	burst  float64   // Maximum number of bytes available
+	tokens float64   // Bytes available at time last, negative when overdrawn
+	last   time.Time // Time tokens were last refilled
+}
+
+// newByteBucket returns a full bucket.
+func newByteBucket(rate, burst int64) *byteBucket {
+	return &byteBucket{
+		rate:   float64(rate),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve takes n bytes from the bucket and returns how long to wait
+// before sending them.
+func (b *byteBucket) reserve(n int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// refund returns n reserved bytes that were not sent to the bucket, so
+// writers that give up waiting don't hold back the ones sharing it.
+func (b *byteBucket) refund(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(b.burst, b.tokens+float64(n))
+}
+
+// throttledWriter writes to w in chunks paced by a byteBucket.
+type throttledWriter struct {
+	w      io.Writer
+	bucket *byteBucket
+	ctx    context.Context // Stops waiting once done
+	chunk  int             // Largest number of bytes written at once
+}
+
+// newThrottledWriter returns a writer to w paced by bucket, stopping
+// once ctx is done.
+func newThrottledWriter(ctx context.Context, w io.Writer, bucket *byteBucket) *throttledWriter {
+	chunk := maxThrottledChunk
+	if burst := int(bucket.burst); burst < chunk {
+		chunk = burst
+	}
+	if chunk < 1 {
+		chunk = 1
+	}
+	return &throttledWriter{w: w, bucket: bucket, ctx: ctx, chunk: chunk}
+}
+
+// Write implements io.Writer.
+func (t *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := len(p)
+		if n > t.chunk {
+			n = t.chunk
+		}
+
+		if wait := t.bucket.reserve(n); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-t.ctx.Done():
+				timer.Stop()
+				t.bucket.refund(n)
+				return written, t.ctx.Err()
+			}
+		}
+
+		m, err := t.w.Write(p[:n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// throttledResponseWriter paces the response body written through it.
+type throttledResponseWriter struct {
+	http.ResponseWriter
+	throttled *throttledWriter
+}
+
+// Write sends data at the configured rate.
+func (w *throttledResponseWriter) Write(data []byte) (int, error) {
+	return w.throttled.Write(data)
+}
+
+// Flush passes flushes through to the underlying ResponseWriter.
+func (w *throttledResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Push passes HTTP/2 server pushes through to the underlying
+// ResponseWriter.
+func (w *throttledResponseWriter) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := w.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Hijack passes connection takeovers, such as WebSocket upgrades,
+// through to the underlying ResponseWriter.
+func (w *throttledResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(w.ResponseWriter, nil)
+}
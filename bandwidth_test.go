@@ -0,0 +1,106 @@
+package goxpress
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBandwidthLimit(t *testing.T) {
+	app := New()
+	app.GET("/download", BandwidthLimitWithConfig(BandwidthConfig{Rate: 10000, Burst: 1000}), func(c *Context) {
+		c.String(200, strings.Repeat("x", 3000))
+	})
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/download", nil))
+	elapsed := time.Since(start)
+
+	if w.Code != 200 || w.Body.Len() != 3000 {
+		t.Fatalf("Expected full body, got %d with %d bytes", w.Code, w.Body.Len())
+	}
+	// The burst is sent at once, the remaining 2000 bytes take 200ms
+	if elapsed < 150*time.Millisecond {
+		t.Errorf("Expected the response to be throttled, took %v", elapsed)
+	}
+}
+
+func TestBandwidthLimitWriter(t *testing.T) {
+	app := New()
+	app.GET("/download", BandwidthLimitWithConfig(BandwidthConfig{Rate: 10000, Burst: 1000}), func(c *Context) {
+		c.Writer.Write([]byte(strings.Repeat("x", 3000)))
+	})
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/download", nil))
+	if w.Body.Len() != 3000 {
+		t.Fatalf("Expected full body, got %d bytes", w.Body.Len())
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Expected writes through c.Writer to be throttled, took %v", elapsed)
+	}
+}
+
+func TestThrottledWriterStopsWhenDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var buf bytes.Buffer
+	w := newThrottledWriter(ctx, &buf, newByteBucket(100, 100))
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	n, err := w.Write(make([]byte, 1000))
+	if err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if n != 100 || buf.Len() != 100 {
+		t.Errorf("Expected only the burst to be written, got %d", n)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Write should stop waiting once the context is done")
+	}
+}
+
+func TestThrottledWriterRefundsWhenDone(t *testing.T) {
+	bucket := newByteBucket(100, 100)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The first chunk uses up the burst, the second is refunded unsent
+	w := newThrottledWriter(ctx, io.Discard, bucket)
+	if _, err := w.Write(make([]byte, 200)); err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if wait := bucket.reserve(1); wait > 20*time.Millisecond {
+		t.Errorf("Expected the unsent bytes to be refunded, next write waits %v", wait)
+	}
+}
+
+func TestContextThrottledWriter(t *testing.T) {
+	app := New()
+	app.GET("/export", func(c *Context) {
+		c.ThrottledWriter(1 << 20).Write([]byte("id,name\n"))
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/export", nil))
+	if w.Body.String() != "id,name\n" {
+		t.Errorf("Unexpected body %q", w.Body.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic for a non-positive rate")
+		}
+	}()
+	(&Context{}).ThrottledWriter(0)
+}
@@ -238,10 +238,12 @@ func BenchmarkRouter_StaticRoutes(b *testing.B) {
 		router.GET(route, handler)
 	}
 
+	var params []param
 	b.ResetTimer()
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		router.getRoute("GET", "/api/v1/users")
+		params = params[:0]
+		router.findRoute("GET", "/api/v1/users", &params)
 	}
 }
 
@@ -264,10 +266,12 @@ func BenchmarkRouter_ParamRoutes(b *testing.B) {
 		router.GET(route, handler)
 	}
 
+	var params []param
 	b.ResetTimer()
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		router.getRoute("GET", "/users/123/posts/456/comments/789")
+		params = params[:0]
+		router.findRoute("GET", "/users/123/posts/456/comments/789", &params)
 	}
 }
 
@@ -280,10 +284,12 @@ func BenchmarkRouter_WildcardRoutes(b *testing.B) {
 	router.GET("/assets/*path", handler)
 	router.GET("/static/*filename", handler)
 
+	var params []param
 	b.ResetTimer()
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
-		router.getRoute("GET", "/files/images/avatars/user123.png")
+		params = params[:0]
+		router.findRoute("GET", "/files/images/avatars/user123.png", &params)
 	}
 }
 
@@ -313,11 +319,13 @@ func BenchmarkRouter_MixedRoutes(b *testing.B) {
 		"/files/css/style.css",
 	}
 
+	var params []param
 	b.ResetTimer()
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		path := testPaths[i%len(testPaths)]
-		router.getRoute("GET", path)
+		params = params[:0]
+		router.findRoute("GET", path, &params)
 	}
 }
 
@@ -326,11 +334,11 @@ func BenchmarkContext_Param(b *testing.B) {
 	req := httptest.NewRequest("GET", "/users/123", nil)
 	w := httptest.NewRecorder()
 	c := NewContext(w, req)
-	c.params = map[string]string{
-		"id":     "123",
-		"name":   "john",
-		"email":  "john@example.com",
-		"status": "active",
+	c.params = []param{
+		{key: "id", value: "123"},
+		{key: "name", value: "john"},
+		{key: "email", value: "john@example.com"},
+		{key: "status", value: "active"},
 	}
 
 	b.ResetTimer()
@@ -0,0 +1,402 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains request binding for query strings, forms and
+// serialization formats beyond JSON.
+package goxpress
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// Codec encodes and decodes values for a serialization format.
+// goxpress keeps third-party encoders out of its dependency tree; formats
+// such as Protocol Buffers are plugged in by assigning a Codec backed by
+// the library of your choice.
+//
+// Example:
+//
+//	type protoCodec struct{}
+//
+//	func (protoCodec) Marshal(v interface{}) ([]byte, error) {
+//		return proto.Marshal(v.(proto.Message))
+//	}
+//
+//	func (protoCodec) Unmarshal(data []byte, v interface{}) error {
+//		return proto.Unmarshal(data, v.(proto.Message))
+//	}
+//
+//	goxpress.ProtobufCodec = protoCodec{}
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// ProtobufCodec is the Codec used by BindProtobuf. The default codec
+// supports messages that implement Marshal() ([]byte, error) and
+// Unmarshal([]byte) error themselves, as generated by gogo/protobuf.
+var ProtobufCodec Codec = selfCodec{format: "protobuf"}
+
+// MsgPackCodec is the Codec used by BindMsgPack. The default codec
+// supports types that implement MarshalMsg and UnmarshalMsg, as generated
+// by tinylib/msgp. Assign a codec backed by a reflection-based library
+// such as vmihailenco/msgpack to bind arbitrary values.
+var MsgPackCodec Codec = msgpCodec{}
+
+// selfCodec is a Codec for values that know how to encode and decode
+// themselves through Marshal and Unmarshal methods.
+type selfCodec struct {
+	format string // Format name used in error messages
+}
+
+// Marshal encodes v using its own Marshal method.
+func (sc selfCodec) Marshal(v interface{}) ([]byte, error) {
+	if m, ok := v.(interface{ Marshal() ([]byte, error) }); ok {
+		return m.Marshal()
+	}
+	return nil, fmt.Errorf("goxpress: %T cannot be encoded as %s, configure a Codec", v, sc.format)
+}
+
+// Unmarshal decodes data into v using its own Unmarshal method.
+func (sc selfCodec) Unmarshal(data []byte, v interface{}) error {
+	if u, ok := v.(interface{ Unmarshal([]byte) error }); ok {
+		return u.Unmarshal(data)
+	}
+	return fmt.Errorf("goxpress: %T cannot be decoded as %s, configure a Codec", v, sc.format)
+}
+
+// msgpCodec is a Codec for types generated by tinylib/msgp.
+type msgpCodec struct{}
+
+// Marshal encodes v using its generated MarshalMsg method.
+func (msgpCodec) Marshal(v interface{}) ([]byte, error) {
+	if m, ok := v.(interface {
+		MarshalMsg([]byte) ([]byte, error)
+	}); ok {
+		return m.MarshalMsg(nil)
+	}
+	return nil, fmt.Errorf("goxpress: %T cannot be encoded as msgpack, configure a Codec", v)
+}
+
+// Unmarshal decodes data into v using its generated UnmarshalMsg method.
+func (msgpCodec) Unmarshal(data []byte, v interface{}) error {
+	if u, ok := v.(interface {
+		UnmarshalMsg([]byte) ([]byte, error)
+	}); ok {
+		_, err := u.UnmarshalMsg(data)
+		return err
+	}
+	return fmt.Errorf("goxpress: %T cannot be decoded as msgpack, configure a Codec", v)
+}
+
+// BindProtobuf reads the request body and decodes it as a Protocol Buffers
+// message into msg using ProtobufCodec. It is intended for services that
+// accept application/x-protobuf request bodies.
+//
+// Example:
+//
+//	var req pb.CreateUserRequest
+//	if err := c.BindProtobuf(&req); err != nil {
+//		c.String(400, "invalid protobuf body")
+//		return
+//	}
+func (c *Context) BindProtobuf(msg interface{}) error {
+	return c.bindWith(ProtobufCodec, msg)
+}
+
+// BindMsgPack reads the request body and decodes it as MessagePack
+// into obj using MsgPackCodec.
+//
+// Example:
+//
+//	var event Event
+//	if err := c.BindMsgPack(&event); err != nil {
+//		c.String(400, "invalid msgpack body")
+//		return
+//	}
+func (c *Context) BindMsgPack(obj interface{}) error {
+	return c.bindWith(MsgPackCodec, obj)
+}
+
+// rawBodyKey is the Context store key caching the request body read by
+// RawBody.
+const rawBodyKey = "goxpress.rawBody"
+
+// RawBody reads the whole request body and returns it. The body is cached,
+// and the request body is replaced with a reader over the cached bytes, so
+// RawBody can be called repeatedly and handlers can still bind the body,
+// e.g. after a middleware verified its signature.
+//
+// Example:
+//
+//	body, err := c.RawBody()
+//	if err != nil {
+//		c.String(400, "cannot read body")
+//		return
+//	}
+//	log.Printf("payload: %s", body)
+func (c *Context) RawBody() ([]byte, error) {
+	if body, ok := c.Get(rawBodyKey); ok {
+		return body.([]byte), nil
+	}
+	if c.Request.Body == nil {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.Request.Body.Close()
+	c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	c.Set(rawBodyKey, body)
+	return body, nil
+}
+
+// Validator is implemented by bound values that check their own
+// constraints. Typed handlers created with H call Validate after binding
+// the request. Returning ValidationErrors reports every invalid field
+// with a 422 Unprocessable Entity response.
+//
+// Example:
+//
+//	func (u User) Validate() error {
+//		if !strings.Contains(u.Email, "@") {
+//			return errors.New("email is invalid")
+//		}
+//		return nil
+//	}
+type Validator interface {
+	Validate() error
+}
+
+// validate calls Validate on obj, a pointer to a bound value, if it
+// implements Validator.
+func validate(obj interface{}) error {
+	if v, ok := obj.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// bindWith reads the whole request body and decodes it into obj
+// using the given codec.
+func (c *Context) bindWith(codec Codec, obj interface{}) error {
+	data, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+	return codec.Unmarshal(data, obj)
+}
+
+// TypeDecoder converts a single query or form value into a value of the
+// type it was registered for.
+type TypeDecoder func(value string) (interface{}, error)
+
+// typeDecoders holds the decoders registered with RegisterTypeDecoder.
+var typeDecoders = struct {
+	sync.RWMutex
+	m map[reflect.Type]TypeDecoder
+}{m: make(map[reflect.Type]TypeDecoder)}
+
+// RegisterTypeDecoder registers a decoder used by BindQuery and BindForm
+// for struct fields of the same type as sample. Registered decoders take
+// precedence over the built-in handling of primitive kinds and
+// encoding.TextUnmarshaler, which makes it possible to bind custom
+// layouts, decimals or enums.
+//
+// Decoders are usually registered during program initialization.
+//
+// Example:
+//
+//	goxpress.RegisterTypeDecoder(time.Time{}, func(value string) (interface{}, error) {
+//		return time.Parse("2006-01-02", value)
+//	})
+func RegisterTypeDecoder(sample interface{}, decoder TypeDecoder) {
+	typeDecoders.Lock()
+	defer typeDecoders.Unlock()
+	typeDecoders.m[reflect.TypeOf(sample)] = decoder
+}
+
+// lookupTypeDecoder returns the decoder registered for typ, if any.
+func lookupTypeDecoder(typ reflect.Type) (TypeDecoder, bool) {
+	typeDecoders.RLock()
+	defer typeDecoders.RUnlock()
+	decoder, ok := typeDecoders.m[typ]
+	return decoder, ok
+}
+
+// BindQuery binds the URL query parameters to the fields of the struct
+// pointed to by obj. Fields are matched by their `query` tag, falling back
+// to the field name; a tag of "-" skips the field. Slice fields collect
+// every value of a repeated parameter.
+//
+// Example:
+//
+//	// Request: "/search?q=golang&page=2&tag=web&tag=api"
+//	var params struct {
+//		Q    string   `query:"q"`
+//		Page int      `query:"page"`
+//		Tags []string `query:"tag"`
+//	}
+//	if err := c.BindQuery(&params); err != nil {
+//		c.String(400, err.Error())
+//		return
+//	}
+func (c *Context) BindQuery(obj interface{}) error {
+	return bindValues(obj, c.queryValues(), "query")
+}
+
+// BindForm binds URL-encoded or multipart form fields to the fields of the
+// struct pointed to by obj. Fields are matched by their `form` tag, falling
+// back to the field name; a tag of "-" skips the field.
+//
+// Example:
+//
+//	var signup struct {
+//		Name  string `form:"name"`
+//		Email string `form:"email"`
+//		Age   int    `form:"age"`
+//	}
+//	if err := c.BindForm(&signup); err != nil {
+//		c.String(400, err.Error())
+//		return
+//	}
+func (c *Context) BindForm(obj interface{}) error {
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil && err != http.ErrNotMultipart {
+		return err
+	}
+	return bindValues(obj, c.Request.Form, "form")
+}
+
+// bindValues maps values onto the fields of the struct pointed to by obj,
+// using tag to look up field names.
+func bindValues(obj interface{}, values url.Values, tag string) error {
+	ptr := reflect.ValueOf(obj)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() || ptr.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("goxpress: bind target must be a non-nil pointer to a struct, got %T", obj)
+	}
+	return bindStruct(ptr.Elem(), values, tag)
+}
+
+// bindStruct sets the fields of the struct value v from values,
+// descending into embedded structs.
+func bindStruct(v reflect.Value, values url.Values, tag string) error {
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue // Unexported field
+		}
+
+		name := field.Tag.Get(tag)
+		if name == "-" {
+			continue
+		}
+
+		fieldValue := v.Field(i)
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			if err := bindStruct(fieldValue, values, tag); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		inputs, ok := values[name]
+		if !ok || len(inputs) == 0 || !fieldValue.CanSet() {
+			continue
+		}
+
+		if err := bindField(fieldValue, inputs); err != nil {
+			return fmt.Errorf("goxpress: cannot bind %s %q to field %s: %v", tag, name, field.Name, err)
+		}
+	}
+	return nil
+}
+
+// bindField assigns inputs to a single struct field.
+func bindField(field reflect.Value, inputs []string) error {
+	if _, ok := lookupTypeDecoder(field.Type()); !ok && field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(field.Type(), len(inputs), len(inputs))
+		for i, input := range inputs {
+			if err := setValue(slice.Index(i), input); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setValue(field, inputs[0])
+}
+
+// setValue parses input into v according to v's type.
+func setValue(v reflect.Value, input string) error {
+	if decoder, ok := lookupTypeDecoder(v.Type()); ok {
+		decoded, err := decoder(input)
+		if err != nil {
+			return err
+		}
+		rv := reflect.ValueOf(decoded)
+		if !rv.IsValid() || !rv.Type().AssignableTo(v.Type()) {
+			return fmt.Errorf("decoder returned %T, want %s", decoded, v.Type())
+		}
+		v.Set(rv)
+		return nil
+	}
+
+	if v.Kind() == reflect.Ptr {
+		elem := reflect.New(v.Type().Elem())
+		if err := setValue(elem.Elem(), input); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	if v.CanAddr() {
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(input))
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(input)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(input)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(input, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(input, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(input, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s, register a TypeDecoder", v.Type())
+	}
+	return nil
+}
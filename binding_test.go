@@ -0,0 +1,274 @@
+package goxpress
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testMessage is a minimal self-encoding message used to exercise the
+// default codecs.
+type testMessage struct {
+	Payload string
+}
+
+func (m *testMessage) Marshal() ([]byte, error) {
+	return []byte(m.Payload), nil
+}
+
+func (m *testMessage) Unmarshal(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("empty message")
+	}
+	m.Payload = string(data)
+	return nil
+}
+
+func (m *testMessage) MarshalMsg(b []byte) ([]byte, error) {
+	return append(b, m.Payload...), nil
+}
+
+func (m *testMessage) UnmarshalMsg(data []byte) ([]byte, error) {
+	m.Payload = string(data)
+	return nil, nil
+}
+
+// upperCodec is a custom codec that upper-cases payloads on decode.
+type upperCodec struct{}
+
+func (upperCodec) Marshal(v interface{}) ([]byte, error) {
+	return []byte(v.(*testMessage).Payload), nil
+}
+
+func (upperCodec) Unmarshal(data []byte, v interface{}) error {
+	v.(*testMessage).Payload = strings.ToUpper(string(data))
+	return nil
+}
+
+func TestContextBindProtobuf(t *testing.T) {
+	t.Run("SelfDecoding", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("hello"))
+		req.Header.Set("Content-Type", "application/x-protobuf")
+		c := NewContext(httptest.NewRecorder(), req)
+
+		var msg testMessage
+		if err := c.BindProtobuf(&msg); err != nil {
+			t.Fatalf("BindProtobuf should not return error: %v", err)
+		}
+		if msg.Payload != "hello" {
+			t.Errorf("Expected payload 'hello', got '%s'", msg.Payload)
+		}
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("hello"))
+		c := NewContext(httptest.NewRecorder(), req)
+
+		var msg struct{}
+		if err := c.BindProtobuf(&msg); err == nil {
+			t.Error("BindProtobuf should fail for values without a codec")
+		}
+	})
+
+	t.Run("CustomCodec", func(t *testing.T) {
+		defer func(codec Codec) { ProtobufCodec = codec }(ProtobufCodec)
+		ProtobufCodec = upperCodec{}
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader("hello"))
+		c := NewContext(httptest.NewRecorder(), req)
+
+		var msg testMessage
+		if err := c.BindProtobuf(&msg); err != nil {
+			t.Fatalf("BindProtobuf should not return error: %v", err)
+		}
+		if msg.Payload != "HELLO" {
+			t.Errorf("Expected payload 'HELLO', got '%s'", msg.Payload)
+		}
+	})
+}
+
+func TestContextBindMsgPack(t *testing.T) {
+	t.Run("Generated", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("\x81\xa1a\x01"))
+		req.Header.Set("Content-Type", "application/msgpack")
+		c := NewContext(httptest.NewRecorder(), req)
+
+		var msg testMessage
+		if err := c.BindMsgPack(&msg); err != nil {
+			t.Fatalf("BindMsgPack should not return error: %v", err)
+		}
+		if msg.Payload != "\x81\xa1a\x01" {
+			t.Errorf("Unexpected payload %q", msg.Payload)
+		}
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("\x80"))
+		c := NewContext(httptest.NewRecorder(), req)
+
+		var obj map[string]interface{}
+		if err := c.BindMsgPack(&obj); err == nil {
+			t.Error("BindMsgPack should fail for values without a codec")
+		}
+	})
+
+	t.Run("CustomCodec", func(t *testing.T) {
+		defer func(codec Codec) { MsgPackCodec = codec }(MsgPackCodec)
+		MsgPackCodec = upperCodec{}
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader("abc"))
+		c := NewContext(httptest.NewRecorder(), req)
+
+		var msg testMessage
+		if err := c.BindMsgPack(&msg); err != nil {
+			t.Fatalf("BindMsgPack should not return error: %v", err)
+		}
+		if msg.Payload != "ABC" {
+			t.Errorf("Expected payload 'ABC', got '%s'", msg.Payload)
+		}
+	})
+}
+
+// level is an enum type bound through a registered TypeDecoder.
+type level int
+
+func TestContextBindQuery(t *testing.T) {
+	type Paging struct {
+		Page  int  `query:"page"`
+		Limit uint `query:"limit"`
+	}
+
+	var params struct {
+		Paging
+		Q       string   `query:"q"`
+		Tags    []string `query:"tag"`
+		Exact   bool     `query:"exact"`
+		Score   *float64 `query:"score"`
+		Ignored string   `query:"-"`
+		Name    string
+	}
+
+	req := httptest.NewRequest("GET", "/search?q=golang&page=2&limit=10&tag=web&tag=api&exact=true&score=1.5&Ignored=x&Name=john", nil)
+	c := NewContext(httptest.NewRecorder(), req)
+
+	if err := c.BindQuery(&params); err != nil {
+		t.Fatalf("BindQuery should not return error: %v", err)
+	}
+
+	if params.Q != "golang" || params.Page != 2 || params.Limit != 10 || !params.Exact {
+		t.Errorf("Unexpected scalar bindings: %+v", params)
+	}
+	if len(params.Tags) != 2 || params.Tags[0] != "web" || params.Tags[1] != "api" {
+		t.Errorf("Expected tags [web api], got %v", params.Tags)
+	}
+	if params.Score == nil || *params.Score != 1.5 {
+		t.Errorf("Expected score 1.5, got %v", params.Score)
+	}
+	if params.Ignored != "" {
+		t.Error("Fields tagged with '-' should be skipped")
+	}
+	if params.Name != "john" {
+		t.Errorf("Expected untagged field to bind by name, got '%s'", params.Name)
+	}
+
+	t.Run("InvalidValue", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/search?page=abc", nil)
+		c := NewContext(httptest.NewRecorder(), req)
+
+		var paging Paging
+		if err := c.BindQuery(&paging); err == nil {
+			t.Error("BindQuery should fail for non-numeric int field")
+		}
+	})
+
+	t.Run("InvalidTarget", func(t *testing.T) {
+		c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+		var target map[string]string
+		if err := c.BindQuery(&target); err == nil {
+			t.Error("BindQuery should fail for non-struct targets")
+		}
+	})
+}
+
+func TestContextBindForm(t *testing.T) {
+	form := url.Values{}
+	form.Add("name", "John Doe")
+	form.Add("age", "30")
+
+	req := httptest.NewRequest("POST", "/signup", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c := NewContext(httptest.NewRecorder(), req)
+
+	var signup struct {
+		Name string `form:"name"`
+		Age  int    `form:"age"`
+	}
+	if err := c.BindForm(&signup); err != nil {
+		t.Fatalf("BindForm should not return error: %v", err)
+	}
+
+	if signup.Name != "John Doe" || signup.Age != 30 {
+		t.Errorf("Unexpected form bindings: %+v", signup)
+	}
+}
+
+func TestRegisterTypeDecoder(t *testing.T) {
+	RegisterTypeDecoder(time.Time{}, func(value string) (interface{}, error) {
+		return time.Parse("2006-01-02", value)
+	})
+	RegisterTypeDecoder(level(0), func(value string) (interface{}, error) {
+		switch value {
+		case "low":
+			return level(1), nil
+		case "high":
+			return level(2), nil
+		}
+		return nil, errors.New("unknown level")
+	})
+
+	var filter struct {
+		Since  time.Time `query:"since"`
+		Levels []level   `query:"level"`
+	}
+
+	req := httptest.NewRequest("GET", "/events?since=2024-03-01&level=low&level=high", nil)
+	c := NewContext(httptest.NewRecorder(), req)
+
+	if err := c.BindQuery(&filter); err != nil {
+		t.Fatalf("BindQuery should not return error: %v", err)
+	}
+
+	if !filter.Since.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected date %v", filter.Since)
+	}
+	if len(filter.Levels) != 2 || filter.Levels[0] != 1 || filter.Levels[1] != 2 {
+		t.Errorf("Unexpected levels %v", filter.Levels)
+	}
+
+	req = httptest.NewRequest("GET", "/events?level=medium", nil)
+	c = NewContext(httptest.NewRecorder(), req)
+	if err := c.BindQuery(&filter); err == nil {
+		t.Error("BindQuery should propagate decoder errors")
+	}
+}
+
+func TestContextRawBody(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"john"}`))
+	c := NewContext(httptest.NewRecorder(), req)
+
+	for i := 0; i < 2; i++ {
+		body, err := c.RawBody()
+		if err != nil || string(body) != `{"name":"john"}` {
+			t.Errorf("Call %d: unexpected body %q, %v", i+1, body, err)
+		}
+	}
+
+	var user struct{ Name string }
+	if err := c.BindJSON(&user); err != nil || user.Name != "john" {
+		t.Errorf("Expected body to remain bindable, got %+v, %v", user, err)
+	}
+}
@@ -0,0 +1,185 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the body dump middleware.
+package goxpress
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// BodyDumpHandler receives the request and response bodies captured by
+// the body dump middleware. Either body is nil if it was empty or its
+// content type is not captured.
+type BodyDumpHandler func(c *Context, reqBody, resBody []byte)
+
+// BodyDumpConfig defines configuration options for the body dump middleware.
+type BodyDumpConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper Skipper
+
+	// Handler receives the captured bodies once the request has been
+	// handled. Required.
+	Handler BodyDumpHandler
+
+	// Limit is the maximum number of bytes captured from each body. Larger
+	// bodies are cut off at Limit; the request and response themselves are
+	// not affected. If zero, defaults to 64KB.
+	Limit int
+
+	// ContentTypes lists the media types whose bodies are captured, such
+	// as "application/json" or "text/*". Binary payloads like uploads and
+	// images are usually not worth keeping.
+	// If empty, defaults to text/*, application/json, application/xml and
+	// application/x-www-form-urlencoded.
+	ContentTypes []string
+}
+
+// BodyDump returns a middleware that captures the request and response
+// bodies and passes them to handler after the request has been handled,
+// for debugging and audit pipelines. It panics if handler is nil.
+//
+// Example:
+//
+//	app.Use(goxpress.BodyDump(func(c *goxpress.Context, reqBody, resBody []byte) {
+//		audit.Record(c.Request.Method, c.Request.URL.Path, c.StatusCode(), reqBody, resBody)
+//	}))
+func BodyDump(handler BodyDumpHandler) HandlerFunc {
+	return BodyDumpWithConfig(BodyDumpConfig{Handler: handler})
+}
+
+// BodyDumpWithConfig returns a body dump middleware with custom
+// configuration. It panics if config.Handler is nil.
+//
+// Example:
+//
+//	app.Use(goxpress.BodyDumpWithConfig(goxpress.BodyDumpConfig{
+//		Handler:      logBodies,
+//		Limit:        4 << 10,
+//		ContentTypes: []string{"application/json"},
+//	}))
+func BodyDumpWithConfig(config BodyDumpConfig) HandlerFunc {
+	if config.Handler == nil {
+		panic("goxpress: BodyDumpConfig.Handler is required")
+	}
+
+	// Set defaults
+	if config.Limit <= 0 {
+		config.Limit = 64 << 10
+	}
+	if len(config.ContentTypes) == 0 {
+		config.ContentTypes = []string{
+			"text/*",
+			"application/json",
+			"application/xml",
+			"application/x-www-form-urlencoded",
+		}
+	}
+
+	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		var reqBody []byte
+		req := c.Request
+		if req.Body != nil && req.Body != http.NoBody &&
+			matchContentType(req.Header.Get("Content-Type"), config.ContentTypes) {
+			// Read the captured part up front and put it back in front of
+			// the rest, so handlers see the whole body
+			reqBody, _ = ioutil.ReadAll(io.LimitReader(req.Body, int64(config.Limit)))
+			req.Body = &multiReadCloser{
+				Reader: io.MultiReader(bytes.NewReader(reqBody), req.Body),
+				Closer: req.Body,
+			}
+		}
+
+		original := c.Response
+		dw := &bodyDumpWriter{ResponseWriter: original, config: &config}
+		c.Response = dw
+		defer func() { c.Response = original }()
+
+		c.Next()
+
+		if len(reqBody) == 0 {
+			reqBody = nil
+		}
+		config.Handler(c, reqBody, dw.body)
+	}
+}
+
+// multiReadCloser reads from Reader and closes Closer.
+type multiReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyDumpWriter copies the beginning of the response body aside.
+type bodyDumpWriter struct {
+	http.ResponseWriter
+	config  *BodyDumpConfig
+	body    []byte
+	decided bool // Whether capture was decided at the first write
+	capture bool // Whether the response body is captured
+}
+
+// Write writes data, capturing it up to the limit.
+func (w *bodyDumpWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.decided = true
+		contentType := w.Header().Get("Content-Type")
+		if contentType == "" {
+			contentType = http.DetectContentType(data)
+		}
+		w.capture = matchContentType(contentType, w.config.ContentTypes)
+	}
+
+	if room := w.config.Limit - len(w.body); w.capture && room > 0 {
+		if len(data) < room {
+			room = len(data)
+		}
+		w.body = append(w.body, data[:room]...)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush passes flushes through to the underlying ResponseWriter.
+func (w *bodyDumpWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack passes connection takeovers through to the underlying
+// ResponseWriter.
+func (w *bodyDumpWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(w.ResponseWriter, nil)
+}
+
+// matchContentType reports whether the media type of contentType matches
+// one of patterns, which may end in a "/*" wildcard.
+func matchContentType(contentType string, patterns []string) bool {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if contentType == "" {
+		return false
+	}
+
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if pattern == contentType || pattern == "*/*" {
+			return true
+		}
+		if strings.HasSuffix(pattern, "/*") && strings.HasPrefix(contentType, pattern[:len(pattern)-1]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package goxpress
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBodyDump(t *testing.T) {
+	var dumpedReq, dumpedRes []byte
+	var calls int
+
+	app := New()
+	app.Use(BodyDumpWithConfig(BodyDumpConfig{
+		Limit: 8,
+		Handler: func(c *Context, reqBody, resBody []byte) {
+			calls++
+			dumpedReq, dumpedRes = reqBody, resBody
+		},
+	}))
+	app.POST("/echo", func(c *Context) {
+		body, _ := ioutil.ReadAll(c.Request.Body)
+		c.String(200, "echo: %s", body)
+	})
+	app.POST("/image", func(c *Context) {
+		c.Header("Content-Type", "image/png")
+		c.Response.Write([]byte("\x89PNG"))
+	})
+
+	t.Run("Captured", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/echo", strings.NewReader(`{"name":"gopher"}`))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		if w.Body.String() != `echo: {"name":"gopher"}` {
+			t.Errorf("Expected handler to read the whole body, got %q", w.Body.String())
+		}
+		if string(dumpedReq) != `{"name":` || string(dumpedRes) != "echo: {\"" {
+			t.Errorf("Expected bodies capped at 8 bytes, got %q and %q", dumpedReq, dumpedRes)
+		}
+	})
+
+	t.Run("Filtered", func(t *testing.T) {
+		calls = 0
+		req := httptest.NewRequest("POST", "/image", strings.NewReader("raw"))
+		req.Header.Set("Content-Type", "application/octet-stream")
+		app.ServeHTTP(httptest.NewRecorder(), req)
+
+		if calls != 1 || dumpedReq != nil || dumpedRes != nil {
+			t.Errorf("Expected handler to run without bodies, got %d calls, %q and %q", calls, dumpedReq, dumpedRes)
+		}
+	})
+}
+
+func TestMatchContentType(t *testing.T) {
+	patterns := []string{"text/*", "application/json"}
+	tests := map[string]bool{
+		"text/html; charset=utf-8": true,
+		"TEXT/PLAIN":               true,
+		"application/json":         true,
+		"application/jsonp":        false,
+		"image/png":                false,
+		"":                         false,
+	}
+	for contentType, expected := range tests {
+		if got := matchContentType(contentType, patterns); got != expected {
+			t.Errorf("matchContentType(%q) = %v, expected %v", contentType, got, expected)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the request body size limiting middleware.
+package goxpress
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// BodyLimitConfig defines configuration options for the body limit middleware.
+type BodyLimitConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper Skipper
+
+	// Limit is the maximum request body size, as a number of bytes or a
+	// human-friendly size such as "512KB", "2MB" or "1.5GB". Units are
+	// powers of 1024 and case-insensitive; "KiB", "MiB" and "GiB" are
+	// accepted as well.
+	Limit string
+
+	// Handler is called when the request declares a body larger than Limit.
+	// If nil, a 413 Request Entity Too Large response is sent.
+	Handler HandlerFunc
+}
+
+// BodyLimit returns a middleware that rejects requests whose body is
+// larger than limit with 413 Request Entity Too Large. It panics if limit
+// is not a valid size.
+//
+// Requests declaring a larger Content-Length are rejected before any
+// handler runs. Bodies of unknown length are cut off at the limit: reading
+// past it fails with an error, which handlers should answer with 413.
+//
+// Groups can raise or lower the limit for their routes with
+// Router.SetBodyLimit, which takes precedence over limit.
+//
+// Example:
+//
+//	app.Use(goxpress.BodyLimit("2MB"))
+//
+//	uploads := app.Route("/uploads").SetBodyLimit("100MB")
+func BodyLimit(limit string) HandlerFunc {
+	return BodyLimitWithConfig(BodyLimitConfig{Limit: limit})
+}
+
+// BodyLimitWithConfig returns a body limit middleware with custom
+// configuration. It panics if config.Limit is not a valid size.
+//
+// Example:
+//
+//	app.Use(goxpress.BodyLimitWithConfig(goxpress.BodyLimitConfig{
+//		Limit: "1MB",
+//		Handler: func(c *goxpress.Context) {
+//			c.JSON(413, map[string]string{"error": "payload too large"})
+//		},
+//	}))
+func BodyLimitWithConfig(config BodyLimitConfig) HandlerFunc {
+	limit, err := parseSize(config.Limit)
+	if err != nil {
+		panic(err)
+	}
+
+	// Set defaults
+	if config.Handler == nil {
+		config.Handler = func(c *Context) {
+			c.String(http.StatusRequestEntityTooLarge, "Request Entity Too Large")
+		}
+	}
+
+	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		limit := limit
+		if c.bodyLimit > 0 {
+			limit = c.bodyLimit
+		}
+
+		if c.Request.ContentLength > limit {
+			c.Abort()
+			config.Handler(c)
+			return
+		}
+
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			c.Request.Body = http.MaxBytesReader(c.Response, c.Request.Body, limit)
+		}
+		c.Next()
+	}
+}
+
+// sizeUnits maps size suffixes to their multipliers.
+var sizeUnits = map[string]float64{
+	"":  1,
+	"b": 1,
+	"k": 1 << 10, "kb": 1 << 10, "kib": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20, "mib": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30, "gib": 1 << 30,
+	"t": 1 << 40, "tb": 1 << 40, "tib": 1 << 40,
+}
+
+// parseSize parses a human-friendly size such as "2MB" into bytes.
+func parseSize(size string) (int64, error) {
+	s := strings.ToLower(strings.TrimSpace(size))
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+
+	n, err := strconv.ParseFloat(s[:i], 64)
+	unit, ok := sizeUnits[strings.TrimSpace(s[i:])]
+	if err != nil || !ok || n < 0 {
+		return 0, fmt.Errorf("goxpress: invalid size %q", size)
+	}
+	return int64(n * unit), nil
+}
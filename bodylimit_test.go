@@ -0,0 +1,103 @@
+package goxpress
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBodyLimit(t *testing.T) {
+	app := New()
+	app.Use(BodyLimit("1KB"))
+	app.POST("/echo", func(c *Context) {
+		body, err := ioutil.ReadAll(c.Request.Body)
+		if err != nil {
+			c.String(413, "too large")
+			return
+		}
+		c.String(200, "%d", len(body))
+	})
+
+	uploads := app.Route("/uploads").SetBodyLimit("4KB")
+	uploads.POST("/", func(c *Context) {
+		body, _ := ioutil.ReadAll(c.Request.Body)
+		c.String(200, "%d", len(body))
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		size    int
+		chunked bool
+		code    int
+		body    string
+	}{
+		{"WithinLimit", "/echo", 1024, false, 200, "1024"},
+		{"ContentLengthTooLarge", "/echo", 1025, false, 413, "Request Entity Too Large"},
+		{"ChunkedTooLarge", "/echo", 2048, true, 413, "too large"},
+		{"GroupOverride", "/uploads", 3000, false, 200, "3000"},
+		{"GroupOverrideTooLarge", "/uploads", 5000, false, 413, "Request Entity Too Large"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", test.path, strings.NewReader(strings.Repeat("x", test.size)))
+			if test.chunked {
+				req.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+
+			if w.Code != test.code || w.Body.String() != test.body {
+				t.Errorf("Expected %d '%s', got %d '%s'", test.code, test.body, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestBodyLimitAfterTimeout(t *testing.T) {
+	app := New()
+	app.Use(Timeout(time.Second, TimeoutConfig{}))
+	app.Use(BodyLimit("1KB"))
+
+	uploads := app.Route("/uploads").SetBodyLimit("4KB")
+	uploads.POST("/", func(c *Context) {
+		body, _ := ioutil.ReadAll(c.Request.Body)
+		c.String(200, "%d", len(body))
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/uploads", strings.NewReader(strings.Repeat("x", 3000))))
+	if w.Code != 200 || w.Body.String() != "3000" {
+		t.Errorf("Expected the group limit to apply under Timeout, got %d '%s'", w.Code, w.Body.String())
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		size     string
+		expected int64
+	}{
+		{"100", 100},
+		{"100B", 100},
+		{"512kb", 512 << 10},
+		{"2MB", 2 << 20},
+		{"2 MiB", 2 << 20},
+		{"1.5G", 3 << 29},
+	}
+
+	for _, test := range tests {
+		n, err := parseSize(test.size)
+		if err != nil || n != test.expected {
+			t.Errorf("parseSize(%q) = %d, %v; expected %d", test.size, n, err, test.expected)
+		}
+	}
+
+	for _, invalid := range []string{"", "MB", "2XB", "-1KB"} {
+		if _, err := parseSize(invalid); err == nil {
+			t.Errorf("parseSize(%q) should fail", invalid)
+		}
+	}
+}
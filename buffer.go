@@ -0,0 +1,194 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the response buffering middleware backed by pooled
+// buffers.
+package goxpress
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// maxPooledBufferSize is the capacity above which response buffers are
+// dropped instead of returned to the pool, so a few large responses don't
+// pin memory.
+const maxPooledBufferSize = 64 << 10
+
+// responseBufferPool holds the buffers used by BufferResponse.
+var responseBufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// BufferConfig defines configuration options for the BufferResponse
+// middleware.
+type BufferConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper Skipper
+
+	// MaxSize is the number of bytes buffered before the response is
+	// streamed to the client instead, without a Content-Length header.
+	// If zero, defaults to 1 MB.
+	MaxSize int
+}
+
+// BufferResponse returns a middleware that buffers responses in pooled
+// buffers with the default configuration.
+//
+// Example:
+//
+//	app.Use(goxpress.BufferResponse())
+func BufferResponse() HandlerFunc {
+	return BufferResponseWithConfig(BufferConfig{})
+}
+
+// BufferResponseWithConfig returns a BufferResponse middleware with custom
+// configuration.
+//
+// The status code and body written by the handlers, e.g. by Context.JSON,
+// are collected in a buffer reused across requests and sent with a single
+// write once the chain has finished. The response gets a Content-Length
+// header, and headers can still be changed after the body was written.
+// Responses growing beyond MaxSize, flushed by the handler or hijacked
+// connections are passed through as they are written.
+//
+// Example:
+//
+//	app.Use(goxpress.BufferResponseWithConfig(goxpress.BufferConfig{
+//		MaxSize: 256 << 10,
+//	}))
+//	app.GET("/report", func(c *goxpress.Context) {
+//		c.JSON(200, report)
+//		c.Header("X-Row-Count", strconv.Itoa(len(report.Rows))) // Still sent
+//	})
+func BufferResponseWithConfig(config BufferConfig) HandlerFunc {
+	// Set defaults
+	if config.MaxSize <= 0 {
+		config.MaxSize = 1 << 20
+	}
+
+	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		buf := responseBufferPool.Get().(*bytes.Buffer)
+		original := c.Response
+		w := &pooledResponseWriter{ResponseWriter: original, buf: buf, maxSize: config.MaxSize}
+		c.Response = w
+		defer func() {
+			c.Response = original
+			if buf.Cap() <= maxPooledBufferSize {
+				buf.Reset()
+				responseBufferPool.Put(buf)
+			}
+		}()
+
+		c.Next()
+		w.finish()
+	}
+}
+
+// pooledResponseWriter holds back the status code and body of a response
+// in a pooled buffer until finish, or until it has to stream.
+type pooledResponseWriter struct {
+	http.ResponseWriter
+	buf       *bytes.Buffer
+	maxSize   int
+	status    int  // Status code passed to WriteHeader, 0 if not called
+	streaming bool // Whether writes go straight to the ResponseWriter
+}
+
+// WriteHeader records the status code without sending it.
+func (w *pooledResponseWriter) WriteHeader(code int) {
+	if w.streaming {
+		return
+	}
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+// Write appends data to the buffer, switching to streaming once the
+// buffer would exceed the maximum size.
+func (w *pooledResponseWriter) Write(data []byte) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.Write(data)
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.buf.Len()+len(data) > w.maxSize {
+		if err := w.stream(); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(data)
+	}
+	return w.buf.Write(data)
+}
+
+// Flush sends the buffered response and streams the rest of it.
+func (w *pooledResponseWriter) Flush() {
+	if !w.streaming {
+		w.stream()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack passes connection takeovers, such as WebSocket upgrades,
+// through to the underlying ResponseWriter.
+func (w *pooledResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(w.ResponseWriter, func() { w.streaming = true })
+}
+
+// stream sends the status code and buffered body and makes later writes
+// go straight to the underlying ResponseWriter.
+func (w *pooledResponseWriter) stream() error {
+	w.streaming = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+// finish sends the buffered response with its Content-Length.
+func (w *pooledResponseWriter) finish() {
+	if w.streaming {
+		return
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	header := w.ResponseWriter.Header()
+	if header.Get("Content-Length") == "" && header.Get("Transfer-Encoding") == "" && bodyAllowedForStatus(w.status) {
+		header.Set("Content-Length", strconv.Itoa(w.buf.Len()))
+	}
+	w.streaming = true
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() > 0 {
+		w.ResponseWriter.Write(w.buf.Bytes())
+	}
+}
+
+// bodyAllowedForStatus reports whether a response with the given status
+// code may have a body.
+func bodyAllowedForStatus(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent, status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
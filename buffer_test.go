@@ -0,0 +1,74 @@
+package goxpress
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBufferResponse(t *testing.T) {
+	app := New()
+	app.Use(BufferResponse())
+	app.GET("/json", func(c *Context) {
+		c.JSON(201, map[string]string{"name": "ann"})
+		c.Header("X-After", "1")
+	})
+	app.GET("/empty", func(c *Context) { c.Status(204) })
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/json", nil))
+	body := "{\"name\":\"ann\"}\n"
+	if w.Code != 201 || w.Body.String() != body {
+		t.Errorf("Expected buffered JSON response, got %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Length") != "15" {
+		t.Errorf("Expected Content-Length 15, got %q", w.Header().Get("Content-Length"))
+	}
+	if w.Result().Header.Get("X-After") != "1" {
+		t.Error("Expected header set after the body to be sent")
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/empty", nil))
+	if w.Code != 204 || w.Header().Get("Content-Length") != "" {
+		t.Errorf("Expected 204 without Content-Length, got %d %q", w.Code, w.Header().Get("Content-Length"))
+	}
+}
+
+func TestBufferResponseMaxSize(t *testing.T) {
+	app := New()
+	app.Use(BufferResponseWithConfig(BufferConfig{MaxSize: 8}))
+	app.GET("/large", func(c *Context) {
+		c.String(200, "0123456789")
+		c.Header("X-After", "1")
+	})
+	app.GET("/flush", func(c *Context) {
+		c.String(200, "a")
+		c.Response.(interface{ Flush() }).Flush()
+		c.String(200, "b")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/large", nil))
+	if w.Body.String() != "0123456789" || w.Header().Get("Content-Length") != "" {
+		t.Errorf("Expected streamed response, got %q (Content-Length %q)", w.Body.String(), w.Header().Get("Content-Length"))
+	}
+	if w.Result().Header.Get("X-After") != "" {
+		t.Error("Headers set after streaming should not be sent")
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/flush", nil))
+	if w.Body.String() != "ab" || !w.Flushed {
+		t.Errorf("Expected flushed response, got %q", w.Body.String())
+	}
+
+	// Buffers are reused, make sure nothing leaks between requests
+	for i := 0; i < 3; i++ {
+		w = httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/large", nil))
+		if !strings.HasPrefix(w.Body.String(), "0123") || len(w.Body.String()) != 10 {
+			t.Errorf("Unexpected body %q", w.Body.String())
+		}
+	}
+}
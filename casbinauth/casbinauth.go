@@ -0,0 +1,123 @@
+// Package casbinauth provides a goxpress middleware enforcing
+// authorization policies with a Casbin enforcer, for teams that already
+// express their policies in Casbin models.
+//
+// Each request is checked as the tuple (subject, object, action), where
+// the subject is taken from the Context, the object is the matched route
+// pattern, such as "/users/:id", and the action is the request method.
+// A matching model looks like:
+//
+//	[request_definition]
+//	r = sub, obj, act
+//
+//	[policy_definition]
+//	p = sub, obj, act
+//
+//	[policy_effect]
+//	e = some(where (p.eft == allow))
+//
+//	[matchers]
+//	m = r.sub == p.sub && r.obj == p.obj && r.act == p.act
+//
+// The package does not depend on Casbin. *casbin.Enforcer,
+// *casbin.SyncedEnforcer and *casbin.CachedEnforcer all satisfy Enforcer:
+//
+//	e, _ := casbin.NewEnforcer("model.conf", "policy.csv")
+//	app.Use(casbinauth.New(e))
+package casbinauth
+
+import (
+	"net/http"
+
+	"github.com/minorcell/goxpress"
+)
+
+// Enforcer is the subset of a Casbin enforcer used by the middleware.
+type Enforcer interface {
+	// Enforce reports whether the request described by rvals is allowed.
+	Enforce(rvals ...interface{}) (bool, error)
+}
+
+// Config defines configuration options for the Casbin middleware.
+type Config struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper goxpress.Skipper
+
+	// Subject returns the subject requests are enforced for.
+	// If nil, defaults to the string stored under the "user" key with
+	// Context.Set, or an empty string if there is none.
+	Subject func(c *goxpress.Context) string
+
+	// Forbidden is called when the enforcer denies a request. If nil, a
+	// 403 Forbidden response is sent.
+	Forbidden goxpress.HandlerFunc
+
+	// ErrorHandler is called when the enforcer returns an error. If nil,
+	// the request is aborted with 500 Internal Server Error and the error
+	// is recorded on the Context.
+	ErrorHandler func(c *goxpress.Context, err error)
+}
+
+// New returns a middleware that authorizes requests with enforcer using
+// the default configuration.
+func New(enforcer Enforcer) goxpress.HandlerFunc {
+	return NewWithConfig(enforcer, Config{})
+}
+
+// NewWithConfig returns a middleware that authorizes requests with
+// enforcer. Requests that did not match a route are enforced against
+// their URL path.
+//
+// Example:
+//
+//	api := app.Route("/api")
+//	api.Use(casbinauth.NewWithConfig(e, casbinauth.Config{
+//		Subject: func(c *goxpress.Context) string {
+//			return c.MustGet("claims").(*Claims).Role
+//		},
+//	}))
+func NewWithConfig(enforcer Enforcer, config Config) goxpress.HandlerFunc {
+	// Set defaults
+	if config.Subject == nil {
+		config.Subject = func(c *goxpress.Context) string {
+			user, _ := c.GetString("user")
+			return user
+		}
+	}
+	if config.Forbidden == nil {
+		config.Forbidden = func(c *goxpress.Context) {
+			c.String(http.StatusForbidden, "Forbidden")
+		}
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = func(c *goxpress.Context, err error) {
+			c.AbortWithError(http.StatusInternalServerError, err)
+		}
+	}
+
+	return func(c *goxpress.Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		object := c.FullPath()
+		if object == "" {
+			object = c.Request.URL.Path
+		}
+
+		allowed, err := enforcer.Enforce(config.Subject(c), object, c.Request.Method)
+		if err != nil {
+			c.Abort()
+			config.ErrorHandler(c, err)
+			return
+		}
+		if !allowed {
+			c.Abort()
+			config.Forbidden(c)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,83 @@
+package casbinauth
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/minorcell/goxpress"
+)
+
+// fakeEnforcer allows the (subject, object, action) tuples in policies.
+type fakeEnforcer struct {
+	policies map[[3]string]bool
+	err      error
+	last     []interface{}
+}
+
+func (f *fakeEnforcer) Enforce(rvals ...interface{}) (bool, error) {
+	f.last = rvals
+	if f.err != nil {
+		return false, f.err
+	}
+	return f.policies[[3]string{rvals[0].(string), rvals[1].(string), rvals[2].(string)}], nil
+}
+
+func TestNew(t *testing.T) {
+	enforcer := &fakeEnforcer{policies: map[[3]string]bool{
+		{"alice", "/users/:id", "GET"}: true,
+	}}
+
+	app := goxpress.New()
+	app.Use(func(c *goxpress.Context) {
+		c.Set("user", c.Request.Header.Get("X-User"))
+		c.Next()
+	})
+	app.Use(New(enforcer))
+	app.GET("/users/:id", func(c *goxpress.Context) {
+		c.String(200, "ok")
+	})
+	app.DELETE("/users/:id", func(c *goxpress.Context) {
+		c.String(204, "")
+	})
+
+	tests := []struct {
+		user   string
+		method string
+		code   int
+	}{
+		{"alice", "GET", 200},
+		{"bob", "GET", 403},
+		{"alice", "DELETE", 403},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/users/7", nil)
+		req.Header.Set("X-User", tt.user)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s %s: expected %d, got %d", tt.user, tt.method, tt.code, w.Code)
+		}
+	}
+	if enforcer.last[1] != "/users/:id" {
+		t.Errorf("Expected route pattern as object, got %v", enforcer.last[1])
+	}
+}
+
+func TestNewWithConfigError(t *testing.T) {
+	enforcer := &fakeEnforcer{err: errors.New("adapter unavailable")}
+
+	app := goxpress.New()
+	app.Use(NewWithConfig(enforcer, Config{
+		Subject: func(c *goxpress.Context) string { return "alice" },
+	}))
+	app.GET("/", func(c *goxpress.Context) {
+		c.String(200, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != 500 {
+		t.Errorf("Expected 500 on enforcer error, got %d", w.Code)
+	}
+}
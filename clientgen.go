@@ -0,0 +1,900 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the generation of API clients from the route table.
+package goxpress
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ClientLanguage selects the language of the client written by
+// GenerateClient.
+type ClientLanguage int
+
+const (
+	// ClientGo generates a Go package built on net/http.
+	ClientGo ClientLanguage = iota
+
+	// ClientTypeScript generates a TypeScript module built on fetch.
+	ClientTypeScript
+)
+
+// ClientConfig defines configuration options for GenerateClient.
+type ClientConfig struct {
+	// Language of the generated client. Defaults to ClientGo.
+	Language ClientLanguage
+
+	// Package is the name of the generated Go package.
+	// If empty, defaults to "client".
+	Package string
+
+	// Include, if set, selects the routes the client covers, e.g. to
+	// leave out internal or documentation routes.
+	Include func(route RouteInfo) bool
+}
+
+// GenerateClient writes the source of an API client with one function per
+// registered route, so clients are regenerated from the server instead of
+// drifting from it. Functions are named after the method and path, such
+// as GetUsersByID for "GET /users/:id", and take the path parameters as
+// arguments.
+//
+// Routes registered with Typed are fully typed: the
+// request and response types are generated from their JSON encoding, the
+// fields with a `query` tag are sent in the query string, and the request
+// is sent as a JSON body for POST, PUT and PATCH. Responses with status
+// 400 or higher are returned as errors. Other routes return the raw
+// response.
+//
+// Example:
+//
+//	// Regenerate with: go run . gen-client
+//	if len(os.Args) > 1 && os.Args[1] == "gen-client" {
+//		f, _ := os.Create("client/client.go")
+//		defer f.Close()
+//		if err := app.GenerateClient(f, goxpress.ClientConfig{}); err != nil {
+//			log.Fatal(err)
+//		}
+//		return
+//	}
+func (e *Engine) GenerateClient(w io.Writer, config ClientConfig) error {
+	// Set defaults
+	if config.Package == "" {
+		config.Package = "client"
+	}
+
+	g := newClientGenerator()
+	taken := make(map[string]int)
+	var routes []clientRoute
+	for _, route := range e.Routes() {
+		if config.Include != nil && !config.Include(route) {
+			continue
+		}
+
+		r := clientRoute{RouteInfo: route, name: clientFuncName(route)}
+		if n := taken[r.name]; n > 0 {
+			taken[r.name]++
+			r.name += strconv.Itoa(n + 1)
+		} else {
+			taken[r.name] = 1
+		}
+		for _, segment := range strings.Split(route.Path, "/") {
+			if segment != "" && (segment[0] == ':' || segment[0] == '*') {
+				r.params = append(r.params, clientParam{
+					arg:      clientArgName(segment[1:]),
+					wildcard: segment[0] == '*',
+				})
+			}
+		}
+
+		if types, ok := e.routeHandlerTypes(route); ok {
+			r.typed = true
+			r.request, r.response = types.request, types.response
+			r.query = clientQueryFields(types.request)
+			switch route.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch:
+				r.body = hasJSONFields(types.request)
+			}
+			r.noContent = types.response == reflect.TypeOf(struct{}{})
+		}
+		routes = append(routes, r)
+	}
+
+	var src []byte
+	switch config.Language {
+	case ClientTypeScript:
+		src = g.typeScript(routes)
+	default:
+		var err error
+		if src, err = g.golang(config.Package, routes); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(src)
+	return err
+}
+
+// clientRoute describes the client function of a route.
+type clientRoute struct {
+	RouteInfo
+	name      string        // Exported function name
+	params    []clientParam // Path parameters in order
+	typed     bool          // Whether the route was registered with Typed
+	request   reflect.Type
+	response  reflect.Type
+	query     []clientField // Request fields sent in the query string
+	body      bool          // Whether the request is sent as a JSON body
+	noContent bool          // Whether the response has no body
+}
+
+// clientParam is a path parameter of a route.
+type clientParam struct {
+	arg      string // Argument name
+	wildcard bool   // Whether the parameter captures the rest of the path
+}
+
+// hasRequest reports whether the client function takes a request value.
+func (r clientRoute) hasRequest() bool {
+	return r.body || len(r.query) > 0
+}
+
+// clientField is a field of a generated struct type.
+type clientField struct {
+	name      string // Go field name
+	typ       reflect.Type
+	json      string // JSON name, empty if not encoded
+	omitempty bool
+	query     string // Query parameter name, empty if not bound
+}
+
+// clientFields returns the fields of the struct type typ encoded in JSON
+// or bound from the query string, flattening embedded structs.
+func clientFields(typ reflect.Type) []clientField {
+	var fields []clientField
+	seen := make(map[string]bool)
+	var collect func(typ reflect.Type)
+	collect = func(typ reflect.Type) {
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.PkgPath != "" && !field.Anonymous {
+				continue
+			}
+
+			jsonTag, query := field.Tag.Get("json"), field.Tag.Get("query")
+			if query == "-" {
+				query = ""
+			}
+			name := strings.Split(jsonTag, ",")[0]
+			fieldType := field.Type
+			for fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if field.Anonymous && name == "" && query == "" && jsonTag != "-" && fieldType.Kind() == reflect.Struct {
+				collect(fieldType)
+				continue
+			}
+			if field.PkgPath != "" || seen[field.Name] {
+				continue
+			}
+
+			f := clientField{name: field.Name, typ: field.Type, query: query}
+			if jsonTag != "-" {
+				f.json = name
+				if f.json == "" {
+					f.json = field.Name
+				}
+				f.omitempty = strings.Contains(jsonTag, ",omitempty")
+			}
+			if f.json == "" && f.query == "" {
+				continue
+			}
+			seen[field.Name] = true
+			fields = append(fields, f)
+		}
+	}
+	collect(typ)
+	return fields
+}
+
+// clientQueryFields returns the fields of a request type sent in the query
+// string, matching those documented by MountDocs.
+func clientQueryFields(typ reflect.Type) []clientField {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []clientField
+	for _, f := range clientFields(typ) {
+		if f.query != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// hasJSONFields reports whether the JSON encoding of typ has content,
+// which is the case for all types but structs without encoded fields.
+func hasJSONFields(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || typ == reflect.TypeOf(time.Time{}) {
+		return true
+	}
+	for _, f := range clientFields(typ) {
+		if f.json != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// clientFuncName names the client function of a route after its method
+// and path, e.g. "GET /users/:id" becomes GetUsersByID.
+func clientFuncName(route RouteInfo) string {
+	name := exportedIdent(route.Method)
+	path := strings.Trim(route.Path, "/")
+	if path == "" {
+		return name + "Root"
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" && (segment[0] == ':' || segment[0] == '*') {
+			name += "By" + exportedIdent(segment[1:])
+		} else {
+			name += exportedIdent(segment)
+		}
+	}
+	return name
+}
+
+// clientInitialisms are words written in upper case in identifiers.
+var clientInitialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// identWords splits s into words at characters that cannot appear in
+// identifiers and at lower to upper case transitions.
+func identWords(s string) []string {
+	var words []string
+	var word []rune
+	for i, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+			continue
+		}
+		if i > 0 && unicode.IsUpper(r) && len(word) > 0 && unicode.IsLower(word[len(word)-1]) {
+			words = append(words, string(word))
+			word = nil
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// exportedIdent converts s to an exported Go identifier in camel case.
+func exportedIdent(s string) string {
+	var b strings.Builder
+	for _, word := range identWords(s) {
+		if upper := strings.ToUpper(word); clientInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		b.WriteString(strings.ToUpper(string(runes[0])) + strings.ToLower(string(runes[1:])))
+	}
+	ident := b.String()
+	if ident == "" || unicode.IsDigit([]rune(ident)[0]) {
+		ident = "X" + ident
+	}
+	return ident
+}
+
+// clientReservedNames are the names path parameter arguments must not
+// take: Go and TypeScript keywords and the names used by the generated
+// functions.
+var clientReservedNames = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true,
+	"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+	"func": true, "go": true, "goto": true, "if": true, "import": true,
+	"interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+	"catch": true, "class": true, "delete": true, "do": true, "enum": true,
+	"export": true, "extends": true, "false": true, "finally": true, "function": true,
+	"in": true, "instanceof": true, "let": true, "new": true, "null": true,
+	"super": true, "this": true, "throw": true, "true": true, "try": true,
+	"typeof": true, "void": true, "while": true, "with": true, "yield": true,
+	"bytes": true, "context": true, "ctx": true, "err": true, "fmt": true,
+	"http": true, "io": true, "json": true, "query": true, "req": true,
+	"res": true, "strings": true, "time": true, "url": true,
+}
+
+// clientArgName converts a path parameter name to an unexported
+// identifier usable as an argument in Go and TypeScript.
+func clientArgName(param string) string {
+	name := exportedIdent(param)
+	words := identWords(name)
+	if len(words) > 0 && (clientInitialisms[words[0]] || len(words[0]) == 1) {
+		name = strings.ToLower(words[0]) + name[len(words[0]):]
+	} else {
+		runes := []rune(name)
+		name = strings.ToLower(string(runes[0])) + string(runes[1:])
+	}
+	if clientReservedNames[name] {
+		name += "Param"
+	}
+	return name
+}
+
+// clientGenerator renders client sources, collecting the named struct
+// types they use.
+type clientGenerator struct {
+	names   map[reflect.Type]string // Generated names of named struct types
+	taken   map[string]bool         // Type names in use
+	pending []reflect.Type          // Named struct types in order of use
+	imports map[string]bool         // Optional Go packages used
+}
+
+// newClientGenerator returns a generator with the names of the client
+// runtime reserved.
+func newClientGenerator() *clientGenerator {
+	return &clientGenerator{
+		names:   make(map[reflect.Type]string),
+		taken:   map[string]bool{"Client": true, "Error": true, "NewClient": true, "APIError": true},
+		imports: make(map[string]bool),
+	}
+}
+
+// typeName returns the generated name of the named struct type typ,
+// registering it to be generated. Type parameters are folded into the
+// name, e.g. Page[app.User] becomes PageUser.
+func (g *clientGenerator) typeName(typ reflect.Type) string {
+	if name, ok := g.names[typ]; ok {
+		return name
+	}
+
+	base := typ.Name()
+	if i := strings.IndexByte(base, '['); i >= 0 {
+		args := strings.Split(strings.TrimSuffix(base[i+1:], "]"), ",")
+		base = base[:i]
+		for _, arg := range args {
+			if j := strings.LastIndexByte(arg, '.'); j >= 0 {
+				arg = arg[j+1:]
+			}
+			base += exportedIdent(arg)
+		}
+	}
+	base = exportedIdent(base)
+
+	name := base
+	for n := 2; g.taken[name]; n++ {
+		name = base + strconv.Itoa(n)
+	}
+	g.taken[name] = true
+	g.names[typ] = name
+	g.pending = append(g.pending, typ)
+	return name
+}
+
+// isJSONMarshaler reports whether typ encodes itself to JSON, so its
+// shape cannot be derived from its fields.
+func isJSONMarshaler(typ reflect.Type) bool {
+	marshaler := reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	return typ.Implements(marshaler) || reflect.PtrTo(typ).Implements(marshaler)
+}
+
+// goType returns the Go type expression of typ in the generated package.
+func (g *clientGenerator) goType(typ reflect.Type) string {
+	switch {
+	case typ == reflect.TypeOf(time.Time{}):
+		g.imports["time"] = true
+		return "time.Time"
+	case typ.Kind() == reflect.Ptr:
+		return "*" + g.goType(typ.Elem())
+	case isJSONMarshaler(typ):
+		return "json.RawMessage"
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return typ.Kind().String()
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return "[]byte"
+		}
+		return "[]" + g.goType(typ.Elem())
+	case reflect.Array:
+		return "[" + strconv.Itoa(typ.Len()) + "]" + g.goType(typ.Elem())
+	case reflect.Map:
+		return "map[" + typ.Key().Kind().String() + "]" + g.goType(typ.Elem())
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return "struct {\n" + g.goFields(typ) + "}"
+		}
+		return g.typeName(typ)
+	}
+	return "interface{}"
+}
+
+// goFields returns the field declarations of the struct type typ.
+func (g *clientGenerator) goFields(typ reflect.Type) string {
+	var b strings.Builder
+	for _, f := range clientFields(typ) {
+		tag := `json:"-"`
+		if f.json != "" {
+			tag = `json:"` + f.json
+			if f.omitempty {
+				tag += ",omitempty"
+			}
+			tag += `"`
+		}
+		if f.query != "" {
+			tag += ` query:"` + f.query + `"`
+		}
+		fmt.Fprintf(&b, "%s %s `%s`\n", f.name, g.goType(f.typ), tag)
+	}
+	return b.String()
+}
+
+// golang renders the Go client package.
+func (g *clientGenerator) golang(pkg string, routes []clientRoute) ([]byte, error) {
+	var funcs bytes.Buffer
+	for _, r := range routes {
+		g.goFunc(&funcs, r)
+	}
+	var types bytes.Buffer
+	for i := 0; i < len(g.pending); i++ {
+		typ := g.pending[i]
+		fmt.Fprintf(&types, "// %s mirrors the JSON encoding of %s.\ntype %s struct {\n%s}\n\n", g.names[typ], typ, g.names[typ], g.goFields(typ))
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by goxpress. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "// Package %s is a client of the API, with one method per route.\n", pkg)
+	fmt.Fprintf(&b, "package %s\n\nimport (\n", pkg)
+	for _, path := range []string{"bytes", "context", "encoding/json", "fmt", "io", "net/http", "net/url", "strings", "time"} {
+		if path != "time" || g.imports[path] {
+			fmt.Fprintf(&b, "%q\n", path)
+		}
+	}
+	b.WriteString(")\n\n")
+	b.WriteString(goClientRuntime)
+	b.Write(types.Bytes())
+	b.Write(funcs.Bytes())
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("goxpress: cannot format generated client: %v", err)
+	}
+	return src, nil
+}
+
+// goFunc renders the client method of a route.
+func (g *clientGenerator) goFunc(b *bytes.Buffer, r clientRoute) {
+	args := "ctx context.Context"
+	for _, p := range r.params {
+		args += ", " + p.arg + " string"
+	}
+	path := clientPathExpr(r, func(p clientParam) string {
+		if p.wildcard {
+			return "(&url.URL{Path: " + p.arg + "}).EscapedPath()"
+		}
+		return "url.PathEscape(" + p.arg + ")"
+	})
+
+	fmt.Fprintf(b, "// %s calls %s %s.\n", r.name, r.Method, r.Path)
+	if !r.typed {
+		body := "nil"
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+			args += ", body interface{}"
+			body = "body"
+		}
+		fmt.Fprintf(b, "// The response is returned as is and its body must be closed.\n")
+		fmt.Fprintf(b, "func (c *Client) %s(%s) (*http.Response, error) {\n", r.name, args)
+		fmt.Fprintf(b, "req, err := c.newRequest(ctx, %q, %s, nil, %s)\n", r.Method, path, body)
+		b.WriteString("if err != nil {\nreturn nil, err\n}\nreturn c.send(req)\n}\n\n")
+		return
+	}
+
+	if r.hasRequest() {
+		args += ", req " + g.goType(r.request)
+	}
+	results, out := "error", "nil"
+	if !r.noContent {
+		results = "(" + g.goType(r.response) + ", error)"
+		out = "&res"
+	}
+	fmt.Fprintf(b, "func (c *Client) %s(%s) %s {\n", r.name, args, results)
+
+	query := "nil"
+	if len(r.query) > 0 {
+		query = "query"
+		b.WriteString("query := url.Values{}\n")
+		for _, f := range r.query {
+			g.goQueryField(b, f)
+		}
+	}
+	body := "nil"
+	if r.body {
+		body = "req"
+	}
+	if r.noContent {
+		fmt.Fprintf(b, "return c.do(ctx, %q, %s, %s, %s, nil)\n}\n\n", r.Method, path, query, body)
+		return
+	}
+	fmt.Fprintf(b, "var res %s\n", g.goType(r.response))
+	fmt.Fprintf(b, "err := c.do(ctx, %q, %s, %s, %s, %s)\nreturn res, err\n}\n\n", r.Method, path, query, body, out)
+}
+
+// goQueryField renders the statement adding a request field to the query
+// string, leaving out zero values.
+func (g *clientGenerator) goQueryField(b *bytes.Buffer, f clientField) {
+	value := "req." + f.name
+	typ := f.typ
+	if typ.Kind() == reflect.Ptr {
+		fmt.Fprintf(b, "if %s != nil {\n", value)
+		value = "(*" + value + ")"
+		typ = typ.Elem()
+		defer b.WriteString("}\n")
+	}
+
+	switch {
+	case typ == reflect.TypeOf(time.Time{}):
+		fmt.Fprintf(b, "if !%s.IsZero() {\nquery.Set(%q, %s.Format(time.RFC3339Nano))\n}\n", value, f.query, value)
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8:
+		fmt.Fprintf(b, "for _, v := range %s {\nquery.Add(%q, fmt.Sprint(v))\n}\n", value, f.query)
+	case typ.Kind() == reflect.String:
+		fmt.Fprintf(b, "if %s != \"\" {\nquery.Set(%q, %s)\n}\n", value, f.query, value)
+	case typ.Kind() == reflect.Bool:
+		fmt.Fprintf(b, "if %s {\nquery.Set(%q, \"true\")\n}\n", value, f.query)
+	case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Float64:
+		fmt.Fprintf(b, "if %s != 0 {\nquery.Set(%q, fmt.Sprint(%s))\n}\n", value, f.query, value)
+	default:
+		fmt.Fprintf(b, "query.Set(%q, fmt.Sprint(%s))\n", f.query, value)
+	}
+}
+
+// clientPathExpr returns the expression building the path of a route,
+// with param rendering the escaped value of each parameter.
+func clientPathExpr(r clientRoute, param func(clientParam) string) string {
+	var parts []string
+	literal := ""
+	i := 0
+	for _, segment := range strings.Split(strings.Trim(r.Path, "/"), "/") {
+		literal += "/"
+		if segment != "" && (segment[0] == ':' || segment[0] == '*') {
+			parts = append(parts, strconv.Quote(literal), param(r.params[i]))
+			literal = ""
+			i++
+			continue
+		}
+		literal += segment
+	}
+	if literal != "" {
+		parts = append(parts, strconv.Quote(literal))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// goClientRuntime is the part of the Go client shared by all methods.
+const goClientRuntime = `// Client calls the API at BaseURL.
+type Client struct {
+	BaseURL    string       // URL the route paths are appended to, e.g. "https://api.example.com"
+	HTTPClient *http.Client // Client sending the requests; http.DefaultClient if nil
+	Header     http.Header  // Headers sent with every request, e.g. Authorization
+}
+
+// NewClient returns a client of the API at baseURL.
+func NewClient(baseURL string) *Client {
+	return &Client{BaseURL: baseURL, Header: make(http.Header)}
+}
+
+// Error is returned for responses with a status code of 400 or higher.
+type Error struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), bytes.TrimSpace(e.Body))
+}
+
+// newRequest creates a request for path with the given query string and
+// body, which is sent as JSON unless nil.
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	target := strings.TrimRight(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.Header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+	return req, nil
+}
+
+// send sends req with the HTTP client.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// do sends a request and decodes the JSON response into out, unless nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	req, err := c.newRequest(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return &Error{StatusCode: resp.StatusCode, Body: data}
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+`
+
+// tsType returns the TypeScript type expression of typ.
+func (g *clientGenerator) tsType(typ reflect.Type) string {
+	switch {
+	case typ == reflect.TypeOf(time.Time{}):
+		return "string"
+	case typ.Kind() == reflect.Ptr:
+		return g.tsType(typ.Elem()) + " | null"
+	case isJSONMarshaler(typ):
+		return "unknown"
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+		elem := g.tsType(typ.Elem())
+		if strings.Contains(elem, " | ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case reflect.Map:
+		return "Record<string, " + g.tsType(typ.Elem()) + ">"
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return "{ " + strings.Join(g.tsFields(typ), " ") + " }"
+		}
+		return g.typeName(typ)
+	}
+	return "unknown"
+}
+
+// tsFields returns the property declarations of the struct type typ.
+// Query-only fields are declared under their query parameter name.
+func (g *clientGenerator) tsFields(typ reflect.Type) []string {
+	var props []string
+	for _, f := range clientFields(typ) {
+		optional := ""
+		if f.omitempty || f.json == "" || f.typ.Kind() == reflect.Ptr {
+			optional = "?"
+		}
+		props = append(props, tsPropName(tsFieldName(f))+optional+": "+g.tsType(f.typ)+";")
+	}
+	return props
+}
+
+// tsFieldName returns the TypeScript property name of a field.
+func tsFieldName(f clientField) string {
+	if f.json != "" {
+		return f.json
+	}
+	return f.query
+}
+
+// tsPropName quotes a property name unless it is a valid identifier.
+func tsPropName(name string) string {
+	for i, r := range name {
+		if !(r == '_' || r == '$' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) {
+			return strconv.Quote(name)
+		}
+	}
+	return name
+}
+
+// tsAccess returns the expression reading a property of value.
+func tsAccess(value, name string) string {
+	if prop := tsPropName(name); prop == name {
+		return value + "." + name
+	}
+	return value + "[" + strconv.Quote(name) + "]"
+}
+
+// typeScript renders the TypeScript client module.
+func (g *clientGenerator) typeScript(routes []clientRoute) []byte {
+	var methods bytes.Buffer
+	for _, r := range routes {
+		g.tsFunc(&methods, r)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by goxpress. DO NOT EDIT.\n\n")
+	for i := 0; i < len(g.pending); i++ {
+		typ := g.pending[i]
+		fmt.Fprintf(&b, "/** Mirrors the JSON encoding of %s. */\nexport interface %s {\n", typ, g.names[typ])
+		for _, prop := range g.tsFields(typ) {
+			b.WriteString("  " + prop + "\n")
+		}
+		b.WriteString("}\n\n")
+	}
+	b.WriteString(tsClientRuntime)
+	b.Write(methods.Bytes())
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+// tsFunc renders the client method of a route.
+func (g *clientGenerator) tsFunc(b *bytes.Buffer, r clientRoute) {
+	var args []string
+	for _, p := range r.params {
+		args = append(args, p.arg+": string")
+	}
+	path := clientPathExpr(r, func(p clientParam) string {
+		if p.wildcard {
+			return p.arg + `.split("/").map(encodeURIComponent).join("/")`
+		}
+		return "encodeURIComponent(" + p.arg + ")"
+	})
+	name := strings.ToLower(r.name[:1]) + r.name[1:]
+
+	fmt.Fprintf(b, "\n  /** Calls %s %s. */\n", r.Method, r.Path)
+	if !r.typed {
+		body := "undefined"
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+			args = append(args, "body?: unknown")
+			body = "body"
+		}
+		fmt.Fprintf(b, "  %s(%s): Promise<Response> {\n", name, strings.Join(args, ", "))
+		fmt.Fprintf(b, "    return this.request(%q, %s, undefined, %s);\n  }\n", r.Method, path, body)
+		return
+	}
+
+	if r.hasRequest() {
+		args = append(args, "req: "+g.tsType(r.request))
+	}
+	result := "void"
+	if !r.noContent {
+		result = g.tsType(r.response)
+	}
+	query := "undefined"
+	if len(r.query) > 0 {
+		var entries []string
+		for _, f := range r.query {
+			entries = append(entries, strconv.Quote(f.query)+": "+tsAccess("req", tsFieldName(f)))
+		}
+		query = "{ " + strings.Join(entries, ", ") + " }"
+	}
+	body := "undefined"
+	if r.body {
+		body = "req"
+	}
+	fmt.Fprintf(b, "  %s(%s): Promise<%s> {\n", name, strings.Join(args, ", "), result)
+	fmt.Fprintf(b, "    return this.call<%s>(%q, %s, %s, %s);\n  }\n", result, r.Method, path, query, body)
+}
+
+// tsClientRuntime is the part of the TypeScript client shared by all
+// methods. The class is closed after the route methods.
+const tsClientRuntime = `/** Thrown for responses with a status code of 400 or higher. */
+export class APIError extends Error {
+  constructor(
+    public status: number,
+    public body: string,
+  ) {
+    super(status + ": " + body);
+  }
+}
+
+type QueryValue = string | number | boolean | null | undefined;
+
+/** Calls the API at baseURL; init is merged into every request. */
+export class Client {
+  constructor(
+    private baseURL: string,
+    private init: RequestInit = {},
+  ) {
+    this.baseURL = baseURL.replace(/\/+$/, "");
+  }
+
+  /** Sends a request with the query string and the JSON body, if any. */
+  request(method: string, path: string, query?: Record<string, QueryValue | QueryValue[]>, body?: unknown): Promise<Response> {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(query ?? {})) {
+      for (const v of Array.isArray(value) ? value : [value]) {
+        if (v !== undefined && v !== null && v !== "" && v !== 0 && v !== false) {
+          params.append(key, String(v));
+        }
+      }
+    }
+    const search = params.toString();
+    const headers = new Headers(this.init.headers);
+    if (!headers.has("Accept")) {
+      headers.set("Accept", "application/json");
+    }
+    if (body !== undefined) {
+      headers.set("Content-Type", "application/json");
+    }
+    return fetch(this.baseURL + path + (search ? "?" + search : ""), {
+      ...this.init,
+      method,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+  }
+
+  /** Sends a request and decodes the JSON response. */
+  private async call<T>(method: string, path: string, query?: Record<string, QueryValue | QueryValue[]>, body?: unknown): Promise<T> {
+    const res = await this.request(method, path, query, body);
+    if (!res.ok) {
+      throw new APIError(res.status, await res.text());
+    }
+    return (res.status === 204 ? undefined : await res.json()) as T;
+  }
+`
@@ -0,0 +1,120 @@
+//go:build go1.18
+
+package goxpress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+type clientGenUser struct {
+	ID      int64          `json:"id"`
+	Name    string         `json:"name"`
+	Tags    []string       `json:"tags,omitempty"`
+	Created time.Time      `json:"created"`
+	Manager *clientGenUser `json:"manager"`
+}
+
+type clientGenListUsers struct {
+	Search string `query:"q" json:"-"`
+	Limit  int    `query:"limit" json:"-"`
+}
+
+type clientGenCreateUser struct {
+	OrgID string `path:"org" json:"-"`
+	Name  string `json:"name"`
+}
+
+// clientGenApp returns an app with typed and untyped routes.
+func clientGenApp() *Engine {
+	app := New()
+	Typed(app, "GET", "/users", func(c *Context, req clientGenListUsers) ([]clientGenUser, error) { return nil, nil })
+	Typed(app, "GET", "/users/:id", func(c *Context, req struct{}) (clientGenUser, error) { return clientGenUser{}, nil })
+	Typed(app, "POST", "/orgs/:org/users", func(c *Context, req clientGenCreateUser) (*clientGenUser, error) { return nil, nil })
+	Typed(app, "DELETE", "/users/:id", func(c *Context, req struct{}) (struct{}, error) { return struct{}{}, nil })
+	app.GET("/files/*path", func(c *Context) {})
+	app.GET("/internal/metrics", func(c *Context) {})
+	return app
+}
+
+func TestGenerateClientGo(t *testing.T) {
+	var buf bytes.Buffer
+	err := clientGenApp().GenerateClient(&buf, ClientConfig{
+		Package: "api",
+		Include: func(route RouteInfo) bool { return !strings.HasPrefix(route.Path, "/internal") },
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	src := buf.String()
+
+	for _, want := range []string{
+		"package api\n",
+		"func (c *Client) GetUsers(ctx context.Context, req ClientGenListUsers) ([]ClientGenUser, error) {",
+		`query.Set("q", req.Search)`,
+		"func (c *Client) GetUsersByID(ctx context.Context, id string) (ClientGenUser, error) {",
+		"func (c *Client) PostOrgsByOrgUsers(ctx context.Context, org string, req ClientGenCreateUser) (*ClientGenUser, error) {",
+		`c.do(ctx, "POST", "/orgs/"+url.PathEscape(org)+"/users", nil, req, &res)`,
+		"func (c *Client) DeleteUsersByID(ctx context.Context, id string) error {",
+		"func (c *Client) GetFilesByPath(ctx context.Context, path string) (*http.Response, error) {",
+		"Manager *ClientGenUser `json:\"manager\"`",
+		"Tags    []string       `json:\"tags,omitempty\"`",
+		"Search string `json:\"-\" query:\"q\"`",
+		`"time"`,
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("Expected generated client to contain %q, got:\n%s", want, src)
+		}
+	}
+	if strings.Contains(src, "Metrics") || strings.Count(src, "type ClientGenUser struct") != 1 {
+		t.Errorf("Expected excluded routes left out and types generated once, got:\n%s", src)
+	}
+}
+
+func TestGenerateClientTypeScript(t *testing.T) {
+	var buf bytes.Buffer
+	if err := clientGenApp().GenerateClient(&buf, ClientConfig{Language: ClientTypeScript}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	src := buf.String()
+
+	for _, want := range []string{
+		"export interface ClientGenUser {\n  id: number;\n  name: string;\n  tags?: string[];\n  created: string;\n  manager?: ClientGenUser | null;\n}",
+		"export interface ClientGenListUsers {\n  q?: string;\n  limit?: number;\n}",
+		`getUsers(req: ClientGenListUsers): Promise<ClientGenUser[]> {`,
+		`this.call<ClientGenUser[]>("GET", "/users", { "q": req.q, "limit": req.limit }, undefined)`,
+		`postOrgsByOrgUsers(org: string, req: ClientGenCreateUser): Promise<ClientGenUser | null> {`,
+		`deleteUsersByID(id: string): Promise<void> {`,
+		`getFilesByPath(path: string): Promise<Response> {`,
+		`"/files/" + path.split("/").map(encodeURIComponent).join("/")`,
+		`getInternalMetrics(): Promise<Response> {`,
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("Expected generated client to contain %q, got:\n%s", want, src)
+		}
+	}
+}
+
+func TestClientNames(t *testing.T) {
+	tests := []struct {
+		route RouteInfo
+		name  string
+	}{
+		{RouteInfo{"GET", "/"}, "GetRoot"},
+		{RouteInfo{"GET", "/api/users/:userId"}, "GetAPIUsersByUserID"},
+		{RouteInfo{"PUT", "/order-items/:item_id"}, "PutOrderItemsByItemID"},
+	}
+	for _, test := range tests {
+		if name := clientFuncName(test.route); name != test.name {
+			t.Errorf("Expected %s for %s %s, got %s", test.name, test.route.Method, test.route.Path, name)
+		}
+	}
+
+	for param, arg := range map[string]string{"id": "id", "user_id": "userID", "type": "typeParam", "url": "urlParam"} {
+		if got := clientArgName(param); got != arg {
+			t.Errorf("Expected argument %s for %s, got %s", arg, param, got)
+		}
+	}
+}
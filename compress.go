@@ -0,0 +1,286 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the response compression middleware.
+package goxpress
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CompressionEncoder creates a compressing writer for one content coding.
+// level is the level configured for the coding in CompressConfig.Levels,
+// or -1 to use the encoder's default.
+type CompressionEncoder func(w io.Writer, level int) (io.WriteCloser, error)
+
+// CompressConfig defines configuration options for the compression middleware.
+type CompressConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper Skipper
+
+	// Encodings lists the content codings the server offers, in order of
+	// preference. Codings without an encoder are ignored.
+	// If empty, defaults to br, zstd, gzip and deflate.
+	Encodings []string
+
+	// Levels sets the compression level per coding, e.g.
+	// map[string]int{"gzip": 6, "br": 4}. Codings without an entry use
+	// their encoder's default level.
+	Levels map[string]int
+
+	// Encoders adds or replaces encoders by coding name. gzip and deflate
+	// are built in; br and zstd are enabled by supplying encoders backed by
+	// libraries such as andybalholm/brotli and klauspost/compress/zstd.
+	Encoders map[string]CompressionEncoder
+}
+
+// defaultEncodings is the server preference order used when
+// CompressConfig.Encodings is empty.
+var defaultEncodings = []string{"br", "zstd", "gzip", "deflate"}
+
+// builtinEncoders are the encoders available without configuration.
+var builtinEncoders = map[string]CompressionEncoder{
+	"gzip": func(w io.Writer, level int) (io.WriteCloser, error) {
+		if level < 0 {
+			level = gzip.DefaultCompression
+		}
+		return gzip.NewWriterLevel(w, level)
+	},
+	"deflate": func(w io.Writer, level int) (io.WriteCloser, error) {
+		if level < 0 {
+			level = flate.DefaultCompression
+		}
+		return flate.NewWriter(w, level)
+	},
+}
+
+// Compress returns a middleware that compresses responses with gzip or
+// deflate, depending on the client's Accept-Encoding header.
+//
+// Example:
+//
+//	app.Use(goxpress.Compress())
+func Compress() HandlerFunc {
+	return CompressWithConfig(CompressConfig{})
+}
+
+// CompressWithConfig returns a compression middleware with custom
+// configuration. The coding is negotiated from Accept-Encoding, honoring
+// quality values, with ties resolved by the order of config.Encodings.
+// Responses that already carry a Content-Encoding, responses without a
+// body and media types that are compressed already (images, audio, video,
+// archives) are sent unchanged.
+//
+// Example:
+//
+//	app.Use(goxpress.CompressWithConfig(goxpress.CompressConfig{
+//		Encodings: []string{"br", "zstd", "gzip"},
+//		Levels:    map[string]int{"br": 5, "gzip": 6},
+//		Encoders: map[string]goxpress.CompressionEncoder{
+//			"br": func(w io.Writer, level int) (io.WriteCloser, error) {
+//				return brotli.NewWriterLevel(w, level), nil
+//			},
+//			"zstd": func(w io.Writer, level int) (io.WriteCloser, error) {
+//				return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+//			},
+//		},
+//	}))
+func CompressWithConfig(config CompressConfig) HandlerFunc {
+	// Set defaults
+	if len(config.Encodings) == 0 {
+		config.Encodings = defaultEncodings
+	}
+
+	encoders := make(map[string]CompressionEncoder, len(builtinEncoders)+len(config.Encoders))
+	for name, encoder := range builtinEncoders {
+		encoders[name] = encoder
+	}
+	for name, encoder := range config.Encoders {
+		encoders[strings.ToLower(name)] = encoder
+	}
+
+	// Only offer codings we can actually produce
+	offered := make([]string, 0, len(config.Encodings))
+	for _, name := range config.Encodings {
+		name = strings.ToLower(name)
+		if encoders[name] != nil {
+			offered = append(offered, name)
+		}
+	}
+
+	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		c.Response.Header().Add("Vary", "Accept-Encoding")
+
+		coding := negotiateEncoding(c.Request.Header.Get("Accept-Encoding"), offered)
+		if coding == "" || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		level, ok := config.Levels[coding]
+		if !ok {
+			level = -1
+		}
+
+		original := c.Response
+		cw := &compressWriter{
+			ResponseWriter: original,
+			coding:         coding,
+			level:          level,
+			encoder:        encoders[coding],
+		}
+		c.Response = cw
+		defer func() {
+			cw.close()
+			c.Response = original
+		}()
+
+		c.Next()
+	}
+}
+
+// negotiateEncoding returns the offered content coding the client prefers
+// according to an Accept-Encoding header, or an empty string if the
+// response should not be compressed.
+func negotiateEncoding(acceptEncoding string, offered []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(params[0]))
+		if coding == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		qualities[coding] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range offered {
+		q, ok := qualities[coding]
+		if !ok {
+			q, ok = qualities["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// compressWriter compresses the response body written through it.
+// Whether to compress is decided when the status code is written.
+type compressWriter struct {
+	http.ResponseWriter
+	coding      string             // Negotiated content coding
+	level       int                // Compression level, -1 for default
+	encoder     CompressionEncoder // Factory for the compressing writer
+	writer      io.WriteCloser     // Active compressing writer, nil if passing through
+	wroteHeader bool               // Whether the status code has been written
+}
+
+// WriteHeader decides whether to compress the response and sends the
+// status code.
+func (w *compressWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	header := w.Header()
+	if shouldCompress(code, header) {
+		if writer, err := w.encoder(w.ResponseWriter, w.level); err == nil {
+			w.writer = writer
+			header.Set("Content-Encoding", w.coding)
+			header.Del("Content-Length")
+			header.Del("Accept-Ranges") // Byte ranges refer to the uncompressed body
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write compresses data if compression is active.
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(data))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.writer == nil {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.writer.Write(data)
+}
+
+// Flush flushes compressed data buffered by the encoder and the
+// underlying ResponseWriter, keeping streaming responses working.
+func (w *compressWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := w.writer.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack passes connection takeovers through to the underlying
+// ResponseWriter.
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(w.ResponseWriter, func() { w.wroteHeader = true })
+}
+
+// close finishes the compressed stream.
+func (w *compressWriter) close() {
+	if w.writer != nil {
+		w.writer.Close()
+	}
+}
+
+// shouldCompress reports whether a response with the given status code
+// and headers benefits from compression.
+func shouldCompress(code int, header http.Header) bool {
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified ||
+		code == http.StatusPartialContent {
+		return false
+	}
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	contentType := strings.ToLower(header.Get("Content-Type"))
+	switch {
+	case strings.HasPrefix(contentType, "image/") && !strings.HasPrefix(contentType, "image/svg"),
+		strings.HasPrefix(contentType, "audio/"),
+		strings.HasPrefix(contentType, "video/"),
+		strings.HasPrefix(contentType, "application/zip"),
+		strings.HasPrefix(contentType, "application/gzip"),
+		strings.HasPrefix(contentType, "application/x-gzip"):
+		return false
+	}
+	return true
+}
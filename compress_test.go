@@ -0,0 +1,138 @@
+package goxpress
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// prefixWriter is a fake encoder that marks its output instead of
+// compressing it.
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	if _, err := io.WriteString(p.w, p.prefix); err != nil {
+		return 0, err
+	}
+	return p.w.Write(data)
+}
+
+func (p *prefixWriter) Close() error { return nil }
+
+func TestCompress(t *testing.T) {
+	app := New()
+	app.Use(Compress())
+	app.GET("/text", func(c *Context) {
+		c.String(200, strings.Repeat("hello ", 100))
+	})
+	app.GET("/image", func(c *Context) {
+		c.Data(200, "image/png", []byte("png"))
+	})
+
+	t.Run("Gzip", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/text", nil)
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		if w.Header().Get("Content-Encoding") != "gzip" {
+			t.Fatalf("Expected gzip encoding, got '%s'", w.Header().Get("Content-Encoding"))
+		}
+		if w.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("Expected Vary: Accept-Encoding, got '%s'", w.Header().Get("Vary"))
+		}
+
+		reader, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatalf("Invalid gzip body: %v", err)
+		}
+		body, _ := ioutil.ReadAll(reader)
+		if string(body) != strings.Repeat("hello ", 100) {
+			t.Errorf("Unexpected decompressed body %q", body)
+		}
+	})
+
+	t.Run("NoAcceptEncoding", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/text", nil))
+
+		if w.Header().Get("Content-Encoding") != "" {
+			t.Error("Response should not be compressed")
+		}
+		if w.Body.String() != strings.Repeat("hello ", 100) {
+			t.Error("Expected uncompressed body")
+		}
+	})
+
+	t.Run("AlreadyCompressedType", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/image", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		if w.Header().Get("Content-Encoding") != "" || w.Body.String() != "png" {
+			t.Error("Images should be sent unchanged")
+		}
+	})
+}
+
+func TestCompressWithConfig(t *testing.T) {
+	levels := make(map[string]int)
+	encoder := func(name string) CompressionEncoder {
+		return func(w io.Writer, level int) (io.WriteCloser, error) {
+			levels[name] = level
+			return &prefixWriter{w: w, prefix: name + ":"}, nil
+		}
+	}
+
+	app := New()
+	app.Use(CompressWithConfig(CompressConfig{
+		Encodings: []string{"br", "zstd", "gzip"},
+		Levels:    map[string]int{"br": 5},
+		Encoders: map[string]CompressionEncoder{
+			"br":   encoder("br"),
+			"zstd": encoder("zstd"),
+		},
+	}))
+	app.GET("/", func(c *Context) {
+		c.String(200, "data")
+	})
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		encoding       string
+	}{
+		{"ServerPreference", "gzip, zstd, br", "br"},
+		{"QualityValues", "br;q=0.5, zstd;q=0.8, gzip;q=0.1", "zstd"},
+		{"Excluded", "br;q=0, gzip", "gzip"},
+		{"Wildcard", "*", "br"},
+		{"Unsupported", "compress", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept-Encoding", test.acceptEncoding)
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Content-Encoding"); got != test.encoding {
+				t.Errorf("Expected encoding '%s', got '%s'", test.encoding, got)
+			}
+		})
+	}
+
+	if levels["br"] != 5 {
+		t.Errorf("Expected br level 5, got %d", levels["br"])
+	}
+	if levels["zstd"] != -1 {
+		t.Errorf("Expected default zstd level -1, got %d", levels["zstd"])
+	}
+}
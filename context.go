@@ -6,12 +6,19 @@ package goxpress
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"mime/multipart"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"reflect"
+	"strings"
 	"sync"
+	"time"
 )
 
 // contextPool is a sync.Pool for Context objects to reduce GC pressure
@@ -19,13 +26,34 @@ import (
 var contextPool = sync.Pool{
 	New: func() interface{} {
 		return &Context{
-			params: make(map[string]string),
-			store:  make(map[string]interface{}),
-			index:  -1,
+			index: -1,
 		}
 	},
 }
 
+// maxPooledStoreSize is the capacity above which a Context's store is
+// released on reset instead of being kept for the next request, so one
+// request storing many values doesn't grow every pooled Context.
+const maxPooledStoreSize = 32
+
+// ContextKey is the type of the request context keys under which Set
+// propagates values when the engine enables it with SetValuePropagation.
+// Code that only receives a context.Context retrieves them with
+// ctx.Value(goxpress.ContextKey(key)).
+type ContextKey string
+
+// param is a URL parameter captured by the router.
+type param struct {
+	key   string
+	value string
+}
+
+// storeEntry is a key-value pair stored with Context.Set.
+type storeEntry struct {
+	key   string
+	value interface{}
+}
+
 // Context represents the context of the current HTTP request.
 // It wraps the http.Request and http.ResponseWriter and provides
 // convenient methods for handling request data, generating responses,
@@ -39,18 +67,46 @@ var contextPool = sync.Pool{
 //   - Request-scoped data storage
 //   - Error handling
 //
+// Context implements context.Context on top of the request's context, so
+// it can be passed directly to database drivers, HTTP clients and any other
+// API that honors cancellation and deadlines while the request is served.
+// Its Value method reads the data store, which is not synchronized unless
+// the engine enables SetConcurrentStore. For work that runs on other
+// goroutines or outlives the handler, pass c.Request.Context() instead.
+//
+// Context used to embed a context.Context field holding the request's
+// context as of NewContext. Code reading that field as c.Context must now
+// use c itself, or the deprecated Context method, which returns the
+// current request context instead.
+//
 // Context instances are pooled for efficient memory usage and should
 // not be stored beyond the scope of a single request.
 type Context struct {
-	// Embedded standard context for cancellation and deadlines
-	context.Context
-
 	// HTTP request and response
 	Request  *http.Request       // Original HTTP request
-	Response http.ResponseWriter // HTTP response writer
+	Response http.ResponseWriter // HTTP response writer, initially Writer
+	Writer   ResponseWriter      // Wrapped response writer tracking status and size
 
-	// URL parameters extracted from route patterns
-	params map[string]string
+	// Backing storage for Writer, reused across pooled requests
+	writer responseWriter
+
+	// URL parameters extracted from route patterns, in path order. Routes
+	// have few parameters, so a slice is faster to scan and reset than a map
+	params []param
+
+	// Route pattern matched for this request (e.g., "/users/:id")
+	fullPath string
+
+	// Body limit set on the matched route with Router.SetBodyLimit, 0 if none
+	bodyLimit int64
+
+	// Whether the Logger's output is a terminal, for ColorLogFormatter
+	logColor bool
+
+	// Query string parameters, parsed on first use. queryRaw is the raw
+	// query they were parsed from, so a rewritten Request.URL is reparsed
+	query    url.Values
+	queryRaw string
 
 	// Middleware chain management
 	handlers []HandlerFunc // Chain of handlers to execute
@@ -63,12 +119,40 @@ type Context struct {
 	statusCodeWritten bool // Whether response status has been written
 
 	// Error handling
-	err error // Error that occurred during request processing
+	err           error     // Error that occurred during request processing
+	Errors        ErrorList // All errors recorded during request processing
+	errorReported bool      // Whether an error was sent to the error reporter
+
+	// Error handler chain, set while the error handlers run
+	errorHandlers []ErrorHandlerFunc
+	errorIndex    int
 
-	// Request-scoped data storage
-	store map[string]interface{} // Key-value store for request data
+	// Request-scoped data storage, a slice for the same reason as params.
+	// storeMu guards it when the engine enables concurrent stores
+	store     []storeEntry // Key-value store for request data
+	storeMu   sync.RWMutex
+	safeStore bool // Whether Set and Get lock storeMu
+
+	// Whether Set also adds values to the request context
+	propagateValues bool
+
+	// Cancels the request context installed by Engine.ServeHTTP, called
+	// once writing to the client fails
+	clientCancel context.CancelFunc
+
+	// Request-scoped logger, created lazily by Logger()
+	logger *log.Logger
+
+	// Values built by request-scoped providers, keyed by their type
+	injected map[reflect.Type]interface{}
+
+	// Engine handling the request, nil for standalone contexts
+	engine *Engine
 }
 
+// Ensure Context can be used wherever a context.Context is expected.
+var _ context.Context = (*Context)(nil)
+
 // NewContext creates a new Context instance from the pool and initializes it
 // with the given HTTP request and response writer.
 //
@@ -79,15 +163,19 @@ func NewContext(w http.ResponseWriter, req *http.Request) *Context {
 	c := contextPool.Get().(*Context)
 
 	// Initialize request-related fields
-	c.Context = req.Context()
 	c.Request = req
-	c.Response = w
+	c.writer.reset(w)
+	c.Writer = &c.writer
+	c.Response = c.Writer
 
 	// Reset state fields
 	c.index = -1
 	c.aborted = false
 	c.statusCodeWritten = false
 	c.err = nil
+	c.Errors = nil
+	c.errorReported = false
+	c.errorHandlers = nil
 
 	return c
 }
@@ -96,24 +184,49 @@ func NewContext(w http.ResponseWriter, req *http.Request) *Context {
 // This method is called internally to clean up Context instances before
 // they are returned to the pool for reuse.
 func (c *Context) reset() {
-	// Clear maps
-	for k := range c.params {
-		delete(c.params, k)
+	// Truncate the slices, keeping their storage for the next request.
+	// Stored values are zeroed so the pool doesn't keep them alive.
+	c.params = c.params[:0]
+	if c.safeStore {
+		c.storeMu.Lock()
+		defer c.storeMu.Unlock()
+		c.safeStore = false
 	}
-
-	for k := range c.store {
-		delete(c.store, k)
+	c.propagateValues = false
+	if c.clientCancel != nil {
+		c.clientCancel()
+	}
+	c.clientCancel = nil
+	if cap(c.store) > maxPooledStoreSize {
+		c.store = nil
+	} else {
+		for i := range c.store {
+			c.store[i] = storeEntry{}
+		}
+		c.store = c.store[:0]
 	}
 
 	// Reset other fields
-	c.Context = nil
 	c.Request = nil
 	c.Response = nil
+	c.Writer = nil
+	c.writer.reset(nil)
+	c.fullPath = ""
+	c.bodyLimit = 0
+	c.logColor = false
+	c.query = nil
+	c.queryRaw = ""
+	c.logger = nil
+	c.injected = nil
+	c.engine = nil
 	c.handlers = nil
 	c.index = -1
 	c.aborted = false
 	c.statusCodeWritten = false
 	c.err = nil
+	c.Errors = nil
+	c.errorReported = false
+	c.errorHandlers = nil
 }
 
 // Param returns the value of the URL parameter with the given name.
@@ -125,7 +238,24 @@ func (c *Context) reset() {
 //	// Request: "/users/123"
 //	id := c.Param("id") // Returns "123"
 func (c *Context) Param(key string) string {
-	return c.params[key]
+	for _, p := range c.params {
+		if p.key == key {
+			return p.value
+		}
+	}
+	return ""
+}
+
+// FullPath returns the route pattern matched for the current request,
+// or an empty string if no route matched.
+//
+// Example:
+//
+//	// Route: "/users/:id"
+//	// Request: "/users/123"
+//	pattern := c.FullPath() // Returns "/users/:id"
+func (c *Context) FullPath() string {
+	return c.fullPath
 }
 
 // Query returns the value of the URL query parameter with the given name.
@@ -138,7 +268,18 @@ func (c *Context) Param(key string) string {
 //	page := c.Query("page")  // Returns "1"
 //	empty := c.Query("foo")  // Returns ""
 func (c *Context) Query(key string) string {
-	return c.Request.URL.Query().Get(key)
+	return c.queryValues().Get(key)
+}
+
+// queryValues returns the parsed query string of the request. The values
+// are parsed once per request and shared by all callers, which must not
+// modify them.
+func (c *Context) queryValues() url.Values {
+	if c.query == nil || c.queryRaw != c.Request.URL.RawQuery {
+		c.queryRaw = c.Request.URL.RawQuery
+		c.query, _ = url.ParseQuery(c.queryRaw)
+	}
+	return c.query
 }
 
 // PostForm returns the value of the form field with the given name.
@@ -153,6 +294,108 @@ func (c *Context) PostForm(key string) string {
 	return c.Request.FormValue(key)
 }
 
+// IsWebSocket returns true if the request is a WebSocket upgrade handshake,
+// i.e. the Connection header contains the "upgrade" token and the Upgrade
+// header names the "websocket" protocol.
+//
+// Example:
+//
+//	if c.IsWebSocket() {
+//		// Hand the connection over to a WebSocket library
+//	}
+func (c *Context) IsWebSocket() bool {
+	return headerContainsToken(c.Request.Header, "Connection", "upgrade") &&
+		headerContainsToken(c.Request.Header, "Upgrade", "websocket")
+}
+
+// IsAJAX returns true if the request was sent by an XMLHttpRequest,
+// as indicated by the X-Requested-With header.
+//
+// Example:
+//
+//	if c.IsAJAX() {
+//		c.JSON(200, data)
+//		return
+//	}
+//	c.HTML(200, page)
+func (c *Context) IsAJAX() bool {
+	return strings.EqualFold(c.Request.Header.Get("X-Requested-With"), "XMLHttpRequest")
+}
+
+// IsTLS returns true if the request was received over a TLS connection
+// terminated by this server. Use Scheme to also account for TLS
+// terminated by a reverse proxy.
+func (c *Context) IsTLS() bool {
+	return c.Request.TLS != nil
+}
+
+// Scheme returns the scheme of the original client request, usually
+// "http" or "https". Requests received over TLS always report "https";
+// otherwise the X-Forwarded-Proto, X-Forwarded-Protocol, X-Forwarded-Ssl
+// and X-Url-Scheme headers are consulted in that order. The headers are
+// honored whoever sent them, as there is no list of trusted proxies: unless
+// a reverse proxy overwrites them, clients can choose the result, so don't
+// base security decisions on it. ClientIP, in contrast, ignores forwarding
+// headers.
+//
+// Example:
+//
+//	url := c.Scheme() + "://" + c.Request.Host + c.Request.URL.Path
+func (c *Context) Scheme() string {
+	if c.IsTLS() {
+		return "https"
+	}
+
+	header := c.Request.Header
+	if proto := header.Get("X-Forwarded-Proto"); proto != "" {
+		// Proxy chains may append values: "https, http"
+		if i := strings.IndexByte(proto, ','); i >= 0 {
+			proto = proto[:i]
+		}
+		return strings.ToLower(strings.TrimSpace(proto))
+	}
+	if proto := header.Get("X-Forwarded-Protocol"); proto != "" {
+		return strings.ToLower(strings.TrimSpace(proto))
+	}
+	if ssl := header.Get("X-Forwarded-Ssl"); strings.EqualFold(ssl, "on") {
+		return "https"
+	}
+	if scheme := header.Get("X-Url-Scheme"); scheme != "" {
+		return strings.ToLower(strings.TrimSpace(scheme))
+	}
+	return "http"
+}
+
+// ClientIP returns the IP address of the client connected to the server,
+// taken from the request's RemoteAddr without the port. Forwarding headers
+// such as X-Forwarded-For are ignored because any client can set them;
+// behind a trusted reverse proxy, read the address from the header the
+// proxy sets instead.
+//
+// Example:
+//
+//	c.Logger().Printf("login attempt from %s", c.ClientIP())
+func (c *Context) ClientIP() string {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(c.Request.RemoteAddr))
+	if err != nil {
+		return strings.TrimSpace(c.Request.RemoteAddr)
+	}
+	return host
+}
+
+// headerContainsToken reports whether the comma-separated header values
+// stored under name contain the given token, compared case-insensitively.
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, value := range header[http.CanonicalHeaderKey(name)] {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // FormFile returns the multipart form file with the given name.
 // It parses the request form data if necessary.
 //
@@ -204,19 +447,6 @@ func (c *Context) SaveUploadedFile(file *multipart.FileHeader, dst string) error
 	return err
 }
 
-// File sends a response with the content of the specified file.
-// It automatically sets the Content-Type header based on the file extension
-// and sets the status code to 200.
-//
-// Example:
-//
-//	c.File("./public/index.html")
-//	c.File("./assets/style.css")
-func (c *Context) File(filepath string) error {
-	http.ServeFile(c.Response, c.Request, filepath)
-	return nil
-}
-
 // BindJSON parses the request body as JSON and stores the result
 // in the value pointed to by obj. The request body is consumed
 // during this operation.
@@ -235,6 +465,30 @@ func (c *Context) BindJSON(obj interface{}) error {
 	return json.NewDecoder(c.Request.Body).Decode(obj)
 }
 
+// BindJSONStrict parses the request body as JSON like BindJSON, but rejects
+// objects containing fields that are not present in obj and any data
+// following the JSON value. Use it to surface client typos such as
+// "emial" instead of silently leaving fields zeroed.
+//
+// Example:
+//
+//	var user User
+//	if err := c.BindJSONStrict(&user); err != nil {
+//		c.JSON(400, map[string]string{"error": err.Error()})
+//		return
+//	}
+func (c *Context) BindJSONStrict(obj interface{}) error {
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(obj); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("goxpress: unexpected data after JSON body")
+	}
+	return nil
+}
+
 // Status sets the HTTP status code for the response.
 // If called multiple times, only the first call takes effect.
 // The status code is written to the response when the first
@@ -250,19 +504,33 @@ func (c *Context) Status(code int) {
 	}
 }
 
-// StatusCode returns the HTTP status code that was set for the response.
-// If no status code was explicitly set, it returns 0.
-func (c *Context) StatusCode() int {
-	// Since we don't store the status code in the context,
-	// we can't return it here. For now, we'll return 200 as default
-	// if the status has been written, otherwise 0.
-	// This is a limitation of the current implementation.
-	if c.statusCodeWritten {
-		// We don't have access to the actual status code that was written
-		// to the ResponseWriter, so we'll just return 200 as a placeholder
-		return 200
+// Header sets a response header. An empty value deletes the header.
+// Headers must be set before the status code is written.
+//
+// Example:
+//
+//	c.Header("Access-Control-Allow-Origin", "*")
+//	c.Header("X-Powered-By", "") // Remove the header
+func (c *Context) Header(key, value string) {
+	if value == "" {
+		c.Response.Header().Del(key)
+		return
 	}
-	return 0
+	c.Response.Header().Set(key, value)
+}
+
+// StatusCode returns the HTTP status code written to the response,
+// or 0 if no status code has been written yet. Middleware can call it
+// after c.Next() to branch on the outcome of the request.
+//
+// Example:
+//
+//	c.Next()
+//	if c.StatusCode() >= 500 {
+//		alerts.Notify(c.Request.URL.Path)
+//	}
+func (c *Context) StatusCode() int {
+	return c.Writer.Status()
 }
 
 // JSON serializes the given data to JSON and writes it to the response
@@ -334,7 +602,9 @@ func (c *Context) Redirect(code int, url string) error {
 
 // Next executes the next handler in the middleware chain.
 // If an error is provided, it will be stored in the context
-// for later processing by error handlers.
+// for later processing by error handlers. Called from an error handler,
+// Next runs the remaining error handlers instead, passing them the
+// provided error if any.
 //
 // This method should be called by middleware to continue processing
 // the request. If not called, the request processing stops.
@@ -351,9 +621,15 @@ func (c *Context) Redirect(code int, url string) error {
 //		log.Println("After")
 //	}
 func (c *Context) Next(err ...error) {
-	// Store error if provided
+	// Record error if provided
 	if len(err) > 0 && err[0] != nil {
-		c.err = err[0]
+		c.Error(err[0])
+	}
+
+	// Inside an error handler, continue with the next error handler
+	if c.errorHandlers != nil {
+		c.nextErrorHandler()
+		return
 	}
 
 	// Advance to next handler and execute it
@@ -401,16 +677,86 @@ func (c *Context) IsAborted() bool {
 	return c.aborted
 }
 
+// AbortWithStatus writes the given status code and aborts the request,
+// preventing pending handlers from being called.
+//
+// Example:
+//
+//	if !isAuthorized(c) {
+//		c.AbortWithStatus(401)
+//		return
+//	}
+func (c *Context) AbortWithStatus(code int) {
+	c.Status(code)
+	c.Abort()
+}
+
+// AbortWithStatusJSON aborts the request and writes data as a JSON
+// response with the given status code, in a single call.
+//
+// Example:
+//
+//	func AuthMiddleware(c *Context) {
+//		if !isAuthorized(c) {
+//			c.AbortWithStatusJSON(401, map[string]string{"error": "Unauthorized"})
+//			return
+//		}
+//		c.Next()
+//	}
+func (c *Context) AbortWithStatusJSON(code int, data interface{}) error {
+	c.Abort()
+	return c.JSON(code, data)
+}
+
+// AbortWithError writes the given status code, aborts the request and
+// records err with Error so the registered error handlers process it once
+// the middleware chain has finished. It returns the recorded entry.
+//
+// Example:
+//
+//	user, err := loadUser(c.Param("id"))
+//	if err != nil {
+//		c.AbortWithError(500, err).SetMeta("user lookup")
+//		return
+//	}
+func (c *Context) AbortWithError(code int, err error) *ErrorEntry {
+	c.Status(code)
+	c.Abort()
+	return c.Error(err)
+}
+
 // Set stores a key-value pair in the context's data store.
 // This data is available throughout the request lifecycle and
-// can be accessed by subsequent middleware and handlers.
+// can be accessed by subsequent middleware and handlers. Set and Get may
+// only be called from other goroutines if the engine enables concurrent
+// stores; see Engine.SetConcurrentStore. If the engine propagates values,
+// Set also adds the value to the request context under ContextKey(key);
+// see Engine.SetValuePropagation.
 //
 // Example:
 //
 //	c.Set("user_id", "123")
 //	c.Set("start_time", time.Now())
 func (c *Context) Set(key string, value interface{}) {
-	c.store[key] = value
+	if c.safeStore {
+		c.storeMu.Lock()
+		defer c.storeMu.Unlock()
+	}
+	c.propagateValue(key, value)
+	for i := range c.store {
+		if c.store[i].key == key {
+			c.store[i].value = value
+			return
+		}
+	}
+	c.store = append(c.store, storeEntry{key: key, value: value})
+}
+
+// propagateValue adds value to the request context under ContextKey(key).
+func (c *Context) propagateValue(key string, value interface{}) {
+	if c.propagateValues && c.Request != nil {
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), ContextKey(key), value))
+	}
 }
 
 // Get retrieves a value from the context's data store.
@@ -423,8 +769,25 @@ func (c *Context) Set(key string, value interface{}) {
 //		fmt.Println("User:", user)
 //	}
 func (c *Context) Get(key string) (interface{}, bool) {
-	value, exists := c.store[key]
-	return value, exists
+	if c.safeStore {
+		c.storeMu.RLock()
+		defer c.storeMu.RUnlock()
+	}
+	for _, entry := range c.store {
+		if entry.key == key {
+			return entry.value, true
+		}
+	}
+	return nil, false
+}
+
+// storeSnapshot returns a copy of the data store entries.
+func (c *Context) storeSnapshot() []storeEntry {
+	if c.safeStore {
+		c.storeMu.RLock()
+		defer c.storeMu.RUnlock()
+	}
+	return append([]storeEntry(nil), c.store...)
 }
 
 // MustGet retrieves a value from the context's data store.
@@ -435,7 +798,7 @@ func (c *Context) Get(key string) (interface{}, bool) {
 //
 //	user := c.MustGet("user").(User)
 func (c *Context) MustGet(key string) interface{} {
-	if value, exists := c.store[key]; exists {
+	if value, exists := c.Get(key); exists {
 		return value
 	}
 	panic("Key \"" + key + "\" does not exist")
@@ -458,3 +821,164 @@ func (c *Context) GetString(key string) (string, bool) {
 	}
 	return "", false
 }
+
+// Logger returns a logger scoped to the current request. Unless replaced
+// with SetLogger, it writes to the standard logger's output and prefixes
+// every message with the request ID (taken from the X-Request-ID header),
+// the HTTP method and the matched route pattern.
+//
+// Example:
+//
+//	c.Logger().Printf("loaded %d users", len(users))
+//	// Output: 2024/01/02 15:04:05 [a1b2c3] GET /users loaded 3 users
+func (c *Context) Logger() *log.Logger {
+	if c.logger == nil {
+		c.logger = log.New(log.Writer(), c.logPrefix(), log.Flags()|log.Lmsgprefix)
+	}
+	return c.logger
+}
+
+// SetLogger replaces the logger returned by Logger for the rest of the
+// request. Middleware can use it to attach a logger with a custom output
+// or additional tags.
+//
+// Example:
+//
+//	app.Use(func(c *goxpress.Context) {
+//		c.SetLogger(log.New(logFile, "[api] ", log.LstdFlags))
+//		c.Next()
+//	})
+func (c *Context) SetLogger(logger *log.Logger) {
+	c.logger = logger
+}
+
+// logPrefix builds the tag prepended to messages of the request logger.
+func (c *Context) logPrefix() string {
+	var b strings.Builder
+	if id := c.requestID(); id != "" {
+		b.WriteString("[")
+		b.WriteString(id)
+		b.WriteString("] ")
+	}
+	b.WriteString(c.Request.Method)
+	b.WriteString(" ")
+	if c.fullPath != "" {
+		b.WriteString(c.fullPath)
+	} else {
+		b.WriteString(c.Request.URL.Path)
+	}
+	b.WriteString(" ")
+	return b.String()
+}
+
+// requestID returns the ID of the current request, looking at the
+// incoming X-Request-ID header first and then at the response header,
+// where request ID middleware usually echoes generated IDs.
+func (c *Context) requestID() string {
+	if id := c.Request.Header.Get("X-Request-ID"); id != "" {
+		return id
+	}
+	return c.Response.Header().Get("X-Request-ID")
+}
+
+// Context returns the request's context.
+//
+// Deprecated: Context no longer embeds a context.Context field; it
+// implements context.Context itself. Pass c, or c.Request.Context() for
+// work that outlives the handler.
+func (c *Context) Context() context.Context {
+	if c.Request == nil {
+		return context.Background()
+	}
+	return c.Request.Context()
+}
+
+// Deadline returns the deadline of the request context, if any.
+// It implements context.Context.
+func (c *Context) Deadline() (deadline time.Time, ok bool) {
+	if c.Request == nil {
+		return time.Time{}, false
+	}
+	return c.Request.Context().Deadline()
+}
+
+// Done returns a channel that is closed when the request context is
+// canceled, for instance because a deadline set by middleware expired or
+// the client disconnected. For requests served by an Engine, it is also
+// closed once writing the response fails because the connection to the
+// client is broken, which the request context alone doesn't always report.
+// It implements context.Context.
+//
+// Example:
+//
+//	select {
+//	case result := <-work:
+//		c.JSON(200, result)
+//	case <-c.Done():
+//		return // Request canceled, nobody is waiting for the result
+//	}
+func (c *Context) Done() <-chan struct{} {
+	if c.Request == nil {
+		return nil
+	}
+	return c.Request.Context().Done()
+}
+
+// Err returns a non-nil error once the channel returned by Done is
+// closed: context.DeadlineExceeded if a deadline expired and
+// context.Canceled otherwise.
+// It implements context.Context.
+func (c *Context) Err() error {
+	if c.Request == nil {
+		return nil
+	}
+	return c.Request.Context().Err()
+}
+
+// IsClientGone reports whether the client went away: the connection was
+// closed or a write to it failed. Long-running handlers can check it to
+// stop computing a response nobody will read.
+//
+// Example:
+//
+//	for _, row := range rows {
+//		if c.IsClientGone() {
+//			return
+//		}
+//		writeRow(c.Response, row)
+//	}
+func (c *Context) IsClientGone() bool {
+	if c.writer.failed {
+		return true
+	}
+	if c.Request == nil {
+		return false
+	}
+	return errors.Is(c.Request.Context().Err(), context.Canceled)
+}
+
+// Value returns the value associated with key. String and ContextKey keys
+// are looked up in the data store populated by Set first, so values stored
+// by middleware are visible to any code that only receives a
+// context.Context; all other lookups are delegated to the request context.
+// It implements context.Context.
+//
+// Example:
+//
+//	c.Set("tenant", "acme")
+//	rows, err := db.QueryContext(c, query) // Driver sees "tenant" via Value
+func (c *Context) Value(key interface{}) interface{} {
+	name, ok := key.(string)
+	if k, isKey := key.(ContextKey); isKey {
+		name, ok = string(k), true
+	}
+	if ok {
+		if value, exists := c.Get(name); exists {
+			return value
+		}
+	}
+	if c.Request == nil {
+		return nil
+	}
+	return c.Request.Context().Value(key)
+}
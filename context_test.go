@@ -1,14 +1,20 @@
 package goxpress
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"log"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewContext(t *testing.T) {
@@ -25,16 +31,20 @@ func TestNewContext(t *testing.T) {
 		t.Error("Context should have the correct request")
 	}
 
-	if c.Response != w {
-		t.Error("Context should have the correct response writer")
+	if c.Writer == nil || c.Response != http.ResponseWriter(c.Writer) {
+		t.Error("Context should expose the wrapped response writer")
 	}
 
-	if c.params == nil {
-		t.Error("Context should have params map initialized")
+	if c.writer.ResponseWriter != w {
+		t.Error("Context should wrap the correct response writer")
 	}
 
-	if c.store == nil {
-		t.Error("Context should have store map initialized")
+	if len(c.params) != 0 {
+		t.Error("Context should have no params initially")
+	}
+
+	if len(c.store) != 0 {
+		t.Error("Context should have an empty store initially")
 	}
 
 	if c.index != -1 {
@@ -57,8 +67,8 @@ func TestContextReset(t *testing.T) {
 	c := NewContext(w, req)
 
 	// Set some data
-	c.params["id"] = "123"
-	c.store["user"] = "john"
+	c.params = append(c.params, param{key: "id", value: "123"})
+	c.Set("user", "john")
 	c.index = 5
 	c.aborted = true
 	c.statusCodeWritten = true
@@ -97,9 +107,9 @@ func TestContextParam(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	c := NewContext(w, req)
-	c.params = map[string]string{
-		"id":   "123",
-		"name": "john",
+	c.params = []param{
+		{key: "id", value: "123"},
+		{key: "name", value: "john"},
 	}
 
 	tests := []struct {
@@ -147,6 +157,31 @@ func TestContextQuery(t *testing.T) {
 	}
 }
 
+func TestContextQueryCached(t *testing.T) {
+	req := httptest.NewRequest("GET", "/search?q=golang", nil)
+	c := NewContext(httptest.NewRecorder(), req)
+
+	c.Query("q")
+	allocs := testing.AllocsPerRun(100, func() {
+		c.Query("q")
+		c.Query("page")
+	})
+	if allocs != 0 {
+		t.Errorf("Expected cached query lookups not to allocate, got %v allocs", allocs)
+	}
+
+	// Rewriting the URL invalidates the cached values
+	c.Request.URL.RawQuery = "q=rust"
+	if got := c.Query("q"); got != "rust" {
+		t.Errorf("Expected query of the rewritten URL, got %q", got)
+	}
+
+	c.reset()
+	if c.query != nil || c.queryRaw != "" {
+		t.Error("Expected reset to clear the cached query")
+	}
+}
+
 func TestContextBindJSON(t *testing.T) {
 	t.Run("ValidJSON", func(t *testing.T) {
 		jsonData := `{"name":"John","age":30,"email":"john@example.com"}`
@@ -199,6 +234,41 @@ func TestContextBindJSON(t *testing.T) {
 	})
 }
 
+func TestContextBindJSONStrict(t *testing.T) {
+	type user struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"Valid", `{"name":"John","email":"john@example.com"}`, false},
+		{"TrailingWhitespace", "{\"name\":\"John\"}\n", false},
+		{"UnknownField", `{"name":"John","emial":"john@example.com"}`, true},
+		{"TrailingGarbage", `{"name":"John"}garbage`, true},
+		{"SecondValue", `{"name":"John"}{"name":"Jane"}`, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/users", strings.NewReader(test.body))
+			c := NewContext(httptest.NewRecorder(), req)
+
+			var u user
+			err := c.BindJSONStrict(&u)
+			if test.wantErr && err == nil {
+				t.Error("BindJSONStrict should return error")
+			}
+			if !test.wantErr && err != nil {
+				t.Errorf("BindJSONStrict should not return error: %v", err)
+			}
+		})
+	}
+}
+
 func TestContextStatus(t *testing.T) {
 	req := httptest.NewRequest("GET", "/test", nil)
 	w := httptest.NewRecorder()
@@ -222,6 +292,22 @@ func TestContextStatus(t *testing.T) {
 	}
 }
 
+func TestContextHeader(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest("GET", "/test", nil))
+
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("X-Powered-By", "goxpress")
+	c.Header("X-Powered-By", "")
+
+	if w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Error("Header should set the response header")
+	}
+	if _, exists := w.Header()["X-Powered-By"]; exists {
+		t.Error("Header with empty value should delete the response header")
+	}
+}
+
 func TestContextStatusCode(t *testing.T) {
 	// Create a new context
 	w := httptest.NewRecorder()
@@ -233,10 +319,10 @@ func TestContextStatusCode(t *testing.T) {
 		t.Errorf("Expected status code 0, got %d", code)
 	}
 
-	// After writing status, should be 200 (our default placeholder)
+	// After writing status, should be the actual status code
 	c.Status(404)
-	if code := c.StatusCode(); code != 200 { // 200 because that's what our placeholder returns
-		t.Errorf("Expected status code 200 (placeholder), got %d", code)
+	if code := c.StatusCode(); code != 404 {
+		t.Errorf("Expected status code 404, got %d", code)
 	}
 }
 
@@ -319,6 +405,102 @@ func TestContextPostForm(t *testing.T) {
 	}
 }
 
+func TestContextRequestClassification(t *testing.T) {
+	t.Run("WebSocket", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/ws", nil)
+		req.Header.Set("Connection", "keep-alive, Upgrade")
+		req.Header.Set("Upgrade", "WebSocket")
+		c := NewContext(httptest.NewRecorder(), req)
+
+		if !c.IsWebSocket() {
+			t.Error("Expected request to be detected as WebSocket")
+		}
+
+		req.Header.Del("Upgrade")
+		if c.IsWebSocket() {
+			t.Error("Request without Upgrade header should not be WebSocket")
+		}
+	})
+
+	t.Run("AJAX", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/data", nil)
+		c := NewContext(httptest.NewRecorder(), req)
+
+		if c.IsAJAX() {
+			t.Error("Plain request should not be AJAX")
+		}
+
+		req.Header.Set("X-Requested-With", "XMLHttpRequest")
+		if !c.IsAJAX() {
+			t.Error("Expected request to be detected as AJAX")
+		}
+	})
+
+	t.Run("Scheme", func(t *testing.T) {
+		tests := []struct {
+			header   string
+			value    string
+			expected string
+		}{
+			{"", "", "http"},
+			{"X-Forwarded-Proto", "https", "https"},
+			{"X-Forwarded-Proto", "HTTPS, http", "https"},
+			{"X-Forwarded-Protocol", "https", "https"},
+			{"X-Forwarded-Ssl", "on", "https"},
+			{"X-Url-Scheme", "https", "https"},
+		}
+
+		for _, test := range tests {
+			req := httptest.NewRequest("GET", "/", nil)
+			if test.header != "" {
+				req.Header.Set(test.header, test.value)
+			}
+			c := NewContext(httptest.NewRecorder(), req)
+
+			if scheme := c.Scheme(); scheme != test.expected {
+				t.Errorf("%s=%q: expected scheme %q, got %q", test.header, test.value, test.expected, scheme)
+			}
+			if c.IsTLS() {
+				t.Error("Request without TLS state should not be TLS")
+			}
+		}
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "https://example.com/", nil)
+		c := NewContext(httptest.NewRecorder(), req)
+
+		if !c.IsTLS() {
+			t.Error("Expected TLS request to be detected")
+		}
+		if c.Scheme() != "https" {
+			t.Errorf("Expected scheme 'https', got '%s'", c.Scheme())
+		}
+	})
+
+	t.Run("ClientIP", func(t *testing.T) {
+		tests := []struct {
+			remoteAddr string
+			expected   string
+		}{
+			{"192.0.2.1:1234", "192.0.2.1"},
+			{"[2001:db8::1]:443", "2001:db8::1"},
+			{"192.0.2.7", "192.0.2.7"},
+		}
+
+		for _, test := range tests {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = test.remoteAddr
+			req.Header.Set("X-Forwarded-For", "203.0.113.9")
+			c := NewContext(httptest.NewRecorder(), req)
+
+			if ip := c.ClientIP(); ip != test.expected {
+				t.Errorf("RemoteAddr %q: expected client IP %q, got %q", test.remoteAddr, test.expected, ip)
+			}
+		}
+	})
+}
+
 func TestContextFile(t *testing.T) {
 	// Create a temporary file for testing
 	content := "Hello, World!"
@@ -513,6 +695,60 @@ func TestContextAbort(t *testing.T) {
 	}
 }
 
+func TestContextAbortWithStatus(t *testing.T) {
+	executed := false
+	next := func(c *Context) { executed = true }
+
+	t.Run("Status", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/test", nil))
+		c.handlers = []HandlerFunc{func(c *Context) { c.AbortWithStatus(401) }, next}
+		c.Next()
+
+		if w.Code != 401 || !c.IsAborted() || executed {
+			t.Errorf("Expected aborted 401, got %d (aborted=%v, next executed=%v)", w.Code, c.IsAborted(), executed)
+		}
+	})
+
+	t.Run("StatusJSON", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/test", nil))
+		c.handlers = []HandlerFunc{func(c *Context) {
+			c.AbortWithStatusJSON(403, map[string]string{"error": "Forbidden"})
+		}, next}
+		c.Next()
+
+		if w.Code != 403 || !c.IsAborted() || executed {
+			t.Errorf("Expected aborted 403, got %d (aborted=%v, next executed=%v)", w.Code, c.IsAborted(), executed)
+		}
+		if w.Body.String() != "{\"error\":\"Forbidden\"}\n" {
+			t.Errorf("Unexpected body %q", w.Body.String())
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		var handled error
+		app := New()
+		app.UseError(func(err error, c *Context) {
+			handled = err
+			c.String(500, "failed")
+		})
+		app.GET("/fail", func(c *Context) {
+			c.AbortWithError(502, fmt.Errorf("upstream down"))
+		}, next)
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/fail", nil))
+
+		if w.Code != 502 || executed {
+			t.Errorf("Expected aborted 502, got %d (next executed=%v)", w.Code, executed)
+		}
+		if handled == nil || handled.Error() != "upstream down" {
+			t.Errorf("Error handlers should receive the error, got %v", handled)
+		}
+	})
+}
+
 func TestContextSetGet(t *testing.T) {
 	req := httptest.NewRequest("GET", "/test", nil)
 	w := httptest.NewRecorder()
@@ -611,6 +847,93 @@ func TestContextGetString(t *testing.T) {
 	}
 }
 
+func TestContextLogger(t *testing.T) {
+	var logOutput strings.Builder
+	log.SetOutput(&logOutput)
+	defer log.SetOutput(os.Stderr)
+
+	app := New()
+	app.GET("/users/:id", func(c *Context) {
+		c.Logger().Printf("loading user %s", c.Param("id"))
+		c.String(200, c.FullPath())
+	})
+
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Body.String() != "/users/:id" {
+		t.Errorf("Expected FullPath '/users/:id', got '%s'", w.Body.String())
+	}
+
+	if !strings.Contains(logOutput.String(), "[req-1] GET /users/:id loading user 42") {
+		t.Errorf("Log should be tagged with request ID, method and route, got '%s'", logOutput.String())
+	}
+
+	t.Run("SetLogger", func(t *testing.T) {
+		var custom strings.Builder
+		c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		c.SetLogger(log.New(&custom, "custom: ", 0))
+		c.Logger().Print("hello")
+
+		if custom.String() != "custom: hello\n" {
+			t.Errorf("Expected custom logger output, got '%s'", custom.String())
+		}
+	})
+}
+
+func TestContextImplementsContext(t *testing.T) {
+	type ctxKey struct{}
+
+	parent, cancel := context.WithTimeout(context.WithValue(context.Background(), ctxKey{}, "from-request"), time.Hour)
+	req := httptest.NewRequest("GET", "/", nil).WithContext(parent)
+	c := NewContext(httptest.NewRecorder(), req)
+
+	if _, ok := c.Deadline(); !ok {
+		t.Error("Deadline should be taken from the request context")
+	}
+
+	c.Set("tenant", "acme")
+	if c.Value("tenant") != "acme" {
+		t.Errorf("Expected Set value through Value, got %v", c.Value("tenant"))
+	}
+	if c.Value(ctxKey{}) != "from-request" {
+		t.Errorf("Expected request context value, got %v", c.Value(ctxKey{}))
+	}
+	if c.Context().Value(ctxKey{}) != "from-request" {
+		t.Errorf("Expected the deprecated accessor to return the request context")
+	}
+
+	select {
+	case <-c.Done():
+		t.Fatal("Done should not be closed before cancellation")
+	default:
+	}
+
+	cancel()
+	<-c.Done()
+	if c.Err() != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", c.Err())
+	}
+
+	t.Run("AfterReset", func(t *testing.T) {
+		c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		c.reset()
+		defer contextPool.Put(c)
+
+		if c.Done() != nil || c.Err() != nil || c.Value("tenant") != nil {
+			t.Error("Released Context should behave like an empty context")
+		}
+		if _, ok := c.Deadline(); ok {
+			t.Error("Released Context should have no deadline")
+		}
+		if c.Context() == nil {
+			t.Error("Released Context should return a background context")
+		}
+	})
+}
+
 func TestContextPool(t *testing.T) {
 	req := httptest.NewRequest("GET", "/test", nil)
 	w := httptest.NewRecorder()
@@ -620,7 +943,7 @@ func TestContextPool(t *testing.T) {
 
 	// Use and reset the context
 	c1.Set("test", "value")
-	c1.params["id"] = "123"
+	c1.params = append(c1.params, param{key: "id", value: "123"})
 	c1.index = 5
 	c1.aborted = true
 
@@ -649,7 +972,7 @@ func BenchmarkContextParam(b *testing.B) {
 	req := httptest.NewRequest("GET", "/users/123", nil)
 	w := httptest.NewRecorder()
 	c := NewContext(w, req)
-	c.params = map[string]string{"id": "123"}
+	c.params = []param{{key: "id", value: "123"}}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -703,3 +1026,194 @@ func BenchmarkContextFromPool(b *testing.B) {
 		contextPool.Put(c)
 	}
 }
+
+func TestContextConcurrentStore(t *testing.T) {
+	app := New()
+	app.SetConcurrentStore(true)
+	app.GET("/", func(c *Context) {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					c.Set(fmt.Sprintf("key%d", i), j)
+					c.Get("key0")
+				}
+			}(i)
+		}
+		wg.Wait()
+		value, _ := c.Get("key7")
+		c.String(200, "%v", value)
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Body.String() != "99" {
+		t.Errorf("Expected concurrently stored value, got %q", w.Body.String())
+	}
+}
+
+func TestContextValuePropagation(t *testing.T) {
+	lookup := func(ctx context.Context) string {
+		tenant, _ := ctx.Value(ContextKey("tenant")).(string)
+		return tenant
+	}
+
+	app := New()
+	app.GET("/", func(c *Context) {
+		c.Set("tenant", "acme")
+		c.String(200, "%s|%s", lookup(c.Request.Context()), lookup(c))
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Body.String() != "|acme" {
+		t.Errorf("Expected values to stay out of the request context by default, got %q", w.Body.String())
+	}
+
+	app.SetValuePropagation(true)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Body.String() != "acme|acme" {
+		t.Errorf("Expected value in the request context, got %q", w.Body.String())
+	}
+}
+
+func TestContextDerivedContextSurvivesSet(t *testing.T) {
+	app := New()
+	app.SetValuePropagation(true)
+	app.GET("/", func(c *Context) {
+		ctx, cancel := context.WithCancel(c)
+		defer cancel()
+		time.Sleep(10 * time.Millisecond) // Let any propagation goroutine wait on c.Done()
+
+		// Set replaces the request context; the derived context must keep
+		// following the Context's cancellation
+		c.Set("tenant", "acme")
+		select {
+		case <-ctx.Done():
+			t.Error("Derived context should not be canceled by Set")
+		default:
+		}
+		if c.Err() != nil || ctx.Value(ContextKey("tenant")) != "acme" {
+			t.Errorf("Unexpected state after Set: err %v", c.Err())
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		c.clientCancel()
+		<-ctx.Done()
+		if ctx.Err() != context.Canceled || c.Err() != context.Canceled {
+			t.Errorf("Expected both contexts canceled, got %v and %v", ctx.Err(), c.Err())
+		}
+		c.String(200, "ok")
+	})
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		if w.Body.String() != "ok" {
+			t.Fatalf("Expected 'ok', got %q", w.Body.String())
+		}
+	}
+}
+
+// brokenPipeWriter is a ResponseWriter whose connection is gone.
+type brokenPipeWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (brokenPipeWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write: broken pipe")
+}
+
+func TestContextClientGoneOnWriteFailure(t *testing.T) {
+	app := New()
+	app.GET("/", func(c *Context) {
+		done := c.Done()
+		if c.IsClientGone() || c.Err() != nil {
+			t.Error("Client should not be gone before writing")
+		}
+		c.String(200, "hello")
+		select {
+		case <-done:
+		default:
+			t.Error("Expected Done to fire after a failed write")
+		}
+		if !c.IsClientGone() || c.Err() != context.Canceled {
+			t.Errorf("Expected client to be gone, got err %v", c.Err())
+		}
+	})
+
+	app.ServeHTTP(brokenPipeWriter{httptest.NewRecorder()}, httptest.NewRequest("GET", "/", nil))
+}
+
+func TestContextClientGoneOnDisconnect(t *testing.T) {
+	canceled := make(chan bool, 1)
+	app := New()
+	app.GET("/slow", func(c *Context) {
+		select {
+		case <-c.Done():
+			canceled <- c.IsClientGone()
+		case <-time.After(5 * time.Second):
+			canceled <- false
+		}
+	})
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/slow", nil)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	http.DefaultClient.Do(req)
+
+	if !<-canceled {
+		t.Error("Expected Done to fire and the client to be gone after disconnecting")
+	}
+}
+
+func TestContextBodyNotAllowedIsNotClientGone(t *testing.T) {
+	gone := make(chan bool, 1)
+	app := New()
+	app.GET("/", func(c *Context) {
+		c.Status(304)
+		c.Response.Write([]byte("ignored")) // Fails with http.ErrBodyNotAllowed
+		gone <- c.IsClientGone()
+	})
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if <-gone {
+		t.Error("A response without body should not mark the client as gone")
+	}
+}
+
+func TestContextDoneFromGoroutine(t *testing.T) {
+	app := New()
+	app.GET("/", func(c *Context) {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			if c.Err() != nil {
+				t.Error("Request context should not be done yet")
+			}
+			c.Done()
+		}()
+		c.String(200, c.Request.URL.Path)
+		<-done
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != 200 {
+		t.Errorf("Expected 200, got %d", w.Code)
+	}
+}
@@ -0,0 +1,113 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the signed-cookie codec, which keeps clients from
+// forging or altering cookie values.
+package goxpress
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrInvalidCookie is returned by SignedCookie for cookies that were not
+// signed with the engine's secret or were altered.
+var ErrInvalidCookie = errors.New("goxpress: invalid cookie signature")
+
+// SetCookieSecret sets the secret signing the values of SetSignedCookie
+// and flash messages. Use at least 32 random bytes, shared by all replicas
+// serving the application; changing it invalidates existing cookies. It
+// panics if secret is empty.
+// Returns the Engine instance for method chaining.
+//
+// Example:
+//
+//	app.SetCookieSecret([]byte(os.Getenv("COOKIE_SECRET")))
+func (e *Engine) SetCookieSecret(secret []byte) *Engine {
+	if len(secret) == 0 {
+		panic("goxpress: cookie secret must not be empty")
+	}
+	e.cookieSecret = append([]byte(nil), secret...)
+	return e
+}
+
+// SetSignedCookie adds a Set-Cookie header with the cookie, its value
+// signed with the secret set by Engine.SetCookieSecret. The value remains
+// readable by the client, so it must not hold secrets. It panics if no
+// secret is set.
+//
+// Example:
+//
+//	c.SetSignedCookie(&http.Cookie{Name: "theme", Value: "dark", Path: "/", MaxAge: 86400})
+func (c *Context) SetSignedCookie(cookie *http.Cookie) {
+	signed := *cookie
+	signed.Value = signCookieValue(c.cookieSecret(), cookie.Name, cookie.Value)
+	http.SetCookie(c.Response, &signed)
+}
+
+// SignedCookie returns the value of the named cookie set with
+// SetSignedCookie. It returns http.ErrNoCookie if the request has no such
+// cookie and ErrInvalidCookie if its signature does not match. It panics
+// if no secret is set.
+//
+// Example:
+//
+//	theme, err := c.SignedCookie("theme")
+//	if err != nil {
+//		theme = "light"
+//	}
+func (c *Context) SignedCookie(name string) (string, error) {
+	cookie, err := c.Request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	value, ok := verifyCookieValue(c.cookieSecret(), name, cookie.Value)
+	if !ok {
+		return "", ErrInvalidCookie
+	}
+	return value, nil
+}
+
+// cookieSecret returns the engine's cookie secret, panicking if none is set.
+func (c *Context) cookieSecret() []byte {
+	if c.engine == nil || len(c.engine.cookieSecret) == 0 {
+		panic("goxpress: signed cookies require Engine.SetCookieSecret")
+	}
+	return c.engine.cookieSecret
+}
+
+// signCookieValue encodes value for the cookie name, followed by a
+// signature covering both, so values can't be moved between cookies.
+func signCookieValue(secret []byte, name, value string) string {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(value))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(cookieMAC(secret, name, encoded))
+}
+
+// verifyCookieValue returns the value encoded by signCookieValue if its
+// signature is valid.
+func verifyCookieValue(secret []byte, name, signed string) (string, bool) {
+	i := strings.LastIndexByte(signed, '.')
+	if i < 0 {
+		return "", false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signed[i+1:])
+	if err != nil || !hmac.Equal(mac, cookieMAC(secret, name, signed[:i])) {
+		return "", false
+	}
+	value, err := base64.RawURLEncoding.DecodeString(signed[:i])
+	if err != nil {
+		return "", false
+	}
+	return string(value), true
+}
+
+// cookieMAC returns the HMAC-SHA256 of the encoded value of a cookie.
+func cookieMAC(secret []byte, name, encoded string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(name))
+	mac.Write([]byte{'='})
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
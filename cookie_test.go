@@ -0,0 +1,58 @@
+package goxpress
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSignedCookie(t *testing.T) {
+	app := New().SetCookieSecret([]byte("0123456789abcdef0123456789abcdef"))
+	app.GET("/set", func(c *Context) {
+		c.SetSignedCookie(&http.Cookie{Name: "theme", Value: "dark; blue", Path: "/"})
+	})
+	app.GET("/get", func(c *Context) {
+		value, err := c.SignedCookie("theme")
+		if err != nil {
+			c.String(400, err.Error())
+			return
+		}
+		c.String(200, value)
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/set", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value == "dark; blue" {
+		t.Fatalf("Expected one signed cookie, got %v", cookies)
+	}
+
+	get := func(cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/get", nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get(cookies[0]); w.Code != 200 || w.Body.String() != "dark; blue" {
+		t.Errorf("Expected the signed value, got %d %q", w.Code, w.Body.String())
+	}
+	if w := get(nil); w.Code != 400 || w.Body.String() != http.ErrNoCookie.Error() {
+		t.Errorf("Expected ErrNoCookie, got %d %q", w.Code, w.Body.String())
+	}
+
+	tampered := []*http.Cookie{
+		{Name: "theme", Value: "ZGFyaw." + cookies[0].Value[len(cookies[0].Value)-43:]},
+		{Name: "theme", Value: "dark"},
+		{Name: "theme", Value: signCookieValue([]byte("other secret"), "theme", "dark")},
+		{Name: "theme", Value: signCookieValue(app.cookieSecret, "lang", "dark")},
+	}
+	for _, cookie := range tampered {
+		if w := get(cookie); w.Code != 400 || w.Body.String() != ErrInvalidCookie.Error() {
+			t.Errorf("Expected ErrInvalidCookie for %q, got %d %q", cookie.Value, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package goxpress
+
+import (
+	"html"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCSPNonce(t *testing.T) {
+	path := writeTempFile(t, "page.html", `<script nonce="{{cspNonce .Ctx}}">init()</script>`)
+
+	app := New()
+	app.SetFuncMap(CSPFuncMap())
+	if err := app.LoadHTMLGlob(filepath.Join(filepath.Dir(path), "*.html")); err != nil {
+		t.Fatalf("LoadHTMLGlob should not return error: %v", err)
+	}
+	app.Use(CSPNonce(CSPConfig{Policy: "script-src 'nonce-{nonce}'"}))
+	app.GET("/", func(c *Context) {
+		c.Render(200, "page.html", map[string]interface{}{"Ctx": c})
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	policy := w.Header().Get("Content-Security-Policy")
+	if !strings.HasPrefix(policy, "script-src 'nonce-") || strings.Contains(policy, CSPNoncePlaceholder) {
+		t.Fatalf("Unexpected policy %q", policy)
+	}
+	nonce := strings.TrimSuffix(strings.TrimPrefix(policy, "script-src 'nonce-"), "'")
+	if len(nonce) != 24 {
+		t.Errorf("Expected a 16-byte base64 nonce, got %q", nonce)
+	}
+	// html/template may escape characters such as "+" in the attribute
+	if html.UnescapeString(w.Body.String()) != `<script nonce="`+nonce+`">init()</script>` {
+		t.Errorf("Expected the nonce in the template, got %q", w.Body.String())
+	}
+
+	w2 := httptest.NewRecorder()
+	app.ServeHTTP(w2, httptest.NewRequest("GET", "/", nil))
+	if w2.Header().Get("Content-Security-Policy") == policy {
+		t.Error("Each request should get a fresh nonce")
+	}
+}
+
+func TestCSPNonceReportOnly(t *testing.T) {
+	var nonce string
+
+	app := New()
+	app.Use(CSPNonce(CSPConfig{ReportOnly: true}))
+	app.GET("/", func(c *Context) {
+		nonce = c.CSPNonce()
+		c.Status(204)
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Header().Get("Content-Security-Policy") != "" {
+		t.Error("Report-only mode should not send an enforced policy")
+	}
+	expected := strings.Replace(DefaultCSPPolicy, CSPNoncePlaceholder, nonce, -1)
+	if nonce == "" || w.Header().Get("Content-Security-Policy-Report-Only") != expected {
+		t.Errorf("Unexpected report-only policy %q", w.Header().Get("Content-Security-Policy-Report-Only"))
+	}
+}
@@ -0,0 +1,416 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the API documentation pages served by MountDocs.
+package goxpress
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// DocsUI selects the page used to browse the API documentation.
+type DocsUI int
+
+const (
+	// DocsSwaggerUI renders the documentation with Swagger UI.
+	DocsSwaggerUI DocsUI = iota
+
+	// DocsReDoc renders the documentation with ReDoc.
+	DocsReDoc
+)
+
+// DocsConfig defines configuration options for MountDocsWithConfig.
+type DocsConfig struct {
+	// Title is the page title and the title of the generated spec.
+	// If empty, defaults to "API".
+	Title string
+
+	// Version is the API version of the generated spec.
+	// If empty, defaults to "1.0.0".
+	Version string
+
+	// UI selects the documentation page loaded from AssetsURL. Defaults
+	// to DocsSwaggerUI. It is ignored if AssetsURL is empty.
+	UI DocsUI
+
+	// Spec is an OpenAPI document in JSON or YAML, e.g. embedded with
+	// go:embed, served instead of the spec generated from the routes.
+	Spec []byte
+
+	// AssetsURL is the base URL the script and stylesheet of UI are
+	// loaded from, e.g. a path where the application serves its own copy
+	// of swagger-ui-dist or of the ReDoc bundles, or a CDN URL pinned to a
+	// release. If empty, the page uses a minimal viewer embedded in the
+	// binary instead, which loads nothing from third parties.
+	AssetsURL string
+
+	// Integrity holds the Subresource Integrity hashes of the UI's assets,
+	// keyed by file name: "swagger-ui.css" and "swagger-ui-bundle.js" for
+	// Swagger UI, "redoc.standalone.js" for ReDoc. Browsers refuse to load
+	// an asset that doesn't match its hash.
+	Integrity map[string]string
+}
+
+// docsUIAssets holds the script and stylesheet of the built-in viewer.
+//
+//go:embed docsui
+var docsUIAssets embed.FS
+
+// docsViewerTemplate is the page of the built-in viewer.
+var docsViewerTemplate = template.Must(template.New("viewer").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.AssetsURL}}/viewer.css">
+</head>
+<body>
+<main id="docs" data-spec-url="{{.SpecURL}}"></main>
+<script src="{{.AssetsURL}}/viewer.js"></script>
+</body>
+</html>
+`))
+
+var docsTemplates = map[DocsUI]*template.Template{
+	DocsSwaggerUI: template.Must(template.New("swagger").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.AssetsURL}}/swagger-ui.css"{{with index .Integrity "swagger-ui.css"}} integrity="{{.}}" crossorigin="anonymous"{{end}}>
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{{.AssetsURL}}/swagger-ui-bundle.js"{{with index .Integrity "swagger-ui-bundle.js"}} integrity="{{.}}" crossorigin="anonymous"{{end}}></script>
+<script>window.ui = SwaggerUIBundle({url: {{.SpecURL}}, dom_id: "#swagger-ui"});</script>
+</body>
+</html>
+`)),
+	DocsReDoc: template.Must(template.New("redoc").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<redoc spec-url="{{.SpecURL}}"></redoc>
+<script src="{{.AssetsURL}}/redoc.standalone.js"{{with index .Integrity "redoc.standalone.js"}} integrity="{{.}}" crossorigin="anonymous"{{end}}></script>
+</body>
+</html>
+`)),
+}
+
+// MountDocs serves API documentation under prefix using the default
+// configuration: a page at prefix rendering, with a viewer embedded in
+// the binary, an OpenAPI 3 spec generated from the registered routes at
+// prefix + "/openapi.json".
+// The spec is generated on each request, so routes registered after
+// MountDocs are included. Routes registered with Typed also document their
+// query parameters and the JSON schemas of their request and response
+// types.
+// Returns the Engine instance for method chaining.
+//
+// Example:
+//
+//	app.MountDocs("/docs")
+func (e *Engine) MountDocs(prefix string) *Engine {
+	return e.MountDocsWithConfig(prefix, DocsConfig{})
+}
+
+// MountDocsWithConfig serves API documentation under prefix with custom
+// configuration. A spec given in config.Spec is served at
+// prefix + "/openapi.json", or prefix + "/openapi.yaml" if it is not JSON.
+// The page, the spec and the built-in viewer ship with the binary, so the
+// documentation works offline. Swagger UI and ReDoc are only used when
+// AssetsURL is set; their script and stylesheet are then loaded from it
+// and checked against the hashes in Integrity.
+// Returns the Engine instance for method chaining.
+//
+// Example:
+//
+//	//go:embed openapi.yaml
+//	var spec []byte
+//
+//	app.MountDocsWithConfig("/docs", goxpress.DocsConfig{
+//		Title:     "Billing API",
+//		Spec:      spec,
+//		UI:        goxpress.DocsReDoc,
+//		AssetsURL: "/static/redoc", // Self-hosted copy of the ReDoc bundles
+//	})
+func (e *Engine) MountDocsWithConfig(prefix string, config DocsConfig) *Engine {
+	// Set defaults
+	if config.Title == "" {
+		config.Title = "API"
+	}
+	if config.Version == "" {
+		config.Version = "1.0.0"
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	specPath := prefix + "/openapi.json"
+	specType := "application/json"
+	if len(config.Spec) > 0 && !json.Valid(config.Spec) {
+		specPath = prefix + "/openapi.yaml"
+		specType = "application/yaml"
+	}
+
+	var page bytes.Buffer
+	tmpl, ok := docsTemplates[config.UI]
+	if !ok {
+		tmpl = docsTemplates[DocsSwaggerUI]
+	}
+	if config.AssetsURL == "" {
+		tmpl = docsViewerTemplate
+		config.AssetsURL = prefix + "/assets"
+		e.mountDocsViewer(config.AssetsURL)
+	}
+	tmpl.Execute(&page, map[string]interface{}{
+		"Title":     config.Title,
+		"AssetsURL": strings.TrimSuffix(config.AssetsURL, "/"),
+		"SpecURL":   specPath,
+		"Integrity": config.Integrity,
+	})
+
+	pagePath := prefix
+	if pagePath == "" {
+		pagePath = "/"
+	}
+	e.GET(pagePath, func(c *Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+	})
+	e.GET(specPath, func(c *Context) {
+		spec := config.Spec
+		if len(spec) == 0 {
+			spec = e.openAPISpec(config.Title, config.Version, prefix)
+		}
+		c.Data(http.StatusOK, specType, spec)
+	})
+	return e
+}
+
+// mountDocsViewer serves the files of the built-in viewer under path.
+func (e *Engine) mountDocsViewer(path string) {
+	for name, contentType := range map[string]string{
+		"viewer.js":  "text/javascript; charset=utf-8",
+		"viewer.css": "text/css; charset=utf-8",
+	} {
+		data, _ := docsUIAssets.ReadFile("docsui/" + name)
+		contentType := contentType
+		e.GET(path+"/"+name, func(c *Context) {
+			c.Data(http.StatusOK, contentType, data)
+		})
+	}
+}
+
+// openAPISpec generates an OpenAPI 3 document listing the registered
+// routes, leaving out the documentation routes under docsPrefix.
+func (e *Engine) openAPISpec(title, version, docsPrefix string) []byte {
+	paths := make(map[string]map[string]interface{})
+	for _, route := range e.Routes() {
+		if route.Path == docsPrefix || strings.HasPrefix(route.Path, docsPrefix+"/") {
+			continue
+		}
+
+		path, params := openAPIPath(route.Path)
+		operation := map[string]interface{}{
+			"responses": map[string]interface{}{
+				"default": map[string]string{"description": "Response"},
+			},
+		}
+		var parameters []map[string]interface{}
+		for _, name := range params {
+			parameters = append(parameters, map[string]interface{}{
+				"name":     name,
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]string{"type": "string"},
+			})
+		}
+
+		if types, ok := e.routeHandlerTypes(route); ok {
+			for _, name := range queryFields(types.request) {
+				parameters = append(parameters, map[string]interface{}{
+					"name":   name,
+					"in":     "query",
+					"schema": map[string]string{"type": "string"},
+				})
+			}
+			switch route.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch:
+				operation["requestBody"] = map[string]interface{}{
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": jsonSchema(types.request, nil)},
+					},
+				}
+			}
+			if types.response == reflect.TypeOf(struct{}{}) {
+				operation["responses"] = map[string]interface{}{
+					"204": map[string]string{"description": "No Content"},
+				}
+			} else {
+				operation["responses"] = map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "OK",
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{"schema": jsonSchema(types.response, nil)},
+						},
+					},
+				}
+			}
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+
+		if paths[path] == nil {
+			paths[path] = make(map[string]interface{})
+		}
+		paths[path][strings.ToLower(route.Method)] = operation
+	}
+
+	spec, _ := json.Marshal(map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    map[string]string{"title": title, "version": version},
+		"paths":   paths,
+	})
+	return spec
+}
+
+// openAPIPath converts a route pattern to an OpenAPI path template and
+// returns the names of its parameters, e.g. "/users/:id" becomes
+// "/users/{id}".
+func openAPIPath(pattern string) (string, []string) {
+	var params []string
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if segment != "" && (segment[0] == ':' || segment[0] == '*') {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// handlerTypes holds the request and response types of a handler
+// registered with Typed.
+type handlerTypes struct {
+	request  reflect.Type
+	response reflect.Type
+}
+
+// routeHandlerTypes returns the types of the route's handler if it was
+// registered with Typed.
+func (e *Engine) routeHandlerTypes(route RouteInfo) (*handlerTypes, bool) {
+	node, _ := e.router.getRoute(route.Method, route.Path)
+	if node == nil || node.types == nil {
+		return nil, false
+	}
+	return node.types, true
+}
+
+// queryFields returns the names of the fields of typ bound from the query
+// string with a `query` tag.
+func queryFields(typ reflect.Type) []string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var names []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Anonymous && field.Tag.Get("query") == "" && field.Type.Kind() == reflect.Struct {
+			names = append(names, queryFields(field.Type)...)
+			continue
+		}
+		if name := field.Tag.Get("query"); name != "" && name != "-" && field.PkgPath == "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// jsonSchema describes the JSON encoding of typ as an OpenAPI schema.
+// seen holds the struct types being described, to stop at recursive types.
+func jsonSchema(typ reflect.Type, seen map[reflect.Type]bool) map[string]interface{} {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == reflect.TypeOf(time.Time{}):
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case typ.Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()):
+		return map[string]interface{}{}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": jsonSchema(typ.Elem(), seen)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": jsonSchema(typ.Elem(), seen)}
+	case reflect.Struct:
+		if seen[typ] {
+			return map[string]interface{}{"type": "object"}
+		}
+		if seen == nil {
+			seen = make(map[reflect.Type]bool)
+		}
+		seen[typ] = true
+		defer delete(seen, typ)
+
+		properties := make(map[string]interface{})
+		jsonProperties(typ, seen, properties)
+		return map[string]interface{}{"type": "object", "properties": properties}
+	}
+	return map[string]interface{}{}
+}
+
+// jsonProperties adds the JSON-encoded fields of the struct type typ to
+// properties, flattening embedded structs as encoding/json does.
+func jsonProperties(typ reflect.Type, seen map[reflect.Type]bool, properties map[string]interface{}) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (field.PkgPath != "" && !field.Anonymous) {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			jsonProperties(fieldType, seen, properties)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = jsonSchema(field.Type, seen)
+	}
+}
@@ -0,0 +1,99 @@
+package goxpress
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMountDocs(t *testing.T) {
+	app := New()
+	app.MountDocs("/docs")
+	app.GET("/users/:id", func(c *Context) {})
+	app.POST("/users", func(c *Context) {})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/docs", nil))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `<script src="/docs/assets/viewer.js">`) {
+		t.Fatalf("Expected built-in viewer page, got %d %q", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `data-spec-url="/docs/openapi.json"`) {
+		t.Errorf("Expected page to load the spec, got %q", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/docs/openapi.json", nil))
+	var spec struct {
+		OpenAPI string                                       `json:"openapi"`
+		Paths   map[string]map[string]map[string]interface{} `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("Spec is not valid JSON: %v", err)
+	}
+	if spec.OpenAPI != "3.0.3" || len(spec.Paths) != 2 {
+		t.Errorf("Expected 2 documented paths, got %v", spec.Paths)
+	}
+	if _, ok := spec.Paths["/users/{id}"]["get"]["parameters"]; !ok {
+		t.Errorf("Expected path parameter for /users/{id}, got %v", spec.Paths["/users/{id}"])
+	}
+	if _, ok := spec.Paths["/users"]["post"]; !ok {
+		t.Errorf("Expected POST /users, got %v", spec.Paths["/users"])
+	}
+}
+
+func TestMountDocsWithSpec(t *testing.T) {
+	app := New()
+	app.MountDocsWithConfig("/docs", DocsConfig{
+		UI:        DocsReDoc,
+		AssetsURL: "/static/redoc",
+		Spec:      []byte("openapi: 3.0.3\n"),
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/docs", nil))
+	if !strings.Contains(w.Body.String(), `<redoc spec-url="/docs/openapi.yaml">`) {
+		t.Errorf("Expected ReDoc page, got %q", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/docs/openapi.yaml", nil))
+	if w.Body.String() != "openapi: 3.0.3\n" || w.Header().Get("Content-Type") != "application/yaml" {
+		t.Errorf("Expected the provided spec, got %q (%s)", w.Body.String(), w.Header().Get("Content-Type"))
+	}
+}
+
+func TestMountDocsAssets(t *testing.T) {
+	app := New()
+	app.MountDocs("/docs")
+	app.MountDocsWithConfig("/internal/docs", DocsConfig{
+		AssetsURL: "/static/swagger-ui/",
+		Integrity: map[string]string{"swagger-ui-bundle.js": "sha384-abc"},
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/docs", nil))
+	if strings.Contains(w.Body.String(), "://") {
+		t.Errorf("Expected no third-party assets by default, got %q", w.Body.String())
+	}
+	for path, contentType := range map[string]string{
+		"/docs/assets/viewer.js":  "text/javascript; charset=utf-8",
+		"/docs/assets/viewer.css": "text/css; charset=utf-8",
+	} {
+		w = httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != 200 || w.Body.Len() == 0 || w.Header().Get("Content-Type") != contentType {
+			t.Errorf("Expected embedded %s, got %d %s", path, w.Code, w.Header().Get("Content-Type"))
+		}
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/internal/docs", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, `<script src="/static/swagger-ui/swagger-ui-bundle.js" integrity="sha384-abc" crossorigin="anonymous">`) {
+		t.Errorf("Expected script with integrity hash, got %q", body)
+	}
+	if !strings.Contains(body, `<link rel="stylesheet" href="/static/swagger-ui/swagger-ui.css">`) {
+		t.Errorf("Expected stylesheet without integrity hash, got %q", body)
+	}
+}
@@ -0,0 +1,118 @@
+// Minimal API documentation viewer embedded by MountDocs. It renders the
+// operations of the OpenAPI document at the data-spec-url of #docs without
+// loading any third-party code. Content of the spec is only ever inserted
+// as text.
+(function () {
+	"use strict";
+
+	var root = document.getElementById("docs");
+	var methods = ["get", "put", "post", "delete", "options", "head", "patch", "trace"];
+
+	function el(tag, className, text) {
+		var node = document.createElement(tag);
+		if (className) {
+			node.className = className;
+		}
+		if (text !== undefined) {
+			node.textContent = text;
+		}
+		return node;
+	}
+
+	function json(value) {
+		return el("pre", "", JSON.stringify(value, null, 2));
+	}
+
+	function parameters(list, body) {
+		if (!list || !list.length) {
+			return;
+		}
+		body.appendChild(el("h3", "", "Parameters"));
+		var table = el("table");
+		list.forEach(function (p) {
+			var row = el("tr");
+			row.appendChild(el("td", "", p.name + (p.required ? " *" : "")));
+			row.appendChild(el("td", "", p["in"]));
+			row.appendChild(el("td", "", (p.schema && p.schema.type) || ""));
+			row.appendChild(el("td", "", p.description || ""));
+			table.appendChild(row);
+		});
+		body.appendChild(table);
+	}
+
+	function content(title, value, body) {
+		var media = value && value.content && value.content["application/json"];
+		body.appendChild(el("h3", "", title));
+		if (value && value.description) {
+			body.appendChild(el("p", "", value.description));
+		}
+		if (media && media.schema) {
+			body.appendChild(json(media.schema));
+		}
+	}
+
+	function operation(path, method, op) {
+		var details = el("details", "operation");
+		var summary = el("summary");
+		summary.appendChild(el("span", "method " + method, method));
+		summary.appendChild(document.createTextNode(path));
+		if (op.summary) {
+			summary.appendChild(document.createTextNode(" — " + op.summary));
+		}
+		details.appendChild(summary);
+
+		var body = el("div", "body");
+		if (op.description) {
+			body.appendChild(el("p", "", op.description));
+		}
+		parameters(op.parameters, body);
+		if (op.requestBody) {
+			content("Request body", op.requestBody, body);
+		}
+		Object.keys(op.responses || {}).forEach(function (status) {
+			content("Response " + status, op.responses[status], body);
+		});
+		details.appendChild(body);
+		return details;
+	}
+
+	function render(spec) {
+		var info = spec.info || {};
+		root.appendChild(el("h1", "", info.title || "API"));
+		if (info.version) {
+			root.appendChild(el("div", "version", "Version " + info.version));
+		}
+		if (info.description) {
+			root.appendChild(el("p", "", info.description));
+		}
+		Object.keys(spec.paths || {}).sort().forEach(function (path) {
+			var item = spec.paths[path];
+			methods.forEach(function (method) {
+				if (item[method]) {
+					root.appendChild(operation(path, method, item[method]));
+				}
+			});
+		});
+		if (spec.components && spec.components.schemas) {
+			root.appendChild(el("h3", "", "Schemas"));
+			root.appendChild(json(spec.components.schemas));
+		}
+	}
+
+	var url = root.getAttribute("data-spec-url");
+	fetch(url).then(function (response) {
+		return response.text();
+	}).then(function (text) {
+		var spec;
+		try {
+			spec = JSON.parse(text);
+		} catch (e) {
+			// YAML specs are shown as they are
+			root.appendChild(el("pre", "", text));
+			return;
+		}
+		render(spec);
+	}).catch(function (err) {
+		root.appendChild(el("p", "error", "Cannot load " + url + ": " + err));
+	});
+})();
@@ -0,0 +1,284 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the error types used to collect errors on the Context
+// and the HTTPError type carrying a response status.
+package goxpress
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// HTTPError is an error that carries the HTTP status code and message of
+// the response it should produce. Handlers return or record one to stop
+// processing with a specific status:
+//
+//	user, ok := users[c.Param("id")]
+//	if !ok {
+//		c.Next(goxpress.ErrNotFound)
+//		return
+//	}
+//
+// When none of the error handlers registered with UseError writes a
+// response, an HTTPError found with errors.As in the error passed to them
+// is answered with its status code and a JSON body of the form
+// {"error": message}, unless the response has already been written. Other
+// errors are answered with 500 Internal Server Error without exposing
+// their message.
+type HTTPError struct {
+	Code     int         // HTTP status code of the response
+	Message  string      // Message sent to the client
+	Internal error       // Underlying cause, logged but not sent to the client
+	Header   http.Header // Headers added to the response, e.g. Retry-After
+}
+
+// HTTPErrorOption configures an HTTPError created by NewHTTPError.
+type HTTPErrorOption func(*HTTPError)
+
+// WithInternal sets the underlying cause of an HTTPError. It is returned
+// by Unwrap and included in Error, but never sent to the client.
+func WithInternal(err error) HTTPErrorOption {
+	return func(e *HTTPError) {
+		e.Internal = err
+	}
+}
+
+// WithHeader adds a header to the response produced for an HTTPError.
+func WithHeader(key, value string) HTTPErrorOption {
+	return func(e *HTTPError) {
+		if e.Header == nil {
+			e.Header = make(http.Header)
+		}
+		e.Header.Add(key, value)
+	}
+}
+
+// NewHTTPError returns an HTTPError with the given status code and
+// message. An empty message defaults to the status text of the code.
+//
+// Example:
+//
+//	return goxpress.NewHTTPError(409, "email already registered",
+//		goxpress.WithInternal(err))
+//
+//	c.Next(goxpress.NewHTTPError(429, "", goxpress.WithHeader("Retry-After", "30")))
+func NewHTTPError(code int, message string, opts ...HTTPErrorOption) *HTTPError {
+	if message == "" {
+		message = http.StatusText(code)
+	}
+	e := &HTTPError{Code: code, Message: message}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Predefined HTTP errors for common statuses. They must not be modified;
+// use NewHTTPError to add a custom message, cause or headers.
+var (
+	ErrBadRequest          = NewHTTPError(http.StatusBadRequest, "")
+	ErrUnauthorized        = NewHTTPError(http.StatusUnauthorized, "")
+	ErrForbidden           = NewHTTPError(http.StatusForbidden, "")
+	ErrNotFound            = NewHTTPError(http.StatusNotFound, "")
+	ErrMethodNotAllowed    = NewHTTPError(http.StatusMethodNotAllowed, "")
+	ErrConflict            = NewHTTPError(http.StatusConflict, "")
+	ErrUnprocessableEntity = NewHTTPError(http.StatusUnprocessableEntity, "")
+	ErrTooManyRequests     = NewHTTPError(http.StatusTooManyRequests, "")
+	ErrInternalServerError = NewHTTPError(http.StatusInternalServerError, "")
+	ErrServiceUnavailable  = NewHTTPError(http.StatusServiceUnavailable, "")
+)
+
+// Error implements the error interface. The internal cause, if any, is
+// appended to the message.
+func (e *HTTPError) Error() string {
+	if e.Internal != nil {
+		return e.Message + ": " + e.Internal.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the internal cause, for use with errors.Is and errors.As.
+func (e *HTTPError) Unwrap() error {
+	return e.Internal
+}
+
+// Is reports whether target is an HTTPError with the same status code, so
+// errors.Is(err, goxpress.ErrNotFound) matches any 404 HTTPError.
+func (e *HTTPError) Is(target error) bool {
+	t, ok := target.(*HTTPError)
+	return ok && t.Code == e.Code
+}
+
+// handleErrors runs the error handlers for the error recorded on c, in
+// order, until one of them writes a response or aborts. If none writes a
+// response, handleDefaultError does.
+func (c *Context) handleErrors(handlers []ErrorHandlerFunc) {
+	// The handler chain has finished, so the abort flag is reused to stop
+	// the error handlers and restored afterwards
+	aborted := c.aborted
+	c.aborted = false
+	c.errorHandlers = handlers
+	c.errorIndex = -1
+	c.nextErrorHandler()
+	c.errorHandlers = nil
+	c.aborted = c.aborted || aborted
+}
+
+// nextErrorHandler runs the error handlers following the current one.
+func (c *Context) nextErrorHandler() {
+	c.errorIndex++
+	for c.errorIndex < len(c.errorHandlers) {
+		if c.aborted {
+			return
+		}
+
+		written, size := c.statusCodeWritten || c.Writer.Written(), c.Writer.Size()
+		c.errorHandlers[c.errorIndex](c.err, c)
+		if (!written && (c.statusCodeWritten || c.Writer.Written())) || c.Writer.Size() != size {
+			// The handler answered the request
+			c.errorIndex = len(c.errorHandlers)
+			return
+		}
+		c.errorIndex++
+	}
+
+	if c.errorIndex == len(c.errorHandlers) && !c.aborted {
+		c.errorIndex++ // Run the default handler once
+		handleDefaultError(c.err, c)
+	}
+}
+
+// handleDefaultError produces the response for err when no error handler
+// did. ValidationErrors are answered with 422 Unprocessable Entity and
+// their localized field errors, HTTPErrors with their status code and
+// message, any other error with 500 Internal Server Error and a generic
+// message, as JSON unless the client prefers plain text. Server errors are
+// logged with the request logger. A response already written by the
+// handlers is left as it is.
+func handleDefaultError(err error, c *Context) {
+	code, message := http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)
+	var httpErr *HTTPError
+	var validationErrs ValidationErrors
+	if errors.As(err, &validationErrs) {
+		code, message = http.StatusUnprocessableEntity, http.StatusText(http.StatusUnprocessableEntity)
+	} else if errors.As(err, &httpErr) {
+		code, message = httpErr.Code, httpErr.Message
+	}
+
+	written := c.statusCodeWritten || c.Writer.Written()
+	if written {
+		code = c.Writer.Status()
+	}
+	if code >= 500 {
+		c.Logger().Printf("error: %v", err)
+	}
+	if written {
+		return
+	}
+
+	if httpErr != nil {
+		header := c.Response.Header()
+		for key, values := range httpErr.Header {
+			for _, value := range values {
+				header.Add(key, value)
+			}
+		}
+	}
+	if c.NegotiateFormat(MIMEJSON, MIMEPlain) == MIMEPlain {
+		if validationErrs != nil {
+			message = validationErrs.Localize(c).Error()
+		}
+		c.String(code, "%s", message)
+		return
+	}
+	if validationErrs != nil {
+		c.JSON(code, validationErrorBody(c, validationErrs))
+		return
+	}
+	c.JSON(code, map[string]string{"error": message})
+}
+
+// ErrorEntry is an error recorded on a Context with Context.Error,
+// together with optional metadata describing it.
+type ErrorEntry struct {
+	Err  error       // Recorded error
+	Meta interface{} // Optional metadata, e.g. the invalid field or upstream name
+}
+
+// Error implements the error interface.
+func (e *ErrorEntry) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the recorded error, for use with errors.Is and errors.As.
+func (e *ErrorEntry) Unwrap() error {
+	return e.Err
+}
+
+// SetMeta attaches metadata to the entry.
+// Returns the ErrorEntry for method chaining.
+//
+// Example:
+//
+//	c.Error(err).SetMeta(map[string]string{"field": "email"})
+func (e *ErrorEntry) SetMeta(meta interface{}) *ErrorEntry {
+	e.Meta = meta
+	return e
+}
+
+// ErrorList holds the errors recorded during a request, in order.
+type ErrorList []*ErrorEntry
+
+// Last returns the most recently recorded error, or nil if there is none.
+func (l ErrorList) Last() *ErrorEntry {
+	if len(l) == 0 {
+		return nil
+	}
+	return l[len(l)-1]
+}
+
+// Errors returns the messages of all recorded errors.
+func (l ErrorList) Errors() []string {
+	messages := make([]string, len(l))
+	for i, entry := range l {
+		messages[i] = entry.Error()
+	}
+	return messages
+}
+
+// String joins the messages of all recorded errors with "; ".
+func (l ErrorList) String() string {
+	return strings.Join(l.Errors(), "; ")
+}
+
+// Error records err on the Context and returns its entry so metadata can
+// be attached. Recorded errors are collected in c.Errors, and the last one
+// is passed to the registered error handlers once the middleware chain has
+// finished, where all of them can be inspected. Passing a nil error is a
+// no-op that returns nil.
+//
+// Example:
+//
+//	if name == "" {
+//		c.Error(errors.New("name is required")).SetMeta("name")
+//	}
+//	if !strings.Contains(email, "@") {
+//		c.Error(errors.New("email is invalid")).SetMeta("email")
+//	}
+//	if len(c.Errors) > 0 {
+//		c.JSON(400, map[string]interface{}{"errors": c.Errors.Errors()})
+//		return
+//	}
+func (c *Context) Error(err error) *ErrorEntry {
+	if err == nil {
+		return nil
+	}
+
+	entry, ok := err.(*ErrorEntry)
+	if !ok {
+		entry = &ErrorEntry{Err: err}
+	}
+	c.Errors = append(c.Errors, entry)
+	c.err = entry.Err
+	return entry
+}
@@ -0,0 +1,245 @@
+package goxpress
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestContextError(t *testing.T) {
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if c.Error(nil) != nil || len(c.Errors) != 0 {
+		t.Error("Recording a nil error should be a no-op")
+	}
+
+	errName := errors.New("name is required")
+	c.Error(errName).SetMeta("name")
+	c.Error(errors.New("email is invalid")).SetMeta("email")
+
+	if len(c.Errors) != 2 {
+		t.Fatalf("Expected 2 recorded errors, got %d", len(c.Errors))
+	}
+	if c.Errors[0].Meta != "name" || c.Errors.Last().Meta != "email" {
+		t.Error("Error metadata should be preserved")
+	}
+	if !errors.Is(c.Errors[0], errName) {
+		t.Error("ErrorEntry should unwrap to the recorded error")
+	}
+	if c.Errors.String() != "name is required; email is invalid" {
+		t.Errorf("Unexpected error summary %q", c.Errors.String())
+	}
+	if c.err == nil || c.err.Error() != "email is invalid" {
+		t.Errorf("Last recorded error should be passed to error handlers, got %v", c.err)
+	}
+
+	if (ErrorList{}).Last() != nil {
+		t.Error("Last of an empty list should be nil")
+	}
+}
+
+func TestErrorsReachHandlersAndLogger(t *testing.T) {
+	var logOutput strings.Builder
+	log.SetOutput(&logOutput)
+	defer log.SetOutput(os.Stderr)
+
+	var handled ErrorList
+	app := New()
+	app.Use(Logger())
+	app.UseError(func(err error, c *Context) {
+		handled = c.Errors
+		c.JSON(400, map[string]interface{}{"errors": c.Errors.Errors()})
+	})
+	app.POST("/users", func(c *Context) {
+		c.Error(errors.New("name is required"))
+		c.Next(errors.New("email is invalid"))
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/users", nil))
+
+	if len(handled) != 2 {
+		t.Errorf("Error handlers should see all recorded errors, got %d", len(handled))
+	}
+	if !strings.Contains(w.Body.String(), "name is required") {
+		t.Errorf("Unexpected body %q", w.Body.String())
+	}
+	if !strings.Contains(logOutput.String(), "| name is required; email is invalid") {
+		t.Errorf("Logger should include recorded errors, got %q", logOutput.String())
+	}
+}
+
+func TestHTTPError(t *testing.T) {
+	cause := errors.New("duplicate key")
+	err := NewHTTPError(409, "email already registered", WithInternal(cause), WithHeader("X-Reason", "email"))
+
+	if err.Error() != "email already registered: duplicate key" {
+		t.Errorf("Unexpected error message %q", err.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Error("HTTPError should unwrap to its internal cause")
+	}
+	if err.Header.Get("X-Reason") != "email" {
+		t.Errorf("Expected header option to be applied, got %v", err.Header)
+	}
+	if ErrNotFound.Message != "Not Found" {
+		t.Errorf("Expected status text as default message, got %q", ErrNotFound.Message)
+	}
+	if !errors.Is(NewHTTPError(404, "user not found"), ErrNotFound) {
+		t.Error("HTTPErrors with the same status should match with errors.Is")
+	}
+	if errors.Is(err, ErrNotFound) {
+		t.Error("HTTPErrors with different statuses should not match")
+	}
+}
+
+func TestDefaultErrorHandler(t *testing.T) {
+	app := New()
+	app.GET("/missing", func(c *Context) {
+		c.Next(ErrNotFound)
+	})
+	app.GET("/wrapped", func(c *Context) {
+		err := NewHTTPError(429, "slow down", WithHeader("Retry-After", "30"))
+		c.Next(fmt.Errorf("quota: %w", err))
+	})
+	app.GET("/written", func(c *Context) {
+		c.String(200, "partial")
+		c.Next(ErrServiceUnavailable)
+	})
+
+	app.GET("/plain", func(c *Context) {
+		c.Next(errors.New("db password is hunter2"))
+	})
+	app.GET("/aborted", func(c *Context) {
+		c.AbortWithError(400, errors.New("bad input"))
+	})
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/missing", 404, `{"error":"Not Found"}`},
+		{"/wrapped", 429, `{"error":"slow down"}`},
+		{"/written", 200, "partial"},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+		if w.Code != test.code || strings.TrimSpace(w.Body.String()) != test.body {
+			t.Errorf("%s: expected %d %s, got %d %q", test.path, test.code, test.body, w.Code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/wrapped", nil))
+	if w.Header().Get("Retry-After") != "30" || w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected HTTPError headers and JSON content type, got %v", w.Header())
+	}
+
+	// Other errors get a generic 500, logged with the request logger
+	var logOutput strings.Builder
+	log.SetOutput(&logOutput)
+	defer log.SetOutput(os.Stderr)
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/plain", nil))
+	if w.Code != 500 || strings.TrimSpace(w.Body.String()) != `{"error":"Internal Server Error"}` {
+		t.Errorf("Expected generic 500 JSON response, got %d %q", w.Code, w.Body.String())
+	}
+	if !strings.Contains(logOutput.String(), "GET /plain error: db password is hunter2") {
+		t.Errorf("Expected server error to be logged, got %q", logOutput.String())
+	}
+
+	req := httptest.NewRequest("GET", "/plain", nil)
+	req.Header.Set("Accept", "text/plain")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != 500 || w.Body.String() != "Internal Server Error" {
+		t.Errorf("Expected plain text 500 response, got %d %q", w.Code, w.Body.String())
+	}
+
+	// A status left by the handler is kept
+	logOutput.Reset()
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/aborted", nil))
+	if w.Code != 400 || w.Body.Len() != 0 || logOutput.Len() != 0 {
+		t.Errorf("Expected handler status to be kept unlogged, got %d %q %q", w.Code, w.Body.String(), logOutput.String())
+	}
+
+	// Registered error handlers take over the response
+	app.UseError(func(err error, c *Context) {
+		c.String(418, "custom")
+	})
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/missing", nil))
+	if w.Code != 418 || w.Body.String() != "custom" {
+		t.Errorf("Expected error handler response, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestErrorHandlerChain(t *testing.T) {
+	var calls []string
+	app := New()
+	app.UseError(func(err error, c *Context) {
+		calls = append(calls, "log")
+	}, func(err error, c *Context) {
+		calls = append(calls, "wrap")
+		c.Next(fmt.Errorf("wrapped: %w", err))
+		calls = append(calls, "wrap done")
+	}, func(err error, c *Context) {
+		calls = append(calls, "respond")
+		if errors.Is(err, ErrConflict) {
+			c.String(409, "%v", err)
+		}
+	}, func(err error, c *Context) {
+		calls = append(calls, "fallback")
+		c.String(500, "fallback")
+	})
+	app.GET("/conflict", func(c *Context) {
+		c.Next(ErrConflict)
+	})
+	app.GET("/abort", func(c *Context) {
+		c.AbortWithError(400, errors.New("bad input"))
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/conflict", nil))
+	if w.Code != 409 || w.Body.String() != "wrapped: Conflict" {
+		t.Errorf("Expected first writing handler to answer, got %d %q", w.Code, w.Body.String())
+	}
+	if strings.Join(calls, ",") != "log,wrap,respond,wrap done" {
+		t.Errorf("Unexpected error handler calls %v", calls)
+	}
+
+	// Handlers that don't write pass the error on; the status left by the
+	// route handler is kept
+	calls = nil
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/abort", nil))
+	if w.Code != 400 || w.Body.String() != "fallback" || strings.Join(calls, ",") != "log,wrap,respond,fallback,wrap done" {
+		t.Errorf("Expected the fallback handler to answer, got %d %q %v", w.Code, w.Body.String(), calls)
+	}
+}
+
+func TestErrorHandlerAbort(t *testing.T) {
+	app := New()
+	app.UseError(func(err error, c *Context) {
+		c.Abort()
+	}, func(err error, c *Context) {
+		t.Error("Error handlers after Abort should not run")
+	})
+	app.GET("/", func(c *Context) {
+		c.Next(errors.New("boom"))
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != 200 || w.Body.Len() != 0 {
+		t.Errorf("Expected no default response after Abort, got %d %q", w.Code, w.Body.String())
+	}
+}
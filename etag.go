@@ -0,0 +1,151 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the ETag middleware for conditional GET support.
+package goxpress
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ETagConfig defines configuration options for the ETag middleware.
+type ETagConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper Skipper
+
+	// Weak generates weak validators (W/"...") instead of strong ones.
+	// Use weak ETags when responses may be transformed on the way to the
+	// client, e.g. by compression middleware or proxies.
+	Weak bool
+}
+
+// ETag returns a middleware that computes a strong ETag for successful
+// GET and HEAD responses and answers with 304 Not Modified when the
+// request's If-None-Match header matches, saving bandwidth for polling
+// clients.
+//
+// Example:
+//
+//	app.Use(goxpress.ETag())
+func ETag() HandlerFunc {
+	return ETagWithConfig(ETagConfig{})
+}
+
+// ETagWithConfig returns an ETag middleware with custom configuration.
+//
+// Responses are buffered in memory to compute their hash, so the
+// middleware should not be used for streaming endpoints. If a handler sets
+// the ETag header itself, that value is used instead of a computed one.
+//
+// Example:
+//
+//	app.Use(goxpress.ETagWithConfig(goxpress.ETagConfig{Weak: true}))
+func ETagWithConfig(config ETagConfig) HandlerFunc {
+	return func(c *Context) {
+		if (config.Skipper != nil && config.Skipper(c)) ||
+			(c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead) {
+			c.Next()
+			return
+		}
+
+		original := c.Response
+		buffer := &bufferedResponseWriter{ResponseWriter: original}
+		c.Response = buffer
+		defer func() { c.Response = original }()
+
+		c.Next()
+
+		if buffer.hijacked {
+			return
+		}
+
+		status := buffer.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if status != http.StatusOK {
+			buffer.flushTo(original, status)
+			return
+		}
+
+		header := original.Header()
+		etag := header.Get("ETag")
+		if etag == "" {
+			sum := sha256.Sum256(buffer.body.Bytes())
+			etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+			if config.Weak {
+				etag = "W/" + etag
+			}
+			header.Set("ETag", etag)
+		}
+
+		if etagMatches(c.Request.Header.Get("If-None-Match"), etag) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			original.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		buffer.flushTo(original, status)
+	}
+}
+
+// etagMatches reports whether an If-None-Match header value matches the
+// given ETag using the weak comparison function of RFC 7232.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedResponseWriter holds back the status code and body of a response
+// so middleware can inspect them before anything is sent to the client.
+// Headers are written to the underlying ResponseWriter directly.
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	status   int          // Status code passed to WriteHeader, 0 if not called
+	body     bytes.Buffer // Buffered response body
+	hijacked bool         // Whether the connection was taken over
+}
+
+// WriteHeader records the status code without sending it.
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+// Write appends data to the buffered body.
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(data)
+}
+
+// Hijack passes connection takeovers, such as WebSocket upgrades,
+// through to the underlying ResponseWriter.
+func (w *bufferedResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(w.ResponseWriter, func() { w.hijacked = true })
+}
+
+// flushTo sends the buffered response to w with the given status code.
+func (w *bufferedResponseWriter) flushTo(dst http.ResponseWriter, status int) {
+	dst.WriteHeader(status)
+	dst.Write(w.body.Bytes())
+}
@@ -0,0 +1,105 @@
+package goxpress
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestETag(t *testing.T) {
+	app := New()
+	app.Use(ETag())
+	app.GET("/data", func(c *Context) {
+		c.JSON(200, map[string]string{"status": "ok"})
+	})
+	app.GET("/missing", func(c *Context) {
+		c.String(404, "not here")
+	})
+	app.GET("/custom", func(c *Context) {
+		c.Response.Header().Set("ETag", `"v42"`)
+		c.String(200, "custom")
+	})
+	app.POST("/data", func(c *Context) {
+		c.String(200, "posted")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/data", nil))
+
+	etag := w.Header().Get("ETag")
+	if w.Code != 200 || !strings.HasPrefix(etag, `"`) {
+		t.Fatalf("Expected 200 with strong ETag, got %d %q", w.Code, etag)
+	}
+	if w.Body.String() != "{\"status\":\"ok\"}\n" {
+		t.Errorf("Unexpected body %q", w.Body.String())
+	}
+
+	t.Run("NotModified", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/data", nil)
+		req.Header.Set("If-None-Match", `"other", `+etag)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		if w.Code != 304 {
+			t.Errorf("Expected status code 304, got %d", w.Code)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("304 response should have empty body, got %q", w.Body.String())
+		}
+	})
+
+	t.Run("Changed", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/data", nil)
+		req.Header.Set("If-None-Match", `"stale"`)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		if w.Code != 200 || w.Body.Len() == 0 {
+			t.Errorf("Expected full 200 response, got %d", w.Code)
+		}
+	})
+
+	t.Run("NonSuccess", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/missing", nil))
+
+		if w.Code != 404 || w.Header().Get("ETag") != "" {
+			t.Errorf("Expected 404 without ETag, got %d %q", w.Code, w.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("HandlerETag", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/custom", nil)
+		req.Header.Set("If-None-Match", `W/"v42"`)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		if w.Code != 304 {
+			t.Errorf("Expected status code 304, got %d", w.Code)
+		}
+	})
+
+	t.Run("NonGET", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("POST", "/data", nil))
+
+		if w.Header().Get("ETag") != "" {
+			t.Error("ETag should only be computed for GET and HEAD")
+		}
+	})
+}
+
+func TestETagWeak(t *testing.T) {
+	app := New()
+	app.Use(ETagWithConfig(ETagConfig{Weak: true}))
+	app.GET("/", func(c *Context) {
+		c.String(200, "hello")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if etag := w.Header().Get("ETag"); !strings.HasPrefix(etag, `W/"`) {
+		t.Errorf("Expected weak ETag, got %q", etag)
+	}
+}
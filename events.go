@@ -0,0 +1,145 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the request lifecycle event hooks.
+package goxpress
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// RequestSummary describes a completed request for telemetry sinks.
+type RequestSummary struct {
+	Method       string        // Request method
+	Path         string        // Request path
+	Route        string        // Matched route pattern, empty for unmatched requests
+	Status       int           // Response status code, 500 for panics
+	Start        time.Time     // Time the request reached the middleware
+	Latency      time.Duration // Time spent in the handler chain
+	RequestSize  int64         // Request body bytes read by the handlers
+	ResponseSize int           // Response body bytes written
+	ClientIP     string        // Address of the connected client
+	RequestID    string        // Request ID, empty if there is none
+	Errors       ErrorList     // Errors recorded on the Context
+}
+
+// RequestEventsConfig defines the callbacks of the request events
+// middleware. All callbacks are optional and run on the goroutine serving
+// the request, so slow sinks should hand the data off, for example to a
+// channel drained by a background writer.
+type RequestEventsConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper Skipper
+
+	// OnStart is called before the rest of the handler chain runs.
+	OnStart func(c *Context)
+
+	// OnFinish is called once the handler chain has finished, including
+	// after a panic.
+	OnFinish func(c *Context, summary RequestSummary)
+
+	// OnPanic is called with the recovered value when a handler panics,
+	// before OnFinish. The panic is then re-raised for Recover to handle,
+	// so stack traces printed by Recover start at this middleware; use
+	// RecoverConfig.OnPanic where the original stack is needed.
+	OnPanic func(c *Context, recovered interface{}, summary RequestSummary)
+
+	// OnError is called for each error recorded on the Context during the
+	// request, before OnFinish.
+	OnError func(c *Context, err error, summary RequestSummary)
+}
+
+// RequestEvents returns a middleware that reports the lifecycle of each
+// request to callbacks, so custom sinks such as Kafka or ClickHouse can
+// consume request telemetry without writing a full middleware. Register
+// it after Recover so panics are reported before they are recovered.
+//
+// Example:
+//
+//	app.Use(goxpress.Recover())
+//	app.Use(goxpress.RequestEvents(goxpress.RequestEventsConfig{
+//		OnFinish: func(c *goxpress.Context, s goxpress.RequestSummary) {
+//			telemetry <- s
+//		},
+//		OnPanic: func(c *goxpress.Context, recovered interface{}, s goxpress.RequestSummary) {
+//			alerts.Notify(s.Route, recovered)
+//		},
+//	}))
+func RequestEvents(config RequestEventsConfig) HandlerFunc {
+	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		errorsBefore := len(c.Errors)
+
+		var body *countingBody
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			body = &countingBody{ReadCloser: c.Request.Body}
+			c.Request.Body = body
+		}
+
+		if config.OnStart != nil {
+			config.OnStart(c)
+		}
+
+		defer func() {
+			recovered := recover()
+
+			summary := RequestSummary{
+				Method:       c.Request.Method,
+				Path:         c.Request.URL.Path,
+				Route:        c.FullPath(),
+				Status:       c.Writer.Status(),
+				Start:        start,
+				Latency:      time.Since(start),
+				ResponseSize: c.Writer.Size(),
+				ClientIP:     c.ClientIP(),
+				RequestID:    c.requestID(),
+				Errors:       c.Errors,
+			}
+			if body != nil {
+				summary.RequestSize = body.n
+			}
+			switch {
+			case recovered != nil:
+				summary.Status = http.StatusInternalServerError
+			case summary.Status == 0:
+				summary.Status = http.StatusOK // Nothing written, net/http sends 200
+			}
+
+			if recovered != nil && config.OnPanic != nil {
+				config.OnPanic(c, recovered, summary)
+			}
+			if config.OnError != nil && len(c.Errors) > errorsBefore {
+				for _, entry := range c.Errors[errorsBefore:] {
+					config.OnError(c, entry, summary)
+				}
+			}
+			if config.OnFinish != nil {
+				config.OnFinish(c, summary)
+			}
+
+			if recovered != nil {
+				panic(recovered)
+			}
+		}()
+
+		c.Next()
+	}
+}
+
+// countingBody counts the bytes read from a request body.
+type countingBody struct {
+	io.ReadCloser
+	n int64
+}
+
+// Read reads from the body, counting the bytes read.
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
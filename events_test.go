@@ -0,0 +1,76 @@
+package goxpress
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestEvents(t *testing.T) {
+	var events []string
+	var finished RequestSummary
+	var reportedErrors []error
+
+	app := New()
+	app.Use(RecoverWithConfig(RecoverConfig{Output: ioutil.Discard}))
+	app.Use(RequestEvents(RequestEventsConfig{
+		OnStart: func(c *Context) {
+			events = append(events, "start")
+		},
+		OnFinish: func(c *Context, s RequestSummary) {
+			events = append(events, "finish")
+			finished = s
+		},
+		OnPanic: func(c *Context, recovered interface{}, s RequestSummary) {
+			events = append(events, "panic")
+		},
+		OnError: func(c *Context, err error, s RequestSummary) {
+			events = append(events, "error")
+			reportedErrors = append(reportedErrors, err)
+		},
+	}))
+	app.POST("/users/:id", func(c *Context) {
+		ioutil.ReadAll(c.Request.Body)
+		c.Error(errors.New("audit failed"))
+		c.String(201, "created")
+	})
+	app.GET("/panic", func(c *Context) {
+		panic("boom")
+	})
+
+	t.Run("Finish", func(t *testing.T) {
+		events, reportedErrors = nil, nil
+		req := httptest.NewRequest("POST", "/users/7", strings.NewReader("name=gopher"))
+		req.Header.Set("X-Request-ID", "req-1")
+		app.ServeHTTP(httptest.NewRecorder(), req)
+
+		if strings.Join(events, ",") != "start,error,finish" {
+			t.Errorf("Unexpected events %v", events)
+		}
+		s := finished
+		if s.Method != "POST" || s.Path != "/users/7" || s.Route != "/users/:id" || s.Status != 201 ||
+			s.RequestSize != 11 || s.ResponseSize != 7 || s.RequestID != "req-1" || s.ClientIP != "192.0.2.1" {
+			t.Errorf("Unexpected summary %+v", s)
+		}
+		if s.Latency <= 0 || s.Start.IsZero() {
+			t.Errorf("Expected timing information, got %+v", s)
+		}
+		if len(reportedErrors) != 1 || reportedErrors[0].Error() != "audit failed" {
+			t.Errorf("Expected recorded error to be reported, got %v", reportedErrors)
+		}
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		events = nil
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/panic", nil))
+
+		if strings.Join(events, ",") != "start,panic,finish" {
+			t.Errorf("Unexpected events %v", events)
+		}
+		if finished.Status != 500 {
+			t.Errorf("Expected status 500 for a panic, got %d", finished.Status)
+		}
+	})
+}
@@ -122,9 +122,9 @@ func Example_restAPI() {
 
 	// Middleware to add CORS headers
 	app.Use(func(c *Context) {
-		c.Response.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Response.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE")
-		c.Response.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		c.Header("Access-Control-Allow-Origin", "*")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE")
+		c.Header("Access-Control-Allow-Headers", "Content-Type")
 		c.Next()
 	})
 
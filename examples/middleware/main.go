@@ -17,7 +17,7 @@ func main() {
 	// app.Use(goxpress.LoggerWithConfig(goxpress.LoggerConfig{
 	// 	Output: ios.Stdout,
 	//  Formatter: goxpress.DefaultLogFormatter,
-	//  SkipPaths: []string{"/health"}
+	//  Skipper: goxpress.PathSkipper("/health"),
 	// }))
 	app.Use(goxpress.Recover()) // Panic recovery
 
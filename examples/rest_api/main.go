@@ -151,9 +151,9 @@ func deleteUser(c *goxpress.Context) {
 // CORSMiddleware adds Cross-Origin Resource Sharing headers to responses
 func CORSMiddleware() goxpress.HandlerFunc {
 	return func(c *goxpress.Context) {
-		c.Response.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Response.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Response.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Origin", "*")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 		if c.Request.Method == "OPTIONS" {
 			c.Status(204)
@@ -0,0 +1,350 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains helpers for serving files from disk and other
+// filesystems.
+package goxpress
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// File sends a response with the content of the specified file.
+// It is built on http.ServeContent, so it sets the Content-Type header
+// based on the file extension (or content sniffing) and honors Range
+// requests for partial content and resumable downloads.
+//
+// Responses carry Last-Modified and an ETag derived from the file's
+// modification time and size, unless the handler set an ETag itself.
+// Conditional requests with a matching If-None-Match or an unchanged
+// If-Modified-Since are answered with 304 Not Modified and an empty body,
+// so repeat visitors and CDNs don't download unchanged files again.
+//
+// If the client accepts brotli or gzip and a pre-compressed sibling of the
+// file exists, such as "app.js.br" or "app.js.gz" next to "app.js", the
+// sibling is sent instead with the matching Content-Encoding, so assets
+// can be compressed at build time rather than on every request. Responses
+// to requests with an Accept-Encoding header carry Vary: Accept-Encoding.
+//
+// If the file cannot be opened or is a directory, an error response is
+// written and the error is returned. As a precaution against path
+// traversal, requests whose URL path contains a ".." element are rejected
+// with 400 Bad Request before the file is opened, as http.ServeFile does.
+//
+// Example:
+//
+//	c.File("./public/index.html")
+//	c.File("./media/intro.mp4") // Supports seeking in video players
+func (c *Context) File(filepath string) error {
+	return c.file(filepath, "")
+}
+
+// file implements File, setting the Content-Disposition header to
+// disposition, if not empty, once the file is found.
+func (c *Context) file(filepath, disposition string) error {
+	if containsDotDot(c.Request.URL.Path) {
+		c.String(http.StatusBadRequest, "invalid URL path")
+		return errInvalidFilePath
+	}
+
+	if f, coding := c.openPrecompressed(filepath, func(name string) (http.File, error) { return os.Open(name) }); f != nil {
+		return c.serveFile(f, filepath, coding, disposition)
+	}
+
+	f, err := os.Open(filepath)
+	if err != nil {
+		c.fileError(err)
+		return err
+	}
+	return c.serveFile(f, filepath, "", disposition)
+}
+
+// FileFromFS sends a response with the content of the file at path within
+// the given filesystem, with the same Range and conditional request
+// handling and pre-compressed siblings as File. It allows serving
+// individual files from an embed.FS (through http.FS) or any custom
+// http.FileSystem. Files without a modification time, such as those of
+// an embed.FS, get an ETag hashed from their content instead.
+//
+// Example:
+//
+//	//go:embed assets
+//	var assets embed.FS
+//
+//	app.GET("/favicon.ico", func(c *goxpress.Context) {
+//		c.FileFromFS("assets/favicon.ico", http.FS(assets))
+//	})
+func (c *Context) FileFromFS(path string, fs http.FileSystem) error {
+	if f, coding := c.openPrecompressed(path, fs.Open); f != nil {
+		return c.serveFile(f, path, coding, "")
+	}
+
+	f, err := fs.Open(path)
+	if err != nil {
+		c.fileError(err)
+		return err
+	}
+	return c.serveFile(f, path, "", "")
+}
+
+// errInvalidFilePath is returned by File for requests whose URL path
+// contains a ".." element.
+var errInvalidFilePath = errors.New("goxpress: invalid URL path")
+
+// containsDotDot reports whether v contains a ".." path element, with
+// either slash or backslash as separator.
+func containsDotDot(v string) bool {
+	if !strings.Contains(v, "..") {
+		return false
+	}
+	for _, element := range strings.FieldsFunc(v, isSlashRune) {
+		if element == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+func isSlashRune(r rune) bool { return r == '/' || r == '\\' }
+
+// precompressedExtensions maps the content codings of pre-compressed
+// sibling files to their file extensions.
+var precompressedExtensions = map[string]string{
+	"br":   ".br",
+	"gzip": ".gz",
+}
+
+// precompressedEncodings lists the codings of pre-compressed files in
+// order of server preference.
+var precompressedEncodings = []string{"br", "gzip"}
+
+// openPrecompressed opens the pre-compressed sibling of the file at name,
+// such as "app.js.br" or "app.js.gz", in the coding the client prefers
+// among those available. It returns a nil file if the client accepts no
+// coding or no sibling exists. When the client sent Accept-Encoding, the
+// response varies on it whether or not a sibling is found, so caches
+// don't serve one representation to clients accepting another.
+func (c *Context) openPrecompressed(name string, open func(name string) (http.File, error)) (http.File, string) {
+	acceptEncoding := c.Request.Header.Get("Accept-Encoding")
+	if acceptEncoding == "" {
+		return nil, ""
+	}
+	c.Response.Header().Add("Vary", "Accept-Encoding")
+
+	offered := precompressedEncodings
+	for len(offered) > 0 {
+		coding := negotiateEncoding(acceptEncoding, offered)
+		if coding == "" {
+			return nil, ""
+		}
+		if f, err := open(name + precompressedExtensions[coding]); err == nil {
+			if info, err := f.Stat(); err == nil && !info.IsDir() {
+				return f, coding
+			}
+			f.Close()
+		}
+
+		// Try the remaining codings
+		remaining := make([]string, 0, len(offered)-1)
+		for _, o := range offered {
+			if o != coding {
+				remaining = append(remaining, o)
+			}
+		}
+		offered = remaining
+	}
+	return nil, ""
+}
+
+// serveFile serves an opened file and closes it afterwards. A non-empty
+// coding marks f as the pre-compressed variant of the file at name, and a
+// non-empty disposition is sent as the Content-Disposition header.
+// Directories are rejected with 404 Not Found, without the disposition.
+func (c *Context) serveFile(f http.File, name, coding, disposition string) error {
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		c.fileError(err)
+		return err
+	}
+	if info.IsDir() {
+		c.fileError(os.ErrNotExist)
+		return &os.PathError{Op: "open", Path: name, Err: errors.New("is a directory")}
+	}
+	if disposition != "" {
+		c.Response.Header().Set("Content-Disposition", disposition)
+	}
+
+	if err := c.setFileETag(f, info, coding); err != nil {
+		c.fileError(err)
+		return err
+	}
+
+	if coding != "" {
+		// The type is that of the original file, not of the archive
+		contentType := mime.TypeByExtension(filepath.Ext(name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		header := c.Response.Header()
+		header.Set("Content-Type", contentType)
+		header.Set("Content-Encoding", coding)
+		c.serveContent(name, info.ModTime(), f)
+		return nil
+	}
+
+	c.serveContent(info.Name(), info.ModTime(), f)
+	return nil
+}
+
+// setFileETag sets the ETag header of a served file unless the handler
+// already did. The tag is built from the modification time and size,
+// or hashed from the content when the modification time is unknown, and
+// includes the coding of pre-compressed variants so each representation
+// has its own tag.
+func (c *Context) setFileETag(f http.File, info os.FileInfo, coding string) error {
+	header := c.Response.Header()
+	if header.Get("ETag") != "" {
+		return nil
+	}
+
+	var tag string
+	if modtime := info.ModTime(); modtime.IsZero() || modtime.Equal(time.Unix(0, 0)) {
+		hash := sha256.New()
+		if _, err := io.Copy(hash, f); err != nil {
+			return err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		tag = hex.EncodeToString(hash.Sum(nil)[:16])
+	} else {
+		tag = strconv.FormatInt(modtime.UnixNano(), 16) + "-" + strconv.FormatInt(info.Size(), 16)
+	}
+	if coding != "" {
+		tag += "-" + coding
+	}
+	header.Set("ETag", `"`+tag+`"`)
+	return nil
+}
+
+// FileAttachment sends the specified file as a download that browsers save
+// under downloadName instead of displaying inline. Names containing
+// non-ASCII characters are encoded following RFC 6266, with an ASCII
+// fallback for older clients. Errors are answered as by File, without the
+// attachment header, so they are not saved as the download.
+//
+// Example:
+//
+//	c.FileAttachment("./reports/2024-q1.pdf", "Quarterly Report.pdf")
+//	c.FileAttachment("./exports/data.csv", "données.csv")
+func (c *Context) FileAttachment(filepath, downloadName string) error {
+	return c.file(filepath, contentDisposition("attachment", downloadName))
+}
+
+// AttachmentFromReader streams the content of r to the client as a
+// download named name, without buffering it in memory or on disk. The
+// Content-Type is derived from the name's extension. If contentLength is
+// non-negative it is sent as Content-Length; otherwise the response is
+// sent with chunked encoding.
+//
+// Example:
+//
+//	pr, pw := io.Pipe()
+//	go func() {
+//		pw.CloseWithError(writeZip(pw, files))
+//	}()
+//	c.AttachmentFromReader("export.zip", -1, pr)
+func (c *Context) AttachmentFromReader(name string, contentLength int64, r io.Reader) error {
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	header := c.Response.Header()
+	header.Set("Content-Disposition", contentDisposition("attachment", name))
+	if contentLength >= 0 {
+		header.Set("Content-Length", strconv.FormatInt(contentLength, 10))
+	}
+	c.writeContentType(http.StatusOK, contentType)
+
+	_, err := io.Copy(c.Response, r)
+	return err
+}
+
+// contentDisposition formats a Content-Disposition header value of the
+// given type for a file name, adding an RFC 5987 encoded filename*
+// parameter when the name is not plain ASCII.
+func contentDisposition(dispositionType, name string) string {
+	fallback := make([]byte, 0, len(name))
+	plain := true
+	for i := 0; i < len(name); i++ {
+		b := name[i]
+		switch {
+		case b >= utf8.RuneSelf || b < 0x20 || b == 0x7f:
+			plain = false
+			if b < utf8.RuneSelf || utf8.RuneStart(b) {
+				fallback = append(fallback, '_')
+			}
+		case b == '"' || b == '\\':
+			plain = false
+			fallback = append(fallback, '_')
+		default:
+			fallback = append(fallback, b)
+		}
+	}
+
+	value := dispositionType + `; filename="` + string(fallback) + `"`
+	if !plain {
+		value += "; filename*=UTF-8''" + rfc5987Escape(name)
+	}
+	return value
+}
+
+// rfc5987Escape percent-encodes every byte of s that is not an
+// attr-char as defined by RFC 5987.
+func rfc5987Escape(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ('0' <= ch && ch <= '9') ||
+			strings.IndexByte("!#$&+-.^_`|~", ch) >= 0 {
+			b.WriteByte(ch)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[ch>>4])
+		b.WriteByte(hex[ch&0x0f])
+	}
+	return b.String()
+}
+
+// serveContent writes content using http.ServeContent and marks the
+// response status as written.
+func (c *Context) serveContent(name string, modtime time.Time, content io.ReadSeeker) {
+	http.ServeContent(c.Response, c.Request, filepath.Base(name), modtime, content)
+	c.statusCodeWritten = true
+}
+
+// fileError writes an error response matching a file access error.
+func (c *Context) fileError(err error) {
+	switch {
+	case os.IsNotExist(err):
+		c.String(http.StatusNotFound, "404 page not found")
+	case os.IsPermission(err):
+		c.String(http.StatusForbidden, "403 Forbidden")
+	default:
+		c.String(http.StatusInternalServerError, "500 Internal Server Error")
+	}
+}
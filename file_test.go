@@ -0,0 +1,387 @@
+package goxpress
+
+import (
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// writeTempFile creates a file with the given name and content in a
+// temporary directory and returns its path.
+func writeTempFile(t *testing.T, name, content string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "goxpress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestContextFileServeContent(t *testing.T) {
+	path := writeTempFile(t, "hello.txt", "Hello, World!")
+
+	t.Run("FullContent", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+		if err := c.File(path); err != nil {
+			t.Fatalf("File should not return error: %v", err)
+		}
+		if w.Code != 200 {
+			t.Errorf("Expected status code 200, got %d", w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+			t.Errorf("Expected Content-Type 'text/plain; charset=utf-8', got '%s'", ct)
+		}
+		if w.Body.String() != "Hello, World!" {
+			t.Errorf("Unexpected body %q", w.Body.String())
+		}
+		if w.Header().Get("Accept-Ranges") != "bytes" {
+			t.Error("File should advertise range support")
+		}
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Range", "bytes=7-11")
+		w := httptest.NewRecorder()
+		c := NewContext(w, req)
+
+		c.File(path)
+
+		if w.Code != http.StatusPartialContent {
+			t.Errorf("Expected status code 206, got %d", w.Code)
+		}
+		if w.Body.String() != "World" {
+			t.Errorf("Expected partial body 'World', got %q", w.Body.String())
+		}
+	})
+
+	t.Run("NotModified", func(t *testing.T) {
+		info, _ := os.Stat(path)
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("If-Modified-Since", info.ModTime().UTC().Format(http.TimeFormat))
+		w := httptest.NewRecorder()
+		c := NewContext(w, req)
+
+		c.File(path)
+
+		if w.Code != http.StatusNotModified {
+			t.Errorf("Expected status code 304, got %d", w.Code)
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+		if err := c.File(path + ".missing"); err == nil {
+			t.Error("File should return error for missing files")
+		}
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status code 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("Directory", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+		if err := c.File(filepath.Dir(path)); err == nil {
+			t.Error("File should return error for directories")
+		}
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status code 404, got %d", w.Code)
+		}
+	})
+}
+
+func TestContextFileDotDot(t *testing.T) {
+	secret := writeTempFile(t, "secret.txt", "secret")
+	public := filepath.Join(filepath.Dir(secret), "public")
+	if err := os.Mkdir(public, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	app := New()
+	app.GET("/files/*name", func(c *Context) {
+		c.File(public + "/" + c.Param("name"))
+	})
+
+	for _, target := range []string{"/files/../secret.txt", "/files/..%2fsecret.txt", "/files/..%5csecret.txt"} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		if w.Code != 400 || strings.Contains(w.Body.String(), "secret") {
+			t.Errorf("%s: expected 400 without the file, got %d %q", target, w.Code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest("GET", "/files/a..b.txt", nil))
+	if err := c.File(secret); err != nil || w.Body.String() != "secret" {
+		t.Errorf("Names merely containing dots should be served, got %v %q", err, w.Body.String())
+	}
+}
+
+func TestContextFileAttachment(t *testing.T) {
+	path := writeTempFile(t, "report.csv", "a,b\n1,2\n")
+
+	tests := []struct {
+		name        string
+		disposition string
+	}{
+		{"report.csv", `attachment; filename="report.csv"`},
+		{"Quarterly Report.csv", `attachment; filename="Quarterly Report.csv"`},
+		{"données.csv", `attachment; filename="donn_es.csv"; filename*=UTF-8''donn%C3%A9es.csv`},
+		{`say "hi".csv`, `attachment; filename="say _hi_.csv"; filename*=UTF-8''say%20%22hi%22.csv`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+			if err := c.FileAttachment(path, test.name); err != nil {
+				t.Fatalf("FileAttachment should not return error: %v", err)
+			}
+			if cd := w.Header().Get("Content-Disposition"); cd != test.disposition {
+				t.Errorf("Expected Content-Disposition %q, got %q", test.disposition, cd)
+			}
+			if w.Body.String() != "a,b\n1,2\n" {
+				t.Errorf("Unexpected body %q", w.Body.String())
+			}
+		})
+	}
+
+	t.Run("Missing", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+		if err := c.FileAttachment(filepath.Join(filepath.Dir(path), "missing.csv"), "report.csv"); err == nil {
+			t.Fatal("FileAttachment should return an error for a missing file")
+		}
+		if w.Code != 404 || w.Header().Get("Content-Disposition") != "" {
+			t.Errorf("Expected 404 without Content-Disposition, got %d %q", w.Code, w.Header().Get("Content-Disposition"))
+		}
+	})
+
+	t.Run("Directory", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+		c.FileAttachment(filepath.Dir(path), "report.csv")
+		if w.Code != 404 || w.Header().Get("Content-Disposition") != "" {
+			t.Errorf("Expected 404 without Content-Disposition, got %d %q", w.Code, w.Header().Get("Content-Disposition"))
+		}
+	})
+}
+
+func TestContextFileFromFS(t *testing.T) {
+	path := writeTempFile(t, "style.css", "body{}")
+	fs := http.Dir(filepath.Dir(path))
+
+	t.Run("Found", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+		if err := c.FileFromFS("/style.css", fs); err != nil {
+			t.Fatalf("FileFromFS should not return error: %v", err)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "text/css; charset=utf-8" {
+			t.Errorf("Expected Content-Type 'text/css; charset=utf-8', got '%s'", ct)
+		}
+		if w.Body.String() != "body{}" {
+			t.Errorf("Unexpected body %q", w.Body.String())
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+		if err := c.FileFromFS("/missing.css", fs); err == nil {
+			t.Error("FileFromFS should return error for missing files")
+		}
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status code 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("Directory", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+		if err := c.FileFromFS("/", fs); err == nil {
+			t.Error("FileFromFS should return error for directories")
+		}
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status code 404, got %d", w.Code)
+		}
+	})
+}
+
+func TestContextAttachmentFromReader(t *testing.T) {
+	t.Run("KnownLength", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+		if err := c.AttachmentFromReader("report.pdf", 8, strings.NewReader("a,b\n1,2\n")); err != nil {
+			t.Fatalf("AttachmentFromReader should not return error: %v", err)
+		}
+		if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="report.pdf"` {
+			t.Errorf("Unexpected Content-Disposition %q", cd)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/pdf" {
+			t.Errorf("Unexpected Content-Type %q", ct)
+		}
+		if cl := w.Header().Get("Content-Length"); cl != "8" {
+			t.Errorf("Expected Content-Length 8, got %q", cl)
+		}
+		if w.Body.String() != "a,b\n1,2\n" {
+			t.Errorf("Unexpected body %q", w.Body.String())
+		}
+	})
+
+	t.Run("UnknownLength", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+		c.AttachmentFromReader("export.bin123", -1, strings.NewReader("data"))
+
+		if w.Header().Get("Content-Length") != "" {
+			t.Error("Content-Length should be omitted for unknown lengths")
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/octet-stream" {
+			t.Errorf("Expected fallback Content-Type, got %q", ct)
+		}
+	})
+}
+
+func TestContextFilePrecompressed(t *testing.T) {
+	path := writeTempFile(t, "app.js", "console.log(1)")
+	dir := filepath.Dir(path)
+	ioutil.WriteFile(path+".gz", []byte("gzip bytes"), 0644)
+	ioutil.WriteFile(path+".br", []byte("brotli bytes"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "only.css.gz"), []byte("gzip css"), 0644)
+
+	tests := []struct {
+		name           string
+		file           string
+		acceptEncoding string
+		encoding       string
+		body           string
+	}{
+		{"Identity", "app.js", "", "", "console.log(1)"},
+		{"Brotli", "app.js", "gzip, deflate, br", "br", "brotli bytes"},
+		{"GzipPreferred", "app.js", "br;q=0.5, gzip", "gzip", "gzip bytes"},
+		{"Unsupported", "app.js", "zstd", "", "console.log(1)"},
+		{"FallbackCoding", "only.css", "br, gzip", "gzip", "gzip css"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if test.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", test.acceptEncoding)
+			}
+
+			for _, fromFS := range []bool{false, true} {
+				w := httptest.NewRecorder()
+				c := NewContext(w, req)
+				var err error
+				if fromFS {
+					err = c.FileFromFS("/"+test.file, http.Dir(dir))
+				} else {
+					err = c.File(filepath.Join(dir, test.file))
+				}
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				header := w.Result().Header
+				if header.Get("Content-Encoding") != test.encoding || w.Body.String() != test.body {
+					t.Errorf("Expected %q encoded %q, got %q encoded %q", test.body, test.encoding, w.Body.String(), header.Get("Content-Encoding"))
+				}
+				if vary := header.Get("Vary"); (vary == "Accept-Encoding") != (test.acceptEncoding != "") {
+					t.Errorf("Expected Vary header only when Accept-Encoding was sent, got %q", vary)
+				}
+				if test.encoding != "" {
+					if !strings.HasPrefix(header.Get("Content-Type"), strings.Split(mime.TypeByExtension(filepath.Ext(test.file)), ";")[0]) {
+						t.Errorf("Expected type of the original file, got %q", header.Get("Content-Type"))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestContextFileConditional(t *testing.T) {
+	path := writeTempFile(t, "index.html", "<h1>home</h1>")
+	ioutil.WriteFile(path+".gz", []byte("gzip bytes"), 0644)
+	embedded := http.FS(fstest.MapFS{"logo.svg": {Data: []byte("<svg/>")}})
+
+	serve := func(file string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header = header
+		w := httptest.NewRecorder()
+		c := NewContext(w, req)
+		if file == "logo.svg" {
+			c.FileFromFS(file, embedded)
+		} else {
+			c.File(file)
+		}
+		return w
+	}
+
+	for _, file := range []string{path, "logo.svg"} {
+		first := serve(file, http.Header{})
+		etag := first.Result().Header.Get("ETag")
+		if first.Code != 200 || !strings.HasPrefix(etag, `"`) {
+			t.Fatalf("Expected 200 with an ETag, got %d %q", first.Code, etag)
+		}
+
+		w := serve(file, http.Header{"If-None-Match": {`"other", ` + etag}})
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("Expected empty 304 for a matching ETag, got %d %q", w.Code, w.Body.String())
+		}
+		if w := serve(file, http.Header{"If-None-Match": {`"other"`}}); w.Code != 200 {
+			t.Errorf("Expected 200 for a stale ETag, got %d", w.Code)
+		}
+	}
+
+	// Last-Modified is checked when no ETag is sent
+	lastModified := serve(path, http.Header{}).Result().Header.Get("Last-Modified")
+	if w := serve(path, http.Header{"If-Modified-Since": {lastModified}}); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("Expected empty 304 for an unchanged file, got %d %q", w.Code, w.Body.String())
+	}
+
+	// Each coding has its own tag
+	identity := serve(path, http.Header{}).Result().Header.Get("ETag")
+	gzipped := serve(path, http.Header{"Accept-Encoding": {"gzip"}})
+	if tag := gzipped.Result().Header.Get("ETag"); tag == identity || gzipped.Body.String() != "gzip bytes" {
+		t.Errorf("Expected a distinct tag for the gzip variant, got %q and %q", identity, tag)
+	}
+	if w := serve(path, http.Header{"Accept-Encoding": {"gzip"}, "If-None-Match": {identity}}); w.Code != 200 {
+		t.Errorf("Expected the identity tag not to match the gzip variant, got %d", w.Code)
+	}
+
+	// A tag set by the handler is kept
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+	c.Response.Header().Set("ETag", `"v2"`)
+	c.File(path)
+	if w.Result().Header.Get("ETag") != `"v2"` {
+		t.Errorf("Expected handler ETag to be kept, got %q", w.Result().Header.Get("ETag"))
+	}
+}
@@ -0,0 +1,119 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains one-shot flash messages stored in a signed cookie.
+package goxpress
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// flashCookieName is the name of the cookie holding pending flashes.
+const flashCookieName = "goxpress_flash"
+
+// flashKey is the Context store key holding the *flashState of a request.
+const flashKey = "goxpress.flash"
+
+// Flash is a message shown once to the user, typically on the page a form
+// submission redirects to.
+type Flash struct {
+	Kind    string `json:"kind"`    // Category used for styling, e.g. "success" or "error"
+	Message string `json:"message"` // Text shown to the user
+}
+
+// flashState tracks the flashes of a request.
+type flashState struct {
+	pending []Flash // Flashes not read yet, received or added
+	cookie  bool    // Whether the client holds or was sent a flash cookie
+}
+
+// Flash adds a message for the next page rendered for the client, usually
+// before redirecting after a form submission (post/redirect/get). Flashes
+// are kept in a cookie signed with the secret set by
+// Engine.SetCookieSecret, so call Flash before writing the response. It
+// panics if no secret is set.
+//
+// Example:
+//
+//	app.POST("/profile", func(c *goxpress.Context) {
+//		if err := saveProfile(c); err != nil {
+//			c.Flash("error", "Your profile could not be saved.")
+//		} else {
+//			c.Flash("success", "Profile saved.")
+//		}
+//		c.Redirect(http.StatusSeeOther, "/profile")
+//	})
+func (c *Context) Flash(kind, message string) {
+	state := c.flashState()
+	state.pending = append(state.pending, Flash{Kind: kind, Message: message})
+	c.writeFlashCookie(state)
+}
+
+// Flashes returns the flash messages added for the client, including
+// those added during this request, and clears them so they are shown only
+// once. Templates of the built-in renderer can read them with the flashes
+// function:
+//
+//	{{range flashes}}<div class="alert-{{.Kind}}">{{.Message}}</div>{{end}}
+//
+// Flashes whose cookie was not signed with the engine's secret are
+// ignored. It panics if no secret is set.
+//
+// Example:
+//
+//	app.GET("/profile", func(c *goxpress.Context) {
+//		c.Render(200, "profile.html", map[string]interface{}{
+//			"Flashes": c.Flashes(),
+//		})
+//	})
+func (c *Context) Flashes() []Flash {
+	state := c.flashState()
+	flashes := state.pending
+	if len(flashes) > 0 {
+		state.pending = nil
+		c.writeFlashCookie(state)
+	}
+	return flashes
+}
+
+// flashState returns the flash state of the request, reading the flash
+// cookie on first use.
+func (c *Context) flashState() *flashState {
+	if value, ok := c.Get(flashKey); ok {
+		return value.(*flashState)
+	}
+
+	state := &flashState{}
+	if value, err := c.SignedCookie(flashCookieName); err == nil {
+		state.cookie = true
+		json.Unmarshal([]byte(value), &state.pending)
+	} else if err == ErrInvalidCookie {
+		state.cookie = true // Cleared with the next write
+	}
+	c.Set(flashKey, state)
+	return state
+}
+
+// writeFlashCookie sends the pending flashes to the client, replacing the
+// flash cookie set earlier in the request, or deletes the cookie once
+// none are left.
+func (c *Context) writeFlashCookie(state *flashState) {
+	if len(state.pending) == 0 && !state.cookie {
+		return
+	}
+
+	cookie := &http.Cookie{
+		Name:     flashCookieName,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   c.Request.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if len(state.pending) == 0 {
+		cookie.MaxAge = -1
+	} else {
+		data, _ := json.Marshal(state.pending)
+		cookie.Value = signCookieValue(c.cookieSecret(), flashCookieName, string(data))
+		state.cookie = true
+	}
+	c.setCookieHeader(cookie)
+}
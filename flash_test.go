@@ -0,0 +1,71 @@
+package goxpress
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestFlash(t *testing.T) {
+	path := writeTempFile(t, "profile.html", `{{range flashes}}[{{.Kind}}: {{.Message}}]{{end}}{{.}}`)
+
+	app := New().SetCookieSecret([]byte("0123456789abcdef0123456789abcdef"))
+	if err := app.LoadHTMLGlob(filepath.Join(filepath.Dir(path), "*.html")); err != nil {
+		t.Fatal(err)
+	}
+	app.POST("/profile", func(c *Context) {
+		c.Flash("success", "Profile saved.")
+		c.Flash("info", "<b>Welcome</b>")
+		c.Redirect(http.StatusSeeOther, "/profile")
+	})
+	app.GET("/profile", func(c *Context) {
+		c.Render(200, "profile.html", "page")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/profile", nil))
+	cookies := w.Result().Cookies()
+	if w.Code != http.StatusSeeOther || len(cookies) != 1 {
+		t.Fatalf("Expected a redirect with one flash cookie, got %d %v", w.Code, w.Header()["Set-Cookie"])
+	}
+
+	req := httptest.NewRequest("GET", "/profile", nil)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if want := "[success: Profile saved.][info: &lt;b&gt;Welcome&lt;/b&gt;]page"; w.Body.String() != want {
+		t.Errorf("Expected %q, got %q", want, w.Body.String())
+	}
+	cleared := w.Result().Cookies()
+	if len(cleared) != 1 || cleared[0].MaxAge >= 0 {
+		t.Errorf("Expected the flash cookie to be cleared, got %v", cleared)
+	}
+
+	// Flashes are shown once, and forged cookies are ignored
+	req = httptest.NewRequest("GET", "/profile", nil)
+	req.AddCookie(&http.Cookie{Name: flashCookieName, Value: `[{"kind":"error","message":"forged"}]`})
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Body.String() != "page" {
+		t.Errorf("Expected no flashes, got %q", w.Body.String())
+	}
+}
+
+func TestFlashesInRequest(t *testing.T) {
+	app := New().SetCookieSecret([]byte("secret"))
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	c.engine = app
+
+	c.Flash("error", "Invalid email")
+	flashes := c.Flashes()
+	if len(flashes) != 1 || flashes[0] != (Flash{Kind: "error", Message: "Invalid email"}) {
+		t.Errorf("Expected the flash added in the request, got %v", flashes)
+	}
+	if len(c.Flashes()) != 0 {
+		t.Error("Flashes should be cleared on read")
+	}
+	if cookies := c.Response.Header()["Set-Cookie"]; len(cookies) != 1 {
+		t.Errorf("Expected the flash cookie to be replaced, got %v", cookies)
+	}
+}
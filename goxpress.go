@@ -36,7 +36,12 @@
 package goxpress
 
 import (
+	"context"
 	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // HandlerFunc defines the signature for HTTP request handlers.
@@ -76,7 +81,29 @@ type ErrorHandlerFunc func(error, *Context)
 type Engine struct {
 	router        *Router            // HTTP router for request matching
 	middlewares   []HandlerFunc      // Global middleware functions
+	notFound      []HandlerFunc      // Handler chain for unmatched requests
 	errorHandlers []ErrorHandlerFunc // Error handling middleware
+	debug         bool               // Development mode features enabled
+	renderer      Renderer           // Template renderer used by Context.Render
+	reporter      ErrorReporter      // Receives recovered panics and handled errors
+	frozen        bool               // Set by Freeze, registrations are rejected afterwards
+	safeStores    bool               // Context stores are guarded by a mutex
+	propagate     bool               // Context.Set adds values to the request context
+	cookieSecret  []byte             // Signs cookies set by SetSignedCookie and Flash
+
+	// Translates the messages of ValidationErrors, nil for the I18n catalogs
+	validationTranslator ValidationTranslator
+
+	// Providers registered with Provide and its variants, keyed by the type
+	// they build
+	providers map[reflect.Type]*provider
+
+	// Server lifecycle
+	mu            sync.Mutex                        // Guards servers and shutdownHooks
+	servers       []*http.Server                    // Servers started by Listen and ListenTLS
+	shutdownHooks []func(ctx context.Context) error // Functions run by Shutdown
+	shuttingDown  int32                             // Set to 1 once Shutdown is called
+	drainDelay    time.Duration                     // Wait between failing readiness and closing listeners
 }
 
 // New creates and returns a new Engine instance with default configuration.
@@ -92,7 +119,10 @@ func New() *Engine {
 		router:        NewRouter(),
 		middlewares:   make([]HandlerFunc, 0),
 		errorHandlers: make([]ErrorHandlerFunc, 0),
+		reporter:      NopErrorReporter{},
 	}
+	engine.router.engine = engine
+	engine.notFound = engine.combineHandlers([]HandlerFunc{notFoundHandler})
 	return engine
 }
 
@@ -114,10 +144,89 @@ func New() *Engine {
 //		c.Next()
 //	})
 func (e *Engine) Use(middleware ...HandlerFunc) *Engine {
+	if e.frozen {
+		panic("goxpress: cannot register middleware after Freeze")
+	}
 	e.middlewares = append(e.middlewares, middleware...)
+	e.rebuildChains()
+	return e
+}
+
+// combineHandlers returns the full handler chain for handlers: the global
+// middleware followed by handlers, in a slice of its own.
+func (e *Engine) combineHandlers(handlers []HandlerFunc) []HandlerFunc {
+	chain := make([]HandlerFunc, 0, len(e.middlewares)+len(handlers))
+	chain = append(chain, e.middlewares...)
+	return append(chain, handlers...)
+}
+
+// rebuildChains recomputes the handler chains cached on the routes after
+// the global middleware changed.
+func (e *Engine) rebuildChains() {
+	e.notFound = e.combineHandlers([]HandlerFunc{notFoundHandler})
+	for _, tree := range e.router.routes {
+		tree.root.walk(func(n *routerNode) {
+			if n.pattern != "" {
+				n.chain = e.combineHandlers(n.handlers)
+			}
+		})
+	}
+}
+
+// Freeze finalizes the routing tables once all routes and middleware are
+// registered: the handler chains of all routes are packed into a single
+// allocation and the route trees are trimmed to their final size. The
+// router is immutable afterwards, so requests read it without any
+// synchronization; registering routes or middleware panics.
+//
+// Listen, ListenTLS and ServeMixed call Freeze before serving. Call it
+// yourself when serving the Engine through another http.Server. Calling
+// Freeze again has no effect.
+// Returns the Engine instance for method chaining.
+//
+// Example:
+//
+//	server := &http.Server{Addr: ":8080", Handler: app.Freeze()}
+func (e *Engine) Freeze() *Engine {
+	if e.frozen {
+		return e
+	}
+
+	var nodes []*routerNode
+	size := len(e.notFound)
+	for _, tree := range e.router.routes {
+		tree.root.walk(func(n *routerNode) {
+			n.children = n.children[:len(n.children):len(n.children)]
+			if n.pattern != "" {
+				nodes = append(nodes, n)
+				size += len(n.chain)
+			}
+		})
+	}
+
+	// Pack all chains into one slice; the full slice expressions keep any
+	// append on a chain from spilling into its neighbour
+	all := make([]HandlerFunc, 0, size)
+	pack := func(chain []HandlerFunc) []HandlerFunc {
+		start := len(all)
+		all = append(all, chain...)
+		return all[start:len(all):len(all)]
+	}
+	e.notFound = pack(e.notFound)
+	for _, n := range nodes {
+		n.chain = pack(n.chain)
+	}
+
+	e.frozen = true
 	return e
 }
 
+// notFoundHandler responds to requests matching no route.
+func notFoundHandler(c *Context) {
+	c.Status(http.StatusNotFound)
+	c.String(http.StatusNotFound, "404 page not found")
+}
+
 // UseError registers error handling middleware that will be called when
 // errors occur during request processing. Error handlers are executed
 // in the order they are registered.
@@ -127,11 +236,23 @@ func (e *Engine) Use(middleware ...HandlerFunc) *Engine {
 //   - A handler calls c.Next(err) with a non-nil error
 //   - A panic occurs and is recovered by the Recover middleware
 //
+// Error handlers run in the order they were registered, until one writes
+// a response or calls c.Abort. Like middleware, an error handler can call
+// c.Next to run the remaining error handlers first, optionally passing a
+// replacement error. If no error handler writes a response, a default
+// handler answers HTTPErrors with their status code and other errors with
+// 500 Internal Server Error, logging server errors; see HTTPError.
+//
+// The error is also sent to the ErrorReporter set with SetErrorReporter.
+//
 // Example:
 //
 //	app.UseError(func(err error, c *Context) {
-//		log.Printf("Error: %v", err)
-//		c.JSON(500, map[string]string{"error": "Internal Server Error"})
+//		log.Printf("Error: %v", err) // Writes nothing, the next handler runs
+//	}, func(err error, c *Context) {
+//		if errors.Is(err, sql.ErrNoRows) {
+//			c.JSON(404, map[string]string{"error": "Not Found"})
+//		} // Otherwise the default handler answers with 500
 //	})
 func (e *Engine) UseError(handler ...ErrorHandlerFunc) *Engine {
 	e.errorHandlers = append(e.errorHandlers, handler...)
@@ -230,6 +351,79 @@ func (e *Engine) OPTIONS(pattern string, handlers ...HandlerFunc) *Engine {
 	return e
 }
 
+// handle registers a route on the Engine's router, recording the types of
+// its typed handler. It implements TypedRouter.
+func (e *Engine) handle(method, pattern string, handlers []HandlerFunc, types *handlerTypes) {
+	e.router.handle(method, pattern, handlers, types)
+}
+
+// SetDebug enables or disables debug mode. Debug mode turns on
+// development conveniences, such as re-parsing HTML templates on every
+// render so changes show up without restarting the server. It should be
+// disabled in production.
+// Returns the Engine instance for method chaining.
+//
+// Example:
+//
+//	app.SetDebug(os.Getenv("APP_ENV") == "development")
+func (e *Engine) SetDebug(debug bool) *Engine {
+	e.debug = debug
+	return e
+}
+
+// SetConcurrentStore makes the data store of every Context, accessed with
+// Set and Get, safe for concurrent use, guarding it with a mutex. Enable
+// it when middleware or handlers touch the Context from other goroutines,
+// e.g. to run hedged requests or background work during a request.
+// Returns the Engine instance for method chaining.
+//
+// Example:
+//
+//	app.SetConcurrentStore(true)
+//	app.GET("/quote", func(c *goxpress.Context) {
+//		var wg sync.WaitGroup
+//		for _, provider := range providers {
+//			wg.Add(1)
+//			go func(p Provider) {
+//				defer wg.Done()
+//				c.Set("quote."+p.Name(), p.Quote(c))
+//			}(provider)
+//		}
+//		wg.Wait()
+//	})
+func (e *Engine) SetConcurrentStore(enabled bool) *Engine {
+	e.safeStores = enabled
+	return e
+}
+
+// SetValuePropagation makes Context.Set also add each value to the
+// request context under ContextKey(key), so the values reach code that
+// only receives the request's context.Context, such as database drivers,
+// gRPC clients or wrapped http.Handlers. Each Set then replaces
+// c.Request with a shallow copy carrying the new context, so Set must
+// not be called from other goroutines while propagation is enabled.
+// Returns the Engine instance for method chaining.
+//
+// Example:
+//
+//	app.SetValuePropagation(true)
+//	app.Use(func(c *goxpress.Context) {
+//		c.Set("tenant", tenantOf(c))
+//		c.Next()
+//	})
+//
+//	// In a repository receiving c.Request.Context()
+//	tenant, _ := ctx.Value(goxpress.ContextKey("tenant")).(string)
+func (e *Engine) SetValuePropagation(enabled bool) *Engine {
+	e.propagate = enabled
+	return e
+}
+
+// IsDebug reports whether debug mode is enabled.
+func (e *Engine) IsDebug() bool {
+	return e.debug
+}
+
 // Route creates a new route group with the specified prefix.
 // Route groups allow organizing related routes and applying
 // group-specific middleware.
@@ -260,7 +454,16 @@ func (e *Engine) Route(prefix string) *Router {
 // be called directly in normal usage.
 func (e *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	// Get Context from pool for efficient memory usage
-	c := NewContext(w, req)
+	// Cancel the request context as well once writing to the client fails.
+	// It is installed before any handler runs, so Done and Err never
+	// modify the Context and may be called from any goroutine.
+	ctx, cancel := context.WithCancel(req.Context())
+	c := NewContext(w, req.WithContext(ctx))
+	c.clientCancel = cancel
+	c.writer.onFail = cancel
+	c.engine = e
+	c.safeStore = e.safeStores
+	c.propagateValues = e.propagate
 
 	// Ensure Context is returned to pool after request processing
 	defer func() {
@@ -268,39 +471,27 @@ func (e *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		contextPool.Put(c)
 	}()
 
-	// Find matching route for the request
-	node, params := e.router.getRoute(req.Method, req.URL.Path)
-
-	// Set URL parameters if route was found
-	if params != nil {
-		c.params = params
-	}
-
-	// Build handler chain: global middleware + route handlers
-	handlers := make([]HandlerFunc, 0)
-	handlers = append(handlers, e.middlewares...)
+	// Find matching route for the request, capturing URL parameters into
+	// the Context's pooled storage
+	node := e.router.findRoute(req.Method, req.URL.Path, &c.params)
 
+	// Use the handler chain precomputed at registration: global middleware +
+	// route handlers, or the 404 handler if no route matched
 	if node != nil {
-		// Route found: add route-specific handlers
-		handlers = append(handlers, node.handlers...)
+		c.fullPath = node.pattern
+		c.bodyLimit = node.bodyLimit
+		c.handlers = node.chain
 	} else {
-		// No route found: add 404 handler
-		handlers = append(handlers, func(c *Context) {
-			c.Status(http.StatusNotFound)
-			c.String(http.StatusNotFound, "404 page not found")
-		})
+		c.handlers = e.notFound
 	}
 
-	c.handlers = handlers
-
 	// Execute the handler chain
 	c.Next()
 
 	// Process any errors that occurred during request handling
-	if c.err != nil && len(e.errorHandlers) > 0 {
-		for _, handler := range e.errorHandlers {
-			handler(c.err, c)
-		}
+	if c.err != nil {
+		c.reportError(c.err, false, nil)
+		c.handleErrors(e.errorHandlers)
 	}
 }
 
@@ -309,7 +500,7 @@ func (e *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 // it begins accepting connections.
 //
 // This is a blocking call that will run until the server is stopped
-// or encounters an error.
+// or encounters an error. The Engine is frozen first; see Freeze.
 //
 // Example:
 //
@@ -317,10 +508,12 @@ func (e *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 //		log.Println("Server started on :8080")
 //	})
 func (e *Engine) Listen(addr string, cb func()) error {
+	e.Freeze()
 	server := &http.Server{
 		Addr:    addr,
 		Handler: e,
 	}
+	e.trackServer(server)
 
 	if cb != nil {
 		cb()
@@ -335,7 +528,7 @@ func (e *Engine) Listen(addr string, cb func()) error {
 // it begins accepting connections.
 //
 // This is a blocking call that will run until the server is stopped
-// or encounters an error.
+// or encounters an error. The Engine is frozen first; see Freeze.
 //
 // Example:
 //
@@ -343,10 +536,12 @@ func (e *Engine) Listen(addr string, cb func()) error {
 //		log.Println("HTTPS Server started on :443")
 //	})
 func (e *Engine) ListenTLS(addr, certFile, keyFile string, cb func()) error {
+	e.Freeze()
 	server := &http.Server{
 		Addr:    addr,
 		Handler: e,
 	}
+	e.trackServer(server)
 
 	if cb != nil {
 		cb()
@@ -354,3 +549,102 @@ func (e *Engine) ListenTLS(addr, certFile, keyFile string, cb func()) error {
 
 	return server.ListenAndServeTLS(certFile, keyFile)
 }
+
+// trackServer records a server started by the Engine so Shutdown can
+// stop it.
+func (e *Engine) trackServer(server *http.Server) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.servers = append(e.servers, server)
+}
+
+// OnShutdown registers a function that Shutdown runs once the servers have
+// stopped, e.g. to close connections taken over from the server, such as
+// WebSockets, which http.Server does not track. Functions run in the order
+// they are registered.
+// Returns the Engine instance for method chaining.
+//
+// Example:
+//
+//	hub := ws.NewHub(ws.Config{})
+//	app.OnShutdown(hub.Shutdown)
+func (e *Engine) OnShutdown(hook func(ctx context.Context) error) *Engine {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.shutdownHooks = append(e.shutdownHooks, hook)
+	return e
+}
+
+// SetDrainDelay sets how long Shutdown keeps serving after marking the
+// Engine as shutting down and before closing its listeners. During the
+// delay the readiness endpoint of the Health middleware fails while
+// requests are still served, giving load balancers time to notice and
+// stop sending traffic. Use at least the interval at which they poll
+// readiness. Defaults to zero.
+// Returns the Engine instance for method chaining.
+//
+// Example:
+//
+//	app.SetDrainDelay(10 * time.Second) // Readiness is polled every 5s
+func (e *Engine) SetDrainDelay(d time.Duration) *Engine {
+	e.drainDelay = d
+	return e
+}
+
+// Shutdown gracefully stops the servers started by Listen and ListenTLS:
+// they stop accepting connections and wait for in-flight requests to
+// finish until ctx is done. The functions registered with OnShutdown run
+// afterwards. Once Shutdown is called, IsShuttingDown reports true, which
+// makes the readiness endpoint of the Health middleware fail; the
+// listeners are closed after the delay set with SetDrainDelay, or earlier
+// if ctx is done.
+//
+// Applications running the Engine in their own http.Server should call
+// Shutdown before shutting down that server.
+//
+// Example:
+//
+//	go app.Listen(":8080", nil)
+//
+//	stop := make(chan os.Signal, 1)
+//	signal.Notify(stop, syscall.SIGTERM)
+//	<-stop
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	app.Shutdown(ctx)
+func (e *Engine) Shutdown(ctx context.Context) error {
+	atomic.StoreInt32(&e.shuttingDown, 1)
+
+	if e.drainDelay > 0 {
+		timer := time.NewTimer(e.drainDelay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+
+	e.mu.Lock()
+	servers := e.servers
+	hooks := e.shutdownHooks
+	e.mu.Unlock()
+
+	var firstErr error
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	for _, hook := range hooks {
+		if err := hook(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// IsShuttingDown reports whether Shutdown has been called.
+func (e *Engine) IsShuttingDown() bool {
+	return atomic.LoadInt32(&e.shuttingDown) == 1
+}
@@ -191,6 +191,88 @@ func TestMiddlewareExecution(t *testing.T) {
 	}
 }
 
+func TestHandlerChainPrecomputed(t *testing.T) {
+	app := New()
+	api := app.Route("/api")
+	api.Use(func(c *Context) {
+		c.Header("X-Group", "1")
+		c.Next()
+	})
+	api.GET("/users", func(c *Context) { c.String(200, "users") })
+
+	// Global middleware registered after the routes still applies
+	app.Use(func(c *Context) {
+		c.Header("X-Global", "1")
+		c.Next()
+	})
+
+	for _, path := range []string{"/api/users", "/missing"} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Header().Get("X-Global") != "1" {
+			t.Errorf("%s: expected global middleware to run", path)
+		}
+	}
+
+	node, _ := app.router.getRoute("GET", "/api/users")
+	if len(node.chain) != 3 || len(node.handlers) != 2 {
+		t.Errorf("Expected cached chain of 3 handlers, got %d (route handlers %d)", len(node.chain), len(node.handlers))
+	}
+
+	var chain []HandlerFunc
+	app.GET("/chain", func(c *Context) { chain = c.handlers })
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/chain", nil))
+	node, _ = app.router.getRoute("GET", "/chain")
+	if len(chain) != 2 || &chain[0] != &node.chain[0] {
+		t.Error("Expected the request to run the cached chain")
+	}
+}
+
+func TestEngineFreeze(t *testing.T) {
+	app := New()
+	app.Use(func(c *Context) { c.Next() })
+	app.GET("/users/:id", func(c *Context) { c.String(200, "user %s", c.Param("id")) })
+	app.GET("/users/new", func(c *Context) { c.String(200, "new") })
+	app.GET("/users/*rest", func(c *Context) { c.String(200, "rest") })
+
+	if app.Freeze() != app || app.Freeze() != app {
+		t.Fatal("Freeze should return the Engine and be idempotent")
+	}
+
+	tests := map[string]string{
+		"/users/new":   "new",
+		"/users/7":     "user 7",
+		"/users/7/x":   "rest",
+		"/users/new/x": "rest",
+	}
+	for path, expected := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Body.String() != expected {
+			t.Errorf("%s: expected %q, got %q", path, expected, w.Body.String())
+		}
+	}
+
+	node, _ := app.router.getRoute("GET", "/users/7")
+	if cap(node.chain) != len(node.chain) {
+		t.Error("Frozen chains should not have spare capacity")
+	}
+
+	for name, register := range map[string]func(){
+		"route":      func() { app.GET("/late", func(c *Context) {}) },
+		"middleware": func() { app.Use(func(c *Context) {}) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected registering a %s after Freeze to panic", name)
+				}
+			}()
+			register()
+		}()
+	}
+}
+
 func TestMiddlewareAbort(t *testing.T) {
 	app := New()
 	var executed []string
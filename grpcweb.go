@@ -0,0 +1,193 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the gRPC-Web transcoding middleware.
+package goxpress
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// grpcWebTrailerFlag marks the frame carrying the trailers in a gRPC-Web
+// response body.
+const grpcWebTrailerFlag = 0x80
+
+// GRPCWeb returns a middleware that translates gRPC-Web requests from
+// browser clients into gRPC requests for grpcHandler, typically a
+// *grpc.Server, and translates the responses back, so a goxpress gateway
+// can expose gRPC backends to browsers without a separate proxy.
+//
+// Requests with a Content-Type of application/grpc-web,
+// application/grpc-web+proto or their base64-encoded -text variants are
+// handled and the chain is aborted; other requests continue down the
+// chain. Response trailers, such as grpc-status, are sent in the final
+// frame of the body as gRPC-Web requires. Cross-origin browser clients
+// additionally need CORS headers allowing the x-grpc-web and
+// x-user-agent request headers.
+//
+// Example:
+//
+//	grpcServer := grpc.NewServer()
+//	pb.RegisterGreeterServer(grpcServer, &greeter{})
+//
+//	app.Use(goxpress.GRPCWeb(grpcServer))
+func GRPCWeb(grpcHandler http.Handler) HandlerFunc {
+	return func(c *Context) {
+		contentType := c.Request.Header.Get("Content-Type")
+		if c.Request.Method != http.MethodPost || !strings.HasPrefix(contentType, "application/grpc-web") {
+			c.Next()
+			return
+		}
+		c.Abort()
+
+		text := strings.HasPrefix(contentType, "application/grpc-web-text")
+		subtype := ""
+		if i := strings.IndexByte(contentType, '+'); i >= 0 {
+			subtype = contentType[i:]
+		}
+
+		// Present the request as gRPC over HTTP/2
+		req := c.Request.Clone(c.Request.Context())
+		req.ProtoMajor, req.ProtoMinor, req.Proto = 2, 0, "HTTP/2"
+		req.Header.Set("Content-Type", "application/grpc"+subtype)
+		req.Header.Set("Te", "trailers")
+		req.Header.Del("Content-Length")
+		req.ContentLength = -1
+		if text {
+			req.Body = struct {
+				io.Reader
+				io.Closer
+			}{base64.NewDecoder(base64.StdEncoding, c.Request.Body), c.Request.Body}
+		}
+
+		responseType := "application/grpc-web" + subtype
+		if text {
+			responseType = "application/grpc-web-text" + subtype
+		}
+		w := &grpcWebResponseWriter{
+			w:           c.Response,
+			header:      make(http.Header),
+			contentType: responseType,
+			text:        text,
+		}
+		grpcHandler.ServeHTTP(w, req)
+		w.finish()
+	}
+}
+
+// grpcWebResponseWriter translates a gRPC response into a gRPC-Web one.
+type grpcWebResponseWriter struct {
+	w           http.ResponseWriter
+	header      http.Header // Headers set by the gRPC handler, including trailers
+	contentType string
+	text        bool // Whether the body is base64-encoded
+	wroteHeader bool
+	sent        http.Header // Headers sent with the response
+}
+
+// Header returns the headers of the gRPC response.
+func (w *grpcWebResponseWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader sends the headers with the gRPC-Web content type, keeping
+// the announced trailers for the final frame.
+func (w *grpcWebResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	w.sent = make(http.Header)
+	header := w.w.Header()
+	for name, values := range w.header {
+		if name == "Trailer" || strings.HasPrefix(name, http.TrailerPrefix) {
+			continue
+		}
+		header[name] = values
+		w.sent[name] = values
+	}
+	header.Set("Content-Type", w.contentType)
+	header.Del("Content-Length")
+	w.w.WriteHeader(code)
+}
+
+// Write writes body data, base64-encoding it for -text clients.
+func (w *grpcWebResponseWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.text {
+		if _, err := io.WriteString(w.w, base64.StdEncoding.EncodeToString(data)); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	return w.w.Write(data)
+}
+
+// Flush sends buffered data to the client, as gRPC does after each message.
+func (w *grpcWebResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// finish writes the trailers as the final frame of the body.
+func (w *grpcWebResponseWriter) finish() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	trailers := make(http.Header)
+	for _, names := range w.header["Trailer"] {
+		for _, name := range strings.Split(names, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if values, ok := w.header[name]; ok {
+				trailers[name] = values
+			}
+		}
+	}
+	for name, values := range w.header {
+		if strings.HasPrefix(name, http.TrailerPrefix) {
+			trailers[http.CanonicalHeaderKey(strings.TrimPrefix(name, http.TrailerPrefix))] = values
+		}
+	}
+	// Trailers-only responses carry the status in the headers
+	for _, name := range []string{"Grpc-Status", "Grpc-Message"} {
+		if _, ok := trailers[name]; !ok && w.sent[name] == nil {
+			if values, ok := w.header[name]; ok {
+				trailers[name] = values
+			}
+		}
+	}
+
+	names := make([]string, 0, len(trailers))
+	for name := range trailers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var payload bytes.Buffer
+	for _, name := range names {
+		for _, value := range trailers[name] {
+			payload.WriteString(strings.ToLower(name))
+			payload.WriteString(": ")
+			payload.WriteString(value)
+			payload.WriteString("\r\n")
+		}
+	}
+
+	frame := make([]byte, 5, 5+payload.Len())
+	frame[0] = grpcWebTrailerFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(payload.Len()))
+	frame = append(frame, payload.Bytes()...)
+	w.Write(frame)
+}
@@ -0,0 +1,94 @@
+package goxpress
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// grpcFrame returns a length-prefixed gRPC message frame.
+func grpcFrame(flag byte, payload string) string {
+	n := len(payload)
+	return string([]byte{flag, byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}) + payload
+}
+
+func TestGRPCWeb(t *testing.T) {
+	var seen *http.Request
+	grpcHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r
+		body, _ := ioutil.ReadAll(r.Body)
+
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Add("Trailer", "Grpc-Status")
+		w.Header().Add("Trailer", "Grpc-Message")
+		w.WriteHeader(200)
+		w.Write([]byte(grpcFrame(0, "reply to "+string(body[5:]))))
+		w.(http.Flusher).Flush()
+		w.Header().Set("Grpc-Status", "0")
+		w.Header().Set("Grpc-Message", "")
+	})
+
+	app := New()
+	app.Use(GRPCWeb(grpcHandler))
+	app.GET("/", func(c *Context) {
+		c.String(200, "page")
+	})
+
+	trailer := "grpc-message: \r\ngrpc-status: 0\r\n"
+	expected := grpcFrame(0, "reply to hi") + grpcFrame(0x80, trailer)
+
+	t.Run("Binary", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/pkg.Greeter/SayHello", strings.NewReader(grpcFrame(0, "hi")))
+		req.Header.Set("Content-Type", "application/grpc-web+proto")
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		if seen.ProtoMajor != 2 || seen.Header.Get("Content-Type") != "application/grpc+proto" {
+			t.Errorf("Expected a gRPC request, got %s %s", seen.Proto, seen.Header.Get("Content-Type"))
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/grpc-web+proto" {
+			t.Errorf("Expected gRPC-Web content type, got '%s'", ct)
+		}
+		if w.Header().Get("Trailer") != "" {
+			t.Error("Trailer announcements should not be sent as headers")
+		}
+		if w.Body.String() != expected {
+			t.Errorf("Unexpected body %q, expected %q", w.Body.String(), expected)
+		}
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		body := base64.StdEncoding.EncodeToString([]byte(grpcFrame(0, "hi")))
+		req := httptest.NewRequest("POST", "/pkg.Greeter/SayHello", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/grpc-web-text")
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		if ct := w.Header().Get("Content-Type"); ct != "application/grpc-web-text" {
+			t.Errorf("Expected gRPC-Web text content type, got '%s'", ct)
+		}
+		var decoded strings.Builder
+		for _, chunk := range regexp.MustCompile(`[^=]+=*`).FindAllString(w.Body.String(), -1) {
+			data, err := base64.StdEncoding.DecodeString(chunk)
+			if err != nil {
+				t.Fatalf("Invalid base64 chunk %q: %v", chunk, err)
+			}
+			decoded.Write(data)
+		}
+		if decoded.String() != expected {
+			t.Errorf("Unexpected decoded body %q, expected %q", decoded.String(), expected)
+		}
+	})
+
+	t.Run("Passthrough", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		if w.Body.String() != "page" {
+			t.Errorf("Expected other requests to reach the routes, got %q", w.Body.String())
+		}
+	})
+}
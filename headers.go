@@ -0,0 +1,86 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains helpers for setting common response headers.
+package goxpress
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NoCache sets the headers that prevent the response from being stored by
+// browsers and intermediate caches, including legacy HTTP/1.0 caches.
+//
+// Example:
+//
+//	app.GET("/account", func(c *goxpress.Context) {
+//		c.NoCache()
+//		c.JSON(200, account)
+//	})
+func (c *Context) NoCache() {
+	header := c.Response.Header()
+	header.Set("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
+	header.Set("Pragma", "no-cache")
+	header.Set("Expires", "Thu, 01 Jan 1970 00:00:00 GMT")
+}
+
+// CacheFor marks the response as cacheable by browsers and shared caches
+// for the given duration, setting both Cache-Control and Expires.
+// A non-positive duration is equivalent to NoCache.
+//
+// Example:
+//
+//	c.CacheFor(24 * time.Hour)
+//	c.Data(200, "image/png", logo)
+func (c *Context) CacheFor(ttl time.Duration) {
+	if ttl <= 0 {
+		c.NoCache()
+		return
+	}
+
+	header := c.Response.Header()
+	header.Set("Cache-Control", "public, max-age="+strconv.FormatInt(int64(ttl/time.Second), 10))
+	header.Set("Expires", time.Now().Add(ttl).UTC().Format(http.TimeFormat))
+	header.Del("Pragma")
+}
+
+// LastModified sets the Last-Modified header to t, allowing clients to
+// revalidate their cached copy with If-Modified-Since. Zero times are
+// ignored.
+//
+// Example:
+//
+//	c.LastModified(article.UpdatedAt)
+//	c.JSON(200, article)
+func (c *Context) LastModified(t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	c.Response.Header().Set("Last-Modified", t.UTC().Format(http.TimeFormat))
+}
+
+// ServerTiming records a backend timing metric in the Server-Timing
+// response header, so browser developer tools can show a breakdown of the
+// time spent on the server. Each call adds one metric; desc is optional.
+// Metrics must be recorded before the response status is written.
+//
+// Example:
+//
+//	start := time.Now()
+//	users, err := db.ListUsers(c)
+//	c.ServerTiming("db", time.Since(start), "List users")
+//	c.JSON(200, users)
+//	// Server-Timing: db;dur=12.7;desc="List users"
+func (c *Context) ServerTiming(name string, d time.Duration, desc string) {
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteString(";dur=")
+	b.WriteString(strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', -1, 64))
+	if desc != "" {
+		b.WriteString(`;desc="`)
+		b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(desc))
+		b.WriteString(`"`)
+	}
+	c.Response.Header().Add("Server-Timing", b.String())
+}
@@ -0,0 +1,92 @@
+package goxpress
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestContextNoCache(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+	c.NoCache()
+
+	if cc := w.Header().Get("Cache-Control"); cc != "no-store, no-cache, must-revalidate, max-age=0" {
+		t.Errorf("Unexpected Cache-Control %q", cc)
+	}
+	if w.Header().Get("Pragma") != "no-cache" {
+		t.Error("NoCache should set Pragma for HTTP/1.0 caches")
+	}
+	if w.Header().Get("Expires") == "" {
+		t.Error("NoCache should set Expires")
+	}
+}
+
+func TestContextCacheFor(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+	c.NoCache()
+	c.CacheFor(90 * time.Minute)
+
+	if cc := w.Header().Get("Cache-Control"); cc != "public, max-age=5400" {
+		t.Errorf("Unexpected Cache-Control %q", cc)
+	}
+	if w.Header().Get("Pragma") != "" {
+		t.Error("CacheFor should clear Pragma")
+	}
+
+	expires, err := http.ParseTime(w.Header().Get("Expires"))
+	if err != nil {
+		t.Fatalf("Expires should be an HTTP date: %v", err)
+	}
+	if d := time.Until(expires); d < 89*time.Minute || d > 91*time.Minute {
+		t.Errorf("Expires should be 90 minutes from now, got %v", d)
+	}
+
+	t.Run("NonPositive", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+		c.CacheFor(0)
+
+		if w.Header().Get("Pragma") != "no-cache" {
+			t.Error("CacheFor(0) should disable caching")
+		}
+	})
+}
+
+func TestContextLastModified(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+	c.LastModified(time.Time{})
+	if w.Header().Get("Last-Modified") != "" {
+		t.Error("Zero time should not set Last-Modified")
+	}
+
+	modified := time.Date(2024, 3, 1, 12, 30, 0, 0, time.FixedZone("CET", 3600))
+	c.LastModified(modified)
+	if lm := w.Header().Get("Last-Modified"); lm != "Fri, 01 Mar 2024 11:30:00 GMT" {
+		t.Errorf("Unexpected Last-Modified %q", lm)
+	}
+}
+
+func TestContextServerTiming(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+	c.ServerTiming("db", 12700*time.Microsecond, "List users")
+	c.ServerTiming("cache", 2*time.Millisecond, "")
+	c.ServerTiming("render", time.Millisecond, `say "hi"`)
+
+	values := w.Header()["Server-Timing"]
+	expected := []string{`db;dur=12.7;desc="List users"`, "cache;dur=2", `render;dur=1;desc="say \"hi\""`}
+	if len(values) != len(expected) {
+		t.Fatalf("Expected %d Server-Timing values, got %v", len(expected), values)
+	}
+	for i := range expected {
+		if values[i] != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], values[i])
+		}
+	}
+}
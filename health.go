@@ -0,0 +1,186 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the health check middleware.
+package goxpress
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Check is a named readiness probe, such as a database ping or a request
+// to a dependency. Run returns nil if the dependency is healthy.
+type Check struct {
+	// Name identifies the check in the readiness response.
+	Name string
+
+	// Run performs the check. It should return promptly once ctx is done.
+	Run func(ctx context.Context) error
+
+	// Timeout bounds the check. If zero, HealthConfig.Timeout is used.
+	Timeout time.Duration
+}
+
+// HealthConfig defines configuration options for the health check middleware.
+type HealthConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for,
+	// which then reach the routes as any other request, e.g. to serve the
+	// health endpoints only on an internal host.
+	Skipper Skipper
+
+	// LivenessPath answers 200 as long as the process can serve requests.
+	// If empty, defaults to "/healthz".
+	LivenessPath string
+
+	// ReadinessPath answers 200 when all Checks pass and 503 otherwise,
+	// or while the Engine is shutting down.
+	// If empty, defaults to "/readyz".
+	ReadinessPath string
+
+	// Checks run concurrently on every readiness request.
+	Checks []Check
+
+	// Timeout is the default timeout of each check.
+	// If zero, defaults to 5 seconds.
+	Timeout time.Duration
+}
+
+// healthResponse is the JSON body of the health endpoints.
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Health returns a middleware serving liveness and readiness endpoints for
+// load balancers and orchestrators such as Kubernetes. Requests to other
+// paths pass through.
+//
+// The readiness endpoint reports 503 once Engine.Shutdown is called, so
+// traffic drains away from an instance before it stops. Set a drain delay
+// with Engine.SetDrainDelay so load balancers see it before the listeners
+// close.
+//
+// Example:
+//
+//	app.Use(goxpress.Health(goxpress.HealthConfig{
+//		Checks: []goxpress.Check{
+//			{Name: "database", Run: db.PingContext},
+//			{Name: "cache", Run: func(ctx context.Context) error {
+//				return rdb.Ping(ctx).Err()
+//			}, Timeout: time.Second},
+//		},
+//	}))
+//
+// Response of GET /readyz:
+//
+//	{"status":"unavailable","checks":{"cache":"ok","database":"context deadline exceeded"}}
+func Health(config HealthConfig) HandlerFunc {
+	// Set defaults
+	if config.LivenessPath == "" {
+		config.LivenessPath = "/healthz"
+	}
+	if config.ReadinessPath == "" {
+		config.ReadinessPath = "/readyz"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 5 * time.Second
+	}
+
+	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		switch c.Request.URL.Path {
+		case config.LivenessPath:
+			c.Abort()
+			c.Header("Cache-Control", "no-store")
+			c.JSON(http.StatusOK, healthResponse{Status: "ok"})
+		case config.ReadinessPath:
+			c.Abort()
+			c.Header("Cache-Control", "no-store")
+			if c.engine != nil && c.engine.IsShuttingDown() {
+				c.JSON(http.StatusServiceUnavailable, healthResponse{Status: "shutting down"})
+				return
+			}
+
+			results, healthy := runChecks(c.Request.Context(), config.Checks, config.Timeout)
+			if !healthy {
+				c.JSON(http.StatusServiceUnavailable, healthResponse{Status: "unavailable", Checks: results})
+				return
+			}
+			c.JSON(http.StatusOK, healthResponse{Status: "ok", Checks: results})
+		default:
+			c.Next()
+		}
+	}
+}
+
+// runChecks runs checks concurrently, each under its own timeout, and
+// returns the outcome of each check by name and whether all passed.
+func runChecks(ctx context.Context, checks []Check, timeout time.Duration) (map[string]string, bool) {
+	if len(checks) == 0 {
+		return nil, true
+	}
+
+	results := make(map[string]string, len(checks))
+	healthy := true
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+
+			d := check.Timeout
+			if d == 0 {
+				d = timeout
+			}
+			checkCtx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			err := runCheck(checkCtx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				results[check.Name] = err.Error()
+				healthy = false
+			} else {
+				results[check.Name] = "ok"
+			}
+		}(check)
+	}
+
+	wg.Wait()
+	return results, healthy
+}
+
+// runCheck runs a single check, giving up when ctx is done even if the
+// check ignores its context, and converting panics into errors.
+func runCheck(ctx context.Context, check Check) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- check.Run(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
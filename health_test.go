@@ -0,0 +1,152 @@
+package goxpress
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealth(t *testing.T) {
+	dbErr := error(nil)
+
+	app := New()
+	app.Use(Health(HealthConfig{
+		Checks: []Check{
+			{Name: "database", Run: func(ctx context.Context) error { return dbErr }},
+			{Name: "cache", Run: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}, Timeout: 10 * time.Millisecond},
+		},
+	}))
+	app.GET("/", func(c *Context) {
+		c.String(200, "home")
+	})
+
+	request := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	if w := request("/healthz"); w.Code != 200 || w.Body.String() != "{\"status\":\"ok\"}\n" {
+		t.Errorf("Unexpected liveness response %d '%s'", w.Code, w.Body.String())
+	}
+
+	w := request("/readyz")
+	expected := "{\"status\":\"unavailable\",\"checks\":{\"cache\":\"context deadline exceeded\",\"database\":\"ok\"}}\n"
+	if w.Code != 503 || w.Body.String() != expected {
+		t.Errorf("Unexpected readiness response %d '%s'", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Cache-Control") != "no-store" {
+		t.Error("Health responses should not be cached")
+	}
+
+	if w := request("/"); w.Body.String() != "home" {
+		t.Error("Other paths should pass through")
+	}
+
+	dbErr = errors.New("connection refused")
+	if w := request("/readyz"); w.Code != 503 {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+}
+
+func TestHealthShutdown(t *testing.T) {
+	var hooked bool
+
+	app := New()
+	app.Use(Health(HealthConfig{}))
+	app.OnShutdown(func(ctx context.Context) error {
+		hooked = true
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != 200 || w.Body.String() != "{\"status\":\"ok\"}\n" {
+		t.Errorf("Unexpected readiness response %d '%s'", w.Code, w.Body.String())
+	}
+
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown should not return error: %v", err)
+	}
+	if !app.IsShuttingDown() {
+		t.Error("Expected engine to report shutting down")
+	}
+	if !hooked {
+		t.Error("Expected Shutdown to run the OnShutdown hooks")
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != 503 {
+		t.Errorf("Expected readiness to fail during shutdown, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+	if w.Code != 200 {
+		t.Errorf("Liveness should pass during shutdown, got %d", w.Code)
+	}
+}
+
+func TestHealthDrainDelay(t *testing.T) {
+	app := New()
+	app.Use(Health(HealthConfig{}))
+	app.SetDrainDelay(100 * time.Millisecond)
+
+	done := make(chan struct{})
+	start := time.Now()
+	go func() {
+		app.Shutdown(context.Background())
+		close(done)
+	}()
+
+	// Readiness fails while Shutdown is still draining
+	time.Sleep(20 * time.Millisecond)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != 503 {
+		t.Errorf("Expected readiness to fail during the drain delay, got %d", w.Code)
+	}
+	select {
+	case <-done:
+		t.Fatal("Shutdown should wait for the drain delay")
+	default:
+	}
+
+	<-done
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Expected Shutdown to take the drain delay, took %v", elapsed)
+	}
+
+	// A done context cuts the delay short
+	app = New().SetDrainDelay(time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	app.Shutdown(ctx)
+}
+
+func TestHealthSkipper(t *testing.T) {
+	app := New()
+	app.Use(Health(HealthConfig{
+		Skipper: func(c *Context) bool { return c.Request.Host != "internal" },
+	}))
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+	if w.Code != 404 {
+		t.Errorf("Expected skipped health endpoint to reach the router, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest("GET", "/healthz", nil)
+	req.Host = "internal"
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != 200 {
+		t.Errorf("Expected health endpoint on the internal host, got %d", w.Code)
+	}
+}
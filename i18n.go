@@ -0,0 +1,345 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains message catalogs and the locale negotiation middleware.
+package goxpress
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// localizerKey is the Context store key holding the current *localizer.
+const localizerKey = "goxpress.localizer"
+
+// Translator holds the message catalogs of an application, one per locale.
+// It is safe for concurrent use.
+type Translator struct {
+	fallback string                       // Locale used for missing messages
+	catalogs map[string]map[string]string // Messages by lowercase locale tag
+	locales  []string                     // Available locale tags, sorted
+}
+
+// LoadTranslations loads message catalogs from the JSON files at the root
+// of fsys. Each file is named after its locale tag, such as "en.json" or
+// "pt-BR.json", and holds an object mapping message keys to messages:
+//
+//	{"welcome": "Welcome, %s!", "logout": "Log out"}
+//
+// Messages are fmt format strings. A message missing from a catalog is
+// looked up in the base language ("pt" for "pt-BR") and then in the
+// fallback locale, which must have a catalog.
+//
+// Example:
+//
+//	//go:embed locales/*.json
+//	var locales embed.FS
+//
+//	catalogs, _ := fs.Sub(locales, "locales")
+//	translator, err := goxpress.LoadTranslations(catalogs, "en")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func LoadTranslations(fsys fs.FS, fallback string) (*Translator, error) {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Translator{catalogs: make(map[string]map[string]string, len(files))}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("goxpress: invalid catalog %s: %v", file, err)
+		}
+
+		locale := strings.TrimSuffix(path.Base(file), ".json")
+		t.catalogs[strings.ToLower(locale)] = catalog
+		t.locales = append(t.locales, locale)
+	}
+	sort.Strings(t.locales)
+
+	t.fallback = t.Match(fallback)
+	if t.fallback == "" {
+		return nil, fmt.Errorf("goxpress: no catalog for fallback locale %q", fallback)
+	}
+	return t, nil
+}
+
+// Locales returns the tags of the available locales.
+func (t *Translator) Locales() []string {
+	return t.locales
+}
+
+// Match returns the available locale best matching tag: an exact match,
+// ignoring case, or else the base language of tag. It returns an empty
+// string if neither is available.
+func (t *Translator) Match(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return ""
+	}
+	if i := strings.IndexByte(tag, '_'); i >= 0 {
+		tag = tag[:i] + "-" + tag[i+1:]
+	}
+
+	for {
+		for _, locale := range t.locales {
+			if strings.ToLower(locale) == tag {
+				return locale
+			}
+		}
+		i := strings.LastIndexByte(tag, '-')
+		if i < 0 {
+			return ""
+		}
+		tag = tag[:i]
+	}
+}
+
+// Translate returns the message for key in locale, formatted with args.
+// If no catalog has the message, the key itself is returned so missing
+// translations stay visible.
+func (t *Translator) Translate(locale, key string, args ...interface{}) string {
+	message, ok := t.lookup(locale, key)
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// lookup finds the message for key in locale, its base languages and the
+// fallback locale.
+func (t *Translator) lookup(locale, key string) (string, bool) {
+	tag := strings.ToLower(locale)
+	for tag != "" {
+		if message, ok := t.catalogs[tag][key]; ok {
+			return message, true
+		}
+		i := strings.LastIndexByte(tag, '-')
+		if i < 0 {
+			break
+		}
+		tag = tag[:i]
+	}
+	message, ok := t.catalogs[strings.ToLower(t.fallback)][key]
+	return message, ok
+}
+
+// FuncMap returns template functions for translating messages in
+// templates: t takes a locale, a message key and format arguments. Pass
+// c.Locale() to the template to select the locale.
+//
+// Example:
+//
+//	app.SetFuncMap(translator.FuncMap())
+//	app.LoadHTMLGlob("templates/*.html")
+//
+//	// In a handler
+//	c.Render(200, "home.html", map[string]interface{}{"Locale": c.Locale(), "Name": user.Name})
+//
+//	// In home.html
+//	<h1>{{t .Locale "welcome" .Name}}</h1>
+func (t *Translator) FuncMap() template.FuncMap {
+	return template.FuncMap{"t": t.Translate}
+}
+
+// I18nConfig defines configuration options for the i18n middleware.
+type I18nConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper Skipper
+
+	// Translator provides the message catalogs. Required.
+	Translator *Translator
+
+	// QueryParam is the query parameter that overrides the locale, as in
+	// "?lang=fr". If empty, defaults to "lang".
+	QueryParam string
+
+	// CookieName is the cookie that overrides the locale, so a language
+	// picked by the user sticks. If empty, defaults to "lang".
+	CookieName string
+}
+
+// localizer is the translator and locale of one request.
+type localizer struct {
+	translator *Translator
+	locale     string
+	cookieName string
+}
+
+// I18n returns a middleware that selects the locale of each request and
+// makes its messages available through c.T. The locale is taken from the
+// query parameter, then the cookie, then the Accept-Language header,
+// using the first one naming an available locale; otherwise the fallback
+// locale of the Translator is used. It panics if config.Translator is nil.
+//
+// The Content-Language response header is set to the selected locale.
+//
+// Example:
+//
+//	app.Use(goxpress.I18n(goxpress.I18nConfig{Translator: translator}))
+//
+//	app.GET("/", func(c *goxpress.Context) {
+//		c.String(200, c.T("welcome", user.Name))
+//	})
+func I18n(config I18nConfig) HandlerFunc {
+	if config.Translator == nil {
+		panic("goxpress: I18nConfig.Translator is required")
+	}
+
+	// Set defaults
+	if config.QueryParam == "" {
+		config.QueryParam = "lang"
+	}
+	if config.CookieName == "" {
+		config.CookieName = "lang"
+	}
+
+	t := config.Translator
+	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		locale := t.Match(c.Query(config.QueryParam))
+		if locale == "" {
+			if cookie, err := c.Request.Cookie(config.CookieName); err == nil {
+				locale = t.Match(cookie.Value)
+			}
+		}
+		if locale == "" {
+			for _, tag := range parseAcceptLanguage(c.Request.Header.Get("Accept-Language")) {
+				if locale = t.Match(tag); locale != "" {
+					break
+				}
+			}
+		}
+		if locale == "" {
+			locale = t.fallback
+		}
+
+		header := c.Response.Header()
+		header.Set("Content-Language", locale)
+		header.Add("Vary", "Accept-Language")
+
+		c.Set(localizerKey, &localizer{translator: t, locale: locale, cookieName: config.CookieName})
+		c.Next()
+	}
+}
+
+// parseAcceptLanguage returns the language tags of an Accept-Language
+// header, most preferred first. Wildcards and refused tags are dropped.
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		tag := strings.TrimSpace(params[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag, q})
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
+
+// Locale returns the locale selected for the request by the I18n
+// middleware, or an empty string if the middleware is not installed.
+func (c *Context) Locale() string {
+	if l, ok := c.localizer(); ok {
+		return l.locale
+	}
+	return ""
+}
+
+// T returns the message for key in the request's locale, formatted with
+// args. Without the I18n middleware, or if no catalog has the message,
+// the key itself is returned.
+//
+// Example:
+//
+//	c.JSON(404, map[string]string{"error": c.T("user.not_found", id)})
+func (c *Context) T(key string, args ...interface{}) string {
+	l, ok := c.localizer()
+	if !ok {
+		return key
+	}
+	return l.translator.Translate(l.locale, key, args...)
+}
+
+// localizer returns the localizer stored by the I18n middleware.
+func (c *Context) localizer() (*localizer, bool) {
+	value, ok := c.Get(localizerKey)
+	if !ok {
+		return nil, false
+	}
+	l, ok := value.(*localizer)
+	return l, ok
+}
+
+// SetLocale switches the rest of the request to locale and remembers it
+// in the locale cookie of the I18n middleware, for example when the user
+// picks a language. It has no effect without the middleware or if locale
+// is not available.
+//
+// Example:
+//
+//	app.POST("/language", func(c *goxpress.Context) {
+//		c.SetLocale(c.PostForm("lang"))
+//		c.Redirect(303, "/")
+//	})
+func (c *Context) SetLocale(locale string) {
+	l, ok := c.localizer()
+	if !ok {
+		return
+	}
+	matched := l.translator.Match(locale)
+	if matched == "" {
+		return
+	}
+
+	l.locale = matched
+	c.Response.Header().Set("Content-Language", matched)
+	c.setCookieHeader(&http.Cookie{
+		Name:   l.cookieName,
+		Value:  matched,
+		Path:   "/",
+		MaxAge: 365 * 24 * 60 * 60,
+	})
+}
@@ -0,0 +1,117 @@
+package goxpress
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func newTestTranslator(t *testing.T) *Translator {
+	translator, err := LoadTranslations(fstest.MapFS{
+		"en.json":    {Data: []byte(`{"welcome": "Welcome, %s!", "logout": "Log out"}`)},
+		"fr.json":    {Data: []byte(`{"welcome": "Bienvenue, %s !"}`)},
+		"pt.json":    {Data: []byte(`{"welcome": "Bem-vindo, %s!"}`)},
+		"pt-BR.json": {Data: []byte(`{"logout": "Sair"}`)},
+	}, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return translator
+}
+
+func TestTranslator(t *testing.T) {
+	translator := newTestTranslator(t)
+
+	if locales := translator.Locales(); !reflect.DeepEqual(locales, []string{"en", "fr", "pt", "pt-BR"}) {
+		t.Errorf("Unexpected locales %v", locales)
+	}
+
+	tests := []struct {
+		locale, key string
+		args        []interface{}
+		expected    string
+	}{
+		{"fr", "welcome", []interface{}{"Ana"}, "Bienvenue, Ana !"},
+		{"pt-BR", "welcome", []interface{}{"Ana"}, "Bem-vindo, Ana!"}, // Base language
+		{"pt-BR", "logout", nil, "Sair"},
+		{"fr", "logout", nil, "Log out"},  // Fallback locale
+		{"fr", "missing", nil, "missing"}, // Key itself
+	}
+	for _, tt := range tests {
+		if got := translator.Translate(tt.locale, tt.key, tt.args...); got != tt.expected {
+			t.Errorf("Translate(%q, %q) = %q, expected %q", tt.locale, tt.key, got, tt.expected)
+		}
+	}
+
+	matches := map[string]string{"PT_br": "pt-BR", "pt-PT": "pt", "fr-CA": "fr", "de": ""}
+	for tag, expected := range matches {
+		if got := translator.Match(tag); got != expected {
+			t.Errorf("Match(%q) = %q, expected %q", tag, got, expected)
+		}
+	}
+
+	if _, err := LoadTranslations(fstest.MapFS{"en.json": {Data: []byte(`{}`)}}, "de"); err == nil {
+		t.Error("Expected error for a fallback locale without catalog")
+	}
+	if _, err := LoadTranslations(fstest.MapFS{"en.json": {Data: []byte(`[`)}}, "en"); err == nil {
+		t.Error("Expected error for an invalid catalog")
+	}
+}
+
+func TestI18n(t *testing.T) {
+	app := New()
+	app.Use(I18n(I18nConfig{Translator: newTestTranslator(t)}))
+	app.GET("/", func(c *Context) {
+		c.String(200, "%s %s", c.Locale(), c.T("welcome", "Ana"))
+	})
+	app.POST("/language", func(c *Context) {
+		c.SetLocale(c.Query("to"))
+		c.String(200, c.Locale())
+	})
+
+	tests := []struct {
+		name, target, cookie, acceptLanguage, expected string
+	}{
+		{"AcceptLanguage", "/", "", "de-DE, fr;q=0.8, en;q=0.5", "fr Bienvenue, Ana !"},
+		{"Quality", "/", "", "en;q=0.1, pt-BR", "pt-BR Bem-vindo, Ana!"},
+		{"Cookie", "/", "pt", "fr", "pt Bem-vindo, Ana!"},
+		{"Query", "/?lang=fr", "pt", "en", "fr Bienvenue, Ana !"},
+		{"Fallback", "/?lang=xx", "", "de, *", "en Welcome, Ana!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			if tt.cookie != "" {
+				req.Header.Set("Cookie", "lang="+tt.cookie)
+			}
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+
+			if w.Body.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, w.Body.String())
+			}
+			if locale := strings.SplitN(tt.expected, " ", 2)[0]; w.Header().Get("Content-Language") != locale {
+				t.Errorf("Expected Content-Language %s, got %s", locale, w.Header().Get("Content-Language"))
+			}
+		})
+	}
+
+	t.Run("SetLocale", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("POST", "/language?to=pt_br", nil))
+
+		if w.Body.String() != "pt-BR" || !strings.HasPrefix(w.Header().Get("Set-Cookie"), "lang=pt-BR;") {
+			t.Errorf("Expected locale switch and cookie, got %q and %q", w.Body.String(), w.Header().Get("Set-Cookie"))
+		}
+	})
+
+	t.Run("WithoutMiddleware", func(t *testing.T) {
+		c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		if c.T("welcome") != "welcome" || c.Locale() != "" {
+			t.Error("Expected key to be returned without the middleware")
+		}
+	})
+}
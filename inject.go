@@ -0,0 +1,107 @@
+//go:build go1.18
+
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the generics-based dependency injection API. It
+// requires Go 1.18 or later.
+package goxpress
+
+import (
+	"reflect"
+)
+
+// Provide registers constructor as the provider of T for the engine's
+// requests. It is called on the first Inject of T in a request, and the
+// value is reused for the rest of that request, so request-scoped values
+// such as a transaction or the current user are built at most once.
+// Constructors may Inject their own dependencies from c.
+//
+// Registering a provider for a type replaces the previous one. Provide
+// panics once the engine is frozen.
+//
+// Example:
+//
+//	goxpress.ProvideValue(app, db)
+//	goxpress.Provide(app, func(c *goxpress.Context) (*UserRepo, error) {
+//		return NewUserRepo(goxpress.Inject[*sql.DB](c)), nil
+//	})
+//
+//	app.GET("/users/:id", func(c *goxpress.Context) {
+//		user, err := goxpress.Inject[*UserRepo](c).Find(c, c.Param("id"))
+//		...
+//	})
+func Provide[T any](e *Engine, constructor func(c *Context) (T, error)) {
+	e.provide(typeOf[T](), &provider{
+		construct: func(c *Context) (interface{}, error) { return constructor(c) },
+		scoped:    true,
+	})
+}
+
+// ProvideSingleton registers constructor as the provider of a T shared by
+// all requests, such as a service or a client. It is called on the first
+// Inject of T; if it fails, the next Inject calls it again.
+//
+// Example:
+//
+//	goxpress.ProvideSingleton(app, func() (*Mailer, error) {
+//		return NewMailer(os.Getenv("SMTP_URL"))
+//	})
+func ProvideSingleton[T any](e *Engine, constructor func() (T, error)) {
+	e.provide(typeOf[T](), &provider{
+		construct: func(*Context) (interface{}, error) { return constructor() },
+	})
+}
+
+// ProvideValue registers value as the T shared by all requests.
+//
+// Example:
+//
+//	goxpress.ProvideValue(app, db)
+//	goxpress.ProvideValue[Clock](app, realClock{}) // Provided as the interface
+func ProvideValue[T any](e *Engine, value T) {
+	e.provide(typeOf[T](), &provider{value: value, built: true})
+}
+
+// Inject returns the T built by the provider registered for it, building
+// it on first use. Handlers declare their dependencies with Inject instead
+// of reaching for global variables, and tests swap them by registering
+// other providers.
+//
+// Inject panics if no provider is registered for T or its constructor
+// fails, which the Recover middleware turns into 500 Internal Server
+// Error; use Resolve to handle the error instead.
+//
+// Example:
+//
+//	repo := goxpress.Inject[*UserRepo](c)
+func Inject[T any](c *Context) T {
+	value, err := Resolve[T](c)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// Resolve is like Inject, but returns an error instead of panicking when
+// no provider is registered for T or its constructor fails.
+//
+// Example:
+//
+//	user, err := goxpress.Resolve[*CurrentUser](c)
+//	if err != nil {
+//		c.Next(goxpress.ErrUnauthorized)
+//		return
+//	}
+func Resolve[T any](c *Context) (T, error) {
+	value, err := c.resolve(typeOf[T]())
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	typed, _ := value.(T) // A nil interface value stays the zero T
+	return typed, nil
+}
+
+// typeOf returns the type T, including interface types.
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
@@ -0,0 +1,78 @@
+//go:build go1.18
+
+package goxpress
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type injectRepo struct{ name string }
+
+type injectClock interface{ Now() string }
+
+type injectFixedClock struct{}
+
+func (injectFixedClock) Now() string { return "noon" }
+
+type injectRequestID string
+
+func TestInject(t *testing.T) {
+	singletons, scoped := 0, 0
+	app := New()
+	ProvideValue[injectClock](app, injectFixedClock{})
+	ProvideSingleton(app, func() (*injectRepo, error) {
+		singletons++
+		return &injectRepo{name: "users"}, nil
+	})
+	Provide(app, func(c *Context) (injectRequestID, error) {
+		scoped++
+		return injectRequestID(c.Query("id") + "@" + Inject[injectClock](c).Now()), nil
+	})
+
+	app.GET("/", func(c *Context) {
+		// Scoped values are built once per request
+		first, second := Inject[injectRequestID](c), Inject[injectRequestID](c)
+		c.String(200, Inject[*injectRepo](c).name+" "+string(first)+" "+string(second))
+	})
+
+	for _, id := range []string{"a", "b"} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/?id="+id, nil))
+		if want := "users " + id + "@noon " + id + "@noon"; w.Body.String() != want {
+			t.Errorf("Expected %q, got %q", want, w.Body.String())
+		}
+	}
+	if singletons != 1 || scoped != 2 {
+		t.Errorf("Expected 1 singleton and 2 scoped constructions, got %d and %d", singletons, scoped)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	app := New()
+	Provide(app, func(c *Context) (*injectRepo, error) {
+		return nil, ErrUnauthorized
+	})
+	Provide(app, func(c *Context) (injectRequestID, error) {
+		return Inject[injectRequestID](c), nil
+	})
+
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	c.engine = app
+
+	if _, err := Resolve[injectClock](c); err == nil || !strings.Contains(err.Error(), "no provider") {
+		t.Errorf("Expected missing provider error, got %v", err)
+	}
+	if _, err := Resolve[*injectRepo](c); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected the constructor error to be wrapped, got %v", err)
+	}
+
+	defer func() {
+		if err, _ := recover().(error); err == nil || !strings.Contains(err.Error(), "depends on itself") {
+			t.Errorf("Expected a dependency cycle panic, got %v", err)
+		}
+	}()
+	Inject[injectRequestID](c)
+}
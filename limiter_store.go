@@ -0,0 +1,240 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the storage backends of the rate limiting middleware.
+package goxpress
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// LimiterStore keeps the state of the rate limiting middleware.
+// Allow consumes one request for key and reports whether it may proceed,
+// and if not, how long the client should wait before retrying.
+// Implementations must be safe for concurrent use.
+type LimiterStore interface {
+	Allow(key string) (allowed bool, retryAfter time.Duration)
+}
+
+// quotaLimiterStore is implemented by stores that can also report the
+// number of requests left, which RateLimit exposes in its headers.
+type quotaLimiterStore interface {
+	take(key string) (allowed bool, remaining int, retryAfter time.Duration)
+}
+
+// MemoryLimiterStore is a LimiterStore that keeps a token bucket per key
+// in process memory. Buckets that have refilled completely are discarded
+// periodically, so memory use follows the number of active clients.
+type MemoryLimiterStore struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// tokenBucket holds the state of a single key.
+type tokenBucket struct {
+	tokens float64   // Tokens available at time last
+	last   time.Time // Time tokens were last refilled
+}
+
+// NewMemoryLimiterStore creates an in-memory store allowing rate requests
+// per second per key with bursts of up to burst requests.
+//
+// Example:
+//
+//	store := goxpress.NewMemoryLimiterStore(5, 20)
+//	app.Use(goxpress.RateLimit(goxpress.RateLimitConfig{Rate: 5, Burst: 20, Store: store}))
+func NewMemoryLimiterStore(rate float64, burst int) *MemoryLimiterStore {
+	return &MemoryLimiterStore{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// Allow consumes a token for key.
+func (s *MemoryLimiterStore) Allow(key string) (bool, time.Duration) {
+	allowed, _, retryAfter := s.takeAt(key, time.Now())
+	return allowed, retryAfter
+}
+
+// take consumes a token for key and reports the tokens left.
+func (s *MemoryLimiterStore) take(key string) (bool, int, time.Duration) {
+	return s.takeAt(key, time.Now())
+}
+
+// takeAt consumes a token for key at time now. It reports whether the
+// request is allowed, the number of whole tokens left, and how long to
+// wait for the next token when it is not allowed.
+func (s *MemoryLimiterStore) takeAt(key string, now time.Time) (allowed bool, remaining int, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: s.burst, last: now}
+		s.buckets[key] = bucket
+	}
+
+	// Refill for the time elapsed since the last request
+	if elapsed := now.Sub(bucket.last); elapsed > 0 {
+		bucket.tokens = math.Min(s.burst, bucket.tokens+elapsed.Seconds()*s.rate)
+		bucket.last = now
+	}
+
+	if bucket.tokens < 1 {
+		wait := (1 - bucket.tokens) / s.rate
+		return false, 0, time.Duration(wait * float64(time.Second))
+	}
+
+	bucket.tokens--
+	return true, int(bucket.tokens), 0
+}
+
+// sweep removes buckets that have refilled completely, as they are
+// indistinguishable from new ones. It runs at most once a minute.
+func (s *MemoryLimiterStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	full := time.Duration(s.burst / s.rate * float64(time.Second))
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.last) >= full {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// RedisEvaler is the subset of a Redis client used by RedisLimiterStore.
+// goxpress does not depend on a Redis driver; adapt the client of your
+// choice with a few lines of code.
+//
+// Example with go-redis:
+//
+//	type redisAdapter struct{ *redis.Client }
+//
+//	func (a redisAdapter) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+//		return a.Client.Eval(ctx, script, keys, args...).Result()
+//	}
+type RedisEvaler interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// redisTokenBucket atomically refills and consumes a token bucket stored
+// in a hash. It uses the Redis server clock, so instances with skewed
+// clocks share consistent buckets. Requires Redis 5 or later.
+//
+// KEYS[1] is the bucket key; ARGV[1] is the rate per second and ARGV[2]
+// the burst size. Returns {allowed, remaining, retry after in ms}.
+const redisTokenBucket = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate * 1000)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000))
+return {allowed, math.floor(tokens), wait}
+`
+
+// RedisLimiterStore is a LimiterStore that keeps token buckets in Redis,
+// so every instance of a multi-instance deployment enforces the same
+// limit. Buckets expire once they have refilled completely.
+//
+// If Redis cannot be reached, requests are allowed: an outage of the
+// limiter should not take the application down with it.
+type RedisLimiterStore struct {
+	// Prefix is prepended to every key stored in Redis.
+	// Defaults to "goxpress:ratelimit:".
+	Prefix string
+
+	// Timeout bounds each Redis round trip. Defaults to 100ms.
+	Timeout time.Duration
+
+	client RedisEvaler
+	rate   float64
+	burst  int
+}
+
+// NewRedisLimiterStore creates a Redis-backed store allowing rate requests
+// per second per key with bursts of up to burst requests.
+//
+// Example:
+//
+//	store := goxpress.NewRedisLimiterStore(redisAdapter{rdb}, 5, 20)
+//	app.Use(goxpress.RateLimit(goxpress.RateLimitConfig{Rate: 5, Burst: 20, Store: store}))
+func NewRedisLimiterStore(client RedisEvaler, rate float64, burst int) *RedisLimiterStore {
+	return &RedisLimiterStore{
+		Prefix:  "goxpress:ratelimit:",
+		Timeout: 100 * time.Millisecond,
+		client:  client,
+		rate:    rate,
+		burst:   burst,
+	}
+}
+
+// Allow consumes a token for key.
+func (s *RedisLimiterStore) Allow(key string) (bool, time.Duration) {
+	allowed, _, retryAfter := s.take(key)
+	return allowed, retryAfter
+}
+
+// take consumes a token for key and reports the tokens left.
+func (s *RedisLimiterStore) take(key string) (bool, int, time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout)
+	defer cancel()
+
+	reply, err := s.client.Eval(ctx, redisTokenBucket, []string{s.Prefix + key}, s.rate, s.burst)
+	if err != nil {
+		return true, s.burst, 0
+	}
+
+	values, err := redisInts(reply, 3)
+	if err != nil {
+		return true, s.burst, 0
+	}
+	return values[0] == 1, int(values[1]), time.Duration(values[2]) * time.Millisecond
+}
+
+// redisInts converts a Redis array reply of n integers.
+func redisInts(reply interface{}, n int) ([]int64, error) {
+	items, ok := reply.([]interface{})
+	if !ok || len(items) != n {
+		return nil, fmt.Errorf("goxpress: unexpected redis reply %v", reply)
+	}
+
+	values := make([]int64, n)
+	for i, item := range items {
+		switch v := item.(type) {
+		case int64:
+			values[i] = v
+		case int:
+			values[i] = int64(v)
+		default:
+			return nil, fmt.Errorf("goxpress: unexpected redis reply %v", reply)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,93 @@
+package goxpress
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// denyStore is a LimiterStore that rejects every request.
+type denyStore struct{}
+
+func (denyStore) Allow(key string) (bool, time.Duration) {
+	return false, 2500 * time.Millisecond
+}
+
+// fakeRedis records Eval calls and returns a canned reply.
+type fakeRedis struct {
+	reply interface{}
+	err   error
+	keys  []string
+	args  []interface{}
+}
+
+func (f *fakeRedis) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	f.keys, f.args = keys, args
+	return f.reply, f.err
+}
+
+func TestMemoryLimiterStore(t *testing.T) {
+	store := NewMemoryLimiterStore(2, 1)
+	now := time.Now()
+
+	if allowed, _, _ := store.takeAt("k", now); !allowed {
+		t.Fatal("First request should be allowed")
+	}
+	allowed, _, retryAfter := store.takeAt("k", now)
+	if allowed || retryAfter != 500*time.Millisecond {
+		t.Fatalf("Expected limit with 500ms retry, got allowed=%v retryAfter=%v", allowed, retryAfter)
+	}
+	if allowed, _, _ := store.takeAt("k", now.Add(500*time.Millisecond)); !allowed {
+		t.Error("Request should be allowed after refill")
+	}
+
+	store.takeAt("k", now.Add(2*time.Minute))
+	store.takeAt("other", now.Add(4*time.Minute))
+	if _, ok := store.buckets["k"]; ok {
+		t.Error("Idle full buckets should be swept")
+	}
+
+	if allowed, _ := store.Allow("fresh"); !allowed {
+		t.Error("Allow should admit a new key")
+	}
+}
+
+func TestRedisLimiterStore(t *testing.T) {
+	t.Run("Limited", func(t *testing.T) {
+		client := &fakeRedis{reply: []interface{}{int64(0), int64(0), int64(1500)}}
+		store := NewRedisLimiterStore(client, 5, 20)
+
+		allowed, retryAfter := store.Allow("10.0.0.1")
+		if allowed || retryAfter != 1500*time.Millisecond {
+			t.Errorf("Expected limit with 1.5s retry, got allowed=%v retryAfter=%v", allowed, retryAfter)
+		}
+		if len(client.keys) != 1 || client.keys[0] != "goxpress:ratelimit:10.0.0.1" {
+			t.Errorf("Unexpected keys %v", client.keys)
+		}
+		if len(client.args) != 2 || client.args[0] != 5.0 || client.args[1] != 20 {
+			t.Errorf("Unexpected args %v", client.args)
+		}
+	})
+
+	t.Run("Allowed", func(t *testing.T) {
+		store := NewRedisLimiterStore(&fakeRedis{reply: []interface{}{int64(1), int64(7), int64(0)}}, 5, 20)
+
+		allowed, remaining, _ := store.take("k")
+		if !allowed || remaining != 7 {
+			t.Errorf("Expected allowed with 7 remaining, got allowed=%v remaining=%d", allowed, remaining)
+		}
+	})
+
+	t.Run("FailOpen", func(t *testing.T) {
+		for _, client := range []*fakeRedis{
+			{err: errors.New("connection refused")},
+			{reply: "OK"},
+		} {
+			store := NewRedisLimiterStore(client, 5, 20)
+			if allowed, _ := store.Allow("k"); !allowed {
+				t.Error("Requests should be allowed when Redis fails")
+			}
+		}
+	})
+}
@@ -0,0 +1,192 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the load shedding middleware.
+package goxpress
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Priority ranks requests for load shedding. When the server is
+// overloaded, requests with the lowest priority are rejected first.
+type Priority int
+
+// Request priorities, from the first to be shed to never shed.
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+	PriorityCritical // Never shed
+)
+
+// LoadShedConfig defines configuration options for the load shedding
+// middleware.
+type LoadShedConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper Skipper
+
+	// TargetLatency is the handler latency the server should stay below.
+	// Shedding starts once the fastest request of an interval took longer.
+	// If zero, defaults to 100ms.
+	TargetLatency time.Duration
+
+	// Interval is how often the latency is checked and the shedding level
+	// adjusted. If zero, defaults to 1s.
+	Interval time.Duration
+
+	// MaxInFlight is the maximum number of requests handled at once. When
+	// it is reached, all requests below PriorityCritical are rejected.
+	// If zero, the number of requests in flight is not limited.
+	MaxInFlight int
+
+	// PriorityFunc returns the priority of a request.
+	// If nil, all requests have PriorityNormal.
+	PriorityFunc func(c *Context) Priority
+
+	// Handler is called when a request is shed. It runs after the
+	// Retry-After header is set. If nil, a 503 Service Unavailable
+	// response is sent.
+	Handler HandlerFunc
+}
+
+// loadShedder tracks handler latency and the current shedding level.
+type loadShedder struct {
+	target   time.Duration
+	interval time.Duration
+	inFlight int64 // Accessed atomically
+
+	mu          sync.Mutex
+	level       Priority // Requests below this priority are shed
+	windowStart time.Time
+	minLatency  time.Duration
+	samples     int
+}
+
+// admit reports whether a request of the given priority may proceed.
+// Intervals that ended without any completed request lower the shedding
+// level, so shed priorities are probed again even though rejected
+// requests are never observed.
+func (s *loadShedder) admit(priority Priority, now time.Time) bool {
+	if priority >= PriorityCritical {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.samples == 0 {
+		if idle := now.Sub(s.windowStart) / s.interval; idle > 0 {
+			s.level -= Priority(idle)
+			if s.level < PriorityLow {
+				s.level = PriorityLow
+			}
+			s.windowStart = s.windowStart.Add(idle * s.interval)
+		}
+	}
+	return priority >= s.level
+}
+
+// observe records the latency of a completed request. At the end of each
+// interval, the shedding level is raised if even the fastest request
+// missed the target, and lowered otherwise, as in CoDel.
+func (s *loadShedder) observe(latency time.Duration, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.samples == 0 || latency < s.minLatency {
+		s.minLatency = latency
+	}
+	s.samples++
+
+	if now.Sub(s.windowStart) < s.interval {
+		return
+	}
+	if s.minLatency > s.target {
+		if s.level < PriorityCritical {
+			s.level++
+		}
+	} else if s.level > PriorityLow {
+		s.level--
+	}
+	s.windowStart = now
+	s.samples = 0
+}
+
+// LoadShed returns a middleware that protects the server from overload by
+// rejecting low-priority requests with 503 Service Unavailable, keeping
+// latency bounded during traffic spikes.
+//
+// Shedding adapts to the observed handler latency in the style of CoDel:
+// every Interval, if the fastest request of the interval took longer than
+// TargetLatency, the lowest priority still admitted starts being shed;
+// once latency is back under the target, or an interval passes without
+// any request completing, priorities are readmitted one interval at a
+// time. Independently, MaxInFlight caps the number of
+// requests handled at once. PriorityCritical requests are never shed.
+//
+// Example:
+//
+//	app.Use(goxpress.LoadShed(goxpress.LoadShedConfig{
+//		TargetLatency: 50 * time.Millisecond,
+//		MaxInFlight:   500,
+//		PriorityFunc: func(c *goxpress.Context) goxpress.Priority {
+//			switch {
+//			case c.Request.URL.Path == "/healthz":
+//				return goxpress.PriorityCritical
+//			case strings.HasPrefix(c.Request.URL.Path, "/reports/"):
+//				return goxpress.PriorityLow
+//			}
+//			return goxpress.PriorityNormal
+//		},
+//	}))
+func LoadShed(config LoadShedConfig) HandlerFunc {
+	// Set defaults
+	if config.TargetLatency <= 0 {
+		config.TargetLatency = 100 * time.Millisecond
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	if config.PriorityFunc == nil {
+		config.PriorityFunc = func(c *Context) Priority { return PriorityNormal }
+	}
+	if config.Handler == nil {
+		config.Handler = func(c *Context) {
+			c.String(http.StatusServiceUnavailable, "Service Unavailable")
+		}
+	}
+
+	shedder := &loadShedder{
+		target:      config.TargetLatency,
+		interval:    config.Interval,
+		windowStart: time.Now(),
+	}
+	retryAfter := strconv.Itoa(ceilSeconds(config.Interval))
+
+	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		priority := config.PriorityFunc(c)
+		inFlight := atomic.AddInt64(&shedder.inFlight, 1)
+		defer atomic.AddInt64(&shedder.inFlight, -1)
+
+		overCapacity := config.MaxInFlight > 0 && inFlight > int64(config.MaxInFlight) &&
+			priority < PriorityCritical
+		if overCapacity || !shedder.admit(priority, time.Now()) {
+			c.Response.Header().Set("Retry-After", retryAfter)
+			c.Abort()
+			config.Handler(c)
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+		now := time.Now()
+		shedder.observe(now.Sub(start), now)
+	}
+}
@@ -0,0 +1,141 @@
+package goxpress
+
+import (
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadShedMaxInFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	app := New()
+	app.Use(LoadShed(LoadShedConfig{
+		MaxInFlight: 1,
+		PriorityFunc: func(c *Context) Priority {
+			if c.Request.URL.Path == "/health" {
+				return PriorityCritical
+			}
+			return PriorityNormal
+		},
+	}))
+	app.GET("/slow", func(c *Context) {
+		close(started)
+		<-release
+		c.String(200, "ok")
+	})
+	app.GET("/fast", func(c *Context) {
+		c.String(200, "ok")
+	})
+	app.GET("/health", func(c *Context) {
+		c.String(200, "ok")
+	})
+
+	done := make(chan struct{})
+	go func() {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+		close(done)
+	}()
+	<-started
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/fast", nil))
+	if w.Code != 503 || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("Expected 503 with Retry-After while at capacity, got %d %v", w.Code, w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+	if w.Code != 200 {
+		t.Errorf("Expected critical request to be admitted, got %d", w.Code)
+	}
+
+	close(release)
+	<-done
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/fast", nil))
+	if w.Code != 200 {
+		t.Errorf("Expected 200 once capacity is free, got %d", w.Code)
+	}
+}
+
+func TestLoadShedderLevel(t *testing.T) {
+	now := time.Now()
+	s := &loadShedder{target: 10 * time.Millisecond, interval: time.Second, windowStart: now}
+
+	// Slow interval: low priority requests are shed
+	s.observe(50*time.Millisecond, now.Add(500*time.Millisecond))
+	s.observe(20*time.Millisecond, now.Add(time.Second))
+	at := now.Add(time.Second)
+	if s.admit(PriorityLow, at) || !s.admit(PriorityNormal, at) {
+		t.Errorf("Expected only low priority to be shed, level %d", s.level)
+	}
+
+	// Another slow interval sheds normal priority too
+	s.observe(20*time.Millisecond, now.Add(2*time.Second))
+	at = now.Add(2 * time.Second)
+	if s.admit(PriorityNormal, at) || !s.admit(PriorityHigh, at) {
+		t.Errorf("Expected normal priority to be shed, level %d", s.level)
+	}
+
+	// A single fast request in an interval is enough to recover a level
+	s.observe(50*time.Millisecond, now.Add(2500*time.Millisecond))
+	s.observe(time.Millisecond, now.Add(3*time.Second))
+	at = now.Add(3 * time.Second)
+	if !s.admit(PriorityNormal, at) || s.admit(PriorityLow, at) {
+		t.Errorf("Expected normal priority to be readmitted, level %d", s.level)
+	}
+
+	if !s.admit(PriorityCritical, at) {
+		t.Error("Critical requests should never be shed")
+	}
+
+	// Intervals without completed requests readmit shed priorities
+	s.observe(50*time.Millisecond, now.Add(4*time.Second))
+	if s.admit(PriorityNormal, now.Add(4500*time.Millisecond)) {
+		t.Errorf("Expected normal priority to be shed, level %d", s.level)
+	}
+	if !s.admit(PriorityNormal, now.Add(5*time.Second)) || s.admit(PriorityLow, now.Add(5*time.Second)) {
+		t.Errorf("Expected one level to be readmitted after an idle interval, level %d", s.level)
+	}
+	if !s.admit(PriorityLow, now.Add(6*time.Second)) {
+		t.Errorf("Expected all priorities to be readmitted, level %d", s.level)
+	}
+}
+
+func TestLoadShedRecovers(t *testing.T) {
+	var slow int32 = 1
+	app := New()
+	app.Use(LoadShed(LoadShedConfig{TargetLatency: time.Millisecond, Interval: 20 * time.Millisecond}))
+	app.GET("/", func(c *Context) {
+		if atomic.LoadInt32(&slow) == 1 {
+			time.Sleep(5 * time.Millisecond)
+		}
+		c.String(200, "ok")
+	})
+
+	request := func() int {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w.Code
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for request() != 503 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected slow requests to be shed")
+		}
+	}
+
+	atomic.StoreInt32(&slow, 0)
+	deadline = time.Now().Add(time.Second)
+	for request() != 200 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected shedding to stop once latency dropped")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,283 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains additional log formatters for the Logger middleware,
+// for log analysis tools and for local development.
+package goxpress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clfTimeFormat is the timestamp layout of the Common Log Format.
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// CommonLogFormatter formats requests in the NCSA Common Log Format used by
+// Apache and nginx, so tools such as GoAccess and AWStats can analyze the
+// access log unchanged.
+//
+// Example:
+//
+//	app.Use(goxpress.LoggerWithConfig(goxpress.LoggerConfig{
+//		Output:    accessLog,
+//		Formatter: goxpress.CommonLogFormatter,
+//	}))
+//
+// Output format: host ident user [time] "request line" status bytes
+// Example output: 127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /index.html HTTP/1.1" 200 2326
+func CommonLogFormatter(c *Context, start time.Time, duration time.Duration) string {
+	return commonLogLine(c, start) + "\n"
+}
+
+// CombinedLogFormatter formats requests in the Apache Combined Log Format,
+// which extends the Common Log Format with the Referer and User-Agent
+// request headers.
+//
+// Example:
+//
+//	app.Use(goxpress.LoggerWithConfig(goxpress.LoggerConfig{
+//		Output:    accessLog,
+//		Formatter: goxpress.CombinedLogFormatter,
+//	}))
+//
+// Example output: 127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET / HTTP/1.1" 200 512 "https://example.com/" "Mozilla/5.0"
+func CombinedLogFormatter(c *Context, start time.Time, duration time.Duration) string {
+	return fmt.Sprintf("%s \"%s\" \"%s\"\n",
+		commonLogLine(c, start),
+		clfHeader(c.Request.Referer()),
+		clfHeader(c.Request.UserAgent()),
+	)
+}
+
+// commonLogLine builds a Common Log Format entry without a line break.
+func commonLogLine(c *Context, start time.Time) string {
+	user := "-"
+	if name, _, ok := c.Request.BasicAuth(); ok && name != "" {
+		user = clfEscape(name)
+	}
+
+	status := c.Writer.Status()
+	if status == 0 {
+		status = 200 // Nothing written, net/http sends 200
+	}
+
+	size := "-"
+	if n := c.Writer.Size(); n > 0 {
+		size = strconv.Itoa(n)
+	}
+
+	return fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s",
+		c.ClientIP(),
+		user,
+		start.Format(clfTimeFormat),
+		clfEscape(c.Request.Method),
+		clfEscape(c.Request.RequestURI),
+		clfEscape(c.Request.Proto),
+		status,
+		size,
+	)
+}
+
+// clfHeader escapes a header value for the log, using "-" for empty values.
+func clfHeader(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return clfEscape(value)
+}
+
+// clfEscape escapes quotes, backslashes and non-printable bytes the way
+// Apache does, so fields cannot break the log line structure.
+func clfEscape(s string) string {
+	needsEscape := false
+	for i := 0; i < len(s); i++ {
+		if b := s[i]; b == '"' || b == '\\' || b < 0x20 || b >= 0x7f {
+			needsEscape = true
+			break
+		}
+	}
+	if !needsEscape {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch == '"' || ch == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(ch)
+		case ch < 0x20 || ch >= 0x7f:
+			fmt.Fprintf(&b, "\\x%02x", ch)
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return b.String()
+}
+
+// jsonLogEntry is the object written by JSONLogFormatter.
+type jsonLogEntry struct {
+	Time      string   `json:"time"`
+	Level     string   `json:"level"`
+	Msg       string   `json:"msg"`
+	Method    string   `json:"method"`
+	Path      string   `json:"path"`
+	Route     string   `json:"route"`
+	Status    int      `json:"status"`
+	Bytes     int      `json:"bytes"`
+	Latency   int64    `json:"latency"`
+	ClientIP  string   `json:"client_ip"`
+	UserAgent string   `json:"user_agent,omitempty"`
+	RequestID string   `json:"request_id,omitempty"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// JSONLogFormatter formats each request as a single-line JSON object, with
+// the same fields and levels as SlogLogger, so the access log can be
+// shipped to ingestion pipelines such as Elasticsearch, Loki or Datadog
+// without a custom Formatter. The latency is given in nanoseconds and the
+// level is "ERROR" for 5xx responses, "WARN" for 4xx responses and "INFO"
+// otherwise.
+//
+// Set Output as well, since the standard logger prefixes entries with a
+// timestamp that would break the JSON lines.
+//
+// Example:
+//
+//	app.Use(goxpress.LoggerWithConfig(goxpress.LoggerConfig{
+//		Output:    os.Stdout,
+//		Formatter: goxpress.JSONLogFormatter,
+//	}))
+//
+// Example output: {"time":"2024-05-01T12:00:00Z","level":"INFO","msg":"request","method":"GET","path":"/users/42","route":"/users/:id","status":200,"bytes":27,"latency":1200000,"client_ip":"10.0.0.1"}
+func JSONLogFormatter(c *Context, start time.Time, duration time.Duration) string {
+	status := c.Writer.Status()
+	if status == 0 {
+		status = 200 // Nothing written, net/http sends 200
+	}
+
+	level := "INFO"
+	switch {
+	case status >= 500:
+		level = "ERROR"
+	case status >= 400:
+		level = "WARN"
+	}
+
+	entry := jsonLogEntry{
+		Time:      start.Format(time.RFC3339Nano),
+		Level:     level,
+		Msg:       "request",
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		Route:     c.FullPath(),
+		Status:    status,
+		Bytes:     c.Writer.Size(),
+		Latency:   int64(duration),
+		ClientIP:  c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		RequestID: c.requestID(),
+	}
+	if len(c.Errors) > 0 {
+		entry.Errors = c.Errors.Errors()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Sprintf("{\"level\":\"ERROR\",\"msg\":%q}\n", err.Error())
+	}
+	return string(data) + "\n"
+}
+
+// ANSI escape sequences used by ColorLogFormatter.
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorBlue   = "\033[34m"
+	colorCyan   = "\033[36m"
+)
+
+// ColorLogFormatter formats requests in aligned columns with the status
+// code colored by class, for readable output during local development.
+// Colors are only emitted when the Output of the Logger, or the output of
+// the standard logger if Output is not set, is a terminal and the NO_COLOR
+// environment variable is not set; otherwise the same columns are written
+// without colors.
+//
+// Example:
+//
+//	app.Use(goxpress.LoggerWithConfig(goxpress.LoggerConfig{
+//		Formatter: goxpress.ColorLogFormatter,
+//	}))
+//
+// Output format: status | duration | client IP | size | method path
+// Example output: 200 |     1.2ms |       127.0.0.1 |      27B | GET     /api/users
+func ColorLogFormatter(c *Context, start time.Time, duration time.Duration) string {
+	status := c.Writer.Status()
+	if status == 0 {
+		status = 200 // Nothing written, net/http sends 200
+	}
+
+	statusColor, methodColor, reset := "", "", ""
+	if c.logColor {
+		statusColor, methodColor, reset = statusCodeColor(status), colorBlue, colorReset
+	}
+
+	line := fmt.Sprintf("%s%3d%s | %10v | %15s | %7dB | %s%-7s%s %s",
+		statusColor, status, reset,
+		duration.Round(time.Microsecond),
+		c.ClientIP(),
+		c.Writer.Size(),
+		methodColor, c.Request.Method, reset,
+		c.Request.URL.Path,
+	)
+	if len(c.Errors) > 0 {
+		line += " | " + c.Errors.String()
+	}
+	return line + "\n"
+}
+
+// statusCodeColor returns the color for a status code's class.
+func statusCodeColor(status int) string {
+	switch {
+	case status >= 500:
+		return colorRed
+	case status >= 400:
+		return colorYellow
+	case status >= 300:
+		return colorCyan
+	default:
+		return colorGreen
+	}
+}
+
+// colorOutput reports whether log entries written to w may be colored:
+// whether w, the standard logger's output for the default Output, or the
+// output behind an AsyncWriter is a terminal, and NO_COLOR is not set.
+func colorOutput(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	switch w := w.(type) {
+	case *os.File:
+		return isTerminal(w)
+	case stdLogOutput:
+		return colorOutput(log.Writer())
+	case *AsyncWriter:
+		return colorOutput(w.out)
+	}
+	return false
+}
+
+// isTerminal reports whether f is a character device such as a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,165 @@
+package goxpress
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommonLogFormatters(t *testing.T) {
+	var output strings.Builder
+
+	start := time.Date(2000, 10, 10, 13, 55, 36, 0, time.FixedZone("", -7*3600))
+	formatter := func(f LogFormatter) LogFormatter {
+		return func(c *Context, _ time.Time, d time.Duration) string {
+			return f(c, start, d)
+		}
+	}
+
+	app := New()
+	app.Use(LoggerWithConfig(LoggerConfig{Output: &output, Formatter: formatter(CommonLogFormatter)}))
+	app.Use(LoggerWithConfig(LoggerConfig{Output: &output, Formatter: formatter(CombinedLogFormatter)}))
+	app.GET("/index.html", func(c *Context) {
+		c.String(200, "hello")
+	})
+	app.GET("/empty", func(c *Context) {
+		c.Status(204)
+	})
+
+	req := httptest.NewRequest("GET", "/index.html?lang=en", nil)
+	req.RemoteAddr = "127.0.0.1:5000"
+	req.SetBasicAuth("frank", "secret")
+	req.Header.Set("Referer", "https://example.com/")
+	req.Header.Set("User-Agent", `Mozilla/5.0 "test"`)
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(output.String(), "\n")
+	common := `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /index.html?lang=en HTTP/1.1" 200 5`
+	if lines[1] != common {
+		t.Errorf("Unexpected common log line:\n%s\nexpected:\n%s", lines[1], common)
+	}
+	combined := common + ` "https://example.com/" "Mozilla/5.0 \"test\""`
+	if lines[0] != combined {
+		t.Errorf("Unexpected combined log line:\n%s\nexpected:\n%s", lines[0], combined)
+	}
+
+	output.Reset()
+	req = httptest.NewRequest("GET", "/empty", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	if line := strings.Split(output.String(), "\n")[0]; line != `10.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /empty HTTP/1.1" 204 - "-" "-"` {
+		t.Errorf("Unexpected combined log line for empty response: %s", line)
+	}
+}
+
+func TestColorLogFormatter(t *testing.T) {
+	var line string
+	var colorEnabled bool
+	app := New()
+	app.Use(LoggerWithConfig(LoggerConfig{
+		Output: ioutil.Discard,
+		Formatter: func(c *Context, start time.Time, d time.Duration) string {
+			if c.logColor {
+				t.Error("Output is not a terminal, colors should be disabled")
+			}
+			c.logColor = colorEnabled
+			line = ColorLogFormatter(c, start, 1500*time.Microsecond)
+			return line
+		},
+	}))
+	app.GET("/missing", func(c *Context) {
+		c.String(404, "not found")
+	})
+
+	format := func() string {
+		req := httptest.NewRequest("GET", "/missing", nil)
+		req.RemoteAddr = "127.0.0.1:5000"
+		app.ServeHTTP(httptest.NewRecorder(), req)
+		return line
+	}
+
+	colorEnabled = false
+	plain := "404 |      1.5ms |       127.0.0.1 |       9B | GET     /missing\n"
+	if line := format(); line != plain {
+		t.Errorf("Unexpected plain line:\n%q\nexpected:\n%q", line, plain)
+	}
+
+	colorEnabled = true
+	colored := "\033[33m404\033[0m |      1.5ms |       127.0.0.1 |       9B | \033[34mGET    \033[0m /missing\n"
+	if line := format(); line != colored {
+		t.Errorf("Unexpected colored line:\n%q\nexpected:\n%q", line, colored)
+	}
+}
+
+func TestColorOutput(t *testing.T) {
+	if os.Getenv("NO_COLOR") != "" {
+		t.Skip("NO_COLOR is set")
+	}
+	device, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil || !isTerminal(device) {
+		t.Skip("No character device available")
+	}
+	defer device.Close()
+
+	async := NewAsyncWriter(device, AsyncLogConfig{})
+	defer async.Close()
+	if !colorOutput(device) || !colorOutput(async) {
+		t.Error("Expected colors for a character device")
+	}
+	if colorOutput(&strings.Builder{}) || colorOutput(ioutil.Discard) {
+		t.Error("Expected no colors for other writers")
+	}
+}
+
+func TestJSONLogFormatter(t *testing.T) {
+	var output strings.Builder
+
+	app := New()
+	app.Use(LoggerWithConfig(LoggerConfig{Output: &output, Formatter: JSONLogFormatter}))
+	app.GET("/users/:id", func(c *Context) {
+		c.Error(errors.New("cache miss"))
+		c.String(404, "not found")
+	})
+
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	req.Header.Set("User-Agent", "curl/8.0")
+	req.Header.Set("X-Request-ID", "req-1")
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	line := output.String()
+	if strings.Count(line, "\n") != 1 || !strings.HasSuffix(line, "\n") {
+		t.Fatalf("Expected a single JSON line, got %q", line)
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatalf("Log entry is not valid JSON: %v", err)
+	}
+	expected := map[string]interface{}{
+		"level":      "WARN",
+		"msg":        "request",
+		"method":     "GET",
+		"path":       "/users/42",
+		"route":      "/users/:id",
+		"status":     float64(404),
+		"bytes":      float64(9),
+		"client_ip":  "10.0.0.1",
+		"user_agent": "curl/8.0",
+		"request_id": "req-1",
+	}
+	for key, value := range expected {
+		if entry[key] != value {
+			t.Errorf("Expected %s %v, got %v", key, value, entry[key])
+		}
+	}
+	if errs, ok := entry["errors"].([]interface{}); !ok || len(errs) != 1 || errs[0] != "cache miss" {
+		t.Errorf("Unexpected errors %v", entry["errors"])
+	}
+}
@@ -0,0 +1,330 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains log output writers for the Logger middleware.
+package goxpress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogOverflowPolicy decides what an AsyncWriter does with entries written
+// while its buffer is full.
+type LogOverflowPolicy int
+
+const (
+	// LogOverflowDrop discards entries that do not fit in the buffer,
+	// keeping the request path fast at the cost of losing log lines.
+	LogOverflowDrop LogOverflowPolicy = iota
+
+	// LogOverflowBlock makes writers wait until the buffer has room,
+	// keeping every entry at the cost of latency when the sink is slow.
+	LogOverflowBlock
+)
+
+// AsyncLogConfig defines configuration options for asynchronous log output.
+type AsyncLogConfig struct {
+	// BufferSize is the number of entries buffered in memory.
+	// If zero, defaults to 1024.
+	BufferSize int
+
+	// Overflow decides what happens when the buffer is full.
+	// Defaults to LogOverflowDrop.
+	Overflow LogOverflowPolicy
+
+	// OnDrop, if set, is called with every entry discarded because the
+	// buffer was full, e.g. to count drops in a metric.
+	OnDrop func(entry []byte)
+}
+
+// AsyncWriter is an io.Writer that hands entries to a background goroutine
+// through a buffered channel, so slow sinks such as files on network
+// storage or remote log collectors don't add latency to requests.
+//
+// Close flushes the buffered entries; call it during shutdown so no log
+// lines are lost.
+type AsyncWriter struct {
+	out     io.Writer
+	config  AsyncLogConfig
+	entries chan []byte
+	done    chan struct{}
+
+	mu     sync.RWMutex // Guards closed against concurrent sends
+	closed bool
+}
+
+// NewAsyncWriter creates an AsyncWriter writing to out and starts its
+// background goroutine.
+//
+// Example:
+//
+//	logs := goxpress.NewAsyncWriter(logFile, goxpress.AsyncLogConfig{BufferSize: 4096})
+//	defer logs.Close()
+//	app.Use(goxpress.LoggerWithConfig(goxpress.LoggerConfig{Output: logs}))
+func NewAsyncWriter(out io.Writer, config AsyncLogConfig) *AsyncWriter {
+	// Set defaults
+	if config.BufferSize <= 0 {
+		config.BufferSize = 1024
+	}
+
+	w := &AsyncWriter{
+		out:     out,
+		config:  config,
+		entries: make(chan []byte, config.BufferSize),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write queues a copy of p for the background writer. It always reports
+// success, as write errors of the sink surface asynchronously. Writes
+// after Close are discarded.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	entry := make([]byte, len(p))
+	copy(entry, p)
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return len(p), nil
+	}
+
+	if w.config.Overflow == LogOverflowBlock {
+		w.entries <- entry
+		return len(p), nil
+	}
+
+	select {
+	case w.entries <- entry:
+	default:
+		if w.config.OnDrop != nil {
+			w.config.OnDrop(entry)
+		}
+	}
+	return len(p), nil
+}
+
+// Close stops accepting entries and waits until the buffered ones have
+// been written.
+func (w *AsyncWriter) Close() error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.entries)
+	}
+	w.mu.Unlock()
+
+	<-w.done
+	return nil
+}
+
+// run writes queued entries to the sink until the writer is closed.
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+	for entry := range w.entries {
+		w.out.Write(entry)
+	}
+}
+
+// backupTimeFormat is the timestamp layout in rotated log file names.
+const backupTimeFormat = "2006-01-02T15-04-05.000000000"
+
+// RotatingWriter is an io.Writer appending to a log file that is rotated
+// once it reaches a maximum size. Rotated files are renamed with a
+// timestamp, e.g. "access-2024-05-01T12-00-00.000000000.log" for
+// "access.log", and pruned by count and age.
+//
+// Create one with RotatingFileWriter. It is safe for concurrent use.
+type RotatingWriter struct {
+	path       string
+	maxSize    int64         // In bytes
+	maxBackups int           // Zero keeps all backups
+	maxAge     time.Duration // Zero keeps backups regardless of age
+	now        func() time.Time
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// RotatingFileWriter creates a RotatingWriter appending to the file at
+// path, so long-running servers don't fill disks with a single unbounded
+// log file. The file is rotated when a write would grow it beyond
+// maxSizeMB megabytes (100 if zero). After each rotation, rotated files
+// beyond the newest maxBackups and those older than maxAgeDays days are
+// removed; zero disables either limit.
+//
+// The file and its directory are created on the first write, which
+// returns any error opening them.
+//
+// Example:
+//
+//	accessLog := goxpress.RotatingFileWriter("/var/log/app/access.log", 100, 7, 30)
+//	defer accessLog.Close()
+//	app.Use(goxpress.LoggerWithConfig(goxpress.LoggerConfig{Output: accessLog}))
+func RotatingFileWriter(path string, maxSizeMB, maxBackups, maxAgeDays int) *RotatingWriter {
+	// Set defaults
+	if maxSizeMB <= 0 {
+		maxSizeMB = 100
+	}
+
+	return &RotatingWriter{
+		path:       path,
+		maxSize:    int64(maxSizeMB) << 20,
+		maxBackups: maxBackups,
+		maxAge:     time.Duration(maxAgeDays) * 24 * time.Hour,
+		now:        time.Now,
+	}
+}
+
+// Write appends p to the log file, rotating it first if p would make it
+// exceed the maximum size. Entries larger than the maximum size are
+// written to a fresh file on their own.
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+		w.prune() // Failing to prune must not lose the entry
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current log file, renames it with a timestamp and
+// starts a new one, e.g. in response to SIGHUP.
+func (w *RotatingWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+	if err := w.rotate(); err != nil {
+		return err
+	}
+	return w.prune()
+}
+
+// Close closes the current log file. A later Write opens it again.
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// open opens the log file for appending, creating it if needed.
+func (w *RotatingWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// rotate renames the open log file to a backup and opens a new one.
+func (w *RotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+
+	prefix, ext := w.backupNameParts()
+	backup := prefix + w.now().UTC().Format(backupTimeFormat) + ext
+	if err := os.Rename(w.path, filepath.Join(filepath.Dir(w.path), backup)); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+// prune removes the backups exceeding maxBackups or older than maxAge.
+func (w *RotatingWriter) prune() error {
+	if w.maxBackups <= 0 && w.maxAge <= 0 {
+		return nil
+	}
+
+	dir := filepath.Dir(w.path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	type backup struct {
+		name string
+		time time.Time
+	}
+	prefix, ext := w.backupNameParts()
+	var backups []backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		stamp := name[len(prefix) : len(name)-len(ext)]
+		t, err := time.Parse(backupTimeFormat, stamp)
+		if err != nil {
+			continue // Not a backup of this file
+		}
+		backups = append(backups, backup{name: name, time: t})
+	}
+
+	// Newest first
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].time.After(backups[j].time)
+	})
+
+	var firstErr error
+	cutoff := w.now().Add(-w.maxAge)
+	for i, b := range backups {
+		expired := w.maxAge > 0 && b.time.Before(cutoff)
+		if (w.maxBackups > 0 && i >= w.maxBackups) || expired {
+			if err := os.Remove(filepath.Join(dir, b.name)); err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("removing old log file: %w", err)
+			}
+		}
+	}
+	return firstErr
+}
+
+// backupNameParts returns the file name parts surrounding the timestamp
+// in backup names, e.g. "access-" and ".log" for "access.log".
+func (w *RotatingWriter) backupNameParts() (prefix, ext string) {
+	name := filepath.Base(w.path)
+	ext = filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "-", ext
+}
@@ -0,0 +1,158 @@
+package goxpress
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedWriter blocks writes until its gate is opened.
+type gatedWriter struct {
+	gate chan struct{}
+	mu   sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (g *gatedWriter) Write(p []byte) (int, error) {
+	<-g.gate
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.Write(p)
+}
+
+func (g *gatedWriter) String() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.String()
+}
+
+func TestAsyncWriter(t *testing.T) {
+	t.Run("Drop", func(t *testing.T) {
+		out := &gatedWriter{gate: make(chan struct{})}
+		var dropped []string
+		w := NewAsyncWriter(out, AsyncLogConfig{
+			BufferSize: 1,
+			OnDrop:     func(entry []byte) { dropped = append(dropped, string(entry)) },
+		})
+
+		// The first entry is picked up by the background goroutine, which
+		// blocks on the sink; the buffer holds one more.
+		w.Write([]byte("a"))
+		for len(w.entries) != 0 {
+			runtime.Gosched()
+		}
+		w.Write([]byte("b"))
+		w.Write([]byte("c"))
+
+		if len(dropped) != 1 || dropped[0] != "c" {
+			t.Errorf("Expected entry 'c' to be dropped, got %v", dropped)
+		}
+
+		close(out.gate)
+		w.Close()
+		if out.String() != "ab" {
+			t.Errorf("Expected 'ab' written, got '%s'", out.String())
+		}
+
+		if n, err := w.Write([]byte("late")); n != 4 || err != nil {
+			t.Errorf("Writes after Close should be discarded silently, got %d, %v", n, err)
+		}
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		out := &gatedWriter{gate: make(chan struct{})}
+		w := NewAsyncWriter(out, AsyncLogConfig{BufferSize: 1, Overflow: LogOverflowBlock})
+
+		written := make(chan struct{})
+		go func() {
+			for _, entry := range []string{"a", "b", "c"} {
+				w.Write([]byte(entry))
+			}
+			close(written)
+		}()
+
+		close(out.gate)
+		<-written
+		w.Close()
+		if out.String() != "abc" {
+			t.Errorf("Expected every entry written, got '%s'", out.String())
+		}
+	})
+}
+
+func TestLoggerWithConfig_Async(t *testing.T) {
+	var output strings.Builder
+	done := make(chan struct{})
+	sink := writerFunc(func(p []byte) (int, error) {
+		output.Write(p)
+		close(done)
+		return len(p), nil
+	})
+
+	app := New()
+	app.Use(LoggerWithConfig(LoggerConfig{
+		Output:    sink,
+		Formatter: func(c *Context, start time.Time, duration time.Duration) string { return "entry\n" },
+		Async:     &AsyncLogConfig{},
+	}))
+	app.GET("/", func(c *Context) {})
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	<-done
+	if output.String() != "entry\n" {
+		t.Errorf("Expected entry written asynchronously, got '%s'", output.String())
+	}
+}
+
+// writerFunc adapts a function to io.Writer.
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+func TestRotatingFileWriter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logs", "access.log")
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	w := RotatingFileWriter(path, 1, 2, 1)
+	w.maxSize = 10
+	w.now = func() time.Time { return now }
+	defer w.Close()
+
+	// Each write after the first exceeds the maximum size and rotates
+	for _, entry := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := w.Write([]byte(entry)); err != nil {
+			t.Fatalf("Write should not return error: %v", err)
+		}
+		now = now.Add(time.Hour)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil || string(data) != "fourth\n" {
+		t.Errorf("Expected current file to hold the last entry, got %q, %v", data, err)
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(dir, "logs", "access-*.log"))
+	if len(backups) != 2 {
+		t.Fatalf("Expected 2 backups to be kept, got %v", backups)
+	}
+	if data, _ := ioutil.ReadFile(backups[1]); string(data) != "third\n" {
+		t.Errorf("Expected newest backup to hold the previous entry, got %q", data)
+	}
+
+	// Backups older than a day are removed on the next rotation
+	now = now.Add(48 * time.Hour)
+	if err := w.Rotate(); err != nil {
+		t.Fatalf("Rotate should not return error: %v", err)
+	}
+	backups, _ = filepath.Glob(filepath.Join(dir, "logs", "access-*.log"))
+	if len(backups) != 1 {
+		t.Errorf("Expected expired backups to be removed, got %v", backups)
+	}
+}
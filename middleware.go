@@ -6,40 +6,147 @@ package goxpress
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
-	"os"
+	"net/http"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // LoggerConfig defines configuration options for the logger middleware
 type LoggerConfig struct {
+	// Skipper, if set, skips logging for requests it returns true for.
+	Skipper Skipper
+
 	// SkipPaths is a list of URL paths to skip logging for.
 	// Supports exact matches and simple wildcard patterns with *.
 	// Examples: "/health", "/metrics", "/api/*/health"
+	//
+	// Deprecated: Use Skipper with PathSkipper, which can be combined
+	// with other conditions.
 	SkipPaths []string
 
 	// Output specifies where to write the log output.
-	// If nil, defaults to os.Stdout.
+	// If nil, entries are written to the standard logger of the log package.
 	Output io.Writer
 
 	// Formatter specifies a function to format log entries.
 	// If nil, defaults to DefaultLogFormatter.
 	Formatter LogFormatter
+
+	// Async, if set, writes entries to Output from a background goroutine
+	// through a buffer, so slow outputs don't delay responses. To flush
+	// pending entries on shutdown, pass an AsyncWriter as Output instead.
+	Async *AsyncLogConfig
+
+	// Sampling, if set, logs only a fraction of successful requests.
+	Sampling *LogSampling
+}
+
+// LogSampling defines which requests the logger middleware records when
+// sampling is enabled. Failed and slow requests are always logged, so
+// sampling reduces volume without hiding problems.
+type LogSampling struct {
+	// Rate logs one of every Rate successful (2xx) requests.
+	// Values below 2 log every request.
+	Rate int
+
+	// SlowThreshold makes requests taking at least this long always
+	// logged, regardless of status. Zero disables the threshold.
+	SlowThreshold time.Duration
 }
 
 // LogFormatter is a function type for custom log formatting
 type LogFormatter func(c *Context, start time.Time, duration time.Duration) string
 
-// DefaultLogFormatter returns the default log format
+// DefaultLogFormatter returns the default log format, which records the
+// status code and the number of body bytes written.
+// Errors recorded on the Context are appended after a "|" separator.
 func DefaultLogFormatter(c *Context, start time.Time, duration time.Duration) string {
-	return fmt.Sprintf("[%s] %s %s %v\n",
-		c.Request.Method,
-		c.Request.URL.Path,
-		c.Request.RemoteAddr,
-		duration,
-	)
+	return string(appendLogEntry(nil, c, duration))
+}
+
+// maxPooledLogBufferSize is the capacity above which log entry buffers are
+// dropped instead of returned to the pool.
+const maxPooledLogBufferSize = 4 << 10
+
+// logBufferPool holds the buffers the logger middleware formats entries
+// into when no custom Formatter is configured.
+var logBufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, 256)
+		return &buf
+	},
+}
+
+// appendLogEntry appends the entry of DefaultLogFormatter to dst.
+func appendLogEntry(dst []byte, c *Context, duration time.Duration) []byte {
+	status := c.Writer.Status()
+	if status == 0 {
+		status = 200 // Nothing written, net/http sends 200
+	}
+
+	dst = append(dst, '[')
+	dst = append(dst, c.Request.Method...)
+	dst = append(dst, "] "...)
+	dst = append(dst, c.Request.URL.Path...)
+	dst = append(dst, ' ')
+	dst = append(dst, c.Request.RemoteAddr...)
+	dst = append(dst, ' ')
+	dst = strconv.AppendInt(dst, int64(status), 10)
+	dst = append(dst, ' ')
+	dst = strconv.AppendInt(dst, int64(c.Writer.Size()), 10)
+	dst = append(dst, "B "...)
+	dst = append(dst, duration.String()...)
+	if len(c.Errors) > 0 {
+		dst = append(dst, " | "...)
+		dst = append(dst, c.Errors.String()...)
+	}
+	return append(dst, '\n')
+}
+
+// stdLogOutput is the default logger output. It writes entries through
+// the standard logger, picking up its output and flags at write time.
+type stdLogOutput struct{}
+
+// Write logs p as a single entry of the standard logger.
+func (stdLogOutput) Write(p []byte) (int, error) {
+	log.Output(2, string(p))
+	return len(p), nil
+}
+
+// Skipper reports whether a middleware should be skipped for a request,
+// letting the request through untouched. Middleware configurations take
+// a Skipper to exclude requests by path, method, header, authenticated
+// user or any other property of the Context.
+//
+// Example:
+//
+//	app.Use(goxpress.LoggerWithConfig(goxpress.LoggerConfig{
+//		Skipper: func(c *goxpress.Context) bool {
+//			return c.Request.Method == "OPTIONS" || c.Request.Header.Get("X-Synthetic-Check") != ""
+//		},
+//	}))
+type Skipper func(c *Context) bool
+
+// PathSkipper returns a Skipper matching request paths against patterns,
+// which may contain * wildcards as in "/api/*/health".
+//
+// Example:
+//
+//	app.Use(goxpress.LoggerWithConfig(goxpress.LoggerConfig{
+//		Skipper: goxpress.PathSkipper("/healthz", "/metrics"),
+//	}))
+func PathSkipper(patterns ...string) Skipper {
+	return func(c *Context) bool {
+		return matchPath(c.Request.URL.Path, patterns)
+	}
 }
 
 // matchPath checks if a path matches any of the skip patterns
@@ -112,19 +219,19 @@ func simpleWildcardMatch(path, pattern string) bool {
 //	app.Use(Logger()) // Enable request logging
 //	app.GET("/", handler)
 //
-// Output format: [METHOD] path clientAddr duration
-// Example output: [GET] /api/users 127.0.0.1:54321 1.2ms
+// Output format: [METHOD] path clientAddr status size duration
+// Example output: [GET] /api/users 127.0.0.1:54321 200 27B 1.2ms
 func Logger() HandlerFunc {
 	return LoggerWithConfig(LoggerConfig{})
 }
 
 // LoggerWithConfig returns a middleware that logs HTTP requests with custom configuration.
-// It allows you to configure skip paths, output destination, and log format.
+// It allows you to configure skipped requests, output destination, and log format.
 //
 // Example:
 //
 //	config := goxpress.LoggerConfig{
-//		SkipPaths: []string{"/health", "/metrics", "/api/*/internal"},
+//		Skipper:   goxpress.PathSkipper("/health", "/metrics", "/api/*/internal"),
 //		Output:    logFile, // io.Writer
 //		Formatter: goxpress.DefaultLogFormatter,
 //	}
@@ -132,16 +239,29 @@ func Logger() HandlerFunc {
 func LoggerWithConfig(config LoggerConfig) HandlerFunc {
 	// Set defaults
 	if config.Output == nil {
-		config.Output = os.Stdout
+		config.Output = stdLogOutput{}
 	}
 	
+	// Without a custom Formatter, entries are appended to pooled buffers
+	// instead of being built as strings.
+	pooled := config.Formatter == nil
 	if config.Formatter == nil {
 		config.Formatter = DefaultLogFormatter
 	}
 
+	if config.Async != nil {
+		config.Output = NewAsyncWriter(config.Output, *config.Async)
+	}
+	color := colorOutput(config.Output)
+
+	var sampler func(c *Context, duration time.Duration) bool
+	if config.Sampling != nil {
+		sampler = config.Sampling.sampler()
+	}
+
 	return func(c *Context) {
-		// Check if this path should be skipped
-		if matchPath(c.Request.URL.Path, config.SkipPaths) {
+		// Check if this request should be skipped
+		if (config.Skipper != nil && config.Skipper(c)) || matchPath(c.Request.URL.Path, config.SkipPaths) {
 			c.Next()
 			return
 		}
@@ -154,17 +274,148 @@ func LoggerWithConfig(config LoggerConfig) HandlerFunc {
 
 		// Log request details after processing
 		duration := time.Since(start)
-		logEntry := config.Formatter(c, start, duration)
-		log.Println(logEntry)
+		if config.Sampling != nil && !sampler(c, duration) {
+			return
+		}
+		if !pooled {
+			c.logColor = color
+			config.Output.Write([]byte(config.Formatter(c, start, duration)))
+			return
+		}
+		buf := logBufferPool.Get().(*[]byte)
+		*buf = appendLogEntry((*buf)[:0], c, duration)
+		config.Output.Write(*buf)
+		if cap(*buf) <= maxPooledLogBufferSize {
+			logBufferPool.Put(buf)
+		}
+	}
+}
 
-		// Write to configured output
-		config.Output.Write([]byte(logEntry))
+// sampler returns a function reporting whether a finished request should
+// be logged. It keeps a counter shared by all requests of one middleware.
+func (s LogSampling) sampler() func(c *Context, duration time.Duration) bool {
+	var count uint64
+	return func(c *Context, duration time.Duration) bool {
+		status := c.Writer.Status()
+		if status == 0 {
+			status = 200 // Nothing written, net/http sends 200
+		}
+		if status < 200 || status >= 300 {
+			return true
+		}
+		if s.SlowThreshold > 0 && duration >= s.SlowThreshold {
+			return true
+		}
+		if s.Rate < 2 {
+			return true
+		}
+		return (atomic.AddUint64(&count, 1)-1)%uint64(s.Rate) == 0
 	}
 }
 
+// RecoverConfig defines configuration options for the recover middleware.
+type RecoverConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	// Panics of skipped requests are left to an outer Recover or to
+	// net/http, which logs them and closes the connection.
+	Skipper Skipper
+
+	// StackSize is the maximum size in bytes of the stack trace captured
+	// for a panic. If zero, defaults to 4KB.
+	StackSize int
+
+	// DisableStackAll limits the stack trace to the panicking goroutine
+	// instead of including all goroutines.
+	DisableStackAll bool
+
+	// DisableStackTrace logs only the panic value, without a stack trace.
+	DisableStackTrace bool
+
+	// DumpRequest includes a sanitized dump of the request in the panic
+	// report: the request line, the headers except Authorization,
+	// Proxy-Authorization and Cookie, and the beginning of the body.
+	DumpRequest bool
+
+	// DumpBodyLimit is the maximum number of body bytes included in the
+	// request dump. If zero, defaults to 1KB.
+	DumpBodyLimit int
+
+	// Output specifies where to write panic reports.
+	// If nil, reports are written to the standard logger of the log package.
+	Output io.Writer
+
+	// OnPanic, if set, is called with the recovered error and the stack
+	// trace before the error handlers run, e.g. to forward panics to an
+	// alerting service. Panics are also sent to the engine's ErrorReporter.
+	OnPanic func(c *Context, err error, stack []byte)
+}
+
+// redactedHeaders lists request headers left out of request dumps.
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+}
+
+// capturedBody records the first bytes read from a request body.
+type capturedBody struct {
+	io.ReadCloser
+	data  []byte
+	limit int
+}
+
+// Read reads from the body, keeping up to limit bytes.
+func (b *capturedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if room := b.limit - len(b.data); room > 0 {
+		if n < room {
+			room = n
+		}
+		b.data = append(b.data, p[:room]...)
+	}
+	return n, err
+}
+
+// dumpRequest formats a sanitized dump of req for panic reports.
+// The body is taken from body, reading what the handler left unread.
+func dumpRequest(req *http.Request, body *capturedBody) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Request:\n%s %s %s\n", req.Method, req.URL.RequestURI(), req.Proto)
+	fmt.Fprintf(&b, "Host: %s\n", req.Host)
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		if !redactedHeaders[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			fmt.Fprintf(&b, "%s: %s\n", name, value)
+		}
+	}
+
+	if body != nil {
+		if room := body.limit - len(body.data); room > 0 {
+			io.CopyN(ioutil.Discard, body, int64(room))
+		}
+		if len(body.data) > 0 {
+			b.WriteString("\n")
+			b.Write(body.data)
+			if body.limit == len(body.data) {
+				b.WriteString("... (truncated)")
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
 // Recover returns a middleware that recovers from panics that occur
-// during request processing. When a panic is caught, it is converted
-// to an error and passed to the error handling middleware chain.
+// during request processing. When a panic is caught, it is logged with a
+// stack trace, converted to an error and passed to the error handling
+// middleware chain.
 //
 // This middleware prevents panics from crashing the entire server
 // and allows for graceful error handling and logging.
@@ -187,12 +438,47 @@ func LoggerWithConfig(config LoggerConfig) HandlerFunc {
 //		panic("Something went wrong!") // Will be recovered
 //	})
 func Recover() HandlerFunc {
+	return RecoverWithConfig(RecoverConfig{})
+}
+
+// RecoverWithConfig returns a recover middleware with custom configuration.
+//
+// Example:
+//
+//	app.Use(goxpress.RecoverWithConfig(goxpress.RecoverConfig{
+//		DisableStackAll: true,
+//		OnPanic: func(c *goxpress.Context, err error, stack []byte) {
+//			alerts.Send(c.Request.URL.Path, err, stack)
+//		},
+//	}))
+func RecoverWithConfig(config RecoverConfig) HandlerFunc {
+	// Set defaults
+	if config.StackSize <= 0 {
+		config.StackSize = 4 << 10
+	}
+	if config.Output == nil {
+		config.Output = stdLogOutput{}
+	}
+	if config.DumpBodyLimit <= 0 {
+		config.DumpBodyLimit = 1 << 10
+	}
+
 	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		// Keep a copy of the start of the body, which the handler may
+		// have consumed by the time it panics
+		var body *capturedBody
+		if config.DumpRequest && c.Request.Body != nil {
+			body = &capturedBody{ReadCloser: c.Request.Body, limit: config.DumpBodyLimit}
+			c.Request.Body = body
+		}
+
 		defer func() {
 			if r := recover(); r != nil {
-				// Log the panic for debugging
-				log.Printf("Panic recovered: %v", r)
-
 				// Abort further processing
 				c.Abort()
 
@@ -206,6 +492,26 @@ func Recover() HandlerFunc {
 					err = fmt.Errorf("%v", r)
 				}
 
+				// Log the panic for debugging
+				var stack []byte
+				if !config.DisableStackTrace {
+					stack = make([]byte, config.StackSize)
+					stack = stack[:runtime.Stack(stack, !config.DisableStackAll)]
+				}
+				report := fmt.Sprintf("Panic recovered: %v\n", err)
+				if config.DumpRequest {
+					report += dumpRequest(c.Request, body)
+				}
+				if len(stack) > 0 {
+					report += string(stack) + "\n"
+				}
+				config.Output.Write([]byte(report))
+
+				if config.OnPanic != nil {
+					config.OnPanic(c, err, stack)
+				}
+				c.reportError(err, true, stack)
+
 				// Pass error to error handling middleware
 				c.Next(err)
 			}
@@ -1,8 +1,10 @@
 package goxpress
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
@@ -212,6 +214,108 @@ func TestRecoverWithNonErrorPanic(t *testing.T) {
 	}
 }
 
+func TestRecoverWithConfig(t *testing.T) {
+	var output strings.Builder
+	var reported error
+	var reportedStack []byte
+
+	app := New()
+	app.Use(RecoverWithConfig(RecoverConfig{
+		Output:          &output,
+		StackSize:       1 << 10,
+		DisableStackAll: true,
+		OnPanic: func(c *Context, err error, stack []byte) {
+			reported, reportedStack = err, stack
+		},
+	}))
+	app.UseError(func(err error, c *Context) {
+		c.String(500, "recovered: %v", err)
+	})
+	app.GET("/panic", func(c *Context) {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+
+	if w.Code != 500 || w.Body.String() != "recovered: boom" {
+		t.Errorf("Expected error handler response, got %d '%s'", w.Code, w.Body.String())
+	}
+	if reported == nil || reported.Error() != "boom" {
+		t.Errorf("Expected OnPanic to receive the error, got %v", reported)
+	}
+	if len(reportedStack) == 0 || len(reportedStack) > 1<<10 {
+		t.Errorf("Expected a stack trace of at most 1KB, got %d bytes", len(reportedStack))
+	}
+	if !strings.HasPrefix(output.String(), "Panic recovered: boom\ngoroutine ") {
+		t.Errorf("Expected panic report with stack trace, got %q", output.String())
+	}
+
+	t.Run("DisableStackTrace", func(t *testing.T) {
+		var output strings.Builder
+		app := New()
+		app.Use(RecoverWithConfig(RecoverConfig{Output: &output, DisableStackTrace: true}))
+		app.GET("/panic", func(c *Context) {
+			panic("boom")
+		})
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/panic", nil))
+
+		if output.String() != "Panic recovered: boom\n" {
+			t.Errorf("Expected one-line report, got %q", output.String())
+		}
+	})
+
+	t.Run("DumpRequest", func(t *testing.T) {
+		var output strings.Builder
+		app := New()
+		app.Use(RecoverWithConfig(RecoverConfig{
+			Output:            &output,
+			DisableStackTrace: true,
+			DumpRequest:       true,
+			DumpBodyLimit:     8,
+		}))
+		app.POST("/panic", func(c *Context) {
+			buf := make([]byte, 4)
+			c.Request.Body.Read(buf)
+			panic("boom")
+		})
+
+		req := httptest.NewRequest("POST", "/panic?id=1", strings.NewReader("name=gopher&pass=x"))
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("Cookie", "session=secret")
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		app.ServeHTTP(httptest.NewRecorder(), req)
+
+		expected := "Panic recovered: boom\n" +
+			"Request:\nPOST /panic?id=1 HTTP/1.1\n" +
+			"Host: example.com\n" +
+			"Content-Type: application/x-www-form-urlencoded\n" +
+			"\nname=gop... (truncated)\n"
+		if output.String() != expected {
+			t.Errorf("Expected report %q, got %q", expected, output.String())
+		}
+	})
+}
+
+func TestRecoverSkipper(t *testing.T) {
+	app := New()
+	app.Use(RecoverWithConfig(RecoverConfig{
+		Skipper: func(c *Context) bool { return c.Request.Header.Get("X-Debug") != "" },
+	}))
+	app.GET("/panic", func(c *Context) {
+		panic("boom")
+	})
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("Expected the panic of a skipped request to propagate, got %v", r)
+		}
+	}()
+	req := httptest.NewRequest("GET", "/panic", nil)
+	req.Header.Set("X-Debug", "1")
+	app.ServeHTTP(httptest.NewRecorder(), req)
+}
+
 func TestRecoverDoesNotAffectNormalRequests(t *testing.T) {
 	app := New()
 	app.Use(Recover())
@@ -386,6 +490,39 @@ func TestBuiltinMiddlewareIntegration(t *testing.T) {
 	}
 }
 
+func TestLoggerWithConfig_Sampling(t *testing.T) {
+	var logOutput strings.Builder
+	log.SetOutput(&strings.Builder{})
+	defer log.SetOutput(os.Stderr)
+
+	config := LoggerConfig{
+		Output: &logOutput,
+		Formatter: func(c *Context, start time.Time, duration time.Duration) string {
+			return c.Request.URL.Path + "\n"
+		},
+		Sampling: &LogSampling{Rate: 3, SlowThreshold: 20 * time.Millisecond},
+	}
+
+	app := New()
+	app.Use(LoggerWithConfig(config))
+	app.GET("/ok", func(c *Context) {
+		c.String(200, "OK")
+	})
+	app.GET("/slow", func(c *Context) {
+		time.Sleep(25 * time.Millisecond)
+		c.String(200, "OK")
+	})
+
+	for _, path := range []string{"/ok", "/ok", "/ok", "/ok", "/missing", "/slow"} {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	expected := "/ok\n/ok\n/missing\n/slow\n"
+	if logOutput.String() != expected {
+		t.Errorf("Expected sampled log %q, got %q", expected, logOutput.String())
+	}
+}
+
 func BenchmarkLogger(b *testing.B) {
 	app := New()
 	app.Use(Logger())
@@ -427,6 +564,84 @@ func BenchmarkRecover(b *testing.B) {
 	}
 }
 
+func TestLoggerWithConfig_Skipper(t *testing.T) {
+	var logOutput strings.Builder
+
+	app := New()
+	app.Use(LoggerWithConfig(LoggerConfig{
+		Output: &logOutput,
+		Skipper: func(c *Context) bool {
+			return c.Request.Method == "OPTIONS" || PathSkipper("/health")(c)
+		},
+	}))
+	app.GET("/health", func(c *Context) {
+		c.String(200, "OK")
+	})
+	app.OPTIONS("/users", func(c *Context) {
+		c.Status(204)
+	})
+	app.GET("/users", func(c *Context) {
+		c.String(200, "Users")
+	})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/health", nil),
+		httptest.NewRequest("OPTIONS", "/users", nil),
+	} {
+		app.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if logOutput.Len() != 0 {
+		t.Errorf("Expected skipped requests not to be logged, got %q", logOutput.String())
+	}
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+	if !strings.Contains(logOutput.String(), "/users") {
+		t.Errorf("Expected request to be logged, got %q", logOutput.String())
+	}
+}
+
+func TestMiddlewareSkipper(t *testing.T) {
+	skipInternal := func(c *Context) bool {
+		return c.Request.Header.Get("X-Internal") != ""
+	}
+
+	tests := []struct {
+		name       string
+		middleware HandlerFunc
+		header     string // Response header set only when the middleware runs
+	}{
+		{"BodyLimit", BodyLimitWithConfig(BodyLimitConfig{Limit: "1B", Skipper: skipInternal}), ""},
+		{"Compress", CompressWithConfig(CompressConfig{Skipper: skipInternal}), "Vary"},
+		{"ETag", ETagWithConfig(ETagConfig{Skipper: skipInternal}), "ETag"},
+		{"RateLimit", RateLimit(RateLimitConfig{Skipper: skipInternal}), "RateLimit-Limit"},
+		{"Timeout", Timeout(time.Second, TimeoutConfig{Skipper: skipInternal}), ""},
+		{"I18n", I18n(I18nConfig{Translator: newTestTranslator(t), Skipper: skipInternal}), "Content-Language"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := New()
+			app.Use(tt.middleware)
+			app.POST("/", func(c *Context) {
+				c.String(200, "ok")
+			})
+
+			req := httptest.NewRequest("POST", "/", strings.NewReader("large body"))
+			req.Header.Set("X-Internal", "1")
+			req.Header.Set("Accept-Encoding", "gzip")
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+
+			if w.Code != 200 || w.Body.String() != "ok" {
+				t.Errorf("Expected skipped middleware to pass the request on, got %d %q", w.Code, w.Body.String())
+			}
+			if tt.header != "" && w.Header().Get(tt.header) != "" {
+				t.Errorf("Expected %s not to be set when skipped", tt.header)
+			}
+		})
+	}
+}
+
 func TestLoggerWithConfig_SkipPaths(t *testing.T) {
 	var logOutput strings.Builder
 
@@ -587,6 +802,32 @@ func TestLoggerWithConfig_SimpleFormatter(t *testing.T) {
 	}
 }
 
+func TestDefaultLogFormatter_StatusAndSize(t *testing.T) {
+	var stdLog, output strings.Builder
+	log.SetOutput(&stdLog)
+	defer log.SetOutput(os.Stderr)
+
+	app := New()
+	app.Use(LoggerWithConfig(LoggerConfig{Output: &output}))
+	app.GET("/users", func(c *Context) {
+		c.String(201, "created")
+	})
+
+	req := httptest.NewRequest("GET", "/users", nil)
+	req.RemoteAddr = "127.0.0.1:12345"
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !strings.HasPrefix(output.String(), "[GET] /users 127.0.0.1:12345 201 7B ") {
+		t.Errorf("Expected status and size in log entry, got %q", output.String())
+	}
+	if strings.Count(output.String(), "\n") != 1 {
+		t.Errorf("Expected exactly one log entry, got %q", output.String())
+	}
+	if stdLog.Len() != 0 {
+		t.Errorf("Entries should not also go to the standard logger, got %q", stdLog.String())
+	}
+}
+
 func TestLoggerWithConfig_CustomOutput(t *testing.T) {
 	var buffer1, buffer2 strings.Builder
 
@@ -741,3 +982,16 @@ func BenchmarkLoggerAndRecover(b *testing.B) {
 		app.ServeHTTP(w, req)
 	}
 }
+
+func TestDefaultLogFormatter_Errors(t *testing.T) {
+	c := &Context{Request: httptest.NewRequest("DELETE", "/users/1", nil)}
+	c.Writer = &responseWriter{ResponseWriter: httptest.NewRecorder()}
+	c.Error(errors.New("not allowed"))
+	c.Writer.WriteHeader(403)
+
+	got := DefaultLogFormatter(c, time.Now(), 1500*time.Microsecond)
+	want := "[DELETE] /users/1 192.0.2.1:1234 403 0B 1.5ms | " + c.Errors.String() + "\n"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
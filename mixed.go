@@ -0,0 +1,201 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains ServeMixed, which serves gRPC and the Engine on a
+// single port.
+package goxpress
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// http2Preface is the client connection preface opening every HTTP/2
+// connection made with prior knowledge, as gRPC clients do over cleartext.
+const http2Preface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+
+// mixedSniffTimeout bounds the time a new connection may take to send
+// enough bytes to be classified.
+const mixedSniffTimeout = 10 * time.Second
+
+// GRPCServer is the subset of *grpc.Server used by ServeMixed.
+type GRPCServer interface {
+	Serve(lis net.Listener) error
+	GracefulStop()
+	Stop()
+}
+
+// ServeMixed serves grpcServer and engine on the same TCP address, for
+// deployments that can only expose one port. Connections opening with the
+// HTTP/2 client preface, as cleartext gRPC clients do, are handed to
+// grpcServer; all other connections, such as HTTP/1.1 requests, are served
+// by the Engine. Browsers never use cleartext HTTP/2, so they always reach
+// the Engine. TLS is expected to be terminated in front of the server.
+//
+// Engine.Shutdown stops both servers, gracefully stopping grpcServer
+// within the shutdown deadline. Like Listen, ServeMixed freezes the Engine,
+// blocks and returns http.ErrServerClosed after Shutdown.
+//
+// Example:
+//
+//	grpcServer := grpc.NewServer()
+//	pb.RegisterGreeterServer(grpcServer, &greeter{})
+//
+//	app := goxpress.New()
+//	app.GET("/healthz", healthz)
+//	log.Fatal(goxpress.ServeMixed(":8080", app, grpcServer))
+func ServeMixed(addr string, engine *Engine, grpcServer GRPCServer) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return serveMixed(lis, engine, grpcServer)
+}
+
+// serveMixed serves engine and grpcServer on lis.
+func serveMixed(lis net.Listener, engine *Engine, grpcServer GRPCServer) error {
+	m := newMixedListener(lis)
+	go m.run()
+
+	engine.Freeze()
+	server := &http.Server{Handler: engine}
+	engine.trackServer(server)
+	engine.OnShutdown(func(ctx context.Context) error {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			grpcServer.Stop()
+			return ctx.Err()
+		}
+	})
+
+	httpErr := make(chan error, 1)
+	grpcErr := make(chan error, 1)
+	go func() { httpErr <- server.Serve(m.http) }()
+	go func() { grpcErr <- grpcServer.Serve(m.grpc) }()
+
+	select {
+	case err := <-httpErr:
+		return err
+	case err := <-grpcErr:
+		select {
+		case <-m.done:
+			// Stopped by a shutdown, report the HTTP server's result
+		default:
+			m.close()
+			return err
+		}
+		return <-httpErr
+	}
+}
+
+// mixedListener splits the connections of a listener between an HTTP and
+// a gRPC listener.
+type mixedListener struct {
+	root       net.Listener
+	http, grpc *connListener
+	closeOnce  sync.Once
+	done       chan struct{}
+}
+
+// newMixedListener creates a mixedListener accepting from root.
+func newMixedListener(root net.Listener) *mixedListener {
+	m := &mixedListener{root: root, done: make(chan struct{})}
+	m.http = &connListener{mixed: m, conns: make(chan net.Conn)}
+	m.grpc = &connListener{mixed: m, conns: make(chan net.Conn)}
+	return m
+}
+
+// run accepts connections and dispatches them until root is closed.
+func (m *mixedListener) run() {
+	defer m.close()
+	for {
+		conn, err := m.root.Accept()
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+			return
+		}
+		go m.dispatch(conn)
+	}
+}
+
+// dispatch classifies conn by its first bytes and hands it to the
+// matching listener.
+func (m *mixedListener) dispatch(conn net.Conn) {
+	conn.SetReadDeadline(time.Now().Add(mixedSniffTimeout))
+	r := bufio.NewReader(conn)
+	target := m.grpc
+	for i := 1; i <= len(http2Preface); i++ {
+		b, err := r.Peek(i)
+		if err != nil || b[i-1] != http2Preface[i-1] {
+			target = m.http // Let the HTTP server deal with short reads
+			break
+		}
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	select {
+	case target.conns <- &sniffedConn{Conn: conn, r: r}:
+	case <-m.done:
+		conn.Close()
+	}
+}
+
+// close closes the root listener and both split listeners.
+func (m *mixedListener) close() error {
+	var err error
+	m.closeOnce.Do(func() {
+		close(m.done)
+		err = m.root.Close()
+	})
+	return err
+}
+
+// connListener is a net.Listener fed by a mixedListener. Closing it closes
+// the underlying listener, as done by the servers on shutdown.
+type connListener struct {
+	mixed *mixedListener
+	conns chan net.Conn
+}
+
+// Accept waits for the next connection classified for this listener.
+func (l *connListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.mixed.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close closes the underlying listener.
+func (l *connListener) Close() error {
+	return l.mixed.close()
+}
+
+// Addr returns the address of the underlying listener.
+func (l *connListener) Addr() net.Addr {
+	return l.mixed.root.Addr()
+}
+
+// sniffedConn replays the bytes read while classifying a connection.
+type sniffedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// Read reads from the buffered bytes first, then from the connection.
+func (c *sniffedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
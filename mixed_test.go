@@ -0,0 +1,91 @@
+package goxpress
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+)
+
+// fakeGRPCServer answers every connection with "grpc" once it has read
+// the HTTP/2 preface.
+type fakeGRPCServer struct {
+	lis     net.Listener
+	stopped chan struct{}
+}
+
+func (s *fakeGRPCServer) Serve(lis net.Listener) error {
+	s.lis = lis
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			preface := make([]byte, len(http2Preface))
+			if _, err := io.ReadFull(conn, preface); err == nil && string(preface) == http2Preface {
+				conn.Write([]byte("grpc"))
+			}
+		}()
+	}
+}
+
+func (s *fakeGRPCServer) GracefulStop() {
+	s.lis.Close()
+	close(s.stopped)
+}
+
+func (s *fakeGRPCServer) Stop() {}
+
+func TestServeMixed(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Cannot listen: %v", err)
+	}
+	addr := lis.Addr().String()
+
+	app := New()
+	app.GET("/", func(c *Context) {
+		c.String(200, "http")
+	})
+	grpcServer := &fakeGRPCServer{stopped: make(chan struct{})}
+
+	served := make(chan error, 1)
+	go func() { served <- serveMixed(lis, app, grpcServer) }()
+
+	resp, err := http.Get("http://" + addr + "/")
+	if err != nil {
+		t.Fatalf("HTTP request failed: %v", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "http" {
+		t.Errorf("Expected the Engine to serve HTTP/1.1, got %q", body)
+	}
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	conn.Write([]byte(http2Preface))
+	reply, _ := ioutil.ReadAll(conn)
+	conn.Close()
+	if string(reply) != "grpc" {
+		t.Errorf("Expected the gRPC server to serve HTTP/2, got %q", reply)
+	}
+
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown should not return error: %v", err)
+	}
+	if err := <-served; err != http.ErrServerClosed {
+		t.Errorf("Expected http.ErrServerClosed, got %v", err)
+	}
+	select {
+	case <-grpcServer.stopped:
+	default:
+		t.Error("Expected Shutdown to stop the gRPC server")
+	}
+}
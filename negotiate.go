@@ -0,0 +1,192 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains content negotiation based on the Accept header.
+package goxpress
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// MIME types understood by Negotiate.
+const (
+	MIMEJSON     = "application/json"
+	MIMEXML      = "application/xml"
+	MIMEYAML     = "application/yaml"
+	MIMEHTML     = "text/html"
+	MIMEPlain    = "text/plain"
+	MIMEMsgPack  = "application/msgpack"
+	MIMEProtobuf = "application/x-protobuf"
+)
+
+// NegotiateConfig describes the representations offered by Negotiate.
+type NegotiateConfig struct {
+	// Offered lists the MIME types the handler can produce, in order of
+	// preference. The first entry is used when the client accepts anything.
+	Offered []string
+
+	// Data is rendered for every offered type without a specific value below.
+	Data interface{}
+
+	// Optional per-format data overriding Data
+	JSONData interface{}
+	XMLData  interface{}
+	YAMLData interface{}
+
+	// HTMLName is the template rendered with Context.Render for text/html.
+	// If empty, HTMLData (or Data) is written as an HTML string.
+	HTMLName string
+	HTMLData interface{}
+}
+
+// Negotiate selects the best representation among config.Offered according
+// to the request's Accept header and renders it with the specified status
+// code. If none of the offered types is acceptable, it responds with
+// 406 Not Acceptable.
+//
+// Example:
+//
+//	c.Negotiate(200, goxpress.NegotiateConfig{
+//		Offered:  []string{goxpress.MIMEJSON, goxpress.MIMEXML, goxpress.MIMEHTML},
+//		Data:     user,
+//		HTMLName: "user.html",
+//	})
+func (c *Context) Negotiate(code int, config NegotiateConfig) error {
+	format := c.NegotiateFormat(config.Offered...)
+	switch format {
+	case MIMEJSON:
+		return c.JSON(code, pickData(config.JSONData, config.Data))
+	case MIMEXML:
+		return c.XML(code, pickData(config.XMLData, config.Data))
+	case MIMEYAML:
+		return c.YAML(code, pickData(config.YAMLData, config.Data))
+	case MIMEHTML:
+		data := pickData(config.HTMLData, config.Data)
+		if config.HTMLName != "" {
+			return c.Render(code, config.HTMLName, data)
+		}
+		return c.HTML(code, fmt.Sprint(data))
+	case MIMEPlain:
+		return c.String(code, "%v", config.Data)
+	case MIMEMsgPack:
+		return c.MsgPack(code, config.Data)
+	case MIMEProtobuf:
+		return c.Protobuf(code, config.Data)
+	case "":
+		return c.String(http.StatusNotAcceptable, "406 Not Acceptable")
+	default:
+		return fmt.Errorf("goxpress: cannot negotiate unsupported type %q", format)
+	}
+}
+
+// NegotiateFormat returns the offered MIME type that best matches the
+// request's Accept header, or an empty string if none is acceptable.
+// Quality values and media range specificity are honored; ties are
+// broken by the order of offered.
+//
+// Example:
+//
+//	// Accept: text/html;q=0.9, application/json
+//	c.NegotiateFormat(goxpress.MIMEHTML, goxpress.MIMEJSON) // Returns "application/json"
+func (c *Context) NegotiateFormat(offered ...string) string {
+	if len(offered) == 0 {
+		return ""
+	}
+
+	accept := c.Request.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return offered[0]
+	}
+
+	ranges := parseAccept(accept)
+	best, bestQ := "", 0.0
+	for _, offer := range offered {
+		if q := acceptQuality(ranges, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// mediaRange is a single entry of an Accept header.
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// parseAccept parses an Accept header into media ranges.
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		if mediaType == "" {
+			continue
+		}
+
+		r := mediaRange{q: 1}
+		if i := strings.IndexByte(mediaType, '/'); i >= 0 {
+			r.typ, r.subtype = mediaType[:i], mediaType[i+1:]
+		} else {
+			r.typ, r.subtype = mediaType, "*"
+		}
+
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					r.q = q
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// acceptQuality returns the quality the client assigns to the given
+// MIME type, using the most specific matching media range.
+func acceptQuality(ranges []mediaRange, mimeType string) float64 {
+	typ, subtype := mimeType, ""
+	if i := strings.IndexByte(mimeType, '/'); i >= 0 {
+		typ, subtype = mimeType[:i], mimeType[i+1:]
+	}
+
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*" && r.subtype == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
+// pickData returns specific if it is set and fallback otherwise.
+func pickData(specific, fallback interface{}) interface{} {
+	if specific != nil {
+		return specific
+	}
+	return fallback
+}
+
+// encodeWith encodes data with the given codec and writes it as the
+// response body with the specified Content-Type.
+func (c *Context) encodeWith(code int, contentType string, codec Codec, data interface{}) error {
+	payload, err := codec.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return c.Data(code, contentType, payload)
+}
@@ -0,0 +1,82 @@
+package goxpress
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContextNegotiateFormat(t *testing.T) {
+	offered := []string{MIMEJSON, MIMEXML, MIMEHTML}
+
+	tests := []struct {
+		accept   string
+		expected string
+	}{
+		{"", MIMEJSON},
+		{"*/*", MIMEJSON},
+		{"application/xml", MIMEXML},
+		{"text/html;q=0.9, application/xml;q=0.8", MIMEHTML},
+		{"text/*, application/json;q=0.5", MIMEHTML},
+		{"application/*;q=0.2, application/xml", MIMEXML},
+		{"image/png", ""},
+		{"application/json;q=0, */*;q=0.1", MIMEXML},
+	}
+
+	for _, test := range tests {
+		t.Run(test.accept, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept", test.accept)
+			c := NewContext(httptest.NewRecorder(), req)
+
+			if format := c.NegotiateFormat(offered...); format != test.expected {
+				t.Errorf("Expected format %q, got %q", test.expected, format)
+			}
+		})
+	}
+}
+
+func TestContextNegotiate(t *testing.T) {
+	type user struct {
+		Name string `json:"name" xml:"name"`
+	}
+
+	config := NegotiateConfig{
+		Offered:  []string{MIMEJSON, MIMEXML, MIMEHTML},
+		Data:     user{Name: "John"},
+		HTMLData: "<p>John</p>",
+	}
+
+	tests := []struct {
+		accept      string
+		code        int
+		contentType string
+		body        string
+	}{
+		{"application/json", 200, "application/json", "{\"name\":\"John\"}\n"},
+		{"application/xml", 200, "application/xml; charset=utf-8", "<user><name>John</name></user>"},
+		{"text/html", 200, "text/html; charset=utf-8", "<p>John</p>"},
+		{"image/png", 406, "text/plain; charset=utf-8", "406 Not Acceptable"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.accept, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept", test.accept)
+			w := httptest.NewRecorder()
+			c := NewContext(w, req)
+
+			if err := c.Negotiate(200, config); err != nil {
+				t.Fatalf("Negotiate should not return error: %v", err)
+			}
+			if w.Code != test.code {
+				t.Errorf("Expected status code %d, got %d", test.code, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != test.contentType {
+				t.Errorf("Expected Content-Type %q, got %q", test.contentType, ct)
+			}
+			if w.Body.String() != test.body {
+				t.Errorf("Expected body %q, got %q", test.body, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,498 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the middleware validating requests and responses
+// against an OpenAPI document.
+package goxpress
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// openAPIMethods lists the operation keys of an OpenAPI path item.
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// OpenAPIValidatorConfig defines configuration options for the
+// OpenAPIValidator middleware.
+type OpenAPIValidatorConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper Skipper
+
+	// Spec is the OpenAPI 3 document in JSON, e.g. embedded with go:embed.
+	// Required.
+	Spec []byte
+
+	// BasePath is removed from request paths before they are matched
+	// against the paths of the spec, e.g. "/api/v1" when the spec's server
+	// URL carries that prefix.
+	BasePath string
+
+	// ValidateResponses also validates the status code and body of
+	// responses. Responses that do not conform are replaced with
+	// 500 Internal Server Error. Responses are then buffered in memory, so
+	// it is mostly meant for development and testing.
+	ValidateResponses bool
+
+	// Handler is called with 400 Bad Request and the violations of an
+	// invalid request, or 500 Internal Server Error and the violations of
+	// an invalid response. The default handler writes a JSON object with
+	// "error" and "violations" fields.
+	Handler func(c *Context, status int, violations []Violation)
+}
+
+// OpenAPIValidator returns a middleware validating requests against the
+// OpenAPI 3 document spec, in JSON, with the default configuration.
+// It panics if spec cannot be parsed.
+//
+// Example:
+//
+//	//go:embed openapi.json
+//	var spec []byte
+//
+//	app.Use(goxpress.OpenAPIValidator(spec))
+func OpenAPIValidator(spec []byte) HandlerFunc {
+	return OpenAPIValidatorWithConfig(OpenAPIValidatorConfig{Spec: spec})
+}
+
+// OpenAPIValidatorWithConfig returns an OpenAPIValidator middleware with
+// custom configuration, for contract-first APIs whose spec is the source
+// of truth.
+//
+// For each request matching an operation of the spec, the path, query,
+// header and cookie parameters are converted to the types of their
+// schemas and validated, and JSON request bodies are validated against the
+// schema of their media type. Invalid requests are rejected with
+// 400 Bad Request listing every violation, before any handler runs.
+// Requests matching no operation are passed through unchanged. Parameters
+// are expected in the default styles: arrays in the query string as
+// repeated parameters, elsewhere as comma-separated lists.
+//
+// Example:
+//
+//	app.Use(goxpress.OpenAPIValidatorWithConfig(goxpress.OpenAPIValidatorConfig{
+//		Spec:              spec,
+//		BasePath:          "/api/v1",
+//		ValidateResponses: os.Getenv("ENV") != "production",
+//	}))
+func OpenAPIValidatorWithConfig(config OpenAPIValidatorConfig) HandlerFunc {
+	spec, err := parseOpenAPISpec(config.Spec)
+	if err != nil {
+		panic("goxpress: invalid OpenAPI spec: " + err.Error())
+	}
+
+	// Set defaults
+	if config.Handler == nil {
+		config.Handler = func(c *Context, status int, violations []Violation) {
+			c.JSON(status, map[string]interface{}{
+				"error":      http.StatusText(status),
+				"violations": violations,
+			})
+		}
+	}
+	config.BasePath = strings.TrimSuffix(config.BasePath, "/")
+
+	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		path := c.Request.URL.Path
+		if config.BasePath != "" {
+			if path != config.BasePath && !strings.HasPrefix(path, config.BasePath+"/") {
+				c.Next()
+				return
+			}
+			path = strings.TrimPrefix(path, config.BasePath)
+		}
+		op, pathParams := spec.match(c.Request.Method, path)
+		if op == nil {
+			c.Next()
+			return
+		}
+
+		if violations := spec.validateRequest(c, op, pathParams); len(violations) > 0 {
+			c.Abort()
+			config.Handler(c, http.StatusBadRequest, violations)
+			return
+		}
+
+		if !config.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		original := c.Response
+		buffer := &bufferedResponseWriter{ResponseWriter: original}
+		c.Response = buffer
+		defer func() { c.Response = original }()
+
+		c.Next()
+
+		if buffer.hijacked {
+			return
+		}
+		status := buffer.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		if violations := spec.validateResponse(op, status, original.Header(), buffer.body.Bytes()); len(violations) > 0 {
+			c.Response = original
+			c.statusCodeWritten = false
+			original.Header().Del("Content-Length")
+			config.Handler(c, http.StatusInternalServerError, violations)
+			return
+		}
+		buffer.flushTo(original, status)
+	}
+}
+
+// openAPISpec is a parsed OpenAPI document.
+type openAPISpec struct {
+	validator  *schemaValidator
+	operations []*openAPIOperation
+}
+
+// openAPIOperation is an operation of an OpenAPI document.
+type openAPIOperation struct {
+	method       string
+	segments     []string // Path template split on "/", e.g. "users", "{id}"
+	literals     int      // Number of non-parameter segments, to prefer specific paths
+	parameters   []map[string]interface{}
+	body         map[string]interface{} // Media types of the request body
+	bodyRequired bool
+	responses    map[string]interface{}
+}
+
+// parseOpenAPISpec parses an OpenAPI document in JSON.
+func parseOpenAPISpec(data []byte) (*openAPISpec, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	paths, ok := root["paths"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("missing paths")
+	}
+
+	spec := &openAPISpec{validator: newSchemaValidator(root)}
+	for template, item := range paths {
+		pathItem, err := spec.object(item)
+		if err != nil {
+			return nil, fmt.Errorf("path %s: %v", template, err)
+		}
+		segments := strings.Split(strings.Trim(template, "/"), "/")
+		literals := 0
+		for _, segment := range segments {
+			if !isTemplateParam(segment) {
+				literals++
+			}
+		}
+
+		for _, method := range openAPIMethods {
+			operation, ok := pathItem[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			op := &openAPIOperation{
+				method:    strings.ToUpper(method),
+				segments:  segments,
+				literals:  literals,
+				responses: map[string]interface{}{},
+			}
+
+			// Operation parameters override path item parameters
+			seen := map[string]bool{}
+			for _, list := range []interface{}{operation["parameters"], pathItem["parameters"]} {
+				params, _ := list.([]interface{})
+				for _, param := range params {
+					p, err := spec.object(param)
+					if err != nil {
+						return nil, fmt.Errorf("%s %s: %v", op.method, template, err)
+					}
+					key := fmt.Sprint(p["in"], " ", p["name"])
+					if !seen[key] {
+						seen[key] = true
+						op.parameters = append(op.parameters, p)
+					}
+				}
+			}
+
+			if requestBody, ok := operation["requestBody"]; ok {
+				body, err := spec.object(requestBody)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: %v", op.method, template, err)
+				}
+				op.body, _ = body["content"].(map[string]interface{})
+				op.bodyRequired = body["required"] == true
+			}
+			if responses, ok := operation["responses"].(map[string]interface{}); ok {
+				op.responses = responses
+			}
+			spec.operations = append(spec.operations, op)
+		}
+	}
+	return spec, nil
+}
+
+// object returns value as a JSON object, following a "$ref" pointer.
+func (s *openAPISpec) object(value interface{}) (map[string]interface{}, error) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected object, got %s", jsonType(value))
+	}
+	if ref, ok := object["$ref"].(string); ok {
+		target, err := s.validator.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+		if object, ok = target.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("reference %q is not an object", ref)
+		}
+	}
+	return object, nil
+}
+
+// match returns the operation for method and path, preferring the path
+// with the most literal segments, and the values of its path parameters.
+func (s *openAPISpec) match(method, path string) (*openAPIOperation, map[string]string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	var best *openAPIOperation
+	for _, op := range s.operations {
+		if op.method != method || len(op.segments) != len(segments) {
+			continue
+		}
+		matched := true
+		for i, segment := range op.segments {
+			if !isTemplateParam(segment) && segment != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched && (best == nil || op.literals > best.literals) {
+			best = op
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+
+	params := make(map[string]string)
+	for i, segment := range best.segments {
+		if isTemplateParam(segment) {
+			params[segment[1:len(segment)-1]] = segments[i]
+		}
+	}
+	return best, params
+}
+
+// validateRequest returns the violations of the request against op.
+func (s *openAPISpec) validateRequest(c *Context, op *openAPIOperation, pathParams map[string]string) []Violation {
+	var violations []Violation
+	query := c.queryValues()
+
+	for _, p := range op.parameters {
+		name, _ := p["name"].(string)
+		in, _ := p["in"].(string)
+
+		var values []string
+		switch in {
+		case "path":
+			if value, ok := pathParams[name]; ok {
+				values = []string{value}
+			}
+		case "query":
+			values = query[name]
+		case "header":
+			values = c.Request.Header.Values(name)
+		case "cookie":
+			if cookie, err := c.Request.Cookie(name); err == nil {
+				values = []string{cookie.Value}
+			}
+		default:
+			continue
+		}
+
+		if len(values) == 0 {
+			if p["required"] == true {
+				violations = append(violations, Violation{In: in, Field: name, Message: "is required"})
+			}
+			continue
+		}
+
+		schema := p["schema"]
+		value, err := s.convertParam(schema, values, in != "query")
+		if err != nil {
+			violations = append(violations, Violation{In: in, Field: name, Message: err.Error()})
+			continue
+		}
+		var paramViolations []Violation
+		s.validator.validate(schema, value, in, "", &paramViolations)
+		for _, violation := range paramViolations {
+			violation.Field = name + violation.Field
+			violations = append(violations, violation)
+		}
+	}
+
+	if op.body == nil {
+		return violations
+	}
+	body, err := c.RawBody()
+	if err != nil {
+		return append(violations, Violation{In: "body", Message: "cannot be read: " + err.Error()})
+	}
+	if len(body) == 0 {
+		if op.bodyRequired {
+			violations = append(violations, Violation{In: "body", Message: "is required"})
+		}
+		return violations
+	}
+
+	contentType := c.Request.Header.Get("Content-Type")
+	media, ok := matchMediaType(op.body, contentType)
+	if !ok {
+		return append(violations, Violation{In: "header", Field: "Content-Type", Message: fmt.Sprintf("media type %q is not supported", contentType)})
+	}
+	return append(violations, s.validateBody(media, contentType, body, "body")...)
+}
+
+// validateResponse returns the violations of a response against op.
+func (s *openAPISpec) validateResponse(op *openAPIOperation, status int, header http.Header, body []byte) []Violation {
+	code := strconv.Itoa(status)
+	response, ok := op.responses[code]
+	if !ok {
+		response, ok = op.responses[code[:1]+"XX"]
+	}
+	if !ok {
+		response, ok = op.responses["default"]
+	}
+	if !ok {
+		return []Violation{{In: "response", Message: fmt.Sprintf("status %d is not documented", status)}}
+	}
+
+	r, err := s.object(response)
+	if err != nil {
+		return []Violation{{In: "response", Message: err.Error()}}
+	}
+	content, _ := r["content"].(map[string]interface{})
+	if len(content) == 0 || len(body) == 0 {
+		return nil
+	}
+
+	contentType := header.Get("Content-Type")
+	media, ok := matchMediaType(content, contentType)
+	if !ok {
+		return []Violation{{In: "response", Field: "Content-Type", Message: fmt.Sprintf("media type %q is not documented", contentType)}}
+	}
+	return s.validateBody(media, contentType, body, "response")
+}
+
+// validateBody validates a JSON body against the schema of its media
+// type. Bodies of other media types are not inspected.
+func (s *openAPISpec) validateBody(media interface{}, contentType string, body []byte, in string) []Violation {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return nil
+	}
+	m, _ := media.(map[string]interface{})
+	schema, ok := m["schema"]
+	if !ok {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []Violation{{In: in, Message: "invalid JSON: " + err.Error()}}
+	}
+	var violations []Violation
+	s.validator.validate(schema, value, in, "", &violations)
+	return violations
+}
+
+// convertParam converts the raw values of a parameter to the JSON value
+// described by schema. Arrays are read from the repeated values, or from a
+// comma-separated list if splitList is set.
+func (s *openAPISpec) convertParam(schema interface{}, values []string, splitList bool) (interface{}, error) {
+	sch, _ := schema.(map[string]interface{})
+	if ref, ok := sch["$ref"].(string); ok {
+		target, err := s.validator.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+		sch, _ = target.(map[string]interface{})
+	}
+
+	types, _ := schemaTypes(sch["type"])
+	if len(types) > 0 && types[0] == "array" {
+		if splitList {
+			values = strings.Split(values[0], ",")
+		}
+		items := make([]interface{}, len(values))
+		for i, value := range values {
+			item, err := s.convertParam(sch["items"], []string{value}, false)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+
+	value := values[0]
+	if len(types) == 0 {
+		return value, nil
+	}
+	switch types[0] {
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected integer, got %q", value)
+		}
+		return float64(n), nil
+	case "number":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected number, got %q", value)
+		}
+		return n, nil
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("expected boolean, got %q", value)
+		}
+		return b, nil
+	}
+	return value, nil
+}
+
+// matchMediaType returns the entry of content matching contentType,
+// trying the exact media type, then "type/*", then "*/*".
+func matchMediaType(content map[string]interface{}, contentType string) (interface{}, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = ""
+	}
+	candidates := []string{mediaType}
+	if i := strings.IndexByte(mediaType, '/'); i > 0 {
+		candidates = append(candidates, mediaType[:i]+"/*")
+	}
+	candidates = append(candidates, "*/*")
+
+	for _, candidate := range candidates {
+		if media, ok := content[candidate]; ok {
+			return media, true
+		}
+	}
+	return nil, false
+}
+
+// isTemplateParam reports whether a path template segment is a parameter,
+// e.g. "{id}".
+func isTemplateParam(segment string) bool {
+	return len(segment) > 2 && segment[0] == '{' && segment[len(segment)-1] == '}'
+}
@@ -0,0 +1,147 @@
+package goxpress
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testOpenAPISpec = `{
+	"openapi": "3.0.3",
+	"info": {"title": "Pets", "version": "1.0.0"},
+	"paths": {
+		"/pets": {
+			"get": {
+				"parameters": [
+					{"name": "limit", "in": "query", "schema": {"type": "integer", "maximum": 100}},
+					{"name": "tag", "in": "query", "schema": {"type": "array", "items": {"type": "string"}}}
+				],
+				"responses": {
+					"200": {"description": "OK", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}}}}
+				}
+			},
+			"post": {
+				"requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+				"responses": {"201": {"description": "Created"}}
+			}
+		},
+		"/pets/{id}": {
+			"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer"}}],
+			"get": {"responses": {"200": {"description": "OK"}}}
+		},
+		"/pets/mine": {
+			"get": {"responses": {"200": {"description": "OK"}}}
+		}
+	},
+	"components": {
+		"schemas": {
+			"Pet": {
+				"type": "object",
+				"required": ["name"],
+				"properties": {"name": {"type": "string"}, "age": {"type": "integer"}}
+			}
+		}
+	}
+}`
+
+func TestOpenAPIValidator(t *testing.T) {
+	app := New()
+	app.Use(OpenAPIValidator([]byte(testOpenAPISpec)))
+	app.GET("/pets", func(c *Context) { c.JSON(200, []map[string]string{{"name": "rex"}}) })
+	app.POST("/pets", func(c *Context) {
+		var pet map[string]interface{}
+		c.BindJSON(&pet)
+		c.JSON(201, pet)
+	})
+	app.GET("/pets/:id", func(c *Context) { c.String(200, "pet") })
+	app.GET("/health", func(c *Context) { c.String(200, "ok") })
+
+	tests := []struct {
+		method, path, body string
+		status             int
+		violations         []string
+	}{
+		{"GET", "/pets?limit=10&tag=a&tag=b", "", 200, nil},
+		{"GET", "/pets?limit=ten", "", 400, []string{`query limit: expected integer, got "ten"`}},
+		{"GET", "/pets?limit=500", "", 400, []string{"query limit: must be at most 100"}},
+		{"POST", "/pets", `{"name":"rex","age":3}`, 201, nil},
+		{"POST", "/pets", `{"age":"old"}`, 400, []string{"body /name: is required", "body /age: expected integer, got string"}},
+		{"POST", "/pets", ``, 400, []string{"body: is required"}},
+		{"GET", "/pets/7", "", 200, nil},
+		{"GET", "/pets/rex", "", 400, []string{`path id: expected integer, got "rex"`}},
+		{"GET", "/pets/mine", "", 200, nil}, // Matches the literal path, not {id}
+		{"GET", "/health", "", 200, nil},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		if tt.body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s %s: expected status %d, got %d (%s)", tt.method, tt.path, tt.status, w.Code, w.Body.String())
+			continue
+		}
+		if tt.status != 400 {
+			continue
+		}
+		var res struct {
+			Violations []Violation `json:"violations"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &res)
+		var got []string
+		for _, v := range res.Violations {
+			got = append(got, v.String())
+		}
+		if strings.Join(got, "|") != strings.Join(tt.violations, "|") {
+			t.Errorf("%s %s: expected violations %v, got %v", tt.method, tt.path, tt.violations, got)
+		}
+	}
+}
+
+func TestOpenAPIValidatorResponses(t *testing.T) {
+	pets := `[{"name":"rex"}]`
+	app := New()
+	app.Use(OpenAPIValidatorWithConfig(OpenAPIValidatorConfig{
+		Spec:              []byte(testOpenAPISpec),
+		BasePath:          "/api",
+		ValidateResponses: true,
+	}))
+	app.GET("/api/pets", func(c *Context) {
+		c.Header("Content-Type", "application/json")
+		c.Response.Write([]byte(pets))
+	})
+	app.GET("/api/pets/:id", func(c *Context) { c.String(404, "not found") })
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/api/pets", nil))
+	if w.Code != 200 || w.Body.String() != pets {
+		t.Errorf("Expected valid response to pass, got %d %q", w.Code, w.Body.String())
+	}
+
+	pets = `[{"age":1}]`
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/api/pets", nil))
+	if w.Code != 500 || !strings.Contains(w.Body.String(), `"field":"/0/name"`) {
+		t.Errorf("Expected invalid response body to be replaced, got %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/api/pets/1", nil))
+	if w.Code != 500 || !strings.Contains(w.Body.String(), "status 404 is not documented") {
+		t.Errorf("Expected undocumented status to be reported, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestOpenAPIValidatorInvalidSpec(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic for a spec without paths")
+		}
+	}()
+	OpenAPIValidator([]byte(`{"openapi":"3.0.3"}`))
+}
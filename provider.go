@@ -0,0 +1,107 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the provider registry behind Provide and Inject.
+package goxpress
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// provider builds the values of one type for Inject.
+type provider struct {
+	construct func(c *Context) (interface{}, error)
+	scoped    bool // Built once per request rather than once per engine
+
+	// Value shared by all requests once built, for unscoped providers
+	mu    sync.Mutex
+	built bool
+	value interface{}
+}
+
+// resolving marks a request-scoped value under construction, to detect
+// providers depending on themselves.
+type resolving struct{}
+
+// provide registers p as the provider of typ, replacing any previous one.
+func (e *Engine) provide(typ reflect.Type, p *provider) {
+	if e.frozen {
+		panic("goxpress: cannot register providers after Freeze")
+	}
+	if e.providers == nil {
+		e.providers = make(map[reflect.Type]*provider)
+	}
+	e.providers[typ] = p
+}
+
+// resolve returns the value of typ built by its provider, building it on
+// first use.
+func (c *Context) resolve(typ reflect.Type) (interface{}, error) {
+	var p *provider
+	if c.engine != nil {
+		p = c.engine.providers[typ]
+	}
+	if p == nil {
+		return nil, fmt.Errorf("goxpress: no provider registered for %s", typ)
+	}
+
+	if !p.scoped {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if !p.built {
+			value, err := p.construct(c)
+			if err != nil {
+				return nil, fmt.Errorf("goxpress: cannot provide %s: %w", typ, err)
+			}
+			p.value, p.built = value, true
+		}
+		return p.value, nil
+	}
+
+	if value, ok := c.injectedValue(typ); ok {
+		if _, ok := value.(resolving); ok {
+			return nil, fmt.Errorf("goxpress: provider of %s depends on itself", typ)
+		}
+		return value, nil
+	}
+
+	c.setInjectedValue(typ, resolving{})
+	value, err := p.construct(c)
+	if err != nil {
+		c.deleteInjectedValue(typ)
+		return nil, fmt.Errorf("goxpress: cannot provide %s: %w", typ, err)
+	}
+	c.setInjectedValue(typ, value)
+	return value, nil
+}
+
+// injectedValue returns the request-scoped value of typ, if built.
+func (c *Context) injectedValue(typ reflect.Type) (interface{}, bool) {
+	if c.safeStore {
+		c.storeMu.RLock()
+		defer c.storeMu.RUnlock()
+	}
+	value, ok := c.injected[typ]
+	return value, ok
+}
+
+// setInjectedValue records the request-scoped value of typ.
+func (c *Context) setInjectedValue(typ reflect.Type, value interface{}) {
+	if c.safeStore {
+		c.storeMu.Lock()
+		defer c.storeMu.Unlock()
+	}
+	if c.injected == nil {
+		c.injected = make(map[reflect.Type]interface{})
+	}
+	c.injected[typ] = value
+}
+
+// deleteInjectedValue forgets the request-scoped value of typ.
+func (c *Context) deleteInjectedValue(typ reflect.Type) {
+	if c.safeStore {
+		c.storeMu.Lock()
+		defer c.storeMu.Unlock()
+	}
+	delete(c.injected, typ)
+}
@@ -0,0 +1,452 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the reverse proxy middleware.
+package goxpress
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ProxyConfig defines configuration options for the reverse proxy middleware.
+type ProxyConfig struct {
+	// Skipper, if set, skips the proxy for requests it returns true for,
+	// passing them on to the next handler instead.
+	Skipper Skipper
+
+	// StripPrefix is removed from the request path before it is forwarded,
+	// so with "/api" a request to /api/users reaches the upstream as /users.
+	// Paths outside the prefix are forwarded unchanged.
+	StripPrefix string
+
+	// Rewrite maps the request path, after StripPrefix is applied, to the
+	// path requested from the upstream. The result is joined to the path
+	// of the target URL.
+	Rewrite func(path string) string
+
+	// PreserveHost forwards the client's Host header instead of the host
+	// of the target URL.
+	PreserveHost bool
+
+	// Headers are set on every upstream request, replacing any values sent
+	// by the client.
+	Headers map[string]string
+
+	// Transport performs the upstream requests.
+	// If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	// FlushInterval is how often the response is flushed to the client
+	// while it is copied. Zero disables periodic flushing and a negative
+	// value flushes after every write. Streaming responses such as
+	// text/event-stream are always flushed immediately.
+	FlushInterval time.Duration
+
+	// ModifyResponse, if set, can modify or replace the upstream response
+	// before it is sent. Returning an error passes it to ErrorHandler.
+	ModifyResponse func(*http.Response) error
+
+	// Balancer chooses the target of each request when the proxy has
+	// several. If nil, RoundRobinBalancer is used.
+	Balancer ProxyBalancer
+
+	// MaxFails is the number of consecutive failures after which a target
+	// is ejected from the rotation. Connection errors and 502, 503 and 504
+	// responses count as failures. If zero, defaults to 3.
+	MaxFails int
+
+	// FailTimeout is how long an ejected target is skipped before it
+	// receives requests again. If zero, defaults to 30 seconds.
+	FailTimeout time.Duration
+
+	// Retry enables retrying failed upstream requests on another target.
+	// If nil, requests are not retried.
+	Retry *ProxyRetry
+
+	// ErrorHandler is called when the upstream cannot be reached or
+	// ModifyResponse fails. If nil, the error is recorded in c.Errors and
+	// a 504 Gateway Timeout is sent for timeouts or a 502 Bad Gateway
+	// otherwise.
+	ErrorHandler func(c *Context, err error)
+}
+
+// proxyContextKey is the request context key holding the *proxyRequest
+// being served, which the httputil.ReverseProxy hooks need.
+type proxyContextKey struct{}
+
+// proxyRequest is a request being proxied and its chosen upstream.
+type proxyRequest struct {
+	c      *Context
+	target *ProxyTarget
+	path   string // Upstream path relative to the target path
+	query  string // Raw query of the client request
+	buffer []byte // Request body kept for retries
+}
+
+// bufferBody reads the request body into memory so retries can resend it.
+func (pr *proxyRequest) bufferBody() error {
+	req := pr.c.Request
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	data, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+	pr.buffer = data
+	req.Body = pr.body()
+	return nil
+}
+
+// body returns a fresh reader over the buffered request body.
+func (pr *proxyRequest) body() io.ReadCloser {
+	if pr.buffer == nil {
+		return http.NoBody
+	}
+	return ioutil.NopCloser(bytes.NewReader(pr.buffer))
+}
+
+// Proxy returns a handler that forwards requests to target and sends the
+// upstream response back to the client, letting goxpress act as an API
+// gateway in front of other services. It ends the handler chain.
+//
+// The path of target is prepended to the request path and its query is
+// merged with the request query. X-Forwarded-For, X-Forwarded-Host and
+// X-Forwarded-Proto describe the original request to the upstream.
+//
+// An optional ProxyConfig adds path rewriting, extra headers and hooks.
+//
+// Example:
+//
+//	legacy, _ := url.Parse("http://10.0.0.5:8080/v1")
+//
+//	// GET /api/users is forwarded to http://10.0.0.5:8080/v1/users
+//	app.GET("/api/*path", goxpress.Proxy(legacy, goxpress.ProxyConfig{
+//		StripPrefix: "/api",
+//		Headers:     map[string]string{"X-Gateway": "goxpress"},
+//	}))
+func Proxy(target *url.URL, opts ...ProxyConfig) HandlerFunc {
+	return ProxyTargets([]*url.URL{target}, opts...)
+}
+
+// ProxyTargets returns a handler that balances requests across several
+// upstream targets, each configured as the target of Proxy. It panics if
+// targets is empty.
+//
+// Targets are checked passively: after ProxyConfig.MaxFails consecutive
+// failures a target is ejected for ProxyConfig.FailTimeout and requests
+// go to the others. If every target is ejected, all of them are tried
+// again rather than failing outright.
+//
+// Example:
+//
+//	upstreams := []*url.URL{node1, node2, node3}
+//	app.Use(goxpress.ProxyTargets(upstreams, goxpress.ProxyConfig{
+//		Balancer: goxpress.LeastConnBalancer(),
+//		MaxFails: 5,
+//	}))
+func ProxyTargets(targets []*url.URL, opts ...ProxyConfig) HandlerFunc {
+	if len(targets) == 0 {
+		panic("goxpress: ProxyTargets requires at least one target")
+	}
+
+	var config ProxyConfig
+	if len(opts) > 0 {
+		config = opts[0]
+	}
+
+	// Set defaults
+	config.StripPrefix = strings.TrimSuffix(config.StripPrefix, "/")
+	if config.Balancer == nil {
+		config.Balancer = RoundRobinBalancer()
+	}
+	if config.MaxFails == 0 {
+		config.MaxFails = 3
+	}
+	if config.FailTimeout == 0 {
+		config.FailTimeout = 30 * time.Second
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = defaultProxyErrorHandler
+	}
+
+	upstreams := make([]*ProxyTarget, len(targets))
+	for i, u := range targets {
+		upstreams[i] = newProxyTarget(u)
+	}
+
+	transport := &proxyTransport{
+		next:    config.Transport,
+		targets: upstreams,
+		config:  &config,
+	}
+	if transport.next == nil {
+		transport.next = http.DefaultTransport
+	}
+	if config.Retry != nil {
+		transport.retry = newProxyRetrier(*config.Retry)
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			pr := req.Context().Value(proxyContextKey{}).(*proxyRequest)
+			rewriteProxyRequest(pr, req, &config)
+		},
+		Transport:      transport,
+		FlushInterval:  config.FlushInterval,
+		ModifyResponse: config.ModifyResponse,
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			pr := req.Context().Value(proxyContextKey{}).(*proxyRequest)
+			config.ErrorHandler(pr.c, err)
+		},
+	}
+
+	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		c.Abort()
+
+		pr := &proxyRequest{c: c}
+		if transport.retry != nil && transport.retry.allows(c.Request.Method) {
+			// Keep the body so it can be sent again
+			if err := pr.bufferBody(); err != nil {
+				config.ErrorHandler(c, err)
+				return
+			}
+			transport.retry.budget.deposit()
+		}
+
+		pr.target = config.Balancer.Select(c, availableTargets(upstreams))
+		ctx := context.WithValue(c.Request.Context(), proxyContextKey{}, pr)
+		proxy.ServeHTTP(c.Response, c.Request.WithContext(ctx))
+	}
+}
+
+// proxyTransport sends upstream requests to the target chosen for them,
+// tracking the health and load of each target and retrying failed
+// requests when retries are enabled.
+type proxyTransport struct {
+	next    http.RoundTripper
+	targets []*ProxyTarget
+	config  *ProxyConfig
+	retry   *proxyRetrier // nil when retries are disabled
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	pr := req.Context().Value(proxyContextKey{}).(*proxyRequest)
+	if t.retry == nil || !t.retry.allows(req.Method) {
+		return t.send(pr.target, req)
+	}
+
+	tried := []*ProxyTarget{pr.target}
+	for attempt := 1; ; attempt++ {
+		res, err := t.send(pr.target, req)
+		if !isUpstreamFailure(res, err) || attempt > t.retry.config.Attempts ||
+			req.Context().Err() != nil || !t.retry.budget.withdraw() {
+			return res, err
+		}
+		if res != nil {
+			io.Copy(ioutil.Discard, io.LimitReader(res.Body, 4<<10))
+			res.Body.Close()
+		}
+
+		if err := t.retry.wait(req.Context(), attempt); err != nil {
+			return nil, err
+		}
+
+		// Prefer a target that has not failed this request yet
+		pr.target = t.config.Balancer.Select(pr.c, retryTargets(t.targets, tried))
+		tried = append(tried, pr.target)
+
+		req = req.Clone(req.Context())
+		req.Body = pr.body()
+		pointProxyRequest(req, pr.target.URL, pr.path, pr.query, t.config.PreserveHost)
+	}
+}
+
+// send performs one upstream request to target, recording its outcome
+// for the passive health checks. The target counts as active until the
+// response body is closed.
+func (t *proxyTransport) send(target *ProxyTarget, req *http.Request) (*http.Response, error) {
+	atomic.AddInt64(&target.active, 1)
+	res, err := t.next.RoundTrip(req)
+
+	switch {
+	case err != nil:
+		// Requests abandoned by the client say nothing about the upstream
+		if req.Context().Err() == nil {
+			target.recordFailure(t.config.MaxFails, t.config.FailTimeout)
+		}
+	case isUpstreamFailure(res, nil):
+		target.recordFailure(t.config.MaxFails, t.config.FailTimeout)
+	default:
+		target.recordSuccess()
+	}
+
+	if err != nil {
+		atomic.AddInt64(&target.active, -1)
+		return nil, err
+	}
+	res.Body = &activeBody{ReadCloser: res.Body, target: target}
+	return res, nil
+}
+
+// isUpstreamFailure reports whether an upstream request failed, either
+// with an error or with a 502, 503 or 504 response.
+func isUpstreamFailure(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch res.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// activeBody releases the active request of its target when closed.
+type activeBody struct {
+	io.ReadCloser
+	target *ProxyTarget
+	closed int32
+}
+
+// Close closes the body and releases the target.
+func (b *activeBody) Close() error {
+	if atomic.CompareAndSwapInt32(&b.closed, 0, 1) {
+		atomic.AddInt64(&b.target.active, -1)
+	}
+	return b.ReadCloser.Close()
+}
+
+// retryTargets returns the available targets that are not in tried, or
+// all available targets if every one of them was tried.
+func retryTargets(targets, tried []*ProxyTarget) []*ProxyTarget {
+	available := availableTargets(targets)
+	untried := make([]*ProxyTarget, 0, len(available))
+	for _, t := range available {
+		seen := false
+		for _, u := range tried {
+			seen = seen || t == u
+		}
+		if !seen {
+			untried = append(untried, t)
+		}
+	}
+	if len(untried) == 0 {
+		return available
+	}
+	return untried
+}
+
+// availableTargets returns the targets that are not ejected, or all of
+// them if every target is ejected.
+func availableTargets(targets []*ProxyTarget) []*ProxyTarget {
+	now := time.Now()
+	for i, t := range targets {
+		if t.available(now) {
+			continue
+		}
+
+		// Copy only when some target is ejected
+		available := append([]*ProxyTarget(nil), targets[:i]...)
+		for _, t := range targets[i+1:] {
+			if t.available(now) {
+				available = append(available, t)
+			}
+		}
+		if len(available) == 0 {
+			return targets
+		}
+		return available
+	}
+	return targets
+}
+
+// rewriteProxyRequest points the outgoing request req at the target
+// chosen for pr and adds the forwarding headers.
+func rewriteProxyRequest(pr *proxyRequest, req *http.Request, config *ProxyConfig) {
+	path := req.URL.Path
+	if prefix := config.StripPrefix; prefix != "" && strings.HasPrefix(path, prefix) &&
+		(len(path) == len(prefix) || path[len(prefix)] == '/') {
+		path = path[len(prefix):]
+		if path == "" {
+			path = "/"
+		}
+	}
+	if config.Rewrite != nil {
+		path = config.Rewrite(path)
+	}
+
+	pr.path, pr.query = path, req.URL.RawQuery
+	pointProxyRequest(req, pr.target.URL, pr.path, pr.query, config.PreserveHost)
+
+	req.Header.Set("X-Forwarded-Host", pr.c.Request.Host)
+	req.Header.Set("X-Forwarded-Proto", pr.c.Scheme())
+	if _, ok := req.Header["User-Agent"]; !ok {
+		// Keep the Go client from adding its own User-Agent
+		req.Header.Set("User-Agent", "")
+	}
+	for key, value := range config.Headers {
+		req.Header.Set(key, value)
+	}
+}
+
+// pointProxyRequest sets the URL of req to path and query relative to
+// target, and its Host header to the target host unless preserveHost.
+func pointProxyRequest(req *http.Request, target *url.URL, path, query string, preserveHost bool) {
+	req.URL.Scheme = target.Scheme
+	req.URL.Host = target.Host
+	req.URL.Path = joinProxyPath(target.Path, path)
+	req.URL.RawPath = ""
+	if target.RawQuery == "" || query == "" {
+		req.URL.RawQuery = target.RawQuery + query
+	} else {
+		req.URL.RawQuery = target.RawQuery + "&" + query
+	}
+
+	if !preserveHost {
+		req.Host = target.Host
+	}
+}
+
+// joinProxyPath joins the target path and the request path with a single
+// slash between them.
+func joinProxyPath(base, path string) string {
+	switch {
+	case base == "" || base == "/":
+		return path
+	case strings.HasSuffix(base, "/") && strings.HasPrefix(path, "/"):
+		return base + path[1:]
+	case !strings.HasSuffix(base, "/") && !strings.HasPrefix(path, "/"):
+		return base + "/" + path
+	}
+	return base + path
+}
+
+// defaultProxyErrorHandler records err and answers with 502 or 504. The
+// error is not passed to the error handlers, as the response is sent here.
+func defaultProxyErrorHandler(c *Context, err error) {
+	c.Errors = append(c.Errors, &ErrorEntry{Err: err, Meta: "proxy"})
+
+	var netErr interface{ Timeout() bool }
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		c.String(http.StatusGatewayTimeout, "Gateway Timeout")
+		return
+	}
+	c.String(http.StatusBadGateway, "Bad Gateway")
+}
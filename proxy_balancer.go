@@ -0,0 +1,148 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the upstream targets and load balancers of the reverse proxy.
+package goxpress
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+)
+
+// ProxyTarget is an upstream server of the reverse proxy. It tracks the
+// requests in flight and the recent failures of the upstream.
+type ProxyTarget struct {
+	// URL is the address requests are forwarded to.
+	URL *url.URL
+
+	id           string // Stable identifier used in sticky cookies
+	active       int64  // Requests in flight
+	failures     int32  // Consecutive failures
+	ejectedUntil int64  // Unix nanoseconds until which the target is skipped
+}
+
+// newProxyTarget creates the target for u.
+func newProxyTarget(u *url.URL) *ProxyTarget {
+	h := fnv.New64a()
+	h.Write([]byte(u.String()))
+	return &ProxyTarget{URL: u, id: fmt.Sprintf("%016x", h.Sum64())}
+}
+
+// Active returns the number of requests in flight to the target.
+func (t *ProxyTarget) Active() int {
+	return int(atomic.LoadInt64(&t.active))
+}
+
+// available reports whether the target is not ejected at time now.
+func (t *ProxyTarget) available(now time.Time) bool {
+	return now.UnixNano() >= atomic.LoadInt64(&t.ejectedUntil)
+}
+
+// recordFailure counts a failed request, ejecting the target for
+// failTimeout once maxFails consecutive requests have failed.
+func (t *ProxyTarget) recordFailure(maxFails int, failTimeout time.Duration) {
+	if atomic.AddInt32(&t.failures, 1) >= int32(maxFails) {
+		atomic.StoreInt32(&t.failures, 0)
+		atomic.StoreInt64(&t.ejectedUntil, time.Now().Add(failTimeout).UnixNano())
+	}
+}
+
+// recordSuccess resets the consecutive failure count.
+func (t *ProxyTarget) recordSuccess() {
+	if atomic.LoadInt32(&t.failures) != 0 {
+		atomic.StoreInt32(&t.failures, 0)
+	}
+}
+
+// ProxyBalancer chooses the upstream of each proxied request among the
+// targets that are currently healthy. Implementations must be safe for
+// concurrent use.
+type ProxyBalancer interface {
+	Select(c *Context, targets []*ProxyTarget) *ProxyTarget
+}
+
+// roundRobinBalancer cycles through the targets in order.
+type roundRobinBalancer struct {
+	next uint64
+}
+
+// RoundRobinBalancer returns a balancer that sends requests to each
+// target in turn. It is the default balancer of ProxyTargets.
+func RoundRobinBalancer() ProxyBalancer {
+	return &roundRobinBalancer{}
+}
+
+// Select implements ProxyBalancer.
+func (b *roundRobinBalancer) Select(c *Context, targets []*ProxyTarget) *ProxyTarget {
+	n := atomic.AddUint64(&b.next, 1) - 1
+	return targets[n%uint64(len(targets))]
+}
+
+// leastConnBalancer picks the target with the fewest requests in flight.
+type leastConnBalancer struct {
+	roundRobin roundRobinBalancer
+}
+
+// LeastConnBalancer returns a balancer that sends requests to the target
+// with the fewest requests in flight, which suits upstreams whose response
+// times vary widely. Ties are broken in round-robin order.
+func LeastConnBalancer() ProxyBalancer {
+	return &leastConnBalancer{}
+}
+
+// Select implements ProxyBalancer.
+func (b *leastConnBalancer) Select(c *Context, targets []*ProxyTarget) *ProxyTarget {
+	start := b.roundRobin.Select(c, targets)
+	best := start
+	for _, t := range targets {
+		if t.Active() < best.Active() {
+			best = t
+		}
+	}
+	return best
+}
+
+// stickyBalancer pins clients to a target with a cookie.
+type stickyBalancer struct {
+	cookieName string
+	fallback   ProxyBalancer
+}
+
+// StickyBalancer returns a balancer that keeps sending a client to the
+// same target, remembered in the cookie cookieName. New clients, and
+// clients whose target is ejected, are assigned a target by fallback,
+// or in round-robin order if fallback is nil.
+//
+// Example:
+//
+//	app.Use(goxpress.ProxyTargets(upstreams, goxpress.ProxyConfig{
+//		Balancer: goxpress.StickyBalancer("goxpress_upstream", goxpress.LeastConnBalancer()),
+//	}))
+func StickyBalancer(cookieName string, fallback ProxyBalancer) ProxyBalancer {
+	if fallback == nil {
+		fallback = RoundRobinBalancer()
+	}
+	return &stickyBalancer{cookieName: cookieName, fallback: fallback}
+}
+
+// Select implements ProxyBalancer.
+func (b *stickyBalancer) Select(c *Context, targets []*ProxyTarget) *ProxyTarget {
+	if cookie, err := c.Request.Cookie(b.cookieName); err == nil {
+		for _, t := range targets {
+			if t.id == cookie.Value {
+				return t
+			}
+		}
+	}
+
+	target := b.fallback.Select(c, targets)
+	c.setCookieHeader(&http.Cookie{
+		Name:     b.cookieName,
+		Value:    target.id,
+		Path:     "/",
+		HttpOnly: true,
+	})
+	return target
+}
@@ -0,0 +1,151 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the retry policy of the reverse proxy.
+package goxpress
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProxyRetry configures retries of failed upstream requests. A request is
+// retried when the upstream cannot be reached or answers 502, 503 or 504,
+// preferably on a target that has not failed it yet.
+//
+// Retries are limited by a budget shared by all requests of the proxy, so
+// that while an upstream is down they add a bounded amount of load instead
+// of multiplying it.
+type ProxyRetry struct {
+	// Attempts is the maximum number of retries after the first attempt.
+	// If zero, defaults to 2.
+	Attempts int
+
+	// Methods lists the request methods that may be retried. Only add
+	// methods whose requests are safe to send twice. Bodies of retried
+	// requests are buffered in memory.
+	// If empty, defaults to GET and HEAD.
+	Methods []string
+
+	// Backoff is the delay before the first retry. It doubles with each
+	// further retry, with random jitter, up to MaxBackoff.
+	// If zero, defaults to 50ms.
+	Backoff time.Duration
+
+	// MaxBackoff caps the delay between retries.
+	// If zero, defaults to 1 second.
+	MaxBackoff time.Duration
+
+	// Budget is the number of retries allowed per retryable request, such
+	// as 0.2 for at most 20% additional upstream requests. A small burst of
+	// retries is always allowed so that isolated failures are retried.
+	// If zero, defaults to 0.2.
+	Budget float64
+}
+
+// retryBudgetBurst is the number of retries the budget holds at most.
+const retryBudgetBurst = 10
+
+// retryToken is the budget cost of one retry. Budgets are counted in
+// thousandths of a retry so fractional ratios add up exactly.
+const retryToken = 1000
+
+// proxyRetrier applies a ProxyRetry policy.
+type proxyRetrier struct {
+	config  ProxyRetry
+	methods map[string]bool
+	budget  *retryBudget
+}
+
+// newProxyRetrier creates a retrier for config, applying its defaults.
+func newProxyRetrier(config ProxyRetry) *proxyRetrier {
+	// Set defaults
+	if config.Attempts == 0 {
+		config.Attempts = 2
+	}
+	if len(config.Methods) == 0 {
+		config.Methods = []string{http.MethodGet, http.MethodHead}
+	}
+	if config.Backoff == 0 {
+		config.Backoff = 50 * time.Millisecond
+	}
+	if config.MaxBackoff == 0 {
+		config.MaxBackoff = time.Second
+	}
+	if config.Budget == 0 {
+		config.Budget = 0.2
+	}
+
+	methods := make(map[string]bool, len(config.Methods))
+	for _, method := range config.Methods {
+		methods[strings.ToUpper(method)] = true
+	}
+	return &proxyRetrier{
+		config:  config,
+		methods: methods,
+		budget: &retryBudget{
+			credit: int64(math.Round(config.Budget * retryToken)),
+			tokens: retryBudgetBurst * retryToken,
+		},
+	}
+}
+
+// allows reports whether requests with the given method may be retried.
+func (r *proxyRetrier) allows(method string) bool {
+	return r.methods[method]
+}
+
+// wait sleeps before the given retry, returning early with the context's
+// error if ctx is done.
+func (r *proxyRetrier) wait(ctx context.Context, retry int) error {
+	d := r.config.Backoff << uint(retry-1)
+	if d > r.config.MaxBackoff || d <= 0 {
+		d = r.config.MaxBackoff
+	}
+	// Spread retries of concurrent requests over [d/2, d)
+	d = d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryBudget is a token bucket refilled by requests and drained by
+// retries.
+type retryBudget struct {
+	mu     sync.Mutex
+	credit int64 // Tokens earned per request
+	tokens int64
+}
+
+// deposit credits the budget for one request.
+func (b *retryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens += b.credit
+	if b.tokens > retryBudgetBurst*retryToken {
+		b.tokens = retryBudgetBurst * retryToken
+	}
+}
+
+// withdraw takes one retry from the budget, reporting false if it is
+// exhausted.
+func (b *retryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tokens < retryToken {
+		return false
+	}
+	b.tokens -= retryToken
+	return true
+}
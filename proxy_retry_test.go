@@ -0,0 +1,120 @@
+package goxpress
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProxyRetry(t *testing.T) {
+	var failingHits int32
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&failingHits, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write([]byte(r.Method + " " + string(body)))
+	}))
+	defer healthy.Close()
+
+	failingURL, _ := url.Parse(failing.URL)
+	healthyURL, _ := url.Parse(healthy.URL)
+	targets := []*url.URL{failingURL, healthyURL}
+	retry := &ProxyRetry{Backoff: time.Millisecond}
+
+	serve := func(handler HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+		app := New()
+		app.Use(handler)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("RetriesOnAnotherTarget", func(t *testing.T) {
+		atomic.StoreInt32(&failingHits, 0)
+		w := serve(ProxyTargets(targets, ProxyConfig{Retry: retry}), httptest.NewRequest("GET", "/", nil))
+
+		if w.Code != 200 || w.Body.String() != "GET " {
+			t.Errorf("Expected retried request to succeed, got %d %q", w.Code, w.Body.String())
+		}
+		if atomic.LoadInt32(&failingHits) != 1 {
+			t.Errorf("Expected one failed attempt, got %d", failingHits)
+		}
+	})
+
+	t.Run("PostNotRetriedByDefault", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("order"))
+		w := serve(ProxyTargets(targets, ProxyConfig{Retry: retry}), req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected upstream 503 to be returned, got %d", w.Code)
+		}
+	})
+
+	t.Run("OptInMethodResendsBody", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/", strings.NewReader("order"))
+		w := serve(ProxyTargets(targets, ProxyConfig{
+			Retry: &ProxyRetry{Methods: []string{"PUT"}, Backoff: time.Millisecond},
+		}), req)
+
+		if w.Code != 200 || w.Body.String() != "PUT order" {
+			t.Errorf("Expected body to be resent, got %d %q", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Attempts", func(t *testing.T) {
+		atomic.StoreInt32(&failingHits, 0)
+		w := serve(ProxyTargets([]*url.URL{failingURL}, ProxyConfig{
+			Retry:    &ProxyRetry{Attempts: 3, Backoff: time.Millisecond},
+			MaxFails: 100,
+		}), httptest.NewRequest("GET", "/", nil))
+
+		if w.Code != http.StatusServiceUnavailable || atomic.LoadInt32(&failingHits) != 4 {
+			t.Errorf("Expected 4 attempts ending in 503, got %d attempts and %d", failingHits, w.Code)
+		}
+	})
+
+	t.Run("Budget", func(t *testing.T) {
+		atomic.StoreInt32(&failingHits, 0)
+		handler := ProxyTargets([]*url.URL{failingURL}, ProxyConfig{
+			Retry:    &ProxyRetry{Attempts: 1, Backoff: time.Millisecond, Budget: 0.1},
+			MaxFails: 100,
+		})
+		for i := 0; i < 20; i++ {
+			serve(handler, httptest.NewRequest("GET", "/", nil))
+		}
+
+		// 20 requests, the burst of 10 retries and 1 retry earned by the
+		// 19 requests made after the budget started draining
+		if hits := atomic.LoadInt32(&failingHits); hits != 31 {
+			t.Errorf("Expected retries to be capped by the budget, got %d upstream requests", hits)
+		}
+	})
+}
+
+func TestProxyRetrierWait(t *testing.T) {
+	r := newProxyRetrier(ProxyRetry{Backoff: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := r.wait(ctx, 1); err != context.Canceled {
+		t.Errorf("Expected wait to stop when the context is done, got %v", err)
+	}
+
+	r = newProxyRetrier(ProxyRetry{Backoff: 4 * time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+	start := time.Now()
+	if err := r.wait(context.Background(), 10); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 5*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Expected backoff capped at MaxBackoff, waited %v", elapsed)
+	}
+}
@@ -0,0 +1,263 @@
+package goxpress
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Upstream", "legacy")
+		fmt.Fprintf(w, "%s %s host=%s xfh=%s xfp=%s xff=%t gw=%s",
+			r.Method, r.URL.RequestURI(), r.Host,
+			r.Header.Get("X-Forwarded-Host"), r.Header.Get("X-Forwarded-Proto"),
+			r.Header.Get("X-Forwarded-For") != "", r.Header.Get("X-Gateway"))
+	}))
+	defer upstream.Close()
+
+	target, _ := url.Parse(upstream.URL + "/v1?key=abc")
+	app := New()
+	app.GET("/api/*path", Proxy(target, ProxyConfig{
+		StripPrefix: "/api/",
+		Headers:     map[string]string{"X-Gateway": "goxpress"},
+	}))
+	app.GET("/old/*path", Proxy(target, ProxyConfig{
+		PreserveHost: true,
+		Rewrite: func(path string) string {
+			return strings.Replace(path, "/old", "/new", 1)
+		},
+	}))
+
+	t.Run("StripPrefix", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/users?page=2", nil)
+		req.Header.Set("X-Gateway", "spoofed")
+		app.ServeHTTP(w, req)
+
+		host := strings.TrimPrefix(upstream.URL, "http://")
+		expected := "GET /v1/users?key=abc&page=2 host=" + host +
+			" xfh=example.com xfp=http xff=true gw=goxpress"
+		if w.Code != 200 || w.Body.String() != expected {
+			t.Errorf("Expected %q, got %d %q", expected, w.Code, w.Body.String())
+		}
+		if w.Header().Get("X-Upstream") != "legacy" {
+			t.Error("Expected upstream headers to be copied")
+		}
+	})
+
+	t.Run("RewriteAndPreserveHost", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/old/items", nil))
+
+		if !strings.HasPrefix(w.Body.String(), "GET /v1/new/items?key=abc host=example.com ") {
+			t.Errorf("Expected rewritten path and client host, got %q", w.Body.String())
+		}
+	})
+}
+
+func TestProxyErrors(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	target, _ := url.Parse(upstream.URL)
+	upstream.Close() // Refuse connections
+
+	t.Run("Default", func(t *testing.T) {
+		var handled error
+		app := New()
+		app.UseError(func(err error, c *Context) {
+			handled = err
+		})
+		app.GET("/", Proxy(target))
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		if w.Code != http.StatusBadGateway || w.Body.String() != "Bad Gateway" {
+			t.Errorf("Expected 502 Bad Gateway, got %d %q", w.Code, w.Body.String())
+		}
+		if handled != nil {
+			t.Errorf("Expected error handlers not to run, got %v", handled)
+		}
+	})
+
+	t.Run("ErrorHandler", func(t *testing.T) {
+		app := New()
+		app.GET("/", Proxy(target, ProxyConfig{
+			ErrorHandler: func(c *Context, err error) {
+				c.JSON(503, map[string]string{"error": "upstream unavailable"})
+			},
+		}))
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		if w.Code != 503 || !strings.Contains(w.Body.String(), "upstream unavailable") {
+			t.Errorf("Expected custom error response, got %d %q", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("ModifyResponse", func(t *testing.T) {
+		live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(500)
+		}))
+		defer live.Close()
+		liveTarget, _ := url.Parse(live.URL)
+
+		var recorded []*ErrorEntry
+		app := New()
+		app.GET("/", Proxy(liveTarget, ProxyConfig{
+			ModifyResponse: func(res *http.Response) error {
+				if res.StatusCode >= 500 {
+					return errors.New("upstream failed")
+				}
+				return nil
+			},
+		}))
+		app.Use(func(c *Context) {
+			c.Next()
+			recorded = c.Errors
+		})
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		if w.Code != http.StatusBadGateway {
+			t.Errorf("Expected 502, got %d", w.Code)
+		}
+		if len(recorded) != 1 || recorded[0].Meta != "proxy" {
+			t.Errorf("Expected proxy error to be recorded, got %v", recorded)
+		}
+	})
+}
+
+func TestJoinProxyPath(t *testing.T) {
+	tests := []struct{ base, path, expected string }{
+		{"", "/users", "/users"},
+		{"/", "/users", "/users"},
+		{"/v1", "/users", "/v1/users"},
+		{"/v1/", "/users", "/v1/users"},
+		{"/v1", "users", "/v1/users"},
+	}
+	for _, tt := range tests {
+		if got := joinProxyPath(tt.base, tt.path); got != tt.expected {
+			t.Errorf("joinProxyPath(%q, %q) = %q, expected %q", tt.base, tt.path, got, tt.expected)
+		}
+	}
+}
+
+// newNamedUpstream starts an upstream answering with its name and the
+// given status code.
+func newNamedUpstream(t *testing.T, name string, status *int32) *url.URL {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := 200
+		if status != nil {
+			code = int(atomic.LoadInt32(status))
+		}
+		w.WriteHeader(code)
+		w.Write([]byte(name))
+	}))
+	t.Cleanup(server.Close)
+	u, _ := url.Parse(server.URL)
+	return u
+}
+
+func TestProxyTargets(t *testing.T) {
+	a := newNamedUpstream(t, "a", nil)
+	b := newNamedUpstream(t, "b", nil)
+
+	serve := func(app *Engine, req *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("RoundRobin", func(t *testing.T) {
+		app := New()
+		app.Use(ProxyTargets([]*url.URL{a, b}))
+
+		var got string
+		for i := 0; i < 4; i++ {
+			got += serve(app, httptest.NewRequest("GET", "/", nil)).Body.String()
+		}
+		if got != "abab" {
+			t.Errorf("Expected targets in turn, got %q", got)
+		}
+	})
+
+	t.Run("Sticky", func(t *testing.T) {
+		app := New()
+		app.Use(ProxyTargets([]*url.URL{a, b}, ProxyConfig{
+			Balancer: StickyBalancer("upstream", nil),
+		}))
+
+		w := serve(app, httptest.NewRequest("GET", "/", nil))
+		cookies := w.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].Name != "upstream" {
+			t.Fatalf("Expected sticky cookie, got %v", cookies)
+		}
+		first := w.Body.String()
+
+		for i := 0; i < 3; i++ {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.AddCookie(cookies[0])
+			w := serve(app, req)
+			if w.Body.String() != first {
+				t.Errorf("Expected sticky target %q, got %q", first, w.Body.String())
+			}
+			if w.Header().Get("Set-Cookie") != "" {
+				t.Error("Expected no new cookie for a pinned client")
+			}
+		}
+	})
+
+	t.Run("PassiveHealthCheck", func(t *testing.T) {
+		status := int32(503)
+		failing := newNamedUpstream(t, "failing", &status)
+
+		app := New()
+		app.Use(ProxyTargets([]*url.URL{failing, a}, ProxyConfig{MaxFails: 2, FailTimeout: time.Hour}))
+
+		var got string
+		for i := 0; i < 6; i++ {
+			got += serve(app, httptest.NewRequest("GET", "/", nil)).Body.String() + " "
+		}
+		if got != "failing a failing a a a " {
+			t.Errorf("Expected failing target to be ejected after 2 failures, got %q", got)
+		}
+	})
+
+	t.Run("AllEjected", func(t *testing.T) {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		down, _ := url.Parse(upstream.URL)
+		upstream.Close()
+
+		app := New()
+		app.Use(ProxyTargets([]*url.URL{down}, ProxyConfig{MaxFails: 1, FailTimeout: time.Hour}))
+
+		for i := 0; i < 2; i++ {
+			if w := serve(app, httptest.NewRequest("GET", "/", nil)); w.Code != http.StatusBadGateway {
+				t.Errorf("Expected ejected target to still be tried, got %d", w.Code)
+			}
+		}
+	})
+}
+
+func TestLeastConnBalancer(t *testing.T) {
+	a := newProxyTarget(&url.URL{Host: "a"})
+	b := newProxyTarget(&url.URL{Host: "b"})
+	a.active = 2
+	b.active = 1
+
+	balancer := LeastConnBalancer()
+	for i := 0; i < 3; i++ {
+		if got := balancer.Select(nil, []*ProxyTarget{a, b}); got != b {
+			t.Errorf("Expected target with fewest active requests, got %s", got.URL.Host)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the quota and usage metering middleware.
+package goxpress
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// QuotaUsage is the usage of a key within a billing window.
+type QuotaUsage struct {
+	Requests int64 // Number of requests
+	Bytes    int64 // Request body bytes read plus response body bytes written
+}
+
+// QuotaStore keeps the usage counters of the quota middleware. Windows
+// are identified by their start time. Implementations must be safe for
+// concurrent use.
+type QuotaStore interface {
+	// Reserve atomically counts one request for key in the window starting
+	// at window, unless the usage of key already reached one of the
+	// non-zero limits. It returns the usage before the reservation and
+	// whether the request was counted. The window ends at end, after which
+	// its usage may be discarded.
+	Reserve(key string, window, end time.Time, limits QuotaUsage) (QuotaUsage, bool, error)
+
+	// Add adds usage to the usage of key in the window starting at window.
+	Add(key string, window time.Time, usage QuotaUsage) error
+}
+
+// QuotaWindow returns the start and end of the billing window containing t.
+type QuotaWindow func(t time.Time) (start, end time.Time)
+
+// DailyQuotaWindow is a QuotaWindow of calendar days in UTC.
+func DailyQuotaWindow(t time.Time) (start, end time.Time) {
+	t = t.UTC()
+	start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// MonthlyQuotaWindow is a QuotaWindow of calendar months in UTC.
+func MonthlyQuotaWindow(t time.Time) (start, end time.Time) {
+	t = t.UTC()
+	start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
+
+// QuotaConfig defines configuration options for the quota middleware.
+type QuotaConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper Skipper
+
+	// KeyFunc returns the key usage is metered against, typically an API
+	// key or account ID. Requests with an empty key are not metered.
+	// If nil, defaults to the X-API-Key request header.
+	KeyFunc func(c *Context) string
+
+	// MaxRequests is the number of requests allowed per window.
+	// Zero means no request limit; usage is still metered.
+	MaxRequests int64
+
+	// MaxBytes is the number of bytes allowed per window, counting request
+	// bodies read and response bodies written.
+	// Zero means no byte limit; usage is still metered.
+	MaxBytes int64
+
+	// Window defines the billing windows.
+	// If nil, defaults to DailyQuotaWindow.
+	Window QuotaWindow
+
+	// Handler is called when a key has exhausted its quota. It runs after
+	// the quota headers are set. If nil, a 429 Too Many Requests response
+	// is sent.
+	Handler HandlerFunc
+
+	// Store keeps the usage counters.
+	// If nil, defaults to a MemoryQuotaStore.
+	Store QuotaStore
+}
+
+// Quota returns a middleware that meters the requests and bytes of each
+// key per billing window and rejects requests with 429 Too Many Requests
+// once the key has exhausted its quota, for monetized APIs.
+//
+// Responses carry the X-Quota-Limit, X-Quota-Remaining and X-Quota-Reset
+// headers (in seconds) for the request quota, and X-Quota-Bytes-Limit and
+// X-Quota-Bytes-Remaining for the byte quota, as of the start of the
+// request. Requests are counted atomically before the handlers run, so
+// concurrent requests can't overshoot the request quota. Bytes are
+// recorded once the request completes, even if a handler panics, so
+// requests in flight may overshoot the byte quota. Rejected requests are
+// not counted. If the store fails, requests are allowed and not metered.
+//
+// Example:
+//
+//	api := app.Route("/api")
+//	api.Use(goxpress.Quota(goxpress.QuotaConfig{
+//		MaxRequests: 10000,
+//		MaxBytes:    1 << 30,
+//		Window:      goxpress.MonthlyQuotaWindow,
+//		Store:       billingStore,
+//	}))
+func Quota(config QuotaConfig) HandlerFunc {
+	// Set defaults
+	if config.KeyFunc == nil {
+		config.KeyFunc = func(c *Context) string { return c.Request.Header.Get("X-API-Key") }
+	}
+	if config.Window == nil {
+		config.Window = DailyQuotaWindow
+	}
+	if config.Handler == nil {
+		config.Handler = func(c *Context) {
+			c.String(http.StatusTooManyRequests, "Too Many Requests")
+		}
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryQuotaStore()
+	}
+
+	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		key := config.KeyFunc(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		now := time.Now()
+		window, end := config.Window(now)
+		usage, ok, err := config.Store.Reserve(key, window, end, QuotaUsage{
+			Requests: config.MaxRequests,
+			Bytes:    config.MaxBytes,
+		})
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		header := c.Response.Header()
+		if config.MaxRequests > 0 {
+			remaining := config.MaxRequests - usage.Requests - 1 // Count this request
+			if remaining < 0 || !ok {
+				remaining = 0
+			}
+			header.Set("X-Quota-Limit", strconv.FormatInt(config.MaxRequests, 10))
+			header.Set("X-Quota-Remaining", strconv.FormatInt(remaining, 10))
+		}
+		if config.MaxBytes > 0 {
+			remaining := config.MaxBytes - usage.Bytes
+			if remaining < 0 {
+				remaining = 0
+			}
+			header.Set("X-Quota-Bytes-Limit", strconv.FormatInt(config.MaxBytes, 10))
+			header.Set("X-Quota-Bytes-Remaining", strconv.FormatInt(remaining, 10))
+		}
+		if config.MaxRequests > 0 || config.MaxBytes > 0 {
+			header.Set("X-Quota-Reset", strconv.Itoa(ceilSeconds(end.Sub(now))))
+		}
+
+		if !ok {
+			c.Abort()
+			config.Handler(c)
+			return
+		}
+
+		var body *countingBody
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			body = &countingBody{ReadCloser: c.Request.Body}
+			c.Request.Body = body
+		}
+		defer func() {
+			used := QuotaUsage{Bytes: int64(c.Writer.Size())}
+			if body != nil {
+				used.Bytes += body.n
+			}
+			if used.Bytes > 0 {
+				config.Store.Add(key, window, used)
+			}
+		}()
+
+		c.Next()
+	}
+}
+
+// MemoryQuotaStore is a QuotaStore that keeps usage counters in process
+// memory. Only the current window of each key is kept, and the usage of
+// ended windows is purged at most once a minute.
+type MemoryQuotaStore struct {
+	mu        sync.Mutex
+	usage     map[string]*windowUsage
+	lastSweep time.Time
+}
+
+// windowUsage holds the usage of a key in one window.
+type windowUsage struct {
+	window time.Time
+	end    time.Time
+	usage  QuotaUsage
+}
+
+// NewMemoryQuotaStore creates an empty in-memory quota store.
+func NewMemoryQuotaStore() *MemoryQuotaStore {
+	return &MemoryQuotaStore{usage: make(map[string]*windowUsage)}
+}
+
+// Usage returns the usage of key in window.
+func (s *MemoryQuotaStore) Usage(key string, window time.Time) (QuotaUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u, ok := s.usage[key]; ok && u.window.Equal(window) {
+		return u.usage, nil
+	}
+	return QuotaUsage{}, nil
+}
+
+// Reserve implements QuotaStore, discarding the usage of earlier windows
+// of key.
+func (s *MemoryQuotaStore) Reserve(key string, window, end time.Time, limits QuotaUsage) (QuotaUsage, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= time.Minute {
+		s.lastSweep = now
+		for k, u := range s.usage {
+			if !now.Before(u.end) {
+				delete(s.usage, k)
+			}
+		}
+	}
+
+	u, ok := s.usage[key]
+	if !ok || !u.window.Equal(window) {
+		u = &windowUsage{window: window, end: end}
+		s.usage[key] = u
+	}
+	usage := u.usage
+	if (limits.Requests > 0 && usage.Requests >= limits.Requests) ||
+		(limits.Bytes > 0 && usage.Bytes >= limits.Bytes) {
+		return usage, false, nil
+	}
+	u.usage.Requests++
+	return usage, true, nil
+}
+
+// Add implements QuotaStore. Usage of a window that is no longer the
+// current one of key is discarded.
+func (s *MemoryQuotaStore) Add(key string, window time.Time, usage QuotaUsage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u, ok := s.usage[key]; ok && u.window.Equal(window) {
+		u.usage.Requests += usage.Requests
+		u.usage.Bytes += usage.Bytes
+	}
+	return nil
+}
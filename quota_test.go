@@ -0,0 +1,137 @@
+package goxpress
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQuota(t *testing.T) {
+	store := NewMemoryQuotaStore()
+
+	app := New()
+	app.Use(Quota(QuotaConfig{MaxRequests: 2, MaxBytes: 100, Store: store}))
+	app.POST("/echo", func(c *Context) {
+		body, _ := ioutil.ReadAll(c.Request.Body)
+		c.String(200, string(body))
+	})
+
+	request := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/echo", strings.NewReader(body))
+		req.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	for i, remaining := range []string{"1", "0"} {
+		w := request("key-1", "hello")
+		if w.Code != 200 {
+			t.Fatalf("Request %d: expected status 200, got %d", i+1, w.Code)
+		}
+		if w.Header().Get("X-Quota-Limit") != "2" || w.Header().Get("X-Quota-Remaining") != remaining {
+			t.Errorf("Request %d: unexpected quota headers %v", i+1, w.Header())
+		}
+		if w.Header().Get("X-Quota-Reset") == "" {
+			t.Errorf("Request %d: expected X-Quota-Reset header", i+1)
+		}
+	}
+
+	w := request("key-1", "hello")
+	if w.Code != 429 || w.Header().Get("X-Quota-Remaining") != "0" {
+		t.Errorf("Expected 429 with no quota remaining, got %d %v", w.Code, w.Header())
+	}
+	if w.Header().Get("X-Quota-Bytes-Remaining") != "80" {
+		t.Errorf("Expected 80 bytes remaining, got '%s'", w.Header().Get("X-Quota-Bytes-Remaining"))
+	}
+
+	window, _ := DailyQuotaWindow(time.Now())
+	if usage, _ := store.Usage("key-1", window); usage != (QuotaUsage{Requests: 2, Bytes: 20}) {
+		t.Errorf("Unexpected usage %+v", usage)
+	}
+
+	if w := request("key-2", strings.Repeat("x", 100)); w.Code != 200 {
+		t.Errorf("Other keys should have their own quota, got %d", w.Code)
+	}
+	if w := request("key-2", "x"); w.Code != 429 {
+		t.Errorf("Expected 429 once the byte quota is exhausted, got %d", w.Code)
+	}
+}
+
+func TestQuotaWindows(t *testing.T) {
+	at := time.Date(2024, 2, 29, 15, 30, 0, 0, time.UTC)
+
+	start, end := DailyQuotaWindow(at)
+	if !start.Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected daily window %v - %v", start, end)
+	}
+	start, end = MonthlyQuotaWindow(at)
+	if !start.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected monthly window %v - %v", start, end)
+	}
+}
+
+func TestQuotaConcurrentRequests(t *testing.T) {
+	app := New()
+	app.Use(Quota(QuotaConfig{MaxRequests: 5}))
+	app.GET("/", func(c *Context) {
+		c.String(200, "ok")
+	})
+
+	var wg sync.WaitGroup
+	var allowed int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("X-API-Key", "key")
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+			if w.Code == 200 {
+				atomic.AddInt32(&allowed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed != 5 {
+		t.Errorf("Expected exactly 5 requests allowed, got %d", allowed)
+	}
+}
+
+func TestQuotaMetersPanics(t *testing.T) {
+	store := NewMemoryQuotaStore()
+	app := New()
+	app.Use(Recover())
+	app.Use(Quota(QuotaConfig{Store: store}))
+	app.POST("/", func(c *Context) {
+		ioutil.ReadAll(c.Request.Body)
+		panic("boom")
+	})
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader("hello"))
+	req.Header.Set("X-API-Key", "key")
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	window, _ := DailyQuotaWindow(time.Now())
+	if usage, _ := store.Usage("key", window); usage != (QuotaUsage{Requests: 1, Bytes: 5}) {
+		t.Errorf("Expected the panicking request to be metered, got %+v", usage)
+	}
+}
+
+func TestMemoryQuotaStoreSweep(t *testing.T) {
+	store := NewMemoryQuotaStore()
+	now := time.Now()
+	store.Reserve("old", now.Add(-2*time.Hour), now.Add(-time.Hour), QuotaUsage{})
+	store.Reserve("current", now.Add(-time.Hour), now.Add(time.Hour), QuotaUsage{})
+
+	store.lastSweep = time.Time{}
+	store.Reserve("current", now.Add(-time.Hour), now.Add(time.Hour), QuotaUsage{})
+	if _, ok := store.usage["old"]; ok || len(store.usage) != 1 {
+		t.Errorf("Expected ended windows to be purged, got %d entries", len(store.usage))
+	}
+}
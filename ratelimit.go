@@ -0,0 +1,121 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the rate limiting middleware.
+package goxpress
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitConfig defines configuration options for the rate limiting middleware.
+type RateLimitConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper Skipper
+
+	// Rate is the number of requests per second each key may sustain.
+	// If zero, defaults to 10.
+	Rate float64
+
+	// Burst is the number of requests a key may make at once before being
+	// limited to Rate. If zero, defaults to Rate rounded up.
+	Burst int
+
+	// KeyFunc returns the key requests are counted against.
+	// If nil, defaults to the client IP.
+	KeyFunc func(c *Context) string
+
+	// Handler is called when a request exceeds the limit. It runs after the
+	// RateLimit-* and Retry-After headers are set. If nil, a 429 Too Many
+	// Requests response is sent.
+	Handler HandlerFunc
+
+	// Store keeps the limiter state. Use a shared store such as
+	// RedisLimiterStore to enforce one limit across several instances;
+	// Rate and Burst should then match the store's settings, as they are
+	// reported in the RateLimit-* headers.
+	// If nil, defaults to a MemoryLimiterStore using Rate and Burst.
+	Store LimiterStore
+}
+
+// RateLimit returns a middleware that limits the request rate per key
+// using a token bucket: each key holds up to Burst tokens, refilled at Rate
+// tokens per second, and every request consumes one. Requests arriving at
+// an empty bucket are aborted with 429 Too Many Requests.
+//
+// Every response carries a RateLimit-Limit header, plus RateLimit-Remaining
+// and RateLimit-Reset when the store can report them, as the built-in
+// stores do; limited responses also carry Retry-After. By default buckets
+// are kept in memory, so limits apply per process.
+//
+// Example:
+//
+//	api := app.Route("/api")
+//	api.Use(goxpress.RateLimit(goxpress.RateLimitConfig{
+//		Rate:  5,
+//		Burst: 20,
+//		KeyFunc: func(c *goxpress.Context) string {
+//			return c.Request.Header.Get("X-API-Key")
+//		},
+//	}))
+func RateLimit(config RateLimitConfig) HandlerFunc {
+	// Set defaults
+	if config.Rate <= 0 {
+		config.Rate = 10
+	}
+	if config.Burst <= 0 {
+		config.Burst = int(math.Ceil(config.Rate))
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = func(c *Context) string { return c.ClientIP() }
+	}
+	if config.Handler == nil {
+		config.Handler = func(c *Context) {
+			c.String(http.StatusTooManyRequests, "Too Many Requests")
+		}
+	}
+
+	if config.Store == nil {
+		config.Store = NewMemoryLimiterStore(config.Rate, config.Burst)
+	}
+
+	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		key := config.KeyFunc(c)
+		header := c.Response.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(config.Burst))
+
+		var allowed bool
+		var retryAfter time.Duration
+		if store, ok := config.Store.(quotaLimiterStore); ok {
+			var remaining int
+			allowed, remaining, retryAfter = store.take(key)
+
+			// Time until the bucket is full again
+			reset := time.Duration(float64(config.Burst-remaining) / config.Rate * float64(time.Second))
+			header.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+			header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+		} else {
+			allowed, retryAfter = config.Store.Allow(key)
+		}
+
+		if !allowed {
+			header.Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+			c.Abort()
+			config.Handler(c)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ceilSeconds rounds d up to whole seconds.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
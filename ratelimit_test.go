@@ -0,0 +1,100 @@
+package goxpress
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRateLimit(t *testing.T) {
+	app := New()
+	app.Use(RateLimit(RateLimitConfig{Rate: 1, Burst: 2}))
+	app.GET("/", func(c *Context) {
+		c.String(200, "ok")
+	})
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	for i, remaining := range []string{"1", "0"} {
+		w := request("10.0.0.1:1234")
+		if w.Code != 200 {
+			t.Fatalf("Request %d: expected status 200, got %d", i+1, w.Code)
+		}
+		if w.Header().Get("RateLimit-Limit") != "2" {
+			t.Errorf("Expected RateLimit-Limit 2, got '%s'", w.Header().Get("RateLimit-Limit"))
+		}
+		if w.Header().Get("RateLimit-Remaining") != remaining {
+			t.Errorf("Expected RateLimit-Remaining %s, got '%s'", remaining, w.Header().Get("RateLimit-Remaining"))
+		}
+	}
+
+	w := request("10.0.0.1:5678")
+	if w.Code != 429 {
+		t.Fatalf("Expected status 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "1" {
+		t.Errorf("Expected Retry-After 1, got '%s'", w.Header().Get("Retry-After"))
+	}
+	if w.Header().Get("RateLimit-Reset") != "2" {
+		t.Errorf("Expected RateLimit-Reset 2, got '%s'", w.Header().Get("RateLimit-Reset"))
+	}
+
+	if w := request("10.0.0.2:1234"); w.Code != 200 {
+		t.Errorf("Other clients should not be limited, got %d", w.Code)
+	}
+}
+
+func TestRateLimitCustomHandler(t *testing.T) {
+	app := New()
+	app.Use(RateLimit(RateLimitConfig{
+		Rate:    1,
+		Burst:   1,
+		KeyFunc: func(c *Context) string { return c.Request.Header.Get("X-API-Key") },
+		Handler: func(c *Context) {
+			c.JSON(429, map[string]string{"error": "slow down"})
+		},
+	}))
+	app.GET("/", func(c *Context) {
+		c.String(200, "ok")
+	})
+
+	for i, expected := range []int{200, 429} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-API-Key", "key")
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+
+		if w.Code != expected {
+			t.Errorf("Request %d: expected status %d, got %d", i+1, expected, w.Code)
+		}
+		if expected == 429 && w.Body.String() != "{\"error\":\"slow down\"}\n" {
+			t.Errorf("Unexpected body %q", w.Body.String())
+		}
+	}
+}
+
+func TestRateLimitCustomStore(t *testing.T) {
+	app := New()
+	app.Use(RateLimit(RateLimitConfig{Store: denyStore{}}))
+	app.GET("/", func(c *Context) {
+		c.String(200, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != 429 {
+		t.Fatalf("Expected status 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "3" {
+		t.Errorf("Expected Retry-After 3, got '%s'", w.Header().Get("Retry-After"))
+	}
+	if w.Header().Get("RateLimit-Remaining") != "" {
+		t.Error("RateLimit-Remaining should be omitted for stores without quota information")
+	}
+}
@@ -0,0 +1,292 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the request recording middleware and the replay
+// utility.
+package goxpress
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RecordedRequest is a request captured by the Record middleware.
+type RecordedRequest struct {
+	Time      time.Time   `json:"time"`
+	Method    string      `json:"method"`
+	URL       string      `json:"url"` // Request URI, e.g. "/users?page=2"
+	Host      string      `json:"host"`
+	Header    http.Header `json:"header"`
+	Body      []byte      `json:"body,omitempty"`
+	Truncated bool        `json:"truncated,omitempty"` // Whether Body was cut at MaxBodySize
+	Status    int         `json:"status"`              // Status of the original response
+}
+
+// RecordSink receives recorded requests. Implementations must be safe for
+// concurrent use.
+type RecordSink interface {
+	Record(r RecordedRequest) error
+}
+
+// RecordSource yields recorded requests for Replay. Next returns io.EOF
+// once all requests have been read.
+type RecordSource interface {
+	Next() (RecordedRequest, error)
+}
+
+// RecordConfig defines configuration options for the record middleware.
+type RecordConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper Skipper
+
+	// Sink receives the recorded requests. Errors returned by the sink
+	// are ignored so recording never affects the request. Required.
+	Sink RecordSink
+
+	// Rate records one of every Rate requests. Values below 2 record
+	// every request.
+	Rate int
+
+	// MaxBodySize is the maximum number of body bytes recorded per
+	// request. If zero, defaults to 1MB.
+	MaxBodySize int
+
+	// RedactHeaders lists headers whose values are replaced with
+	// "[REDACTED]". If nil, defaults to Authorization,
+	// Proxy-Authorization and Cookie.
+	RedactHeaders []string
+
+	// Redact, if set, is called with each recorded request before it is
+	// sent to the sink, e.g. to mask personal data in the body.
+	Redact func(r *RecordedRequest)
+}
+
+// Record returns a middleware that captures requests, including their
+// headers and body, and sends them to a sink once they complete, so
+// production traffic can later be replayed with Replay, e.g. for
+// regression testing. Sensitive headers are redacted and bodies are
+// truncated at MaxBodySize. Requests whose handlers panic are recorded
+// with status 500 before the panic continues.
+//
+// Example:
+//
+//	file, _ := os.Create("traffic.jsonl")
+//	app.Use(goxpress.Record(goxpress.RecordConfig{
+//		Sink: goxpress.NewJSONRecordSink(file),
+//		Rate: 100,
+//		Redact: func(r *goxpress.RecordedRequest) {
+//			r.Body = passwordPattern.ReplaceAll(r.Body, []byte(`"password":"***"`))
+//		},
+//	}))
+func Record(config RecordConfig) HandlerFunc {
+	if config.Sink == nil {
+		panic("goxpress: Record requires a Sink")
+	}
+
+	// Set defaults
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = 1 << 20
+	}
+	if config.RedactHeaders == nil {
+		config.RedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+	}
+
+	var count uint64
+
+	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+		if config.Rate >= 2 && (atomic.AddUint64(&count, 1)-1)%uint64(config.Rate) != 0 {
+			c.Next()
+			return
+		}
+
+		record := RecordedRequest{
+			Time:   time.Now(),
+			Method: c.Request.Method,
+			URL:    c.Request.URL.RequestURI(),
+			Host:   c.Request.Host,
+			Header: c.Request.Header.Clone(),
+		}
+		for _, name := range config.RedactHeaders {
+			if _, ok := record.Header[http.CanonicalHeaderKey(name)]; ok {
+				record.Header.Set(name, "[REDACTED]")
+			}
+		}
+
+		// Capture the body as the handlers read it, with one extra byte
+		// to detect truncation
+		var body *capturedBody
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			body = &capturedBody{ReadCloser: c.Request.Body, limit: config.MaxBodySize + 1}
+			c.Request.Body = body
+		}
+
+		defer func() {
+			recovered := recover()
+
+			if body != nil {
+				// Read what the handlers left unread
+				if room := body.limit - len(body.data); room > 0 {
+					io.CopyN(ioutil.Discard, body, int64(room))
+				}
+				record.Body = body.data
+				if len(record.Body) > config.MaxBodySize {
+					record.Body = record.Body[:config.MaxBodySize]
+					record.Truncated = true
+				}
+			}
+
+			record.Status = c.Writer.Status()
+			switch {
+			case recovered != nil:
+				record.Status = http.StatusInternalServerError
+			case record.Status == 0:
+				record.Status = http.StatusOK // Nothing written, net/http sends 200
+			}
+
+			if config.Redact != nil {
+				config.Redact(&record)
+			}
+			config.Sink.Record(record)
+
+			if recovered != nil {
+				panic(recovered)
+			}
+		}()
+
+		c.Next()
+	}
+}
+
+// JSONRecordSink is a RecordSink writing each request as a line of JSON.
+type JSONRecordSink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSONRecordSink creates a sink writing JSON lines to w. Wrap w in an
+// AsyncWriter to keep slow outputs off the request path.
+func NewJSONRecordSink(w io.Writer) *JSONRecordSink {
+	return &JSONRecordSink{encoder: json.NewEncoder(w)}
+}
+
+// Record writes r as a line of JSON.
+func (s *JSONRecordSink) Record(r RecordedRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.encoder.Encode(r)
+}
+
+// JSONRecordSource is a RecordSource reading the JSON lines written by a
+// JSONRecordSink.
+type JSONRecordSource struct {
+	decoder *json.Decoder
+}
+
+// NewJSONRecordSource creates a source reading JSON lines from r.
+func NewJSONRecordSource(r io.Reader) *JSONRecordSource {
+	return &JSONRecordSource{decoder: json.NewDecoder(r)}
+}
+
+// Next returns the next recorded request, or io.EOF at the end of input.
+func (s *JSONRecordSource) Next() (RecordedRequest, error) {
+	var r RecordedRequest
+	err := s.decoder.Decode(&r)
+	return r, err
+}
+
+// ReplayResult is the outcome of replaying a recorded request.
+type ReplayResult struct {
+	Request RecordedRequest // Recorded request
+	Status  int             // Status of the replayed response
+	Header  http.Header     // Headers of the replayed response
+	Body    []byte          // Body of the replayed response
+}
+
+// StatusChanged reports whether the replayed response has a different
+// status than the recorded one.
+func (r ReplayResult) StatusChanged() bool {
+	return r.Status != r.Request.Status
+}
+
+// Replay re-issues every request of source against handler, typically an
+// Engine, and returns the responses. Requests are served in order, in
+// process, without a network round trip. Replay stops at the first error
+// returned by the source other than io.EOF.
+//
+// Example:
+//
+//	file, _ := os.Open("traffic.jsonl")
+//	results, err := goxpress.Replay(goxpress.NewJSONRecordSource(file), app)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, r := range results {
+//		if r.StatusChanged() {
+//			log.Printf("%s %s: %d, was %d", r.Request.Method, r.Request.URL, r.Status, r.Request.Status)
+//		}
+//	}
+func Replay(source RecordSource, handler http.Handler) ([]ReplayResult, error) {
+	var results []ReplayResult
+	for {
+		record, err := source.Next()
+		if err == io.EOF {
+			return results, nil
+		}
+		if err != nil {
+			return results, err
+		}
+
+		req, err := http.NewRequest(record.Method, record.URL, bytes.NewReader(record.Body))
+		if err != nil {
+			return results, err
+		}
+		req.RequestURI = record.URL
+		req.Host = record.Host
+		req.RemoteAddr = "192.0.2.1:1234" // Documentation address, as used by httptest
+		if record.Header != nil {
+			req.Header = record.Header.Clone()
+		}
+
+		w := &replayWriter{header: make(http.Header)}
+		handler.ServeHTTP(w, req)
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		results = append(results, ReplayResult{
+			Request: record,
+			Status:  w.status,
+			Header:  w.header,
+			Body:    w.body.Bytes(),
+		})
+	}
+}
+
+// replayWriter is the http.ResponseWriter buffering a replayed response.
+type replayWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *replayWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *replayWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *replayWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
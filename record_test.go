@@ -0,0 +1,99 @@
+package goxpress
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	var traffic bytes.Buffer
+
+	app := New()
+	app.Use(Record(RecordConfig{
+		Sink:        NewJSONRecordSink(&traffic),
+		MaxBodySize: 8,
+		Redact: func(r *RecordedRequest) {
+			r.Header.Del("X-Internal")
+		},
+	}))
+	app.POST("/users", func(c *Context) {
+		body, _ := ioutil.ReadAll(c.Request.Body)
+		c.String(201, "created "+string(body))
+	})
+	app.GET("/users/:id", func(c *Context) {
+		c.String(200, "user "+c.Param("id"))
+	})
+
+	req := httptest.NewRequest("POST", "/users", strings.NewReader("name=john"))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Internal", "1")
+	app.ServeHTTP(httptest.NewRecorder(), req)
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/7?full=1", nil))
+
+	source := NewJSONRecordSource(bytes.NewReader(traffic.Bytes()))
+	first, err := source.Next()
+	if err != nil {
+		t.Fatalf("Next should not return error: %v", err)
+	}
+	if first.Method != "POST" || first.URL != "/users" || first.Status != 201 {
+		t.Errorf("Unexpected recorded request %+v", first)
+	}
+	if string(first.Body) != "name=joh" || !first.Truncated {
+		t.Errorf("Expected truncated body, got %q truncated=%v", first.Body, first.Truncated)
+	}
+	if first.Header.Get("Authorization") != "[REDACTED]" || first.Header.Get("X-Internal") != "" {
+		t.Errorf("Expected redacted headers, got %v", first.Header)
+	}
+
+	// Replay against a changed version of the application
+	changed := New()
+	changed.POST("/users", func(c *Context) {
+		body, _ := ioutil.ReadAll(c.Request.Body)
+		c.String(201, "created "+string(body))
+	})
+	changed.GET("/users/:id", func(c *Context) {
+		c.String(404, "not found")
+	})
+
+	results, err := Replay(NewJSONRecordSource(bytes.NewReader(traffic.Bytes())), changed)
+	if err != nil {
+		t.Fatalf("Replay should not return error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].StatusChanged() || string(results[0].Body) != "created name=joh" {
+		t.Errorf("Unexpected first result %d %q", results[0].Status, results[0].Body)
+	}
+	if !results[1].StatusChanged() || results[1].Request.URL != "/users/7?full=1" {
+		t.Errorf("Expected status change for %s, got %d", results[1].Request.URL, results[1].Status)
+	}
+}
+
+func TestRecordPanic(t *testing.T) {
+	var traffic bytes.Buffer
+
+	app := New()
+	app.Use(Recover())
+	app.Use(Record(RecordConfig{Sink: NewJSONRecordSink(&traffic)}))
+	app.POST("/crash", func(c *Context) {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/crash", strings.NewReader("payload")))
+	if w.Code != 500 {
+		t.Errorf("Expected the panic to reach Recover, got %d", w.Code)
+	}
+
+	record, err := NewJSONRecordSource(&traffic).Next()
+	if err != nil {
+		t.Fatalf("Expected the panicking request to be recorded: %v", err)
+	}
+	if record.Status != 500 || string(record.Body) != "payload" {
+		t.Errorf("Unexpected recorded request %+v", record)
+	}
+}
@@ -0,0 +1,337 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains response rendering helpers for formats beyond the
+// JSON, String and HTML helpers of the Context.
+package goxpress
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// YAMLCodec is the Codec used by the YAML response helper. The default
+// codec renders values through their JSON representation, honoring `json`
+// struct tags, as block-style YAML. It cannot decode; assign a codec backed
+// by a full YAML library such as gopkg.in/yaml.v3 if needed.
+var YAMLCodec Codec = jsonYAMLCodec{}
+
+// SecureJSONPrefix is the prefix SecureJSON prepends to top-level JSON
+// arrays. Clients must strip it before parsing the response.
+var SecureJSONPrefix = "while(1);"
+
+// XML serializes the given data to XML and writes it to the response
+// with the specified status code. It automatically sets the Content-Type
+// header to "application/xml; charset=utf-8".
+//
+// Example:
+//
+//	type User struct {
+//		XMLName xml.Name `xml:"user"`
+//		Name    string   `xml:"name"`
+//	}
+//	c.XML(200, User{Name: "John"})
+func (c *Context) XML(code int, data interface{}) error {
+	c.writeContentType(code, "application/xml; charset=utf-8")
+	return xml.NewEncoder(c.Response).Encode(data)
+}
+
+// YAML serializes the given data to YAML using YAMLCodec and writes it to
+// the response with the specified status code. It automatically sets the
+// Content-Type header to "application/yaml; charset=utf-8".
+//
+// Example:
+//
+//	app.GET("/debug/config", func(c *goxpress.Context) {
+//		c.YAML(200, config)
+//	})
+func (c *Context) YAML(code int, data interface{}) error {
+	out, err := YAMLCodec.Marshal(data)
+	if err != nil {
+		return err
+	}
+	c.writeContentType(code, "application/yaml; charset=utf-8")
+	_, err = c.Response.Write(out)
+	return err
+}
+
+// IndentedJSON serializes the given data to human-readable, indented JSON
+// and writes it to the response with the specified status code. It is
+// meant for development and debugging endpoints; prefer JSON in production
+// as indentation increases the response size.
+//
+// Example:
+//
+//	c.IndentedJSON(200, map[string]interface{}{"status": "ok", "uptime": uptime})
+func (c *Context) IndentedJSON(code int, data interface{}) error {
+	c.writeContentType(code, "application/json")
+	encoder := json.NewEncoder(c.Response)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(data)
+}
+
+// SecureJSON serializes the given data to JSON like JSON, but prepends
+// SecureJSONPrefix when the top-level value is an array. This prevents the
+// response from being evaluated as a script by a third-party page, which
+// protects against JSON hijacking in old browsers.
+//
+// Example:
+//
+//	c.SecureJSON(200, []string{"alice", "bob"})
+//	// Response: while(1);["alice","bob"]
+func (c *Context) SecureJSON(code int, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	c.writeContentType(code, "application/json")
+	if len(payload) > 0 && payload[0] == '[' {
+		if _, err := c.Response.Write([]byte(SecureJSONPrefix)); err != nil {
+			return err
+		}
+	}
+	_, err = c.Response.Write(payload)
+	return err
+}
+
+// PureJSON serializes the given data to JSON like JSON, but without
+// escaping the HTML characters <, > and & as unicode sequences, so payloads
+// round-trip literally for non-browser consumers.
+//
+// Example:
+//
+//	c.PureJSON(200, map[string]string{"html": "<b>Hello</b>"})
+//	// Response: {"html":"<b>Hello</b>"}
+func (c *Context) PureJSON(code int, data interface{}) error {
+	c.writeContentType(code, "application/json")
+	encoder := json.NewEncoder(c.Response)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(data)
+}
+
+// jsonpCallback matches callback names that are safe to echo into a
+// JavaScript response: dotted identifiers with optional index access.
+var jsonpCallback = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*(?:(?:\.[A-Za-z_$][A-Za-z0-9_$]*)|(?:\[[0-9]+\]))*$`)
+
+// JSONP serializes the given data to JSON and wraps it in a call to the
+// function named by the "callback" query parameter, for legacy cross-domain
+// consumers. The Content-Type header is set to
+// "application/javascript; charset=utf-8". If the callback parameter is
+// missing or is not a valid JavaScript identifier path, a plain JSON
+// response is written instead.
+//
+// Example:
+//
+//	// Request: "/users?callback=handleUsers"
+//	c.JSONP(200, users)
+//	// Response: /**/handleUsers([...]);
+func (c *Context) JSONP(code int, data interface{}) error {
+	callback := c.Query("callback")
+	if !jsonpCallback.MatchString(callback) {
+		return c.JSON(code, data)
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	c.writeContentType(code, "application/javascript; charset=utf-8")
+
+	// The leading comment prevents content-sniffing attacks that abuse
+	// attacker-controlled callback names
+	var buf bytes.Buffer
+	buf.Grow(len(callback) + len(payload) + 8)
+	buf.WriteString("/**/")
+	buf.WriteString(callback)
+	buf.WriteByte('(')
+	buf.Write(payload)
+	buf.WriteString(");")
+	_, err = c.Response.Write(buf.Bytes())
+	return err
+}
+
+// Data writes raw bytes to the response with the specified status code and
+// Content-Type. Use it for images, PDFs, protobuf blobs and other binary
+// payloads.
+//
+// Example:
+//
+//	png, _ := renderChart()
+//	c.Data(200, "image/png", png)
+func (c *Context) Data(code int, contentType string, data []byte) error {
+	c.writeContentType(code, contentType)
+	_, err := c.Response.Write(data)
+	return err
+}
+
+// Protobuf encodes msg as a Protocol Buffers message using ProtobufCodec
+// and writes it to the response with the specified status code. It
+// automatically sets the Content-Type header to "application/x-protobuf".
+//
+// Example:
+//
+//	c.Protobuf(200, &pb.User{Id: 42, Name: "John"})
+func (c *Context) Protobuf(code int, msg interface{}) error {
+	return c.encodeWith(code, MIMEProtobuf, ProtobufCodec, msg)
+}
+
+// MsgPack encodes data as MessagePack using MsgPackCodec and writes it to
+// the response with the specified status code. It automatically sets the
+// Content-Type header to "application/msgpack".
+//
+// Example:
+//
+//	if c.NegotiateFormat(goxpress.MIMEJSON, goxpress.MIMEMsgPack) == goxpress.MIMEMsgPack {
+//		c.MsgPack(200, quotes)
+//		return
+//	}
+//	c.JSON(200, quotes)
+func (c *Context) MsgPack(code int, data interface{}) error {
+	return c.encodeWith(code, MIMEMsgPack, MsgPackCodec, data)
+}
+
+// writeContentType sets the Content-Type header and writes the status code,
+// unless a status code has already been written for this response.
+func (c *Context) writeContentType(code int, contentType string) {
+	if !c.statusCodeWritten {
+		c.Response.Header().Set("Content-Type", contentType)
+		c.Response.WriteHeader(code)
+		c.statusCodeWritten = true
+	}
+}
+
+// jsonYAMLCodec encodes values as YAML by way of their JSON representation.
+type jsonYAMLCodec struct{}
+
+// Marshal encodes v as a block-style YAML document.
+func (jsonYAMLCodec) Marshal(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeYAML(&buf, generic, 0)
+	return buf.Bytes(), nil
+}
+
+// Unmarshal is not supported by the built-in YAML codec.
+func (jsonYAMLCodec) Unmarshal(data []byte, v interface{}) error {
+	return errors.New("goxpress: YAML decoding requires a configured YAMLCodec")
+}
+
+// writeYAML writes v to buf as YAML lines indented by indent spaces.
+// v must be a value produced by decoding JSON into an interface{}.
+func writeYAML(buf *bytes.Buffer, v interface{}, indent int) {
+	pad := strings.Repeat(" ", indent)
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if len(value) == 0 {
+			buf.WriteString(pad + "{}\n")
+			return
+		}
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			buf.WriteString(pad + yamlScalar(k) + ":")
+			writeYAMLChild(buf, value[k], indent)
+		}
+	case []interface{}:
+		if len(value) == 0 {
+			buf.WriteString(pad + "[]\n")
+			return
+		}
+		for _, item := range value {
+			if isYAMLCollection(item) {
+				// Render the item one level deeper and hoist its first
+				// line onto the "- " marker
+				var nested bytes.Buffer
+				writeYAML(&nested, item, indent+2)
+				buf.WriteString(pad + "- ")
+				buf.Write(nested.Bytes()[indent+2:])
+				continue
+			}
+			buf.WriteString(pad + "- " + yamlScalar(item) + "\n")
+		}
+	default:
+		buf.WriteString(pad + yamlScalar(value) + "\n")
+	}
+}
+
+// writeYAMLChild writes the value of a mapping entry whose key has already
+// been written at the given indentation.
+func writeYAMLChild(buf *bytes.Buffer, v interface{}, indent int) {
+	if isYAMLCollection(v) {
+		buf.WriteString("\n")
+		writeYAML(buf, v, indent+2)
+		return
+	}
+	switch value := v.(type) {
+	case map[string]interface{}:
+		buf.WriteString(" {}\n")
+	case []interface{}:
+		buf.WriteString(" []\n")
+	default:
+		buf.WriteString(" " + yamlScalar(value) + "\n")
+	}
+}
+
+// isYAMLCollection reports whether v is a non-empty mapping or sequence.
+func isYAMLCollection(v interface{}) bool {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		return len(value) > 0
+	case []interface{}:
+		return len(value) > 0
+	}
+	return false
+}
+
+// yamlPlainString matches strings that can be written without quotes.
+var yamlPlainString = regexp.MustCompile(`^[A-Za-z_/][A-Za-z0-9_ ./()-]*$`)
+
+// yamlReserved lists plain scalars YAML parsers interpret as non-strings.
+var yamlReserved = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true, "on": true,
+	"off": true, "y": true, "n": true, "null": true, "~": true,
+}
+
+// yamlScalar formats a scalar JSON value as a YAML scalar, quoting strings
+// that would otherwise be read back as a different type.
+func yamlScalar(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(value)
+	case json.Number:
+		return value.String()
+	case string:
+		if yamlPlainString.MatchString(value) &&
+			!strings.HasSuffix(value, " ") &&
+			!yamlReserved[strings.ToLower(value)] {
+			return value
+		}
+		return strconv.Quote(value)
+	}
+	return strconv.Quote(fmt.Sprint(v))
+}
@@ -0,0 +1,272 @@
+package goxpress
+
+import (
+	"encoding/xml"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContextXML(t *testing.T) {
+	type user struct {
+		XMLName xml.Name `xml:"user"`
+		Name    string   `xml:"name"`
+	}
+
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+	if err := c.XML(201, user{Name: "John"}); err != nil {
+		t.Fatalf("XML should not return error: %v", err)
+	}
+
+	if w.Code != 201 {
+		t.Errorf("Expected status code 201, got %d", w.Code)
+	}
+
+	contentType := w.Header().Get("Content-Type")
+	if contentType != "application/xml; charset=utf-8" {
+		t.Errorf("Expected Content-Type 'application/xml; charset=utf-8', got '%s'", contentType)
+	}
+
+	if w.Body.String() != "<user><name>John</name></user>" {
+		t.Errorf("Unexpected body '%s'", w.Body.String())
+	}
+}
+
+func TestContextYAML(t *testing.T) {
+	type server struct {
+		Host string   `json:"host"`
+		Port int      `json:"port"`
+		Tags []string `json:"tags"`
+	}
+
+	data := map[string]interface{}{
+		"name":    "api",
+		"debug":   true,
+		"version": "1.0",
+		"enabled": "yes",
+		"empty":   []string{},
+		"servers": []server{{Host: "a.example.com", Port: 80, Tags: []string{"edge"}}},
+		"limits":  map[string]interface{}{"rps": 100, "note": "x: y"},
+	}
+
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+	if err := c.YAML(200, data); err != nil {
+		t.Fatalf("YAML should not return error: %v", err)
+	}
+
+	contentType := w.Header().Get("Content-Type")
+	if contentType != "application/yaml; charset=utf-8" {
+		t.Errorf("Expected Content-Type 'application/yaml; charset=utf-8', got '%s'", contentType)
+	}
+
+	expected := `debug: true
+empty: []
+enabled: "yes"
+limits:
+  note: "x: y"
+  rps: 100
+name: api
+servers:
+  - host: a.example.com
+    port: 80
+    tags:
+      - edge
+version: "1.0"
+`
+	if w.Body.String() != expected {
+		t.Errorf("Unexpected YAML output:\n%s", w.Body.String())
+	}
+
+	t.Run("CustomCodec", func(t *testing.T) {
+		defer func(codec Codec) { YAMLCodec = codec }(YAMLCodec)
+		YAMLCodec = upperCodec{}
+
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+		c.YAML(200, &testMessage{Payload: "custom"})
+
+		if w.Body.String() != "custom" {
+			t.Errorf("Expected custom codec output, got '%s'", w.Body.String())
+		}
+	})
+}
+
+func TestContextJSONP(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		body        string
+	}{
+		{"Callback", "?callback=handle", "application/javascript; charset=utf-8", `/**/handle({"html":"\u003cb\u003e"});`},
+		{"DottedCallback", "?callback=app.cbs[0]", "application/javascript; charset=utf-8", `/**/app.cbs[0]({"html":"\u003cb\u003e"});`},
+		{"NoCallback", "", "application/json", "{\"html\":\"\\u003cb\\u003e\"}\n"},
+		{"UnsafeCallback", "?callback=alert(1)//", "application/json", "{\"html\":\"\\u003cb\\u003e\"}\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c := NewContext(w, httptest.NewRequest("GET", "/data"+test.query, nil))
+
+			if err := c.JSONP(200, map[string]string{"html": "<b>"}); err != nil {
+				t.Fatalf("JSONP should not return error: %v", err)
+			}
+
+			if ct := w.Header().Get("Content-Type"); ct != test.contentType {
+				t.Errorf("Expected Content-Type '%s', got '%s'", test.contentType, ct)
+			}
+			if w.Body.String() != test.body {
+				t.Errorf("Expected body %q, got %q", test.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestContextIndentedJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+	if err := c.IndentedJSON(200, map[string]interface{}{"user": map[string]string{"name": "John"}}); err != nil {
+		t.Fatalf("IndentedJSON should not return error: %v", err)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected Content-Type 'application/json', got '%s'", ct)
+	}
+
+	expected := "{\n    \"user\": {\n        \"name\": \"John\"\n    }\n}\n"
+	if w.Body.String() != expected {
+		t.Errorf("Expected body %q, got %q", expected, w.Body.String())
+	}
+}
+
+func TestContextSecureJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+		body string
+	}{
+		{"Array", []string{"alice", "bob"}, `while(1);["alice","bob"]`},
+		{"Object", map[string]int{"count": 2}, `{"count":2}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+			if err := c.SecureJSON(200, test.data); err != nil {
+				t.Fatalf("SecureJSON should not return error: %v", err)
+			}
+			if w.Body.String() != test.body {
+				t.Errorf("Expected body %q, got %q", test.body, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("CustomPrefix", func(t *testing.T) {
+		defer func(prefix string) { SecureJSONPrefix = prefix }(SecureJSONPrefix)
+		SecureJSONPrefix = ")]}',\n"
+
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+		c.SecureJSON(200, []int{1})
+
+		if w.Body.String() != ")]}',\n[1]" {
+			t.Errorf("Unexpected body %q", w.Body.String())
+		}
+	})
+}
+
+func TestContextPureJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+	if err := c.PureJSON(200, map[string]string{"html": "<b>Tom & Jerry</b>"}); err != nil {
+		t.Fatalf("PureJSON should not return error: %v", err)
+	}
+
+	expected := "{\"html\":\"<b>Tom & Jerry</b>\"}\n"
+	if w.Body.String() != expected {
+		t.Errorf("Expected body %q, got %q", expected, w.Body.String())
+	}
+}
+
+func TestContextData(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+	payload := []byte{0x89, 'P', 'N', 'G'}
+	if err := c.Data(200, "image/png", payload); err != nil {
+		t.Fatalf("Data should not return error: %v", err)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Expected Content-Type 'image/png', got '%s'", ct)
+	}
+	if w.Body.String() != string(payload) {
+		t.Errorf("Expected raw payload, got %q", w.Body.String())
+	}
+}
+
+func TestContextProtobuf(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+	if err := c.Protobuf(200, &testMessage{Payload: "\x08\x2a"}); err != nil {
+		t.Fatalf("Protobuf should not return error: %v", err)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-protobuf" {
+		t.Errorf("Expected Content-Type 'application/x-protobuf', got '%s'", ct)
+	}
+	if w.Body.String() != "\x08\x2a" {
+		t.Errorf("Unexpected body %q", w.Body.String())
+	}
+
+	t.Run("EncodeError", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+		if err := c.Protobuf(200, struct{}{}); err == nil {
+			t.Error("Protobuf should fail for values without a codec")
+		}
+		if w.Body.Len() != 0 || c.Writer.Written() {
+			t.Error("Nothing should be written when encoding fails")
+		}
+	})
+}
+
+func TestContextMsgPack(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+	if err := c.MsgPack(200, &testMessage{Payload: "\x81\xa1a\x01"}); err != nil {
+		t.Fatalf("MsgPack should not return error: %v", err)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/msgpack" {
+		t.Errorf("Expected Content-Type 'application/msgpack', got '%s'", ct)
+	}
+	if w.Body.String() != "\x81\xa1a\x01" {
+		t.Errorf("Unexpected body %q", w.Body.String())
+	}
+
+	t.Run("Negotiated", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", "application/msgpack")
+		w := httptest.NewRecorder()
+		c := NewContext(w, req)
+
+		c.Negotiate(200, NegotiateConfig{
+			Offered: []string{MIMEJSON, MIMEMsgPack},
+			Data:    &testMessage{Payload: "packed"},
+		})
+
+		if w.Body.String() != "packed" {
+			t.Errorf("Expected msgpack representation, got %q", w.Body.String())
+		}
+	})
+}
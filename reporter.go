@@ -0,0 +1,94 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the error reporting hook.
+package goxpress
+
+import (
+	"net/http"
+)
+
+// ErrorReport describes an error or recovered panic for error tracking
+// services.
+type ErrorReport struct {
+	Err       error       // Reported error, the converted value for panics
+	Panic     bool        // Whether the error comes from a recovered panic
+	Stack     []byte      // Stack trace of the panic, nil for errors or if disabled
+	Method    string      // Request method
+	URL       string      // Request URI
+	Route     string      // Matched route pattern, empty for unmatched requests
+	ClientIP  string      // Address of the connected client
+	RequestID string      // Request ID, empty if there is none
+	Header    http.Header // Request headers without Authorization, Proxy-Authorization and Cookie
+}
+
+// ErrorReporter receives the panics recovered by Recover and the errors
+// passed to the error handlers, so error tracking services such as Sentry
+// or Bugsnag can be integrated with a small adapter. Each error is
+// reported once per request. Report runs on the goroutine serving the
+// request, so implementations should send reports asynchronously.
+//
+// Example:
+//
+//	type sentryReporter struct{}
+//
+//	func (sentryReporter) Report(c *goxpress.Context, r goxpress.ErrorReport) {
+//		hub := sentry.CurrentHub().Clone()
+//		hub.Scope().SetTag("route", r.Route)
+//		hub.Scope().SetRequest(c.Request)
+//		hub.CaptureException(r.Err)
+//	}
+//
+//	app.SetErrorReporter(sentryReporter{})
+type ErrorReporter interface {
+	Report(c *Context, report ErrorReport)
+}
+
+// NopErrorReporter is an ErrorReporter that discards all reports.
+// It is the default reporter of an Engine.
+type NopErrorReporter struct{}
+
+// Report discards the report.
+func (NopErrorReporter) Report(c *Context, report ErrorReport) {}
+
+// SetErrorReporter sets the reporter notified of recovered panics and of
+// the errors passed to the error handlers. Passing nil restores the
+// default NopErrorReporter.
+// Returns the Engine instance for method chaining.
+//
+// Example:
+//
+//	app.SetErrorReporter(sentryReporter{})
+func (e *Engine) SetErrorReporter(reporter ErrorReporter) *Engine {
+	if reporter == nil {
+		reporter = NopErrorReporter{}
+	}
+	e.reporter = reporter
+	return e
+}
+
+// reportError sends err to the engine's error reporter, unless an error
+// was already reported for the request.
+func (c *Context) reportError(err error, panicked bool, stack []byte) {
+	if c.errorReported || c.engine == nil {
+		return
+	}
+	c.errorReported = true
+
+	header := make(http.Header, len(c.Request.Header))
+	for name, values := range c.Request.Header {
+		if !redactedHeaders[name] {
+			header[name] = values
+		}
+	}
+
+	c.engine.reporter.Report(c, ErrorReport{
+		Err:       err,
+		Panic:     panicked,
+		Stack:     stack,
+		Method:    c.Request.Method,
+		URL:       c.Request.URL.RequestURI(),
+		Route:     c.fullPath,
+		ClientIP:  c.ClientIP(),
+		RequestID: c.requestID(),
+		Header:    header,
+	})
+}
@@ -0,0 +1,68 @@
+package goxpress
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+)
+
+// recordingReporter keeps the reports it receives.
+type recordingReporter struct {
+	reports []ErrorReport
+}
+
+func (r *recordingReporter) Report(c *Context, report ErrorReport) {
+	r.reports = append(r.reports, report)
+}
+
+func TestErrorReporter(t *testing.T) {
+	reporter := &recordingReporter{}
+
+	app := New()
+	app.SetErrorReporter(reporter)
+	app.Use(RecoverWithConfig(RecoverConfig{Output: ioutil.Discard}))
+	app.GET("/panic/:id", func(c *Context) {
+		panic("boom")
+	})
+	app.GET("/error", func(c *Context) {
+		c.Next(errors.New("db down"))
+	})
+	app.UseError(func(err error, c *Context) {
+		c.String(500, err.Error())
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		reporter.reports = nil
+		req := httptest.NewRequest("GET", "/panic/7?x=1", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("X-Request-ID", "req-1")
+		app.ServeHTTP(httptest.NewRecorder(), req)
+
+		if len(reporter.reports) != 1 {
+			t.Fatalf("Expected the panic to be reported once, got %d reports", len(reporter.reports))
+		}
+		r := reporter.reports[0]
+		if !r.Panic || r.Err.Error() != "boom" || len(r.Stack) == 0 {
+			t.Errorf("Unexpected panic report %+v", r)
+		}
+		if r.Method != "GET" || r.URL != "/panic/7?x=1" || r.Route != "/panic/:id" || r.RequestID != "req-1" {
+			t.Errorf("Unexpected request metadata %+v", r)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Error("Authorization header should be redacted")
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		reporter.reports = nil
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/error", nil))
+
+		if len(reporter.reports) != 1 {
+			t.Fatalf("Expected the error to be reported once, got %d reports", len(reporter.reports))
+		}
+		if r := reporter.reports[0]; r.Panic || r.Err.Error() != "db down" || r.Stack != nil {
+			t.Errorf("Unexpected error report %+v", r)
+		}
+	})
+}
@@ -0,0 +1,170 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the ResponseWriter wrapper that tracks the state of
+// the response written by handlers.
+package goxpress
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// ResponseWriter extends http.ResponseWriter with information about the
+// response written so far. Every Context wraps the server's ResponseWriter
+// in one, exposed as both Context.Writer and Context.Response, so logging,
+// metrics and conditional middleware can observe the outcome of a request.
+//
+// The writer always implements http.Flusher, http.Hijacker and
+// http.Pusher, whether or not the server's ResponseWriter does, so type
+// assertions for them always succeed: Hijack fails over HTTP/2 and Push
+// over HTTP/1.x. Hijack and Push then return http.ErrNotSupported, and
+// Flush does nothing. Check the returned error, or use
+// http.ResponseController, instead of relying on the type assertion.
+type ResponseWriter interface {
+	http.ResponseWriter
+
+	// Status returns the HTTP status code written to the response,
+	// or 0 if nothing has been written yet.
+	Status() int
+
+	// Size returns the number of body bytes written to the response.
+	Size() int
+
+	// Written reports whether the status code has been written.
+	Written() bool
+}
+
+// responseWriter is the default ResponseWriter implementation.
+// It is embedded in Context to avoid an allocation per request.
+type responseWriter struct {
+	http.ResponseWriter
+	status int  // Status code written, 0 if not written yet
+	size   int  // Number of body bytes written
+	failed bool // Whether a write failed because the client is gone
+
+	// Called when a write fails because the client is gone
+	onFail func()
+}
+
+// Ensure responseWriter satisfies the ResponseWriter interface and passes
+// through the optional interfaces of net/http writers.
+var (
+	_ ResponseWriter = (*responseWriter)(nil)
+	_ http.Flusher   = (*responseWriter)(nil)
+	_ http.Hijacker  = (*responseWriter)(nil)
+	_ http.Pusher    = (*responseWriter)(nil)
+)
+
+// reset prepares the writer to wrap w for a new request.
+func (w *responseWriter) reset(rw http.ResponseWriter) {
+	w.ResponseWriter = rw
+	w.status = 0
+	w.size = 0
+	w.failed = false
+	w.onFail = nil
+}
+
+// WriteHeader sends the status code. Only the first call takes effect,
+// which avoids superfluous WriteHeader warnings from net/http.
+func (w *responseWriter) WriteHeader(code int) {
+	if w.Written() {
+		return
+	}
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write writes the body data, sending a 200 OK status first if no status
+// code has been written yet.
+func (w *responseWriter) Write(data []byte) (int, error) {
+	if !w.Written() {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(data)
+	w.size += n
+	if err != nil && !w.failed && clientGoneError(err) {
+		w.failed = true
+		if w.onFail != nil {
+			w.onFail()
+		}
+	}
+	return n, err
+}
+
+// clientGoneError reports whether a write error means the connection to
+// the client is broken, as opposed to the response not allowing a body.
+func clientGoneError(err error) bool {
+	switch err {
+	case http.ErrBodyNotAllowed, http.ErrHijacked, http.ErrContentLength, http.ErrHandlerTimeout:
+		return false
+	}
+	return true
+}
+
+// Status implements ResponseWriter.
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+// Size implements ResponseWriter.
+func (w *responseWriter) Size() int {
+	return w.size
+}
+
+// Written implements ResponseWriter.
+func (w *responseWriter) Written() bool {
+	return w.status != 0
+}
+
+// Flush sends any buffered data to the client if the underlying
+// ResponseWriter supports it, and does nothing otherwise. It implements
+// http.Flusher.
+func (w *responseWriter) Flush() {
+	if !w.Written() {
+		w.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection, as required for
+// WebSocket upgrades. It implements http.Hijacker and returns
+// http.ErrNotSupported if the underlying ResponseWriter cannot be hijacked.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(w.ResponseWriter, func() {
+		if !w.Written() {
+			w.status = http.StatusSwitchingProtocols
+		}
+	})
+}
+
+// Push initiates an HTTP/2 server push. It implements http.Pusher and
+// returns http.ErrNotSupported if the underlying ResponseWriter does not
+// support server push.
+func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := w.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// hijack hijacks the connection of rw if it supports it, calling
+// onHijack after a successful takeover.
+func hijack(rw http.ResponseWriter, onHijack func()) (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, buf, err := hijacker.Hijack()
+	if err == nil && onHijack != nil {
+		onHijack()
+	}
+	return conn, buf, err
+}
+
+// Unwrap returns the underlying ResponseWriter, for use by
+// http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,151 @@
+package goxpress
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// hijackRecorder is a ResponseRecorder that supports hijacking and
+// server push, like the writers of a real HTTP server.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+	pushed   []string
+}
+
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.hijacked = true
+	server, client := net.Pipe()
+	client.Close()
+	return server, bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server)), nil
+}
+
+func (r *hijackRecorder) Push(target string, opts *http.PushOptions) error {
+	r.pushed = append(r.pushed, target)
+	return nil
+}
+
+func TestResponseWriter(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+	if c.Writer.Written() || c.Writer.Status() != 0 || c.Writer.Size() != 0 {
+		t.Error("Fresh ResponseWriter should report nothing written")
+	}
+
+	c.String(201, "Hello")
+	c.Response.Write([]byte(", World"))
+
+	if !c.Writer.Written() {
+		t.Error("ResponseWriter should report written state")
+	}
+	if c.Writer.Status() != 201 {
+		t.Errorf("Expected status 201, got %d", c.Writer.Status())
+	}
+	if c.Writer.Size() != len("Hello, World") {
+		t.Errorf("Expected size %d, got %d", len("Hello, World"), c.Writer.Size())
+	}
+
+	c.Writer.WriteHeader(500)
+	if w.Code != 201 || c.Writer.Status() != 201 {
+		t.Error("Subsequent WriteHeader calls should be ignored")
+	}
+
+	t.Run("ImplicitStatus", func(t *testing.T) {
+		c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		c.Response.Write([]byte("data"))
+
+		if c.Writer.Status() != 200 {
+			t.Errorf("Write without WriteHeader should record 200, got %d", c.Writer.Status())
+		}
+	})
+
+	t.Run("ThroughMiddleware", func(t *testing.T) {
+		var status, size int
+		app := New()
+		app.Use(func(c *Context) {
+			c.Next()
+			status, size = c.Writer.Status(), c.Writer.Size()
+		})
+		app.GET("/", func(c *Context) {
+			c.JSON(202, map[string]bool{"ok": true})
+		})
+
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+		if status != 202 || size != len("{\"ok\":true}\n") {
+			t.Errorf("Middleware should observe status and size, got %d %d", status, size)
+		}
+	})
+}
+
+func TestResponseWriterOptionalInterfaces(t *testing.T) {
+	t.Run("Supported", func(t *testing.T) {
+		rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+		c := NewContext(rec, httptest.NewRequest("GET", "/", nil))
+
+		if err := c.Response.(http.Pusher).Push("/app.css", nil); err != nil {
+			t.Errorf("Push should succeed, got %v", err)
+		}
+		if len(rec.pushed) != 1 || rec.pushed[0] != "/app.css" {
+			t.Errorf("Push should reach the underlying writer, got %v", rec.pushed)
+		}
+
+		c.Response.(http.Flusher).Flush()
+		if !rec.Flushed {
+			t.Error("Flush should reach the underlying writer")
+		}
+
+		conn, _, err := c.Response.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Fatalf("Hijack should succeed, got %v", err)
+		}
+		conn.Close()
+		if !rec.hijacked {
+			t.Error("Hijack should reach the underlying writer")
+		}
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+		if _, _, err := c.Response.(http.Hijacker).Hijack(); err != http.ErrNotSupported {
+			t.Errorf("Expected http.ErrNotSupported from Hijack, got %v", err)
+		}
+		if err := c.Response.(http.Pusher).Push("/app.css", nil); err != http.ErrNotSupported {
+			t.Errorf("Expected http.ErrNotSupported from Push, got %v", err)
+		}
+	})
+
+	t.Run("HijackMarksWritten", func(t *testing.T) {
+		rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+		c := NewContext(rec, httptest.NewRequest("GET", "/", nil))
+
+		conn, _, _ := c.Response.(http.Hijacker).Hijack()
+		conn.Close()
+
+		if c.StatusCode() != http.StatusSwitchingProtocols {
+			t.Errorf("Expected status 101 after hijack, got %d", c.StatusCode())
+		}
+	})
+
+	t.Run("ThroughETag", func(t *testing.T) {
+		rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+		app := New()
+		app.Use(ETag())
+		app.GET("/ws", func(c *Context) {
+			if conn, _, err := c.Response.(http.Hijacker).Hijack(); err == nil {
+				conn.Close()
+			}
+		})
+
+		app.ServeHTTP(rec, httptest.NewRequest("GET", "/ws", nil))
+
+		if !rec.hijacked {
+			t.Error("Hijack should pass through the ETag middleware")
+		}
+	})
+}
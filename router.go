@@ -4,18 +4,10 @@
 package goxpress
 
 import (
+	"sort"
 	"strings"
-	"sync"
 )
 
-// builderPool is a sync.Pool for strings.Builder to reduce memory allocations
-// during path parsing operations.
-var builderPool = sync.Pool{
-	New: func() interface{} {
-		return &strings.Builder{}
-	},
-}
-
 // Router represents the HTTP router that manages route registration and matching.
 // It uses a Radix Tree data structure for efficient route lookup and supports:
 //   - Static routes: "/users"
@@ -27,7 +19,8 @@ var builderPool = sync.Pool{
 type Router struct {
 	prefix      string                 // Route group prefix
 	middlewares []HandlerFunc          // Group-specific middleware
-	engine      *Engine                // Reference to parent engine
+	bodyLimit   int64                  // Body limit set with SetBodyLimit, 0 for none
+	engine      *Engine                // Reference to parent engine, nil for standalone routers
 	subRouters  map[string]*Router     // Nested route groups
 	routes      map[string]*routerTree // HTTP method -> route tree mapping
 }
@@ -36,18 +29,22 @@ type Router struct {
 // Each HTTP method has its own tree to avoid conflicts between
 // different HTTP verbs on the same path.
 type routerTree struct {
-	root *routerNode // Root node of the tree
+	root   *routerNode            // Root node of the tree
+	static map[string]*routerNode // Routes without parameters, by exact path
 }
 
 // routerNode represents a single node in the Radix Tree.
 // Each node can represent part of a URL path and may contain
 // handlers if it represents a complete route.
 type routerNode struct {
-	pattern  string        // Complete route pattern (e.g., "/users/:id")
-	part     string        // Path segment for this node (e.g., ":id")
-	children []*routerNode // Child nodes
-	isWild   bool          // True if this node represents a parameter or wildcard
-	handlers []HandlerFunc // Route handlers (only set for terminal nodes)
+	pattern   string        // Complete route pattern (e.g., "/users/:id")
+	part      string        // Path segment for this node (e.g., ":id")
+	children  []*routerNode // Child nodes
+	isWild    bool          // True if this node represents a parameter or wildcard
+	handlers  []HandlerFunc // Route handlers (only set for terminal nodes)
+	chain     []HandlerFunc // Global middleware + handlers, run by the Engine
+	types     *handlerTypes // Types of the handler registered with Typed, if any
+	bodyLimit int64         // Body limit of the route, 0 for none
 }
 
 // NewRouter creates and returns a new Router instance.
@@ -91,6 +88,7 @@ func (r *Router) Group(prefix string) *Router {
 	router := &Router{
 		prefix:      r.prefix + prefix,
 		middlewares: make([]HandlerFunc, len(r.middlewares)), // Copy parent middleware
+		bodyLimit:   r.bodyLimit,
 		engine:      r.engine,
 		subRouters:  make(map[string]*Router),
 		routes:      r.routes, // Share route trees with parent
@@ -103,12 +101,39 @@ func (r *Router) Group(prefix string) *Router {
 	return router
 }
 
+// SetBodyLimit overrides the limit of the BodyLimit middleware for the
+// routes registered on this router and its sub-groups after the call,
+// given as a size like the BodyLimit limit. It panics if limit is not a
+// valid size. Routes without a BodyLimit middleware are not limited.
+// Returns the Router instance for method chaining.
+//
+// Example:
+//
+//	app.Use(goxpress.BodyLimit("2MB"))
+//
+//	uploads := app.Route("/uploads").SetBodyLimit("100MB")
+//	uploads.POST("/", uploadHandler)
+func (r *Router) SetBodyLimit(limit string) *Router {
+	n, err := parseSize(limit)
+	if err != nil {
+		panic(err)
+	}
+	r.bodyLimit = n
+	return r
+}
+
 // Handle registers a new route with the specified HTTP method and pattern.
 // This is the core route registration method used by all HTTP method helpers.
 //
 // The method combines the router's prefix with the pattern and prepares
 // the final handler chain including group middleware.
 func (r *Router) Handle(method, pattern string, handlers ...HandlerFunc) {
+	r.handle(method, pattern, handlers, nil)
+}
+
+// handle registers a route like Handle and records the types of its typed
+// handler, nil for other routes.
+func (r *Router) handle(method, pattern string, handlers []HandlerFunc, types *handlerTypes) {
 	// Combine router prefix with route pattern
 	fullPattern := r.prefix + pattern
 	if r.prefix != "" && pattern == "/" {
@@ -121,7 +146,9 @@ func (r *Router) Handle(method, pattern string, handlers ...HandlerFunc) {
 	finalHandlers = append(finalHandlers, handlers...)
 
 	// Register the route
-	r.addRoute(method, fullPattern, finalHandlers)
+	node := r.addRoute(method, fullPattern, finalHandlers)
+	node.types = types
+	node.bodyLimit = r.bodyLimit
 }
 
 // GET registers a new route for HTTP GET requests.
@@ -178,7 +205,8 @@ func (r *Router) OPTIONS(pattern string, handlers ...HandlerFunc) *Router {
 }
 
 // parsePattern splits a URL pattern into path segments, removing empty segments.
-// It uses a pool of strings.Builder for efficient string operations.
+// Patterns are parsed once, when the route is registered; request paths are
+// walked in place with nextSegment instead.
 //
 // Examples:
 //
@@ -186,59 +214,113 @@ func (r *Router) OPTIONS(pattern string, handlers ...HandlerFunc) *Router {
 //	"/api/v1/users" -> ["api", "v1", "users"]
 //	"/files/*filepath" -> ["files", "*filepath"]
 func parsePattern(pattern string) []string {
-	// Get builder from pool for efficient string operations
-	builder := builderPool.Get().(*strings.Builder)
-	defer func() {
-		builder.Reset()
-		builderPool.Put(builder)
-	}()
-
-	// Pre-allocate slice with estimated capacity
 	parts := make([]string, 0, strings.Count(pattern, "/"))
+	for part, i := nextSegment(pattern, 0); part != ""; part, i = nextSegment(pattern, i) {
+		parts = append(parts, part)
+	}
+	return parts
+}
 
-	// Split by '/' and filter out empty parts
-	segments := strings.Split(pattern, "/")
-	for _, segment := range segments {
-		if segment != "" {
-			parts = append(parts, segment)
-		}
+// nextSegment returns the first non-empty segment of path at or after
+// index i and the index following it, without allocating. The segment is
+// empty once the path is exhausted.
+//
+// Example:
+//
+//	nextSegment("/users//42", 6) -> "42", 10
+func nextSegment(path string, i int) (string, int) {
+	for i < len(path) && path[i] == '/' {
+		i++
+	}
+	start := i
+	for i < len(path) && path[i] != '/' {
+		i++
 	}
+	return path[start:i], i
+}
 
-	return parts
+// remainingPath returns the rest of path from index i with empty segments
+// removed, as captured by wildcard parameters.
+func remainingPath(path string, i int) string {
+	rest := strings.Trim(path[i:], "/")
+	if !strings.Contains(rest, "//") {
+		return rest
+	}
+	return strings.Join(parsePattern(rest), "/")
 }
 
 // addRoute adds a new route to the appropriate route tree.
 // It creates the tree for the HTTP method if it doesn't exist,
-// then inserts the route pattern into the Radix Tree.
-func (r *Router) addRoute(method, pattern string, handlers []HandlerFunc) {
+// then inserts the route pattern into the Radix Tree. It returns the node
+// holding the route.
+func (r *Router) addRoute(method, pattern string, handlers []HandlerFunc) *routerNode {
+	if r.engine != nil && r.engine.frozen {
+		panic("goxpress: cannot register route " + method + " " + pattern + " after Freeze")
+	}
+
 	// Create route tree for method if it doesn't exist
-	if r.routes[method] == nil {
-		r.routes[method] = &routerTree{root: &routerNode{}}
+	tree := r.routes[method]
+	if tree == nil {
+		tree = &routerTree{root: &routerNode{}, static: make(map[string]*routerNode)}
+		r.routes[method] = tree
 	}
 
 	parts := parsePattern(pattern)
 
-	// Insert pattern into the Radix Tree
-	r.routes[method].insertRoute(pattern, parts, 0, handlers)
+	// Insert pattern into the Radix Tree and cache the full handler chain
+	node := tree.root.insertRoute(pattern, parts, 0, handlers)
+	node.chain = handlers
+	if r.engine != nil {
+		node.chain = r.engine.combineHandlers(handlers)
+	}
+
+	// Index static routes by their canonical path so requests for them can
+	// skip the tree traversal
+	for _, part := range parts {
+		if part[0] == ':' || part[0] == '*' {
+			return node
+		}
+	}
+	tree.static["/"+strings.Join(parts, "/")] = node
+	return node
 }
 
 // getRoute finds a matching route for the given HTTP method and path.
 // Returns the matching node and extracted URL parameters, or nil if no match.
+// The parameters are nil when the matched route has none.
+func (r *Router) getRoute(method, path string) (*routerNode, map[string]string) {
+	var ps []param
+	node := r.findRoute(method, path, &ps)
+	if len(ps) == 0 {
+		return node, nil
+	}
+
+	params := make(map[string]string, len(ps))
+	for _, p := range ps {
+		params[p.key] = p.value
+	}
+	return node, params
+}
+
+// findRoute finds a matching route for the given HTTP method and path and
+// appends the extracted URL parameters to params, reusing its storage.
+// Returns the matching node, or nil if no match.
 //
-// The method performs efficient tree traversal to find the best match,
+// Static routes requested by their exact path are looked up in a map;
+// other requests perform efficient tree traversal to find the best match,
 // extracting parameters along the way.
-func (r *Router) getRoute(method, path string) (*routerNode, map[string]string) {
+func (r *Router) findRoute(method, path string, params *[]param) *routerNode {
 	root, ok := r.routes[method]
 	if !ok {
-		return nil, nil
+		return nil
 	}
 
-	searchParts := parsePattern(path)
-	params := make(map[string]string)
-
-	node := root.searchRoute(searchParts, 0, params)
+	// Fast path: static routes requested by their canonical path
+	if node, ok := root.static[path]; ok {
+		return node
+	}
 
-	return node, params
+	return root.root.searchRoute(path, 0, params)
 }
 
 // walkMountRoutes recursively walks through route tree nodes to mount routes
@@ -266,17 +348,18 @@ func (r *Router) walkMountRoutes(node *routerNode, method, mountPrefix string, g
 
 // insertRoute recursively inserts a route pattern into the Radix Tree.
 // It builds the tree structure by creating nodes for each path segment
-// and handles parameter and wildcard matching.
-func (t *routerTree) insertRoute(pattern string, parts []string, height int, handlers []HandlerFunc) {
+// and handles parameter and wildcard matching. It returns the node
+// holding the route.
+func (n *routerNode) insertRoute(pattern string, parts []string, height int, handlers []HandlerFunc) *routerNode {
 	// Base case: all segments processed
 	if len(parts) == height {
-		t.root.pattern = pattern
-		t.root.handlers = handlers
-		return
+		n.pattern = pattern
+		n.handlers = handlers
+		return n
 	}
 
 	part := parts[height]
-	child := t.root.matchChild(part)
+	child := n.matchChild(part)
 
 	if child == nil {
 		// Create new child node
@@ -284,62 +367,132 @@ func (t *routerTree) insertRoute(pattern string, parts []string, height int, han
 			part:   part,
 			isWild: part[0] == ':' || part[0] == '*',
 		}
-		t.root.children = append(t.root.children, child)
+		n.addChild(child)
 	}
 
 	// Recursively insert remaining parts
-	childTree := &routerTree{root: child}
-	childTree.insertRoute(pattern, parts, height+1, handlers)
+	return child.insertRoute(pattern, parts, height+1, handlers)
 }
 
 // searchRoute performs recursive search through the Radix Tree to find
-// a matching route. It extracts URL parameters during traversal.
-func (t *routerTree) searchRoute(parts []string, height int, params map[string]string) *routerNode {
+// a matching route, walking path from index pos without splitting it. It
+// appends URL parameters to params during traversal. The search recurses
+// on the nodes themselves, so matching a route allocates nothing.
+func (n *routerNode) searchRoute(path string, pos int, params *[]param) *routerNode {
+	part, next := nextSegment(path, pos)
+
 	// Base case: all parts processed or wildcard encountered
-	if len(parts) == height || strings.HasPrefix(t.root.part, "*") {
-		if t.root.pattern == "" {
+	if part == "" || strings.HasPrefix(n.part, "*") {
+		if n.pattern == "" {
 			return nil
 		}
-		return t.root
+		return n
 	}
 
-	part := parts[height]
 	// Check all children for matches
-	for _, child := range t.root.children {
+	for _, child := range n.children {
 		if child.part == part || child.isWild {
 			// Handle parameter matching
+			captured := len(*params)
 			if child.isWild && child.part[0] == ':' {
-				params[child.part[1:]] = part
+				*params = append(*params, param{key: child.part[1:], value: part})
 			} else if child.isWild && child.part[0] == '*' {
 				// For wildcard, capture the rest of the path
-				params[child.part[1:]] = strings.Join(parts[height:], "/")
+				*params = append(*params, param{key: child.part[1:], value: remainingPath(path, pos)})
 				return child
 			}
 
 			// Recursively search in child node
-			childTree := &routerTree{root: child}
-			result := childTree.searchRoute(parts, height+1, params)
+			result := child.searchRoute(path, next, params)
 			if result != nil {
 				return result
 			}
 
 			// Backtrack parameters if necessary
-			if child.isWild && child.part[0] == ':' {
-				delete(params, child.part[1:])
-			}
+			*params = (*params)[:captured]
 		}
 	}
 
 	return nil
 }
 
-// matchChild finds a direct child node that matches the given part.
-// Returns nil if no exact match is found.
+// matchChild finds the direct child node registered for the given part.
+// Returns nil if no exact match is found. Parameters only match children
+// with the same name, so static routes registered next to a parameter get
+// their own node.
 func (n *routerNode) matchChild(part string) *routerNode {
 	for _, child := range n.children {
-		if child.part == part || child.isWild {
+		if child.part == part {
 			return child
 		}
 	}
 	return nil
 }
+
+// addChild adds child to n, keeping static children ahead of parameters and
+// parameters ahead of wildcards so the most specific route matches first.
+func (n *routerNode) addChild(child *routerNode) {
+	i := len(n.children)
+	for i > 0 && childRank(n.children[i-1]) > childRank(child) {
+		i--
+	}
+	n.children = append(n.children, nil)
+	copy(n.children[i+1:], n.children[i:])
+	n.children[i] = child
+}
+
+// childRank orders children for matching: static parts, then parameters,
+// then wildcards.
+func childRank(n *routerNode) int {
+	switch {
+	case !n.isWild:
+		return 0
+	case n.part[0] == ':':
+		return 1
+	}
+	return 2
+}
+
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	Method string // HTTP method
+	Path   string // Route pattern, e.g. "/users/:id"
+}
+
+// Routes returns the registered routes, sorted by path and method.
+//
+// Example:
+//
+//	for _, route := range app.Routes() {
+//		fmt.Println(route.Method, route.Path)
+//	}
+func (e *Engine) Routes() []RouteInfo {
+	var routes []RouteInfo
+	for method, tree := range e.router.routes {
+		tree.root.collectRoutes(method, &routes)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// collectRoutes appends the routes registered at n and below to routes.
+func (n *routerNode) collectRoutes(method string, routes *[]RouteInfo) {
+	n.walk(func(node *routerNode) {
+		if node.pattern != "" {
+			*routes = append(*routes, RouteInfo{Method: method, Path: node.pattern})
+		}
+	})
+}
+
+// walk calls fn for n and every node below it.
+func (n *routerNode) walk(fn func(*routerNode)) {
+	fn(n)
+	for _, child := range n.children {
+		child.walk(fn)
+	}
+}
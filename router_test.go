@@ -2,6 +2,7 @@ package goxpress
 
 import (
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -197,6 +198,83 @@ func TestRouterGroups(t *testing.T) {
 	}
 }
 
+func TestRouterStaticRouteParams(t *testing.T) {
+	router := NewRouter()
+	router.GET("/users", func(c *Context) {})
+	router.GET("/users/:id", func(c *Context) {})
+
+	node, params := router.getRoute("GET", "/users")
+	if node == nil || params != nil {
+		t.Errorf("Expected static route without params map, got %v", params)
+	}
+
+	node, params = router.getRoute("GET", "/users/7")
+	if node == nil || params["id"] != "7" {
+		t.Errorf("Expected id param, got %v", params)
+	}
+}
+
+func TestRouterStaticFastPath(t *testing.T) {
+	router := NewRouter()
+	router.GET("/users/:id", func(c *Context) {})
+	router.GET("/users/new", func(c *Context) {})
+	router.GET("/", func(c *Context) {})
+
+	tree := router.routes["GET"]
+	if len(tree.static) != 2 || tree.static["/users/new"] == nil || tree.static["/"] == nil {
+		t.Fatalf("Expected static routes to be indexed, got %v", tree.static)
+	}
+
+	if node, _ := router.getRoute("GET", "/users/new"); node == nil || node.pattern != "/users/new" {
+		t.Errorf("Expected static route to win, got %v", node)
+	}
+	// Non-canonical paths still match through the tree
+	if node, _ := router.getRoute("GET", "/users/new/"); node == nil {
+		t.Error("Expected trailing slash to match through the tree")
+	}
+	if node, params := router.getRoute("GET", "/users/7"); node == nil || params["id"] != "7" {
+		t.Errorf("Expected param route, got %v", params)
+	}
+}
+
+func TestRouterSearchNoAllocs(t *testing.T) {
+	router := NewRouter()
+	router.GET("/orgs/:org/repos/:repo/issues/:number", func(c *Context) {})
+	router.GET("/orgs/:org/repos/:repo/files/*path", func(c *Context) {})
+
+	params := make([]param, 0, 4)
+	allocs := testing.AllocsPerRun(100, func() {
+		params = params[:0]
+		if router.findRoute("GET", "/orgs/acme/repos/web/issues/42", &params) == nil {
+			t.Fatal("Expected deep param route to match")
+		}
+		params = params[:0]
+		if router.findRoute("GET", "/orgs/acme/repos/web/files/src/main.go", &params) == nil {
+			t.Fatal("Expected wildcard route to match")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Expected route search not to allocate, got %v allocs", allocs)
+	}
+}
+
+func TestNextSegment(t *testing.T) {
+	path := "//users//42/"
+	var parts []string
+	for part, i := nextSegment(path, 0); part != ""; part, i = nextSegment(path, i) {
+		parts = append(parts, part)
+	}
+	if strings.Join(parts, ",") != "users,42" {
+		t.Errorf("Expected segments users,42, got %v", parts)
+	}
+
+	router := NewRouter()
+	router.GET("/files/*filepath", func(c *Context) {})
+	if _, params := router.getRoute("GET", "/files//a//b/"); params["filepath"] != "a/b" {
+		t.Errorf("Expected empty segments to be dropped from wildcard, got %q", params["filepath"])
+	}
+}
+
 func TestRouterNestedGroups(t *testing.T) {
 	router := NewRouter()
 
@@ -417,14 +495,13 @@ func TestRouterHTTPIntegration(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	// Simulate the engine's ServeHTTP behavior
-	node, params := router.getRoute(req.Method, req.URL.Path)
+	c := NewContext(w, req)
+	node := router.findRoute(req.Method, req.URL.Path, &c.params)
 	if node == nil {
 		t.Fatal("Route should be found")
 	}
 
-	// Create context and execute handlers
-	c := NewContext(w, req)
-	c.params = params
+	// Execute handlers
 	c.handlers = node.handlers
 	c.Next()
 
@@ -443,3 +520,29 @@ func TestRouterHTTPIntegration(t *testing.T) {
 		t.Errorf("Expected response %s, got %s", expected, actual)
 	}
 }
+
+func TestEngineRoutes(t *testing.T) {
+	app := New()
+	handler := func(c *Context) {}
+	app.GET("/", handler)
+	app.POST("/users", handler)
+	app.GET("/users", handler)
+	api := app.Route("/api")
+	api.GET("/items/:id", handler)
+
+	expected := []RouteInfo{
+		{"GET", "/"},
+		{"GET", "/api/items/:id"},
+		{"GET", "/users"},
+		{"POST", "/users"},
+	}
+	routes := app.Routes()
+	if len(routes) != len(expected) {
+		t.Fatalf("Expected %d routes, got %v", len(expected), routes)
+	}
+	for i, route := range routes {
+		if route != expected[i] {
+			t.Errorf("Route %d: expected %v, got %v", i, expected[i], route)
+		}
+	}
+}
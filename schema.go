@@ -0,0 +1,544 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the JSON Schema validator used to check requests and
+// responses against schemas, and the ValidateSchema middleware.
+package goxpress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// maxSchemaRefDepth bounds the number of "$ref" pointers followed while
+// validating a single value, to stop at schemas referencing themselves.
+const maxSchemaRefDepth = 64
+
+// Violation describes a part of a request or response that does not
+// conform to its schema.
+type Violation struct {
+	// In is the location of the value: "path", "query", "header",
+	// "cookie", "body" or "response".
+	In string `json:"in"`
+
+	// Field is the parameter name, or the JSON Pointer of the value within
+	// the body, e.g. "/items/0/id". It is empty for the body itself.
+	Field string `json:"field,omitempty"`
+
+	// Message describes the violation, e.g. "must be at least 3 characters".
+	Message string `json:"message"`
+}
+
+// String formats the violation for logs, e.g. "body /name: is required".
+func (v Violation) String() string {
+	if v.Field == "" {
+		return v.In + ": " + v.Message
+	}
+	return v.In + " " + v.Field + ": " + v.Message
+}
+
+// SchemaConfig defines configuration options for the ValidateSchema
+// middleware.
+type SchemaConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper Skipper
+
+	// Route restricts validation to one route, given as a route pattern
+	// such as "/users/:id", optionally preceded by a method, as in
+	// "POST /users". If empty, every request is validated, which suits
+	// middleware registered on a single route.
+	Route string
+
+	// Schema is the JSON Schema document the request body is validated
+	// against. Required.
+	Schema []byte
+
+	// Handler is called with the violations of an invalid body. The
+	// default handler responds with 400 Bad Request and a JSON object with
+	// "error" and "violations" fields.
+	Handler func(c *Context, violations []Violation)
+}
+
+// ValidateSchema returns a middleware validating the JSON request bodies of
+// route against the JSON Schema document schema, rejecting invalid bodies
+// with 400 Bad Request and the list of violations. Unlike struct-based
+// binding, schemas can describe dynamic or user-defined payload shapes and
+// can be replaced without recompiling. route is a route pattern,
+// optionally preceded by a method; see SchemaConfig.Route.
+//
+// The body stays readable by handlers. Schemas may reference their own
+// definitions with local "$ref" pointers such as "#/$defs/address".
+// ValidateSchema panics if schema is not valid JSON.
+//
+// Example:
+//
+//	app.Use(goxpress.ValidateSchema("POST /events", []byte(`{
+//		"type": "object",
+//		"required": ["type"],
+//		"properties": {"type": {"enum": ["click", "view"]}}
+//	}`)))
+func ValidateSchema(route string, schema []byte) HandlerFunc {
+	return ValidateSchemaWithConfig(SchemaConfig{Route: route, Schema: schema})
+}
+
+// ValidateSchemaFS is like ValidateSchema, but reads the schema from the
+// file name of fsys, e.g. an embed.FS. It panics if the file cannot be read.
+//
+// Example:
+//
+//	//go:embed schemas
+//	var schemas embed.FS
+//
+//	app.POST("/orders", goxpress.ValidateSchemaFS("", schemas, "schemas/order.json"), createOrder)
+func ValidateSchemaFS(route string, fsys fs.FS, name string) HandlerFunc {
+	schema, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		panic("goxpress: cannot read schema: " + err.Error())
+	}
+	return ValidateSchema(route, schema)
+}
+
+// ValidateSchemaWithConfig returns a ValidateSchema middleware with custom
+// configuration.
+//
+// Example:
+//
+//	app.Use(goxpress.ValidateSchemaWithConfig(goxpress.SchemaConfig{
+//		Route:  "PUT /forms/:id",
+//		Schema: schema,
+//		Handler: func(c *goxpress.Context, violations []goxpress.Violation) {
+//			c.JSON(422, map[string]interface{}{"violations": violations})
+//		},
+//	}))
+func ValidateSchemaWithConfig(config SchemaConfig) HandlerFunc {
+	var schema interface{}
+	if err := json.Unmarshal(config.Schema, &schema); err != nil {
+		panic("goxpress: invalid JSON schema: " + err.Error())
+	}
+	validator := newSchemaValidator(schema)
+
+	method, pattern := "", config.Route
+	if i := strings.IndexByte(pattern, ' '); i >= 0 {
+		method, pattern = strings.ToUpper(pattern[:i]), strings.TrimSpace(pattern[i+1:])
+	}
+
+	// Set defaults
+	if config.Handler == nil {
+		config.Handler = func(c *Context, violations []Violation) {
+			c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error":      http.StatusText(http.StatusBadRequest),
+				"violations": violations,
+			})
+		}
+	}
+
+	return func(c *Context) {
+		if (config.Skipper != nil && config.Skipper(c)) ||
+			(pattern != "" && c.FullPath() != pattern) ||
+			(method != "" && c.Request.Method != method) {
+			c.Next()
+			return
+		}
+
+		var violations []Violation
+		body, err := c.RawBody()
+		switch {
+		case err != nil:
+			violations = []Violation{{In: "body", Message: "cannot be read: " + err.Error()}}
+		case len(body) == 0:
+			violations = []Violation{{In: "body", Message: "is required"}}
+		default:
+			var value interface{}
+			if err := json.Unmarshal(body, &value); err != nil {
+				violations = []Violation{{In: "body", Message: "invalid JSON: " + err.Error()}}
+			} else {
+				validator.validate(schema, value, "body", "", &violations)
+			}
+		}
+
+		if len(violations) > 0 {
+			c.Abort()
+			config.Handler(c, violations)
+			return
+		}
+		c.Next()
+	}
+}
+
+// schemaValidator validates decoded JSON values against JSON Schema
+// documents, supporting the keywords used by OpenAPI 3: type, nullable,
+// enum, const, format, the string, number, array and object constraints,
+// allOf, anyOf, oneOf, not and local "$ref" pointers.
+type schemaValidator struct {
+	root interface{} // Document "$ref" pointers are resolved against
+
+	mu       sync.Mutex
+	patterns map[string]*regexp.Regexp // Compiled "pattern" keywords
+}
+
+// newSchemaValidator creates a validator resolving references against root.
+func newSchemaValidator(root interface{}) *schemaValidator {
+	return &schemaValidator{root: root, patterns: make(map[string]*regexp.Regexp)}
+}
+
+// validate appends the violations of value against schema to violations.
+// in and pointer locate value in the request or response.
+func (v *schemaValidator) validate(schema, value interface{}, in, pointer string, violations *[]Violation) {
+	v.validateDepth(schema, value, in, pointer, violations, 0)
+}
+
+// validateDepth implements validate, counting the references followed.
+func (v *schemaValidator) validateDepth(schema, value interface{}, in, pointer string, violations *[]Violation, depth int) {
+	s, ok := schema.(map[string]interface{})
+	if !ok {
+		if schema == false {
+			*violations = append(*violations, Violation{In: in, Field: pointer, Message: "is not allowed"})
+		}
+		return
+	}
+
+	fail := func(format string, args ...interface{}) {
+		*violations = append(*violations, Violation{In: in, Field: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if ref, ok := s["$ref"].(string); ok {
+		if depth >= maxSchemaRefDepth {
+			fail("schema reference %q is too deeply nested", ref)
+			return
+		}
+		target, err := v.resolve(ref)
+		if err != nil {
+			fail("%v", err)
+			return
+		}
+		v.validateDepth(target, value, in, pointer, violations, depth+1)
+		return
+	}
+
+	if value == nil && s["nullable"] == true {
+		return
+	}
+
+	if types, ok := schemaTypes(s["type"]); ok {
+		actual := jsonType(value)
+		matched := false
+		for _, typ := range types {
+			if typ == actual || (typ == "number" && actual == "integer") {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("expected %s, got %s", strings.Join(types, " or "), actual)
+			return
+		}
+	}
+
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, candidate := range enum {
+			if reflect.DeepEqual(candidate, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("must be one of %s", formatJSONValues(enum))
+		}
+	}
+	if constant, ok := s["const"]; ok && !reflect.DeepEqual(constant, value) {
+		fail("must be %s", formatJSONValues([]interface{}{constant}))
+	}
+
+	switch value := value.(type) {
+	case string:
+		v.validateString(s, value, fail)
+	case float64:
+		validateNumber(s, value, fail)
+	case []interface{}:
+		if min, ok := s["minItems"].(float64); ok && float64(len(value)) < min {
+			fail("must have at least %v items", min)
+		}
+		if max, ok := s["maxItems"].(float64); ok && float64(len(value)) > max {
+			fail("must have at most %v items", max)
+		}
+		if s["uniqueItems"] == true {
+			for i := 1; i < len(value); i++ {
+				for j := 0; j < i; j++ {
+					if reflect.DeepEqual(value[i], value[j]) {
+						fail("items %d and %d are equal", j, i)
+					}
+				}
+			}
+		}
+		if items, ok := s["items"]; ok {
+			for i, item := range value {
+				v.validateDepth(items, item, in, fmt.Sprintf("%s/%d", pointer, i), violations, depth)
+			}
+		}
+	case map[string]interface{}:
+		v.validateObject(s, value, in, pointer, violations, depth, fail)
+	}
+
+	if all, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			v.validateDepth(sub, value, in, pointer, violations, depth)
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]interface{}); ok && v.countMatches(anyOf, value, depth) == 0 {
+		fail("must match at least one schema in anyOf")
+	}
+	if oneOf, ok := s["oneOf"].([]interface{}); ok {
+		if n := v.countMatches(oneOf, value, depth); n != 1 {
+			fail("must match exactly one schema in oneOf, matched %d", n)
+		}
+	}
+	if not, ok := s["not"]; ok && v.countMatches([]interface{}{not}, value, depth) == 1 {
+		fail("must not match the schema in not")
+	}
+}
+
+// validateString checks the string constraints of s.
+func (v *schemaValidator) validateString(s map[string]interface{}, value string, fail func(string, ...interface{})) {
+	length := float64(utf8.RuneCountInString(value))
+	if min, ok := s["minLength"].(float64); ok && length < min {
+		fail("must be at least %v characters", min)
+	}
+	if max, ok := s["maxLength"].(float64); ok && length > max {
+		fail("must be at most %v characters", max)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := v.compile(pattern)
+		if err != nil {
+			fail("invalid pattern %q in schema", pattern)
+		} else if !re.MatchString(value) {
+			fail("must match pattern %q", pattern)
+		}
+	}
+	if format, ok := s["format"].(string); ok && !validFormat(format, value) {
+		fail("must be a valid %s", format)
+	}
+}
+
+// validateNumber checks the numeric constraints of s.
+func validateNumber(s map[string]interface{}, value float64, fail func(string, ...interface{})) {
+	if min, ok := s["minimum"].(float64); ok {
+		if s["exclusiveMinimum"] == true && value <= min {
+			fail("must be greater than %v", min)
+		} else if value < min {
+			fail("must be at least %v", min)
+		}
+	}
+	if max, ok := s["maximum"].(float64); ok {
+		if s["exclusiveMaximum"] == true && value >= max {
+			fail("must be less than %v", max)
+		} else if value > max {
+			fail("must be at most %v", max)
+		}
+	}
+	if min, ok := s["exclusiveMinimum"].(float64); ok && value <= min {
+		fail("must be greater than %v", min)
+	}
+	if max, ok := s["exclusiveMaximum"].(float64); ok && value >= max {
+		fail("must be less than %v", max)
+	}
+	if multiple, ok := s["multipleOf"].(float64); ok && multiple > 0 {
+		if q := value / multiple; math.Abs(q-math.Round(q)) > 1e-9 {
+			fail("must be a multiple of %v", multiple)
+		}
+	}
+}
+
+// validateObject checks the object constraints of s and validates the
+// properties of value.
+func (v *schemaValidator) validateObject(s map[string]interface{}, value map[string]interface{}, in, pointer string, violations *[]Violation, depth int, fail func(string, ...interface{})) {
+	if min, ok := s["minProperties"].(float64); ok && float64(len(value)) < min {
+		fail("must have at least %v properties", min)
+	}
+	if max, ok := s["maxProperties"].(float64); ok && float64(len(value)) > max {
+		fail("must have at most %v properties", max)
+	}
+
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				if _, present := value[name]; !present {
+					*violations = append(*violations, Violation{In: in, Field: pointer + "/" + escapeJSONPointer(name), Message: "is required"})
+				}
+			}
+		}
+	}
+
+	properties, _ := s["properties"].(map[string]interface{})
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field := pointer + "/" + escapeJSONPointer(name)
+		if sub, ok := properties[name]; ok {
+			v.validateDepth(sub, value[name], in, field, violations, depth)
+			continue
+		}
+		switch additional := s["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*violations = append(*violations, Violation{In: in, Field: field, Message: "is not allowed"})
+			}
+		case map[string]interface{}:
+			v.validateDepth(additional, value[name], in, field, violations, depth)
+		}
+	}
+}
+
+// countMatches returns the number of schemas value is valid against.
+func (v *schemaValidator) countMatches(schemas []interface{}, value interface{}, depth int) int {
+	matches := 0
+	for _, schema := range schemas {
+		var violations []Violation
+		v.validateDepth(schema, value, "", "", &violations, depth)
+		if len(violations) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+// resolve returns the schema a local "$ref" pointer such as
+// "#/components/schemas/User" refers to.
+func (v *schemaValidator) resolve(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported schema reference %q", ref)
+	}
+
+	current := v.root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#"), "/")[1:] {
+		token = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolvable schema reference %q", ref)
+		}
+		if current, ok = object[token]; !ok {
+			return nil, fmt.Errorf("unresolvable schema reference %q", ref)
+		}
+	}
+	return current, nil
+}
+
+// compile returns the compiled regular expression of a "pattern" keyword.
+func (v *schemaValidator) compile(pattern string) (*regexp.Regexp, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if re, ok := v.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	v.patterns[pattern] = re
+	return re, nil
+}
+
+// schemaTypes returns the types listed by a "type" keyword, which is
+// either a single name or a list of names.
+func schemaTypes(keyword interface{}) ([]string, bool) {
+	switch keyword := keyword.(type) {
+	case string:
+		return []string{keyword}, true
+	case []interface{}:
+		types := make([]string, 0, len(keyword))
+		for _, typ := range keyword {
+			if typ, ok := typ.(string); ok {
+				types = append(types, typ)
+			}
+		}
+		return types, len(types) > 0
+	}
+	return nil, false
+}
+
+// jsonType returns the JSON Schema type name of a decoded JSON value.
+func jsonType(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if value == math.Trunc(value) && !math.IsInf(value, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// validFormat reports whether value conforms to a "format" keyword.
+// Unknown formats are accepted, as JSON Schema treats them as annotations.
+func validFormat(format, value string) bool {
+	var err error
+	switch format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, value)
+	case "date":
+		_, err = time.Parse("2006-01-02", value)
+	case "email":
+		var addr *mail.Address
+		addr, err = mail.ParseAddress(value)
+		if err == nil && addr.Address != value {
+			return false
+		}
+	case "uri":
+		var u *url.URL
+		u, err = url.Parse(value)
+		if err == nil && !u.IsAbs() {
+			return false
+		}
+	case "uuid":
+		return uuidPattern.MatchString(value)
+	case "ipv4":
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() != nil && !strings.Contains(value, ":")
+	case "ipv6":
+		ip := net.ParseIP(value)
+		return ip != nil && strings.Contains(value, ":")
+	}
+	return err == nil
+}
+
+// uuidPattern matches UUIDs in their canonical textual form.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// formatJSONValues formats values as JSON for violation messages.
+func formatJSONValues(values []interface{}) string {
+	data, _ := json.Marshal(values)
+	if len(values) == 1 {
+		data = data[1 : len(data)-1]
+	}
+	return string(data)
+}
+
+// escapeJSONPointer escapes a property name for use in a JSON Pointer.
+func escapeJSONPointer(name string) string {
+	return strings.Replace(strings.Replace(name, "~", "~0", -1), "/", "~1", -1)
+}
@@ -0,0 +1,134 @@
+package goxpress
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSchemaValidator(t *testing.T) {
+	var root interface{}
+	json.Unmarshal([]byte(`{
+		"definitions": {
+			"tag": {"type": "string", "pattern": "^[a-z]+$"}
+		},
+		"type": "object",
+		"required": ["name", "age"],
+		"additionalProperties": false,
+		"properties": {
+			"name": {"type": "string", "minLength": 2},
+			"age": {"type": "integer", "minimum": 0, "maximum": 150},
+			"email": {"type": "string", "format": "email"},
+			"role": {"enum": ["admin", "user"]},
+			"tags": {"type": "array", "uniqueItems": true, "items": {"$ref": "#/definitions/tag"}},
+			"nickname": {"type": "string", "nullable": true},
+			"id": {"oneOf": [{"type": "integer"}, {"type": "string", "format": "uuid"}]}
+		}
+	}`), &root)
+	v := newSchemaValidator(root)
+
+	tests := []struct {
+		value    string
+		expected []Violation
+	}{
+		{`{"name":"ann","age":30,"tags":["go"],"nickname":null,"id":7}`, nil},
+		{`{"name":"a","age":30.5}`, []Violation{
+			{In: "body", Field: "/age", Message: "expected integer, got number"},
+			{In: "body", Field: "/name", Message: "must be at least 2 characters"},
+		}},
+		{`{"age":-1,"extra":true}`, []Violation{
+			{In: "body", Field: "/name", Message: "is required"},
+			{In: "body", Field: "/age", Message: "must be at least 0"},
+			{In: "body", Field: "/extra", Message: "is not allowed"},
+		}},
+		{`{"name":"ann","age":1,"email":"nope","role":"root","tags":["Go","go","go"],"id":"x"}`, []Violation{
+			{In: "body", Field: "/email", Message: "must be a valid email"},
+			{In: "body", Field: "/id", Message: "must match exactly one schema in oneOf, matched 0"},
+			{In: "body", Field: "/role", Message: `must be one of ["admin","user"]`},
+			{In: "body", Field: "/tags", Message: "items 1 and 2 are equal"},
+			{In: "body", Field: "/tags/0", Message: `must match pattern "^[a-z]+$"`},
+		}},
+		{`[]`, []Violation{{In: "body", Message: "expected object, got array"}}},
+	}
+
+	for _, tt := range tests {
+		var value interface{}
+		if err := json.Unmarshal([]byte(tt.value), &value); err != nil {
+			t.Fatalf("Invalid test value %s: %v", tt.value, err)
+		}
+		var violations []Violation
+		v.validate(root, value, "body", "", &violations)
+		if !reflect.DeepEqual(violations, tt.expected) {
+			t.Errorf("Value %s: expected %v, got %v", tt.value, tt.expected, violations)
+		}
+	}
+}
+
+func TestSchemaValidatorRecursiveRef(t *testing.T) {
+	var root interface{}
+	json.Unmarshal([]byte(`{"$ref": "#"}`), &root)
+
+	var violations []Violation
+	newSchemaValidator(root).validate(root, "x", "body", "", &violations)
+	if len(violations) != 1 {
+		t.Errorf("Expected a violation for a self-referencing schema, got %v", violations)
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	fsys := fstest.MapFS{
+		"schemas/event.json": {Data: []byte(`{
+			"type": "object",
+			"required": ["type"],
+			"properties": {"type": {"enum": ["click", "view"]}}
+		}`)},
+	}
+
+	app := New()
+	app.Use(ValidateSchemaFS("POST /events", fsys, "schemas/event.json"))
+	app.POST("/events", func(c *Context) {
+		var event map[string]string
+		c.BindJSON(&event)
+		c.String(201, event["type"])
+	})
+	app.PUT("/events", func(c *Context) { c.String(200, "unchecked") })
+
+	tests := []struct {
+		method, body string
+		status       int
+		response     string
+	}{
+		{"POST", `{"type":"click"}`, 201, "click"},
+		{"POST", `{"type":"hover"}`, 400, `"message":"must be one of [\"click\",\"view\"]"`},
+		{"POST", `{}`, 400, `"field":"/type","message":"is required"`},
+		{"POST", `{`, 400, `invalid JSON`},
+		{"POST", ``, 400, `"in":"body","message":"is required"`},
+		{"PUT", `{}`, 200, "unchecked"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(tt.method, "/events", strings.NewReader(tt.body)))
+		if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.response) {
+			t.Errorf("%s %q: expected %d containing %q, got %d %q", tt.method, tt.body, tt.status, tt.response, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestValidateSchemaRouteLevel(t *testing.T) {
+	app := New()
+	app.POST("/items", ValidateSchemaWithConfig(SchemaConfig{
+		Schema: []byte(`{"type": "array", "maxItems": 1}`),
+		Handler: func(c *Context, violations []Violation) {
+			c.String(422, violations[0].String())
+		},
+	}), func(c *Context) { c.String(200, "ok") })
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/items", strings.NewReader(`[1,2]`)))
+	if w.Code != 422 || w.Body.String() != "body: must have at most 1 items" {
+		t.Errorf("Expected custom handler response, got %d %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,365 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains server-side sessions and the SessionStore interface.
+package goxpress
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// sessionKey is the Context store key holding the current *Session.
+const sessionKey = "goxpress.session"
+
+// SessionStore persists encoded session data by session ID. Stores backed
+// by shared infrastructure, such as the ones in the session/redisstore and
+// session/sqlstore subpackages, keep sessions across restarts and replicas.
+// Implementations must be safe for concurrent use.
+type SessionStore interface {
+	// Load returns the data saved for id, or nil data and a nil error if
+	// the session does not exist or has expired.
+	Load(ctx context.Context, id string) ([]byte, error)
+
+	// Save stores data for id, expiring it after ttl.
+	Save(ctx context.Context, id string, data []byte, ttl time.Duration) error
+
+	// Delete removes the session with the given id.
+	Delete(ctx context.Context, id string) error
+}
+
+// SessionConfig defines configuration options for the session middleware.
+type SessionConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for,
+	// e.g. for static assets and health checks. Handlers of skipped
+	// requests have no session and must not call Context.Session.
+	Skipper Skipper
+
+	// Store persists session data. Required.
+	Store SessionStore
+
+	// CookieName is the name of the cookie holding the session ID.
+	// If empty, defaults to "goxpress_session".
+	CookieName string
+
+	// MaxAge is how long a session lives after it was last modified.
+	// If zero, defaults to 24 hours.
+	MaxAge time.Duration
+
+	// SaveTimeout bounds the time spent saving or deleting the session
+	// once the handlers are done. The store is called with a context of
+	// its own rather than the request's, so changes such as a logout are
+	// persisted even if the client already went away.
+	// If zero, defaults to 5 seconds.
+	SaveTimeout time.Duration
+
+	// Path, Domain, Secure and SameSite set the attributes of the session
+	// cookie. Path defaults to "/" and SameSite to http.SameSiteLaxMode.
+	// The cookie is always HttpOnly.
+	Path     string
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// Session holds the data of one client session. Values are encoded as
+// JSON when saved, so numbers read back from a stored session are float64
+// and structs come back as map[string]interface{}.
+type Session struct {
+	// ID is the session identifier sent to the client in the cookie.
+	ID string
+
+	values    map[string]interface{}
+	oldID     string // Previous ID to delete after Regenerate
+	modified  bool
+	destroyed bool
+	c         *Context
+	config    *SessionConfig
+}
+
+// Get returns the value stored under key, or nil if there is none.
+func (s *Session) Get(key string) interface{} {
+	return s.values[key]
+}
+
+// Set stores a value in the session. The session is saved when the
+// request completes.
+func (s *Session) Set(key string, value interface{}) {
+	s.values[key] = value
+	s.touch()
+}
+
+// Delete removes the value stored under key.
+func (s *Session) Delete(key string) {
+	if _, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.touch()
+	}
+}
+
+// Values returns all values stored in the session.
+func (s *Session) Values() map[string]interface{} {
+	return s.values
+}
+
+// Regenerate assigns the session a new ID while keeping its values.
+// Call it after a login or privilege change to prevent session fixation.
+func (s *Session) Regenerate() {
+	if s.oldID == "" {
+		s.oldID = s.ID
+	}
+	s.ID = newSessionID()
+	s.touch()
+}
+
+// Destroy removes the session from the store and expires its cookie.
+func (s *Session) Destroy() {
+	s.values = make(map[string]interface{})
+	s.destroyed = true
+	s.modified = false
+	s.c.setCookieHeader(&http.Cookie{
+		Name:     s.config.CookieName,
+		Value:    "",
+		Path:     s.config.Path,
+		Domain:   s.config.Domain,
+		MaxAge:   -1,
+		Secure:   s.config.Secure,
+		HttpOnly: true,
+		SameSite: s.config.SameSite,
+	})
+}
+
+// touch marks the session as modified and sends the session cookie.
+// The cookie is sent while the response headers can still be changed,
+// since the session itself is only saved once the handlers are done.
+func (s *Session) touch() {
+	s.modified = true
+	s.destroyed = false
+	s.c.setCookieHeader(&http.Cookie{
+		Name:     s.config.CookieName,
+		Value:    s.ID,
+		Path:     s.config.Path,
+		Domain:   s.config.Domain,
+		MaxAge:   int(s.config.MaxAge / time.Second),
+		Secure:   s.config.Secure,
+		HttpOnly: true,
+		SameSite: s.config.SameSite,
+	})
+}
+
+// setCookieHeader replaces any Set-Cookie header for the cookie's
+// name with cookie, unless the response headers were already sent.
+func (c *Context) setCookieHeader(cookie *http.Cookie) {
+	if c.statusCodeWritten || c.Writer.Written() {
+		return
+	}
+
+	header := c.Response.Header()
+	prefix := cookie.Name + "="
+	cookies := header["Set-Cookie"][:0]
+	for _, line := range header["Set-Cookie"] {
+		if len(line) < len(prefix) || line[:len(prefix)] != prefix {
+			cookies = append(cookies, line)
+		}
+	}
+	header["Set-Cookie"] = append(cookies, cookie.String())
+}
+
+// Sessions returns a middleware that loads the client's session from
+// store before the handlers run and saves it afterwards if it was
+// modified. Handlers access the session through c.Session().
+//
+// Example:
+//
+//	app.Use(goxpress.Sessions(goxpress.NewMemorySessionStore()))
+//
+//	app.POST("/login", func(c *goxpress.Context) {
+//		session := c.Session()
+//		session.Regenerate()
+//		session.Set("user_id", user.ID)
+//		c.Redirect(303, "/")
+//	})
+func Sessions(store SessionStore) HandlerFunc {
+	return SessionsWithConfig(SessionConfig{Store: store})
+}
+
+// SessionsWithConfig returns a session middleware with custom configuration.
+// It panics if config.Store is nil.
+//
+// Example:
+//
+//	app.Use(goxpress.SessionsWithConfig(goxpress.SessionConfig{
+//		Store:  redisstore.New(client),
+//		MaxAge: 7 * 24 * time.Hour,
+//		Secure: true,
+//	}))
+func SessionsWithConfig(config SessionConfig) HandlerFunc {
+	if config.Store == nil {
+		panic("goxpress: SessionConfig.Store is required")
+	}
+
+	// Set defaults
+	if config.CookieName == "" {
+		config.CookieName = "goxpress_session"
+	}
+	if config.MaxAge == 0 {
+		config.MaxAge = 24 * time.Hour
+	}
+	if config.SaveTimeout <= 0 {
+		config.SaveTimeout = 5 * time.Second
+	}
+	if config.Path == "" {
+		config.Path = "/"
+	}
+	if config.SameSite == 0 {
+		config.SameSite = http.SameSiteLaxMode
+	}
+
+	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		session := &Session{c: c, config: &config}
+
+		// Only adopt IDs the store knows, so clients cannot choose their own
+		if cookie, err := c.Request.Cookie(config.CookieName); err == nil && cookie.Value != "" {
+			data, err := config.Store.Load(c.Request.Context(), cookie.Value)
+			if err != nil {
+				recordSessionError(c, err)
+			} else if data != nil && json.Unmarshal(data, &session.values) == nil {
+				session.ID = cookie.Value
+			}
+		}
+		if session.ID == "" {
+			session.ID = newSessionID()
+		}
+		if session.values == nil {
+			session.values = make(map[string]interface{})
+		}
+
+		c.Set(sessionKey, session)
+		c.Next()
+
+		if session.oldID == "" && !session.destroyed && !session.modified {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), config.SaveTimeout)
+		defer cancel()
+		if session.oldID != "" {
+			if err := config.Store.Delete(ctx, session.oldID); err != nil {
+				recordSessionError(c, err)
+			}
+		}
+		if session.destroyed {
+			if err := config.Store.Delete(ctx, session.ID); err != nil {
+				recordSessionError(c, err)
+			}
+		} else if session.modified {
+			data, err := json.Marshal(session.values)
+			if err == nil {
+				err = config.Store.Save(ctx, session.ID, data, config.MaxAge)
+			}
+			if err != nil {
+				recordSessionError(c, err)
+			}
+		}
+	}
+}
+
+// recordSessionError adds a session storage error to c.Errors so it is
+// logged. The error is not passed to the error handlers, as the response
+// has usually been sent by the time the session is saved.
+func recordSessionError(c *Context, err error) {
+	c.Errors = append(c.Errors, &ErrorEntry{Err: err, Meta: "session"})
+}
+
+// Session returns the session of the current request. It panics if the
+// Sessions middleware is not installed.
+//
+// Example:
+//
+//	if userID := c.Session().Get("user_id"); userID == nil {
+//		c.Redirect(302, "/login")
+//		return
+//	}
+func (c *Context) Session() *Session {
+	return c.MustGet(sessionKey).(*Session)
+}
+
+// newSessionID returns a random, URL-safe session identifier.
+func newSessionID() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic("goxpress: cannot generate session ID: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// MemorySessionStore is a SessionStore that keeps sessions in process
+// memory. Sessions are lost on restart and are not shared between
+// instances; use it for development and single-instance deployments.
+type MemorySessionStore struct {
+	mu        sync.Mutex
+	sessions  map[string]memorySession
+	lastSweep time.Time
+}
+
+// memorySession is a session stored by MemorySessionStore.
+type memorySession struct {
+	data    []byte
+	expires time.Time
+}
+
+// NewMemorySessionStore creates an empty in-memory session store.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]memorySession)}
+}
+
+// Load implements SessionStore.
+func (s *MemorySessionStore) Load(ctx context.Context, id string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	if time.Now().After(session.expires) {
+		delete(s.sessions, id)
+		return nil, nil
+	}
+	return session.data, nil
+}
+
+// Save implements SessionStore. Expired sessions are purged at most once
+// a minute as part of a save.
+func (s *MemorySessionStore) Save(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= time.Minute {
+		s.lastSweep = now
+		for key, session := range s.sessions {
+			if now.After(session.expires) {
+				delete(s.sessions, key)
+			}
+		}
+	}
+	s.sessions[id] = memorySession{data: data, expires: now.Add(ttl)}
+	return nil
+}
+
+// Delete implements SessionStore.
+func (s *MemorySessionStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, id)
+	return nil
+}
@@ -0,0 +1,77 @@
+// Package redisstore provides a goxpress.SessionStore backed by Redis,
+// so sessions survive restarts and are shared between replicas.
+//
+// The package does not depend on a Redis driver. Wrap the client of your
+// choice in a type implementing Client:
+//
+//	type redisClient struct{ *redis.Client }
+//
+//	func (c redisClient) Get(ctx context.Context, key string) ([]byte, error) {
+//		data, err := c.Client.Get(ctx, key).Bytes()
+//		if err == redis.Nil {
+//			return nil, nil
+//		}
+//		return data, err
+//	}
+//
+//	func (c redisClient) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+//		return c.Client.Set(ctx, key, value, ttl).Err()
+//	}
+//
+//	func (c redisClient) Del(ctx context.Context, key string) error {
+//		return c.Client.Del(ctx, key).Err()
+//	}
+//
+//	app.Use(goxpress.Sessions(redisstore.New(redisClient{rdb})))
+package redisstore
+
+import (
+	"context"
+	"time"
+)
+
+// Client is the subset of a Redis client used by Store.
+type Client interface {
+	// Get returns the value of key, or nil and a nil error if the key
+	// does not exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set stores value under key with the given expiration.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Del removes key.
+	Del(ctx context.Context, key string) error
+}
+
+// Store is a goxpress.SessionStore keeping each session in a Redis key
+// that expires together with the session.
+type Store struct {
+	// Prefix is prepended to session IDs to form Redis keys.
+	// Defaults to "goxpress:session:".
+	Prefix string
+
+	client Client
+}
+
+// New creates a Store using client.
+func New(client Client) *Store {
+	return &Store{
+		Prefix: "goxpress:session:",
+		client: client,
+	}
+}
+
+// Load implements goxpress.SessionStore.
+func (s *Store) Load(ctx context.Context, id string) ([]byte, error) {
+	return s.client.Get(ctx, s.Prefix+id)
+}
+
+// Save implements goxpress.SessionStore.
+func (s *Store) Save(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, s.Prefix+id, data, ttl)
+}
+
+// Delete implements goxpress.SessionStore.
+func (s *Store) Delete(ctx context.Context, id string) error {
+	return s.client.Del(ctx, s.Prefix+id)
+}
@@ -0,0 +1,51 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeClient is an in-memory Client.
+type fakeClient struct {
+	data map[string][]byte
+	ttls map[string]time.Duration
+}
+
+func (f *fakeClient) Get(ctx context.Context, key string) ([]byte, error) {
+	return f.data[key], nil
+}
+
+func (f *fakeClient) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	f.data[key] = value
+	f.ttls[key] = ttl
+	return nil
+}
+
+func (f *fakeClient) Del(ctx context.Context, key string) error {
+	delete(f.data, key)
+	return nil
+}
+
+func TestStore(t *testing.T) {
+	client := &fakeClient{data: make(map[string][]byte), ttls: make(map[string]time.Duration)}
+	store := New(client)
+	ctx := context.Background()
+
+	if err := store.Save(ctx, "abc", []byte(`{"user":"john"}`), time.Hour); err != nil {
+		t.Fatalf("Save should not return error: %v", err)
+	}
+	if client.ttls["goxpress:session:abc"] != time.Hour {
+		t.Errorf("Expected prefixed key with 1h TTL, got %v", client.ttls)
+	}
+
+	data, err := store.Load(ctx, "abc")
+	if err != nil || string(data) != `{"user":"john"}` {
+		t.Errorf("Unexpected Load result %q, %v", data, err)
+	}
+
+	store.Delete(ctx, "abc")
+	if data, _ := store.Load(ctx, "abc"); data != nil {
+		t.Error("Deleted session should not load")
+	}
+}
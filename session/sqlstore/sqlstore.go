@@ -0,0 +1,131 @@
+// Package sqlstore provides a goxpress.SessionStore backed by a SQL
+// database through database/sql, so sessions survive restarts and are
+// shared between replicas.
+//
+// The store expects a table with the following columns; adjust the types
+// to your database:
+//
+//	CREATE TABLE sessions (
+//		id         VARCHAR(64) PRIMARY KEY,
+//		data       BLOB NOT NULL,
+//		expires_at BIGINT NOT NULL
+//	);
+//
+// expires_at holds a Unix timestamp in seconds. Expired rows are ignored
+// when loading; call DeleteExpired periodically to remove them.
+//
+// Sessions are saved with a single upsert statement, so concurrent saves
+// of the same session don't conflict. The defaults suit SQLite; set
+// Placeholder for PostgreSQL and Upsert for MySQL.
+//
+// Example:
+//
+//	db, _ := sql.Open("pgx", dsn)
+//	store := sqlstore.New(db, "sessions")
+//	store.Placeholder = sqlstore.Dollar
+//	app.Use(goxpress.Sessions(store))
+//
+//	// MySQL and MariaDB
+//	store := sqlstore.New(db, "sessions")
+//	store.Upsert = sqlstore.OnDuplicateKey
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Question formats placeholders as "?", as used by MySQL and SQLite.
+func Question(n int) string {
+	return "?"
+}
+
+// Dollar formats placeholders as "$1", "$2", ..., as used by PostgreSQL.
+func Dollar(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// Clauses appended to the INSERT statement of Save to update the row of
+// an existing session instead.
+const (
+	// OnConflict is the upsert clause of PostgreSQL and SQLite 3.24+.
+	OnConflict = "ON CONFLICT (id) DO UPDATE SET data = excluded.data, expires_at = excluded.expires_at"
+
+	// OnDuplicateKey is the upsert clause of MySQL and MariaDB.
+	OnDuplicateKey = "ON DUPLICATE KEY UPDATE data = VALUES(data), expires_at = VALUES(expires_at)"
+)
+
+// Store is a goxpress.SessionStore keeping sessions in a SQL table.
+type Store struct {
+	// Placeholder formats the n-th (1-based) query parameter.
+	// Defaults to Question.
+	Placeholder func(n int) string
+
+	// Upsert is the clause making the INSERT of Save update an existing
+	// session. Defaults to OnConflict.
+	Upsert string
+
+	db    *sql.DB
+	table string
+}
+
+// New creates a Store using the given database and table name. The table
+// name is inserted into queries verbatim and must not come from user input.
+func New(db *sql.DB, table string) *Store {
+	return &Store{
+		Placeholder: Question,
+		Upsert:      OnConflict,
+		db:          db,
+		table:       table,
+	}
+}
+
+// Load implements goxpress.SessionStore.
+func (s *Store) Load(ctx context.Context, id string) ([]byte, error) {
+	query := fmt.Sprintf("SELECT data FROM %s WHERE id = %s AND expires_at > %s",
+		s.table, s.Placeholder(1), s.Placeholder(2))
+
+	var data []byte
+	err := s.db.QueryRowContext(ctx, query, id, time.Now().Unix()).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return data, err
+}
+
+// Save implements goxpress.SessionStore. It inserts or updates the
+// session row with a single statement, using the Upsert clause.
+func (s *Store) Save(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	query := fmt.Sprintf("INSERT INTO %s (id, data, expires_at) VALUES (%s, %s, %s) %s",
+		s.table, s.Placeholder(1), s.Placeholder(2), s.Placeholder(3), s.Upsert)
+	_, err := s.db.ExecContext(ctx, query, id, data, time.Now().Add(ttl).Unix())
+	return err
+}
+
+// Delete implements goxpress.SessionStore.
+func (s *Store) Delete(ctx context.Context, id string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = %s", s.table, s.Placeholder(1))
+	_, err := s.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// DeleteExpired removes all expired sessions and returns how many were
+// removed.
+//
+// Example:
+//
+//	go func() {
+//		for range time.Tick(time.Hour) {
+//			store.DeleteExpired(context.Background())
+//		}
+//	}()
+func (s *Store) DeleteExpired(ctx context.Context) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE expires_at <= %s", s.table, s.Placeholder(1))
+	result, err := s.db.ExecContext(ctx, query, time.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
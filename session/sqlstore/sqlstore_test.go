@@ -0,0 +1,222 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDriver is a database/sql driver keeping the rows of a sessions
+// table in memory. It understands the statements issued by Store and
+// fails inserts of existing ids without an upsert clause, like a primary
+// key would.
+type fakeDriver struct {
+	mu      sync.Mutex
+	rows    map[string]fakeRow
+	queries []string
+}
+
+type fakeRow struct {
+	data      []byte
+	expiresAt int64
+}
+
+var (
+	fakeDriverMu    sync.Mutex
+	fakeDriverCount int
+)
+
+// openFake returns a database backed by a new fakeDriver.
+func openFake(t *testing.T) (*sql.DB, *fakeDriver) {
+	fakeDriverMu.Lock()
+	fakeDriverCount++
+	name := fmt.Sprintf("sqlstore-fake-%d", fakeDriverCount)
+	fakeDriverMu.Unlock()
+
+	d := &fakeDriver{rows: make(map[string]fakeRow)}
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, d
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.d, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("transactions not supported") }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	d := s.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, s.query)
+
+	switch {
+	case strings.HasPrefix(s.query, "INSERT INTO sessions "):
+		id := args[0].(string)
+		if _, exists := d.rows[id]; exists && !strings.Contains(s.query, "ON CONFLICT (id) DO UPDATE") {
+			return nil, errors.New("duplicate key")
+		}
+		d.rows[id] = fakeRow{data: append([]byte(nil), args[1].([]byte)...), expiresAt: args[2].(int64)}
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(s.query, "DELETE FROM sessions WHERE id = "):
+		if _, exists := d.rows[args[0].(string)]; !exists {
+			return driver.RowsAffected(0), nil
+		}
+		delete(d.rows, args[0].(string))
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(s.query, "DELETE FROM sessions WHERE expires_at <= "):
+		var n int64
+		for id, row := range d.rows {
+			if row.expiresAt <= args[0].(int64) {
+				delete(d.rows, id)
+				n++
+			}
+		}
+		return driver.RowsAffected(n), nil
+	}
+	return nil, fmt.Errorf("unexpected statement %q", s.query)
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	d := s.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, s.query)
+
+	if !strings.HasPrefix(s.query, "SELECT data FROM sessions WHERE id = ") {
+		return nil, fmt.Errorf("unexpected query %q", s.query)
+	}
+	row, ok := d.rows[args[0].(string)]
+	if !ok || row.expiresAt <= args[1].(int64) {
+		return &fakeRows{}, nil
+	}
+	return &fakeRows{values: [][]byte{row.data}}, nil
+}
+
+type fakeRows struct {
+	values [][]byte
+}
+
+func (r *fakeRows) Columns() []string { return []string{"data"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
+
+func TestStore(t *testing.T) {
+	db, d := openFake(t)
+	store := New(db, "sessions")
+	ctx := context.Background()
+
+	if data, err := store.Load(ctx, "abc"); data != nil || err != nil {
+		t.Errorf("Expected no session, got %q, %v", data, err)
+	}
+
+	if err := store.Save(ctx, "abc", []byte(`{"user":"john"}`), time.Hour); err != nil {
+		t.Fatalf("Save should not return error: %v", err)
+	}
+	if err := store.Save(ctx, "abc", []byte(`{"user":"jane"}`), time.Hour); err != nil {
+		t.Fatalf("Saving an existing session should not return error: %v", err)
+	}
+	data, err := store.Load(ctx, "abc")
+	if err != nil || string(data) != `{"user":"jane"}` {
+		t.Errorf("Unexpected Load result %q, %v", data, err)
+	}
+
+	if err := store.Delete(ctx, "abc"); err != nil {
+		t.Fatalf("Delete should not return error: %v", err)
+	}
+	if data, _ := store.Load(ctx, "abc"); data != nil {
+		t.Error("Deleted session should not load")
+	}
+
+	if got := d.queries[1]; got != "INSERT INTO sessions (id, data, expires_at) VALUES (?, ?, ?) "+OnConflict {
+		t.Errorf("Unexpected save statement %q", got)
+	}
+}
+
+func TestStoreExpiry(t *testing.T) {
+	db, _ := openFake(t)
+	store := New(db, "sessions")
+	ctx := context.Background()
+
+	store.Save(ctx, "old", []byte("{}"), -time.Minute)
+	store.Save(ctx, "new", []byte("{}"), time.Hour)
+
+	if data, _ := store.Load(ctx, "old"); data != nil {
+		t.Error("Expired session should not load")
+	}
+	n, err := store.DeleteExpired(ctx)
+	if err != nil || n != 1 {
+		t.Errorf("Expected 1 expired session removed, got %d, %v", n, err)
+	}
+	if data, _ := store.Load(ctx, "new"); data == nil {
+		t.Error("Live session should survive DeleteExpired")
+	}
+}
+
+func TestStoreConcurrentSave(t *testing.T) {
+	db, _ := openFake(t)
+	store := New(db, "sessions")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- store.Save(context.Background(), "abc", []byte(fmt.Sprint(i)), time.Hour)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Concurrent saves should not fail: %v", err)
+		}
+	}
+}
+
+func TestStoreDialect(t *testing.T) {
+	db, d := openFake(t)
+	store := New(db, "sessions")
+	store.Placeholder = Dollar
+	store.Upsert = OnDuplicateKey
+
+	store.Save(context.Background(), "abc", []byte("{}"), time.Hour)
+	store.Load(context.Background(), "abc")
+
+	if got := d.queries[0]; got != "INSERT INTO sessions (id, data, expires_at) VALUES ($1, $2, $3) "+OnDuplicateKey {
+		t.Errorf("Unexpected save statement %q", got)
+	}
+	if got := d.queries[1]; got != "SELECT data FROM sessions WHERE id = $1 AND expires_at > $2" {
+		t.Errorf("Unexpected load statement %q", got)
+	}
+}
@@ -0,0 +1,166 @@
+package goxpress
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSessions(t *testing.T) {
+	store := NewMemorySessionStore()
+
+	app := New()
+	app.Use(Sessions(store))
+	app.POST("/login", func(c *Context) {
+		session := c.Session()
+		session.Regenerate()
+		session.Set("user", "john")
+		c.String(200, "ok")
+	})
+	app.GET("/me", func(c *Context) {
+		user, _ := c.Session().Get("user").(string)
+		c.String(200, user)
+	})
+	app.POST("/logout", func(c *Context) {
+		c.Session().Destroy()
+		c.String(200, "bye")
+	})
+
+	request := func(method, path string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("GET", "/me", nil)
+	if w.Body.String() != "" || w.Header().Get("Set-Cookie") != "" {
+		t.Error("Unmodified sessions should not be saved or sent")
+	}
+
+	w = request("POST", "/login", &http.Cookie{Name: "goxpress_session", Value: "attacker-chosen"})
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "goxpress_session" || !cookies[0].HttpOnly {
+		t.Fatalf("Expected one HttpOnly session cookie, got %v", cookies)
+	}
+	session := cookies[0]
+	if session.Value == "attacker-chosen" {
+		t.Error("Unknown session IDs must not be adopted")
+	}
+	if session.MaxAge != 86400 {
+		t.Errorf("Expected MaxAge 86400, got %d", session.MaxAge)
+	}
+
+	if w := request("GET", "/me", session); w.Body.String() != "john" {
+		t.Errorf("Expected session user 'john', got '%s'", w.Body.String())
+	}
+
+	w = request("POST", "/logout", session)
+	if cookies := w.Result().Cookies(); len(cookies) != 1 || cookies[0].MaxAge != -1 {
+		t.Errorf("Expected expired session cookie, got %v", cookies)
+	}
+	if data, _ := store.Load(context.Background(), session.Value); data != nil {
+		t.Error("Destroyed session should be removed from the store")
+	}
+	if w := request("GET", "/me", session); w.Body.String() != "" {
+		t.Error("Destroyed session should not be loaded")
+	}
+}
+
+func TestSessionRegenerate(t *testing.T) {
+	store := NewMemorySessionStore()
+	store.Save(context.Background(), "old-id", []byte(`{"cart":3}`), time.Hour)
+
+	app := New()
+	app.Use(Sessions(store))
+	app.GET("/", func(c *Context) {
+		c.Session().Regenerate()
+		c.String(200, "%v", c.Session().Get("cart"))
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: "goxpress_session", Value: "old-id"})
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	if w.Body.String() != "3" {
+		t.Errorf("Regenerate should keep values, got '%s'", w.Body.String())
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value == "old-id" {
+		t.Fatalf("Expected a new session ID, got %v", cookies)
+	}
+	if data, _ := store.Load(context.Background(), "old-id"); data != nil {
+		t.Error("Old session should be removed")
+	}
+	if data, _ := store.Load(context.Background(), cookies[0].Value); string(data) != `{"cart":3}` {
+		t.Errorf("Expected session saved under new ID, got %q", data)
+	}
+}
+
+func TestMemorySessionStoreExpiry(t *testing.T) {
+	store := NewMemorySessionStore()
+	ctx := context.Background()
+
+	store.Save(ctx, "expired", []byte("{}"), -time.Second)
+	if data, err := store.Load(ctx, "expired"); data != nil || err != nil {
+		t.Errorf("Expired sessions should not load, got %q, %v", data, err)
+	}
+	if data, err := store.Load(ctx, "missing"); data != nil || err != nil {
+		t.Errorf("Missing sessions should load as nil, got %q, %v", data, err)
+	}
+}
+
+func TestSessionsSkipper(t *testing.T) {
+	app := New()
+	app.Use(SessionsWithConfig(SessionConfig{
+		Store:   NewMemorySessionStore(),
+		Skipper: func(c *Context) bool { return c.Request.URL.Path == "/assets/app.js" },
+	}))
+	app.GET("/assets/app.js", func(c *Context) {
+		_, exists := c.Get(sessionKey)
+		c.String(200, "%v", exists)
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/assets/app.js", nil))
+	if w.Body.String() != "false" {
+		t.Error("Skipped requests should have no session")
+	}
+}
+
+// ctxCheckingStore is a SessionStore failing calls with a done context,
+// like stores honoring cancellation.
+type ctxCheckingStore struct {
+	*MemorySessionStore
+}
+
+func (s ctxCheckingStore) Save(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.MemorySessionStore.Save(ctx, id, data, ttl)
+}
+
+func TestSessionsSaveAfterClientGone(t *testing.T) {
+	store := ctxCheckingStore{NewMemorySessionStore()}
+
+	app := New()
+	app.Use(Sessions(store))
+	var id string
+	app.POST("/login", func(c *Context) {
+		c.Session().Set("user", "john")
+		id = c.Session().ID
+		c.clientCancel() // The client went away
+	})
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/login", nil))
+
+	if data, _ := store.Load(context.Background(), id); data == nil {
+		t.Error("Expected the session to be saved after the client went away")
+	}
+}
@@ -0,0 +1,183 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the request coalescing middleware.
+package goxpress
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+)
+
+// SingleflightConfig defines configuration options for the singleflight
+// middleware.
+type SingleflightConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper Skipper
+
+	// KeyFunc returns the key identifying identical requests. Requests
+	// with an empty key are never coalesced.
+	// If nil, the method and URL of GET requests are used, and requests
+	// carrying a Cookie or Authorization header are not coalesced, since
+	// their responses may be specific to the user.
+	KeyFunc func(c *Context) string
+}
+
+// flightCall is an in-flight execution shared by identical requests.
+type flightCall struct {
+	done   chan struct{}
+	ok     bool // Whether the leader completed without panicking
+	status int
+	header http.Header
+	body   []byte
+}
+
+// Singleflight returns a middleware that coalesces concurrent identical GET
+// requests: while one of them runs the handlers, the others wait and
+// receive a copy of its response. A thundering herd against an expensive
+// endpoint then costs a single execution.
+//
+// Only headers set after the middleware are shared, and Set-Cookie headers
+// are never copied to the waiting requests. WebSocket upgrades are not
+// coalesced.
+//
+// Example:
+//
+//	app.GET("/reports/daily", goxpress.Singleflight(), dailyReport)
+func Singleflight() HandlerFunc {
+	return SingleflightWithConfig(SingleflightConfig{})
+}
+
+// SingleflightWithConfig returns a singleflight middleware with custom
+// configuration.
+//
+// Example:
+//
+//	// Coalesce per product, ignoring tracking parameters
+//	app.GET("/products/:id", goxpress.SingleflightWithConfig(goxpress.SingleflightConfig{
+//		KeyFunc: func(c *goxpress.Context) string {
+//			return c.Param("id")
+//		},
+//	}), getProduct)
+func SingleflightWithConfig(config SingleflightConfig) HandlerFunc {
+	// Set defaults
+	if config.KeyFunc == nil {
+		config.KeyFunc = defaultFlightKey
+	}
+
+	var mu sync.Mutex
+	calls := make(map[string]*flightCall)
+
+	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		key := config.KeyFunc(c)
+		if key == "" || c.IsWebSocket() {
+			c.Next()
+			return
+		}
+
+		mu.Lock()
+		if call, ok := calls[key]; ok {
+			mu.Unlock()
+
+			select {
+			case <-call.done:
+			case <-c.Request.Context().Done():
+				c.Abort()
+				return
+			}
+			if !call.ok {
+				// The shared execution failed; run the handlers ourselves
+				c.Next()
+				return
+			}
+
+			c.Abort()
+			header := c.Response.Header()
+			for k, v := range call.header {
+				if k != "Set-Cookie" {
+					header[k] = v
+				}
+			}
+			c.Response.WriteHeader(call.status)
+			c.statusCodeWritten = true
+			c.Response.Write(call.body)
+			return
+		}
+
+		call := &flightCall{done: make(chan struct{})}
+		calls[key] = call
+		mu.Unlock()
+
+		defer func() {
+			mu.Lock()
+			delete(calls, key)
+			mu.Unlock()
+			close(call.done)
+		}()
+
+		original := c.Response
+		recorder := &flightRecorder{header: make(http.Header)}
+		c.Response = recorder
+		defer func() { c.Response = original }()
+
+		c.Next()
+
+		call.ok = true
+		call.status = recorder.status
+		if call.status == 0 {
+			call.status = http.StatusOK
+		}
+		call.header = recorder.header
+		call.body = recorder.body.Bytes()
+
+		header := original.Header()
+		for k, v := range recorder.header {
+			header[k] = v
+		}
+		original.WriteHeader(call.status)
+		c.statusCodeWritten = true
+		original.Write(call.body)
+	}
+}
+
+// defaultFlightKey coalesces anonymous GET requests by URL.
+func defaultFlightKey(c *Context) string {
+	req := c.Request
+	if req.Method != http.MethodGet || req.Header.Get("Cookie") != "" || req.Header.Get("Authorization") != "" {
+		return ""
+	}
+	return req.Host + req.URL.RequestURI()
+}
+
+// flightRecorder buffers the response of the request executing a shared
+// call, with its own header map so headers set for it alone by earlier
+// middleware are not shared.
+type flightRecorder struct {
+	header http.Header
+	status int          // Status code passed to WriteHeader, 0 if not called
+	body   bytes.Buffer // Buffered response body
+}
+
+// Header returns the recorded response headers.
+func (w *flightRecorder) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader records the status code.
+func (w *flightRecorder) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+// Write appends data to the buffered body.
+func (w *flightRecorder) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(data)
+}
@@ -0,0 +1,127 @@
+package goxpress
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleflight(t *testing.T) {
+	var executions int32
+	release := make(chan struct{})
+
+	app := New()
+	app.GET("/report", Singleflight(), func(c *Context) {
+		atomic.AddInt32(&executions, 1)
+		<-release
+		c.Header("X-Report", "daily")
+		c.Header("Set-Cookie", "leader=1")
+		c.String(200, "report")
+	})
+
+	const clients = 5
+	responses := make([]*httptest.ResponseRecorder, clients)
+	var wg sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = httptest.NewRecorder()
+			app.ServeHTTP(responses[i], httptest.NewRequest("GET", "/report", nil))
+		}(i)
+	}
+
+	// Let every request reach the middleware before the handler finishes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&executions); n != 1 {
+		t.Errorf("Expected one execution, got %d", n)
+	}
+	cookies := 0
+	for _, w := range responses {
+		if w.Code != 200 || w.Body.String() != "report" || w.Header().Get("X-Report") != "daily" {
+			t.Errorf("Expected shared response, got %d %q", w.Code, w.Body.String())
+		}
+		if w.Header().Get("Set-Cookie") != "" {
+			cookies++
+		}
+	}
+	if cookies != 1 {
+		t.Errorf("Expected only the executing request to set a cookie, got %d", cookies)
+	}
+}
+
+func TestSingleflightSkipsUserRequests(t *testing.T) {
+	var executions int32
+	release := make(chan struct{})
+
+	app := New()
+	app.GET("/me", Singleflight(), func(c *Context) {
+		atomic.AddInt32(&executions, 1)
+		<-release
+		c.String(200, "me")
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest("GET", "/me", nil)
+			req.Header.Set("Authorization", "Bearer token")
+			app.ServeHTTP(httptest.NewRecorder(), req)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&executions); n != 3 {
+		t.Errorf("Expected authenticated requests to run separately, got %d executions", n)
+	}
+}
+
+func TestSingleflightPanic(t *testing.T) {
+	var executions int32
+	release := make(chan struct{})
+
+	app := New()
+	app.Use(RecoverWithConfig(RecoverConfig{Output: ioutil.Discard}))
+	app.UseError(func(err error, c *Context) {
+		c.String(500, "Internal Server Error")
+	})
+	app.GET("/flaky", Singleflight(), func(c *Context) {
+		if atomic.AddInt32(&executions, 1) == 1 {
+			<-release
+			panic("boom")
+		}
+		c.String(200, "ok")
+	})
+
+	leader := httptest.NewRecorder()
+	follower := httptest.NewRecorder()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		app.ServeHTTP(leader, httptest.NewRequest("GET", "/flaky", nil))
+	}()
+	time.Sleep(20 * time.Millisecond)
+	go func() {
+		defer wg.Done()
+		app.ServeHTTP(follower, httptest.NewRequest("GET", "/flaky", nil))
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if leader.Code != 500 || follower.Code != 200 || follower.Body.String() != "ok" {
+		t.Errorf("Expected follower to run the handler itself, got %d and %d %q",
+			leader.Code, follower.Code, follower.Body.String())
+	}
+}
@@ -0,0 +1,74 @@
+//go:build go1.21
+
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the log/slog request logging middleware. It requires
+// Go 1.21 or later.
+package goxpress
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// SlogLogger returns a middleware that emits one structured log record per
+// request to logger, ready for JSON log pipelines. Each record carries the
+// method, path, route pattern, status, response size in bytes, latency,
+// client IP and, when present, the request ID and recorded errors.
+//
+// Records are logged at Error level for 5xx responses, Warn level for 4xx
+// responses and Info level otherwise.
+//
+// Example:
+//
+//	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+//	app.Use(goxpress.SlogLogger(logger))
+//
+// Output:
+//
+//	{"time":"...","level":"INFO","msg":"request","method":"GET","path":"/users/42",
+//	 "route":"/users/:id","status":200,"bytes":27,"latency":1200000,"client_ip":"10.0.0.1"}
+func SlogLogger(logger *slog.Logger) HandlerFunc {
+	return func(c *Context) {
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status == 0 {
+			status = http.StatusOK // Nothing written, net/http sends 200
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		ctx := c.Request.Context()
+		if !logger.Enabled(ctx, level) {
+			return
+		}
+
+		attrs := make([]slog.Attr, 0, 10)
+		attrs = append(attrs,
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+		)
+		if id := c.requestID(); id != "" {
+			attrs = append(attrs, slog.String("request_id", id))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.Any("errors", c.Errors.Errors()))
+		}
+
+		logger.LogAttrs(ctx, level, "request", attrs...)
+	}
+}
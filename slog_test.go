@@ -0,0 +1,69 @@
+//go:build go1.21
+
+package goxpress
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	app := New()
+	app.Use(SlogLogger(logger))
+	app.GET("/users/:id", func(c *Context) {
+		c.String(200, "user %s", c.Param("id"))
+	})
+	app.GET("/fail", func(c *Context) {
+		c.Error(errors.New("db down"))
+		c.String(503, "unavailable")
+	})
+
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Request-ID", "abc")
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Expected one JSON record, got %q", buf.String())
+	}
+
+	expected := map[string]interface{}{
+		"level":      "INFO",
+		"msg":        "request",
+		"method":     "GET",
+		"path":       "/users/42",
+		"route":      "/users/:id",
+		"status":     200.0,
+		"bytes":      7.0,
+		"client_ip":  "10.0.0.1",
+		"request_id": "abc",
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("Expected %s=%v, got %v", key, value, record[key])
+		}
+	}
+	if _, ok := record["latency"]; !ok {
+		t.Error("Expected latency attribute")
+	}
+
+	buf.Reset()
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+
+	record = nil
+	json.Unmarshal(buf.Bytes(), &record)
+	if record["level"] != "ERROR" {
+		t.Errorf("Expected ERROR level for 5xx, got %v", record["level"])
+	}
+	if errs, _ := record["errors"].([]interface{}); len(errs) != 1 || errs[0] != "db down" {
+		t.Errorf("Expected recorded errors, got %v", record["errors"])
+	}
+}
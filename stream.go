@@ -0,0 +1,193 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains streaming response helpers that flush data to the
+// client incrementally.
+package goxpress
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServerSentEvent is a single message of a text/event-stream response.
+// Only Data is required; empty fields are omitted from the wire format.
+type ServerSentEvent struct {
+	ID    string        // Event ID, echoed back by browsers in Last-Event-ID
+	Event string        // Event name, dispatched to addEventListener(name)
+	Data  interface{}   // Payload: strings and []byte are sent as-is, other values as JSON
+	Retry time.Duration // Reconnection delay advertised to the client
+}
+
+// Stream sends a streaming response by calling step repeatedly and flushing
+// the written data to the client after each call. Streaming stops when step
+// returns false or when the client disconnects. Stream returns true if the
+// client went away before step finished.
+//
+// Example:
+//
+//	app.GET("/progress", func(c *goxpress.Context) {
+//		c.Response.Header().Set("Content-Type", "text/plain; charset=utf-8")
+//		n := 0
+//		c.Stream(func(w io.Writer) bool {
+//			n++
+//			fmt.Fprintf(w, "step %d done\n", n)
+//			time.Sleep(time.Second)
+//			return n < 10
+//		})
+//	})
+func (c *Context) Stream(step func(w io.Writer) bool) bool {
+	done := c.Done()
+
+	for {
+		select {
+		case <-done:
+			return true
+		default:
+			keepOpen := step(c.Response)
+			c.statusCodeWritten = true
+			c.flush()
+			if !keepOpen {
+				return false
+			}
+		}
+	}
+}
+
+// SSEvent writes a Server-Sent Event with the given name and data and
+// flushes it to the client. The text/event-stream headers are set
+// automatically before the first event is written. Strings and []byte are
+// sent as-is (split into multiple data lines on newlines); any other value
+// is encoded as JSON.
+//
+// Example:
+//
+//	app.GET("/events", func(c *goxpress.Context) {
+//		for update := range updates {
+//			if err := c.SSEvent("update", update); err != nil {
+//				return
+//			}
+//		}
+//	})
+func (c *Context) SSEvent(name string, data interface{}) error {
+	return c.writeSSE(ServerSentEvent{Event: name, Data: data})
+}
+
+// SSEStream sends every event received from events to the client until the
+// channel is closed or the client disconnects. If heartbeat is positive, a
+// comment line is sent whenever no event was written for that long, which
+// keeps idle connections open through proxies and detects dead clients.
+// SSEStream returns true if the client went away before events was closed.
+//
+// Example:
+//
+//	app.GET("/dashboard/stream", func(c *goxpress.Context) {
+//		events := make(chan goxpress.ServerSentEvent)
+//		unsubscribe := metrics.Subscribe(events)
+//		defer unsubscribe()
+//		c.SSEStream(events, 15*time.Second)
+//	})
+func (c *Context) SSEStream(events <-chan ServerSentEvent, heartbeat time.Duration) bool {
+	c.setSSEHeaders()
+	c.flush()
+
+	var tick <-chan time.Time
+	if heartbeat > 0 {
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	done := c.Done()
+	for {
+		select {
+		case <-done:
+			return true
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			if err := c.writeSSE(event); err != nil {
+				return true
+			}
+		case <-tick:
+			if _, err := io.WriteString(c.Response, ": heartbeat\n\n"); err != nil {
+				return true
+			}
+			c.flush()
+		}
+	}
+}
+
+// setSSEHeaders prepares the response for an event stream, unless the
+// status code has already been written.
+func (c *Context) setSSEHeaders() {
+	if c.statusCodeWritten {
+		return
+	}
+	header := c.Response.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // Disable nginx response buffering
+	c.Response.WriteHeader(http.StatusOK)
+	c.statusCodeWritten = true
+}
+
+// writeSSE encodes a single event in the text/event-stream format,
+// writes it to the response and flushes it.
+func (c *Context) writeSSE(event ServerSentEvent) error {
+	c.setSSEHeaders()
+
+	var data string
+	switch payload := event.Data.(type) {
+	case string:
+		data = payload
+	case []byte:
+		data = string(payload)
+	default:
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		data = string(encoded)
+	}
+
+	var buf bytes.Buffer
+	if event.ID != "" {
+		buf.WriteString("id: " + sseEscape(event.ID) + "\n")
+	}
+	if event.Event != "" {
+		buf.WriteString("event: " + sseEscape(event.Event) + "\n")
+	}
+	if event.Retry > 0 {
+		buf.WriteString("retry: " + strconv.FormatInt(int64(event.Retry/time.Millisecond), 10) + "\n")
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+	buf.WriteString("\n")
+
+	if _, err := c.Response.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	c.flush()
+	return nil
+}
+
+// sseEscape strips line breaks from single-line event fields, which would
+// otherwise terminate the field early.
+func sseEscape(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+// flush sends any buffered response data to the client if the
+// underlying ResponseWriter supports it.
+func (c *Context) flush() {
+	if flusher, ok := c.Response.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,116 @@
+package goxpress
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContextStream(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest("GET", "/", nil))
+
+	steps := 0
+	clientGone := c.Stream(func(out io.Writer) bool {
+		steps++
+		fmt.Fprintf(out, "step %d\n", steps)
+		return steps < 3
+	})
+
+	if clientGone {
+		t.Error("Stream should report that the client is still connected")
+	}
+	if w.Body.String() != "step 1\nstep 2\nstep 3\n" {
+		t.Errorf("Unexpected body %q", w.Body.String())
+	}
+	if !w.Flushed {
+		t.Error("Stream should flush the response")
+	}
+
+	t.Run("ClientDisconnect", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		req := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+		c := NewContext(httptest.NewRecorder(), req)
+
+		steps := 0
+		clientGone := c.Stream(func(out io.Writer) bool {
+			steps++
+			if steps == 2 {
+				cancel()
+			}
+			return true
+		})
+
+		if !clientGone {
+			t.Error("Stream should report the client disconnect")
+		}
+		if steps != 2 {
+			t.Errorf("Expected streaming to stop after 2 steps, got %d", steps)
+		}
+	})
+}
+
+func TestContextSSEvent(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := NewContext(w, httptest.NewRequest("GET", "/events", nil))
+
+	if err := c.SSEvent("message", "line one\nline two"); err != nil {
+		t.Fatalf("SSEvent should not return error: %v", err)
+	}
+	if err := c.SSEvent("update", map[string]int{"count": 1}); err != nil {
+		t.Fatalf("SSEvent should not return error: %v", err)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected Content-Type 'text/event-stream', got '%s'", ct)
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "no-cache" {
+		t.Errorf("Expected Cache-Control 'no-cache', got '%s'", cc)
+	}
+
+	expected := "event: message\ndata: line one\ndata: line two\n\n" +
+		"event: update\ndata: {\"count\":1}\n\n"
+	if w.Body.String() != expected {
+		t.Errorf("Expected body %q, got %q", expected, w.Body.String())
+	}
+}
+
+func TestContextSSEStream(t *testing.T) {
+	t.Run("ChannelClosed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/events", nil))
+
+		events := make(chan ServerSentEvent, 2)
+		events <- ServerSentEvent{ID: "1", Event: "tick", Data: "a", Retry: 3 * time.Second}
+		events <- ServerSentEvent{Data: []byte("b")}
+		close(events)
+
+		if c.SSEStream(events, 0) {
+			t.Error("SSEStream should not report a disconnect when the channel closes")
+		}
+
+		expected := "id: 1\nevent: tick\nretry: 3000\ndata: a\n\ndata: b\n\n"
+		if w.Body.String() != expected {
+			t.Errorf("Expected body %q, got %q", expected, w.Body.String())
+		}
+	})
+
+	t.Run("HeartbeatAndDisconnect", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		w := httptest.NewRecorder()
+		c := NewContext(w, httptest.NewRequest("GET", "/events", nil).WithContext(ctx))
+
+		if !c.SSEStream(make(chan ServerSentEvent), 10*time.Millisecond) {
+			t.Error("SSEStream should report the client disconnect")
+		}
+		if !strings.Contains(w.Body.String(), ": heartbeat\n\n") {
+			t.Errorf("Expected heartbeat comments, got %q", w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,254 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the pluggable template rendering system and the
+// built-in html/template renderer.
+package goxpress
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"io"
+	"sync"
+	"text/template/parse"
+)
+
+// Renderer renders named templates for Context.Render. Implement it to
+// plug alternative template engines such as pongo2, jet or templ into the
+// Engine; the built-in implementation, configured through LoadHTMLGlob,
+// uses html/template.
+//
+// Example:
+//
+//	type jetRenderer struct{ set *jet.Set }
+//
+//	func (r jetRenderer) Render(w io.Writer, name string, data interface{}, c *goxpress.Context) error {
+//		t, err := r.set.GetTemplate(name)
+//		if err != nil {
+//			return err
+//		}
+//		return t.Execute(w, nil, data)
+//	}
+//
+//	app.SetRenderer(jetRenderer{set: views})
+type Renderer interface {
+	Render(w io.Writer, name string, data interface{}, c *Context) error
+}
+
+// errNoRenderer is returned by Render when no renderer has been configured.
+var errNoRenderer = errors.New("goxpress: no renderer configured, call Engine.LoadHTMLGlob or Engine.SetRenderer")
+
+// htmlRenderer is the built-in Renderer based on html/template.
+type htmlRenderer struct {
+	mu       sync.RWMutex
+	pattern  string             // Glob pattern the templates were loaded from
+	funcs    template.FuncMap   // Functions available to templates
+	template *template.Template // Parsed template set
+
+	// Whether the templates call request functions, in which case the set
+	// is cloned for each render and never executed itself
+	perRequest bool
+}
+
+// requestFuncs are the template functions bound to the request being
+// rendered. They are registered as placeholders when parsing, so templates
+// can call them, unless SetFuncMap provides functions of the same name.
+var requestFuncs = map[string]func(c *Context) interface{}{
+	"flashes": func(c *Context) interface{} { return c.Flashes },
+}
+
+// requestFuncPlaceholders returns the request functions not overridden by
+// funcs, doing nothing.
+func requestFuncPlaceholders(funcs template.FuncMap) template.FuncMap {
+	placeholders := make(template.FuncMap)
+	for name := range requestFuncs {
+		if _, ok := funcs[name]; !ok {
+			placeholders[name] = func() interface{} { return nil }
+		}
+	}
+	return placeholders
+}
+
+// SetRenderer replaces the renderer used by Context.Render.
+// Returns the Engine instance for method chaining.
+func (e *Engine) SetRenderer(renderer Renderer) *Engine {
+	e.renderer = renderer
+	return e
+}
+
+// htmlRenderer returns the built-in renderer, installing it if another
+// renderer (or none) is configured.
+func (e *Engine) htmlRenderer() *htmlRenderer {
+	r, ok := e.renderer.(*htmlRenderer)
+	if !ok {
+		r = &htmlRenderer{}
+		e.renderer = r
+	}
+	return r
+}
+
+// SetFuncMap registers functions that are made available to HTML templates
+// of the built-in renderer. It must be called before LoadHTMLGlob.
+// Returns the Engine instance for method chaining.
+//
+// Example:
+//
+//	app.SetFuncMap(template.FuncMap{
+//		"upper": strings.ToUpper,
+//	})
+//	app.LoadHTMLGlob("templates/*.html")
+func (e *Engine) SetFuncMap(funcs template.FuncMap) *Engine {
+	e.htmlRenderer().funcs = funcs
+	return e
+}
+
+// LoadHTMLGlob parses the HTML templates matching the glob pattern with the
+// built-in html/template renderer and makes them available to
+// Context.Render under their file names. In debug mode the templates are
+// parsed again on every render, so changes show up without restarting the
+// server.
+//
+// Example:
+//
+//	if err := app.LoadHTMLGlob("templates/*.html"); err != nil {
+//		log.Fatal(err)
+//	}
+func (e *Engine) LoadHTMLGlob(pattern string) error {
+	r := e.htmlRenderer()
+
+	t, err := template.New("").Funcs(requestFuncPlaceholders(r.funcs)).Funcs(r.funcs).ParseGlob(pattern)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.pattern = pattern
+	r.template = t
+	r.perRequest = callsRequestFuncs(t, r.funcs)
+	r.mu.Unlock()
+	return nil
+}
+
+// Render implements Renderer, re-parsing the templates from disk first
+// when the engine runs in debug mode.
+func (r *htmlRenderer) Render(w io.Writer, name string, data interface{}, c *Context) error {
+	r.mu.RLock()
+	t, pattern, perRequest := r.template, r.pattern, r.perRequest
+	r.mu.RUnlock()
+
+	if t == nil {
+		return errNoRenderer
+	}
+
+	if c.engine != nil && c.engine.debug {
+		reloaded, err := template.New("").Funcs(requestFuncPlaceholders(r.funcs)).Funcs(r.funcs).ParseGlob(pattern)
+		if err != nil {
+			return err
+		}
+		t, perRequest = reloaded, callsRequestFuncs(reloaded, r.funcs)
+	} else if perRequest {
+		clone, err := t.Clone()
+		if err != nil {
+			return err
+		}
+		t = clone
+	}
+
+	if perRequest {
+		funcs := make(template.FuncMap)
+		for name := range requestFuncPlaceholders(r.funcs) {
+			funcs[name] = requestFuncs[name](c)
+		}
+		t.Funcs(funcs)
+	}
+	return t.ExecuteTemplate(w, name, data)
+}
+
+// callsRequestFuncs reports whether the templates of t call request
+// functions not overridden by funcs.
+func callsRequestFuncs(t *template.Template, funcs template.FuncMap) bool {
+	placeholders := requestFuncPlaceholders(funcs)
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree != nil && nodeCallsFuncs(tmpl.Tree.Root, placeholders) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeCallsFuncs reports whether the parse tree below node calls one of
+// the functions in funcs.
+func nodeCallsFuncs(node parse.Node, funcs template.FuncMap) bool {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, child := range n.Nodes {
+			if nodeCallsFuncs(child, funcs) {
+				return true
+			}
+		}
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				if nodeCallsFuncs(arg, funcs) {
+					return true
+				}
+			}
+		}
+	case *parse.IdentifierNode:
+		_, ok := funcs[n.Ident]
+		return ok
+	case *parse.ActionNode:
+		return nodeCallsFuncs(n.Pipe, funcs)
+	case *parse.TemplateNode:
+		return nodeCallsFuncs(n.Pipe, funcs)
+	case *parse.IfNode:
+		return branchCallsFuncs(&n.BranchNode, funcs)
+	case *parse.RangeNode:
+		return branchCallsFuncs(&n.BranchNode, funcs)
+	case *parse.WithNode:
+		return branchCallsFuncs(&n.BranchNode, funcs)
+	}
+	return false
+}
+
+// branchCallsFuncs reports whether the pipeline or either list of an if,
+// range or with action calls one of the functions in funcs.
+func branchCallsFuncs(n *parse.BranchNode, funcs template.FuncMap) bool {
+	return nodeCallsFuncs(n.Pipe, funcs) || nodeCallsFuncs(n.List, funcs) || nodeCallsFuncs(n.ElseList, funcs)
+}
+
+// Render executes the named template with the Engine's Renderer and writes
+// the result to the response with the specified status code. It
+// automatically sets the Content-Type header to "text/html; charset=utf-8"
+// unless the renderer already set one.
+//
+// Example:
+//
+//	c.Render(200, "user.html", map[string]interface{}{
+//		"Name": user.Name,
+//	})
+func (c *Context) Render(code int, name string, data interface{}) error {
+	if c.engine == nil || c.engine.renderer == nil {
+		return errNoRenderer
+	}
+
+	// Render into a buffer so template errors don't leave a
+	// half-written page behind
+	var buf bytes.Buffer
+	if err := c.engine.renderer.Render(&buf, name, data, c); err != nil {
+		return err
+	}
+
+	contentType := c.Response.Header().Get("Content-Type")
+	if contentType == "" {
+		contentType = "text/html; charset=utf-8"
+	}
+	c.writeContentType(code, contentType)
+	_, err := c.Response.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,127 @@
+package goxpress
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	path := writeTempFile(t, "hello.html", `<h1>Hello {{upper .Name}}</h1>`)
+
+	app := New()
+	app.SetFuncMap(template.FuncMap{"upper": strings.ToUpper})
+	if err := app.LoadHTMLGlob(filepath.Join(filepath.Dir(path), "*.html")); err != nil {
+		t.Fatalf("LoadHTMLGlob should not return error: %v", err)
+	}
+
+	app.GET("/hello", func(c *Context) {
+		c.Render(200, "hello.html", map[string]string{"Name": "<john>"})
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/hello", nil))
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("Expected Content-Type 'text/html; charset=utf-8', got '%s'", ct)
+	}
+	if w.Body.String() != "<h1>Hello &lt;JOHN&gt;</h1>" {
+		t.Errorf("Unexpected body %q", w.Body.String())
+	}
+
+	t.Run("NoTemplates", func(t *testing.T) {
+		c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		if err := c.Render(200, "hello.html", nil); err == nil {
+			t.Error("Render should fail without loaded templates")
+		}
+	})
+
+	t.Run("UnknownTemplate", func(t *testing.T) {
+		var renderErr error
+		app.GET("/unknown", func(c *Context) {
+			renderErr = c.Render(200, "missing.html", nil)
+		})
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/unknown", nil))
+
+		if renderErr == nil {
+			t.Error("Render should fail for unknown templates")
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("Failed render should not write a body, got %q", w.Body.String())
+		}
+	})
+}
+
+func TestRenderDebugReload(t *testing.T) {
+	path := writeTempFile(t, "page.html", "v1")
+	pattern := filepath.Join(filepath.Dir(path), "*.html")
+
+	render := func(app *Engine) string {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w.Body.String()
+	}
+	handler := func(c *Context) {
+		c.Render(200, "page.html", nil)
+	}
+
+	production := New()
+	production.GET("/", handler)
+	development := New().SetDebug(true)
+	development.GET("/", handler)
+
+	for _, app := range []*Engine{production, development} {
+		if err := app.LoadHTMLGlob(pattern); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ioutil.WriteFile(path, []byte("v2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if body := render(production); body != "v1" {
+		t.Errorf("Templates should be cached outside debug mode, got %q", body)
+	}
+	if body := render(development); body != "v2" {
+		t.Errorf("Templates should be reloaded in debug mode, got %q", body)
+	}
+	if !development.IsDebug() || production.IsDebug() {
+		t.Error("IsDebug should reflect SetDebug")
+	}
+}
+
+// greetingRenderer is a custom Renderer used to test SetRenderer.
+type greetingRenderer struct{}
+
+func (greetingRenderer) Render(w io.Writer, name string, data interface{}, c *Context) error {
+	if name != "greeting" {
+		return fmt.Errorf("unknown template %s", name)
+	}
+	_, err := fmt.Fprintf(w, "Hi %v from %s", data, c.FullPath())
+	return err
+}
+
+func TestSetRenderer(t *testing.T) {
+	app := New().SetRenderer(greetingRenderer{})
+	app.GET("/greet", func(c *Context) {
+		c.Render(200, "greeting", "John")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/greet", nil))
+
+	if w.Body.String() != "Hi John from /greet" {
+		t.Errorf("Unexpected body %q", w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("Expected default Content-Type, got '%s'", ct)
+	}
+}
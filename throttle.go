@@ -0,0 +1,257 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the per-IP throttling middleware with temporary bans.
+package goxpress
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// BanStore keeps the violations and bans of the throttling middleware.
+// Implementations must be safe for concurrent use.
+type BanStore interface {
+	// BannedFor returns how long key remains banned, or zero if it is
+	// not banned.
+	BannedFor(key string) time.Duration
+
+	// Strike records a violation for key and returns the number of
+	// violations recorded in the current window of the given length.
+	Strike(key string, window time.Duration) int
+
+	// Ban bans key for d and clears its violations.
+	Ban(key string, d time.Duration)
+}
+
+// ThrottleConfig defines configuration options for the throttling
+// middleware.
+type ThrottleConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper Skipper
+
+	// Rate is the number of requests per second each client may sustain.
+	// If zero, defaults to 10.
+	Rate float64
+
+	// Burst is the number of requests a client may make at once before
+	// being throttled to Rate. If zero, defaults to twice Rate.
+	Burst int
+
+	// KeyFunc returns the key clients are tracked by.
+	// If nil, defaults to the client IP.
+	KeyFunc func(c *Context) string
+
+	// BanThreshold is the number of throttled requests within BanWindow
+	// that gets a client banned. If zero, defaults to 20.
+	BanThreshold int
+
+	// BanWindow is the period throttled requests are counted over.
+	// If zero, defaults to 1 minute.
+	BanWindow time.Duration
+
+	// BanDuration is how long a banned client is rejected.
+	// If zero, defaults to 10 minutes.
+	BanDuration time.Duration
+
+	// OnBan, if set, is called when a client gets banned, e.g. to log the
+	// offending address or push it to a firewall.
+	OnBan func(c *Context, key string, d time.Duration)
+
+	// Handler is called for throttled requests. It runs after the
+	// Retry-After header is set. If nil, a 429 Too Many Requests response
+	// is sent.
+	Handler HandlerFunc
+
+	// BannedHandler is called for requests of banned clients. It runs
+	// after the Retry-After header is set. If nil, a 403 Forbidden
+	// response is sent.
+	BannedHandler HandlerFunc
+
+	// Limiter keeps the request rate state.
+	// If nil, defaults to a MemoryLimiterStore using Rate and Burst.
+	Limiter LimiterStore
+
+	// Store keeps violations and bans. Use a shared store to ban clients
+	// across several instances.
+	// If nil, defaults to a MemoryBanStore.
+	Store BanStore
+}
+
+// Throttle returns a middleware that limits the request rate of each
+// client and temporarily bans clients that keep exceeding it.
+//
+// Unlike RateLimit, which only smooths the request rate, Throttle treats
+// persistent excess as abuse: requests over the rate are rejected with
+// 429 Too Many Requests, and once a client has been throttled
+// BanThreshold times within BanWindow, all its requests are rejected with
+// 403 Forbidden for BanDuration.
+//
+// Example:
+//
+//	app.Use(goxpress.Throttle(goxpress.ThrottleConfig{
+//		Rate:         20,
+//		Burst:        100,
+//		BanThreshold: 50,
+//		BanDuration:  time.Hour,
+//		OnBan: func(c *goxpress.Context, ip string, d time.Duration) {
+//			log.Printf("banned %s for %v", ip, d)
+//		},
+//	}))
+func Throttle(config ThrottleConfig) HandlerFunc {
+	// Set defaults
+	if config.Rate <= 0 {
+		config.Rate = 10
+	}
+	if config.Burst <= 0 {
+		config.Burst = int(math.Ceil(2 * config.Rate))
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = func(c *Context) string { return c.ClientIP() }
+	}
+	if config.BanThreshold <= 0 {
+		config.BanThreshold = 20
+	}
+	if config.BanWindow <= 0 {
+		config.BanWindow = time.Minute
+	}
+	if config.BanDuration <= 0 {
+		config.BanDuration = 10 * time.Minute
+	}
+	if config.Handler == nil {
+		config.Handler = func(c *Context) {
+			c.String(http.StatusTooManyRequests, "Too Many Requests")
+		}
+	}
+	if config.BannedHandler == nil {
+		config.BannedHandler = func(c *Context) {
+			c.String(http.StatusForbidden, "Forbidden")
+		}
+	}
+	if config.Limiter == nil {
+		config.Limiter = NewMemoryLimiterStore(config.Rate, config.Burst)
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryBanStore()
+	}
+
+	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		key := config.KeyFunc(c)
+		if banned := config.Store.BannedFor(key); banned > 0 {
+			c.Response.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(banned)))
+			c.Abort()
+			config.BannedHandler(c)
+			return
+		}
+
+		allowed, retryAfter := config.Limiter.Allow(key)
+		if allowed {
+			c.Next()
+			return
+		}
+
+		if config.Store.Strike(key, config.BanWindow) >= config.BanThreshold {
+			config.Store.Ban(key, config.BanDuration)
+			if config.OnBan != nil {
+				config.OnBan(c, key, config.BanDuration)
+			}
+		}
+
+		c.Response.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+		c.Abort()
+		config.Handler(c)
+	}
+}
+
+// MemoryBanStore is a BanStore that keeps violations and bans in process
+// memory. Expired entries are discarded periodically.
+type MemoryBanStore struct {
+	mu        sync.Mutex
+	strikes   map[string]*strikeWindow
+	bans      map[string]time.Time // Ban expiry per key
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// strikeWindow counts the violations of a key in a fixed window.
+type strikeWindow struct {
+	count int
+	end   time.Time
+}
+
+// NewMemoryBanStore creates an empty in-memory ban store.
+func NewMemoryBanStore() *MemoryBanStore {
+	return &MemoryBanStore{
+		strikes: make(map[string]*strikeWindow),
+		bans:    make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// BannedFor returns how long key remains banned.
+func (s *MemoryBanStore) BannedFor(key string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until, ok := s.bans[key]
+	if !ok {
+		return 0
+	}
+	remaining := until.Sub(s.now())
+	if remaining <= 0 {
+		delete(s.bans, key)
+		return 0
+	}
+	return remaining
+}
+
+// Strike records a violation for key.
+func (s *MemoryBanStore) Strike(key string, window time.Duration) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	w, ok := s.strikes[key]
+	if !ok || !now.Before(w.end) {
+		w = &strikeWindow{end: now.Add(window)}
+		s.strikes[key] = w
+	}
+	w.count++
+	return w.count
+}
+
+// Ban bans key for d.
+func (s *MemoryBanStore) Ban(key string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bans[key] = s.now().Add(d)
+	delete(s.strikes, key)
+}
+
+// sweep removes expired windows and bans. It runs at most once a minute.
+func (s *MemoryBanStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, w := range s.strikes {
+		if !now.Before(w.end) {
+			delete(s.strikes, key)
+		}
+	}
+	for key, until := range s.bans {
+		if !now.Before(until) {
+			delete(s.bans, key)
+		}
+	}
+}
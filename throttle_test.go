@@ -0,0 +1,73 @@
+package goxpress
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestThrottle(t *testing.T) {
+	var bannedKey string
+
+	app := New()
+	app.Use(Throttle(ThrottleConfig{
+		Rate:         0.001,
+		Burst:        1,
+		BanThreshold: 2,
+		BanDuration:  time.Hour,
+		OnBan: func(c *Context, key string, d time.Duration) {
+			bannedKey = key
+		},
+	}))
+	app.GET("/", func(c *Context) {
+		c.String(200, "ok")
+	})
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	for i, code := range []int{200, 429, 429, 403} {
+		if w := request("10.0.0.1:1234"); w.Code != code {
+			t.Errorf("Request %d: expected status %d, got %d", i+1, code, w.Code)
+		}
+	}
+	if bannedKey != "10.0.0.1" {
+		t.Errorf("Expected OnBan for 10.0.0.1, got '%s'", bannedKey)
+	}
+	if w := request("10.0.0.1:1234"); w.Header().Get("Retry-After") != "3600" {
+		t.Errorf("Expected Retry-After 3600 for banned client, got '%s'", w.Header().Get("Retry-After"))
+	}
+
+	if w := request("10.0.0.2:1234"); w.Code != 200 {
+		t.Errorf("Other clients should not be throttled, got %d", w.Code)
+	}
+}
+
+func TestMemoryBanStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryBanStore()
+	store.now = func() time.Time { return now }
+
+	store.Strike("a", time.Minute)
+	if n := store.Strike("a", time.Minute); n != 2 {
+		t.Errorf("Expected 2 strikes, got %d", n)
+	}
+	now = now.Add(time.Minute)
+	if n := store.Strike("a", time.Minute); n != 1 {
+		t.Errorf("Expected strikes to reset with a new window, got %d", n)
+	}
+
+	store.Ban("a", 10*time.Minute)
+	if d := store.BannedFor("a"); d != 10*time.Minute {
+		t.Errorf("Expected 10m ban, got %v", d)
+	}
+	now = now.Add(10 * time.Minute)
+	if d := store.BannedFor("a"); d != 0 {
+		t.Errorf("Expected ban to expire, got %v", d)
+	}
+}
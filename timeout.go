@@ -0,0 +1,188 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the request timeout middleware.
+package goxpress
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TimeoutConfig defines configuration options for the timeout middleware.
+type TimeoutConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper Skipper
+
+	// Handler writes the response sent when the deadline expires.
+	// If nil, a 503 Service Unavailable response is sent.
+	Handler HandlerFunc
+}
+
+// Timeout returns a middleware that runs the rest of the handler chain
+// under a deadline of d. The request's context is canceled when the
+// deadline expires, and if the chain has not finished by then a 503
+// Service Unavailable response is sent instead.
+//
+// Handlers run on a separate goroutine with their own Context, and their
+// response is buffered until they finish. Writes made after the deadline
+// fail with http.ErrHandlerTimeout and never reach the client, so
+// long-running handlers should watch c.Done() and stop early. Streaming
+// and connection hijacking are not available under Timeout.
+//
+// An optional TimeoutConfig customizes the response sent on expiry.
+//
+// Example:
+//
+//	app.Use(goxpress.Timeout(5 * time.Second))
+//
+//	api.Use(goxpress.Timeout(2*time.Second, goxpress.TimeoutConfig{
+//		Handler: func(c *goxpress.Context) {
+//			c.JSON(503, map[string]string{"error": "request timed out"})
+//		},
+//	}))
+func Timeout(d time.Duration, opts ...TimeoutConfig) HandlerFunc {
+	var config TimeoutConfig
+	if len(opts) > 0 {
+		config = opts[0]
+	}
+
+	// Set defaults
+	if config.Handler == nil {
+		config.Handler = func(c *Context) {
+			c.String(http.StatusServiceUnavailable, "Service Unavailable")
+		}
+	}
+
+	return func(c *Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+
+		tw := &timeoutWriter{header: make(http.Header)}
+		tc := c.detach(c.Request.WithContext(ctx), tw)
+
+		done := make(chan struct{})
+		panicked := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+					return
+				}
+				close(done)
+			}()
+			tc.Next()
+		}()
+
+		// The rest of the chain runs on tc
+		c.index = len(c.handlers)
+
+		select {
+		case p := <-panicked:
+			panic(p)
+		case <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+
+			c.Errors = append(c.Errors, tc.Errors...)
+			if tc.err != nil {
+				c.err = tc.err
+			}
+			for _, entry := range tc.storeSnapshot() {
+				c.Set(entry.key, entry.value)
+			}
+			tw.flushTo(c)
+		case <-ctx.Done():
+			tw.mu.Lock()
+			tw.timedOut = true
+			tw.mu.Unlock()
+
+			config.Handler(c)
+		}
+	}
+}
+
+// detach returns a standalone copy of the Context for running the rest of
+// the handler chain on another goroutine. The copy is not pooled, so it
+// stays valid after the request completes.
+func (c *Context) detach(req *http.Request, w http.ResponseWriter) *Context {
+	dc := &Context{
+		Request:   req,
+		params:    append([]param(nil), c.params...),
+		fullPath:  c.fullPath,
+		bodyLimit: c.bodyLimit,
+		handlers:  c.handlers,
+		index:     c.index,
+		store:     c.storeSnapshot(),
+		logger:    c.logger,
+		engine:    c.engine,
+	}
+	dc.safeStore = c.safeStore
+	dc.propagateValues = c.propagateValues
+	dc.writer.reset(w)
+	dc.Writer = &dc.writer
+	dc.Response = dc.Writer
+	return dc
+}
+
+// timeoutWriter buffers the response of a handler running under Timeout.
+// It keeps its own header map so a timed-out handler cannot race with the
+// timeout response.
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	status   int          // Status code passed to WriteHeader, 0 if not called
+	body     bytes.Buffer // Buffered response body
+	timedOut bool         // Whether the deadline expired
+}
+
+// Header returns the buffered response headers.
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader records the status code without sending it.
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.status == 0 && !w.timedOut {
+		w.status = code
+	}
+}
+
+// Write appends data to the buffered body, failing once the deadline has
+// expired.
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(data)
+}
+
+// flushTo sends the buffered response through c. The caller must hold mu.
+func (w *timeoutWriter) flushTo(c *Context) {
+	header := c.Response.Header()
+	for k, v := range w.header {
+		header[k] = v
+	}
+
+	if w.status == 0 {
+		return // Nothing was written
+	}
+	c.Response.WriteHeader(w.status)
+	c.statusCodeWritten = true
+	c.Response.Write(w.body.Bytes())
+}
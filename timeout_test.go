@@ -0,0 +1,107 @@
+package goxpress
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	lateWrite := make(chan error, 1)
+
+	app := New()
+	app.Use(Timeout(50 * time.Millisecond))
+	app.GET("/fast/:id", func(c *Context) {
+		c.Set("user", "john")
+		c.Header("X-Id", c.Param("id"))
+		c.String(201, "created")
+	})
+	app.GET("/slow", func(c *Context) {
+		<-c.Done()
+		time.Sleep(10 * time.Millisecond)
+		_, err := c.Response.Write([]byte("too late"))
+		lateWrite <- err
+	})
+
+	t.Run("Completed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/fast/7", nil))
+
+		if w.Code != 201 || w.Body.String() != "created" {
+			t.Errorf("Expected 201 'created', got %d '%s'", w.Code, w.Body.String())
+		}
+		if w.Header().Get("X-Id") != "7" {
+			t.Errorf("Expected X-Id header '7', got '%s'", w.Header().Get("X-Id"))
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", "/slow", nil))
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status 503, got %d", w.Code)
+		}
+		if w.Body.String() != "Service Unavailable" {
+			t.Errorf("Unexpected body '%s'", w.Body.String())
+		}
+
+		if err := <-lateWrite; err != http.ErrHandlerTimeout {
+			t.Errorf("Expected late write to fail with ErrHandlerTimeout, got %v", err)
+		}
+		if w.Body.String() != "Service Unavailable" {
+			t.Error("Late writes should not reach the client")
+		}
+	})
+}
+
+func TestTimeoutConfig(t *testing.T) {
+	app := New()
+	app.Use(Timeout(10*time.Millisecond, TimeoutConfig{
+		Handler: func(c *Context) {
+			c.JSON(504, map[string]string{"error": "timeout"})
+		},
+	}))
+	app.GET("/", func(c *Context) {
+		<-c.Done()
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != 504 || w.Body.String() != "{\"error\":\"timeout\"}\n" {
+		t.Errorf("Expected custom timeout response, got %d '%s'", w.Code, w.Body.String())
+	}
+}
+
+func TestTimeoutPropagatesErrors(t *testing.T) {
+	var handled error
+
+	app := New()
+	app.Use(Recover(), Timeout(time.Second))
+	app.UseError(func(err error, c *Context) {
+		handled = err
+		c.String(500, "error")
+	})
+	app.GET("/error", func(c *Context) {
+		c.Next(errors.New("boom"))
+	})
+	app.GET("/panic", func(c *Context) {
+		panic("kaboom")
+	})
+
+	for _, path := range []string{"/error", "/panic"} {
+		handled = nil
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+		if handled == nil {
+			t.Errorf("%s: expected error to reach error handlers", path)
+		}
+		if w.Code != 500 {
+			t.Errorf("%s: expected status 500, got %d", path, w.Code)
+		}
+	}
+}
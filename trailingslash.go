@@ -0,0 +1,123 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the trailing slash normalization middleware.
+package goxpress
+
+import (
+	"strings"
+)
+
+// TrailingSlashConfig defines configuration options for the trailing slash
+// middleware.
+type TrailingSlashConfig struct {
+	// Skipper, if set, skips the middleware for requests it returns true for.
+	Skipper Skipper
+
+	// RedirectCode, if set, redirects clients to the normalized URL with
+	// this status code, such as 301 Moved Permanently or 308 Permanent
+	// Redirect, which keeps the request method and body. If zero, the
+	// request path is rewritten in place instead.
+	RedirectCode int
+}
+
+// AddTrailingSlash returns a middleware that adds a trailing slash to
+// request paths that lack one, rewriting the path seen by the handlers.
+//
+// Routes match with or without a trailing slash either way; the middleware
+// normalizes the URL that handlers, loggers and proxies see. Use
+// AddTrailingSlashWithConfig to redirect clients to the canonical URL
+// instead.
+//
+// Example:
+//
+//	app.Use(goxpress.AddTrailingSlash())
+func AddTrailingSlash() HandlerFunc {
+	return AddTrailingSlashWithConfig(TrailingSlashConfig{})
+}
+
+// AddTrailingSlashWithConfig returns a trailing slash adding middleware
+// with custom configuration.
+//
+// Example:
+//
+//	// GET /docs?page=2 redirects to /docs/?page=2
+//	app.Use(goxpress.AddTrailingSlashWithConfig(goxpress.TrailingSlashConfig{
+//		RedirectCode: 301,
+//	}))
+func AddTrailingSlashWithConfig(config TrailingSlashConfig) HandlerFunc {
+	return trailingSlash(config, func(path string) string {
+		if strings.HasSuffix(path, "/") {
+			return path
+		}
+		return path + "/"
+	})
+}
+
+// RemoveTrailingSlash returns a middleware that removes the trailing
+// slash from request paths other than "/", rewriting the path seen by
+// the handlers.
+//
+// Routes match with or without a trailing slash either way; the middleware
+// normalizes the URL that handlers, loggers and proxies see. Use
+// RemoveTrailingSlashWithConfig to redirect clients to the canonical URL
+// instead.
+//
+// Example:
+//
+//	app.Use(goxpress.RemoveTrailingSlash())
+func RemoveTrailingSlash() HandlerFunc {
+	return RemoveTrailingSlashWithConfig(TrailingSlashConfig{})
+}
+
+// RemoveTrailingSlashWithConfig returns a trailing slash removing
+// middleware with custom configuration.
+//
+// Example:
+//
+//	// GET /users/?sort=name redirects to /users?sort=name
+//	app.Use(goxpress.RemoveTrailingSlashWithConfig(goxpress.TrailingSlashConfig{
+//		RedirectCode: 301,
+//	}))
+func RemoveTrailingSlashWithConfig(config TrailingSlashConfig) HandlerFunc {
+	return trailingSlash(config, func(path string) string {
+		return strings.TrimRight(path, "/")
+	})
+}
+
+// trailingSlash returns a middleware normalizing request paths with
+// normalize, either by redirecting or by rewriting the request URL.
+func trailingSlash(config TrailingSlashConfig, normalize func(path string) string) HandlerFunc {
+	return func(c *Context) {
+		url := c.Request.URL
+		if (config.Skipper != nil && config.Skipper(c)) || url.Path == "/" || url.Path == "" {
+			c.Next()
+			return
+		}
+
+		path := normalize(url.Path)
+		if path == url.Path {
+			c.Next()
+			return
+		}
+		if path == "" {
+			path = "/"
+		}
+
+		if config.RedirectCode == 0 {
+			url.Path = path
+			if url.RawPath != "" {
+				url.RawPath = normalize(url.RawPath)
+			}
+			c.Next()
+			return
+		}
+
+		// Collapse leading slashes so "//evil.com/" cannot turn into a
+		// redirect to another host
+		target := "/" + strings.TrimLeft(normalize(url.EscapedPath()), "/")
+		if url.RawQuery != "" {
+			target += "?" + url.RawQuery
+		}
+		c.Abort()
+		c.Redirect(config.RedirectCode, target)
+	}
+}
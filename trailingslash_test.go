@@ -0,0 +1,50 @@
+package goxpress
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTrailingSlash(t *testing.T) {
+	tests := []struct {
+		name       string
+		middleware HandlerFunc
+		target     string
+		code       int
+		expected   string // Path seen by the handler or redirect location
+	}{
+		{"AddRewrite", AddTrailingSlash(), "/docs?page=2", 200, "/docs/"},
+		{"AddUnchanged", AddTrailingSlash(), "/docs/", 200, "/docs/"},
+		{"AddRedirect", AddTrailingSlashWithConfig(TrailingSlashConfig{RedirectCode: 301}), "/docs?page=2", 301, "/docs/?page=2"},
+		{"RemoveRewrite", RemoveTrailingSlash(), "/docs/", 200, "/docs"},
+		{"RemoveRoot", RemoveTrailingSlash(), "/", 200, "/"},
+		{"RemoveRedirect", RemoveTrailingSlashWithConfig(TrailingSlashConfig{RedirectCode: 308}), "/docs/?page=2", 308, "/docs?page=2"},
+		{"RemoveRedirectEscaped", RemoveTrailingSlashWithConfig(TrailingSlashConfig{RedirectCode: 301}), "/a%2Fb/", 301, "/a%2Fb"},
+		{"NoOpenRedirect", RemoveTrailingSlashWithConfig(TrailingSlashConfig{RedirectCode: 301}), "//evil.com/", 301, "/evil.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := New()
+			app.Use(tt.middleware)
+			app.Use(func(c *Context) {
+				c.Abort()
+				c.String(200, c.Request.URL.Path)
+			})
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest("GET", tt.target, nil))
+
+			if w.Code != tt.code {
+				t.Fatalf("Expected status %d, got %d", tt.code, w.Code)
+			}
+			got := w.Body.String()
+			if tt.code != 200 {
+				got = w.Header().Get("Location")
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
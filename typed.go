@@ -0,0 +1,158 @@
+//go:build go1.18
+
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the generics-based typed handler API. It requires
+// Go 1.18 or later.
+package goxpress
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// H adapts a typed handler function to a HandlerFunc. For each request, H
+// binds a new Req from the request, validates it, calls fn and writes the
+// returned Res as JSON with status 200, or 204 if Res is struct{}.
+//
+// Req is bound in this order, later sources overriding earlier ones:
+//   - the body, as a form for form content types and as JSON otherwise,
+//     when the request has one
+//   - the query string, as with BindQuery
+//   - the path parameters, matched by the `path` tag of the fields as
+//     BindQuery matches the `query` tag
+//
+// If Req implements Validator, Validate is called after binding. Binding
+// and validation errors abort the request with a 400 Bad Request
+// HTTPError wrapping them; other errors returned by fn abort it with a 500
+// Internal Server Error HTTPError. In both cases the error is recorded
+// with Context.Error without writing a status, so the handlers registered
+// with UseError choose the response. ValidationErrors and HTTPErrors are
+// recorded as they are, so by default they are answered with 422
+// Unprocessable Entity and their own status. If fn writes the response
+// itself, Res is not written.
+//
+// Register the handler with Typed instead of a routing method to record
+// the Req and Res types on the route, so MountDocs and GenerateClient
+// describe them.
+//
+// Example:
+//
+//	type CreateUser struct {
+//		OrgID string `path:"org" json:"-"`
+//		Name  string `json:"name"`
+//	}
+//
+//	func (r CreateUser) Validate() error {
+//		if r.Name == "" {
+//			return errors.New("name is required")
+//		}
+//		return nil
+//	}
+//
+//	app.POST("/orgs/:org/users", goxpress.H(func(c *goxpress.Context, req CreateUser) (User, error) {
+//		return users.Create(c.Request.Context(), req.OrgID, req.Name)
+//	}))
+func H[Req, Res any](fn func(c *Context, req Req) (Res, error)) HandlerFunc {
+	return func(c *Context) {
+		var req Req
+		if err := c.bindTyped(&req); err != nil {
+			c.Abort()
+			c.Error(NewHTTPError(http.StatusBadRequest, "", WithInternal(err)))
+			return
+		}
+		if err := validate(&req); err != nil {
+			var validationErrs ValidationErrors
+			if !errors.As(err, &validationErrs) {
+				err = NewHTTPError(http.StatusBadRequest, "", WithInternal(err))
+			}
+			c.Abort()
+			c.Error(err)
+			return
+		}
+
+		res, err := fn(c, req)
+		if err != nil {
+			var httpErr *HTTPError
+			var validationErrs ValidationErrors
+			if !errors.As(err, &httpErr) && !errors.As(err, &validationErrs) {
+				err = NewHTTPError(http.StatusInternalServerError, "", WithInternal(err))
+			}
+			c.Abort()
+			c.Error(err)
+			return
+		}
+		if c.statusCodeWritten || c.Writer.Written() {
+			return
+		}
+		if _, ok := interface{}(res).(struct{}); ok {
+			c.Status(http.StatusNoContent)
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+// TypedRouter is implemented by Engine and Router, on which Typed
+// registers routes.
+type TypedRouter interface {
+	handle(method, pattern string, handlers []HandlerFunc, types *handlerTypes)
+}
+
+// Typed registers a route for the given method and pattern on r, running
+// middleware and then fn adapted with H. The Req and Res types are recorded
+// on the route, so MountDocs documents their JSON schemas and
+// GenerateClient generates typed functions for it.
+//
+// Example:
+//
+//	goxpress.Typed(app, "POST", "/orgs/:org/users", func(c *goxpress.Context, req CreateUser) (User, error) {
+//		return users.Create(c.Request.Context(), req.OrgID, req.Name)
+//	})
+//
+//	api := app.Route("/api")
+//	goxpress.Typed(api, "GET", "/users/:id", getUser, auth)
+func Typed[Req, Res any](r TypedRouter, method, pattern string, fn func(c *Context, req Req) (Res, error), middleware ...HandlerFunc) {
+	handlers := append(middleware[:len(middleware):len(middleware)], H(fn))
+	r.handle(method, pattern, handlers, &handlerTypes{request: typeOf[Req](), response: typeOf[Res]()})
+}
+
+// bindTyped binds the body, query string and path parameters into obj, a
+// pointer to the request value of a typed handler.
+func (c *Context) bindTyped(obj interface{}) error {
+	req := c.Request
+	if req.Body != nil && req.Body != http.NoBody && (req.ContentLength > 0 || req.ContentLength == -1) {
+		contentType := req.Header.Get("Content-Type")
+		switch {
+		case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"),
+			strings.HasPrefix(contentType, "multipart/form-data"):
+			if err := c.BindForm(obj); err != nil {
+				return err
+			}
+		default:
+			if err := c.BindJSON(obj); err != nil {
+				return err
+			}
+		}
+	}
+
+	if reflect.TypeOf(obj).Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	if len(req.URL.RawQuery) > 0 {
+		if err := c.BindQuery(obj); err != nil {
+			return err
+		}
+	}
+	if len(c.params) > 0 {
+		values := make(map[string][]string, len(c.params))
+		for _, p := range c.params {
+			values[p.key] = []string{p.value}
+		}
+		if err := bindValues(obj, values, "path"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+//go:build go1.18
+
+package goxpress
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type typedRequest struct {
+	OrgID string `path:"org" json:"-"`
+	Name  string `json:"name"`
+	Dry   bool   `query:"dry" json:"-"`
+}
+
+func (r typedRequest) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+type typedResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Dry  bool   `json:"dry"`
+}
+
+func TestTypedHandler(t *testing.T) {
+	var handled error
+	app := New()
+	app.UseError(func(err error, c *Context) {
+		handled = err
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			c.String(httpErr.Code, errors.Unwrap(httpErr).Error())
+		}
+	})
+	app.POST("/orgs/:org/users", H(func(c *Context, req typedRequest) (typedResponse, error) {
+		if req.Name == "fail" {
+			return typedResponse{}, errors.New("db down")
+		}
+		return typedResponse{ID: req.OrgID + "/1", Name: req.Name, Dry: req.Dry}, nil
+	}))
+	app.DELETE("/orgs/:org", H(func(c *Context, req struct{}) (struct{}, error) {
+		return struct{}{}, nil
+	}))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/orgs/acme/users?dry=true", strings.NewReader(`{"name":"ann"}`))
+	app.ServeHTTP(w, req)
+	var res typedResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil || w.Code != 200 {
+		t.Fatalf("Expected JSON response, got %d %q", w.Code, w.Body.String())
+	}
+	if res != (typedResponse{ID: "acme/1", Name: "ann", Dry: true}) {
+		t.Errorf("Expected body, query and path to be bound, got %+v", res)
+	}
+
+	tests := []struct {
+		body   string
+		status int
+		err    string
+	}{
+		{`{"name":`, 400, "unexpected EOF"},
+		{`{}`, 400, "name is required"},
+		{`{"name":"fail"}`, 500, "db down"},
+	}
+	for _, tt := range tests {
+		handled = nil
+		w = httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("POST", "/orgs/acme/users", strings.NewReader(tt.body)))
+		if w.Code != tt.status || handled == nil || w.Body.String() != tt.err {
+			t.Errorf("Body %s: expected %d %q, got %d %q", tt.body, tt.status, tt.err, w.Code, w.Body.String())
+		}
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("DELETE", "/orgs/acme", nil))
+	if w.Code != 204 || w.Body.Len() != 0 {
+		t.Errorf("Expected 204 for struct{} response, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestTypedHandlerErrorStatus(t *testing.T) {
+	app := New()
+	app.UseError(func(err error, c *Context) {
+		if errors.Is(err, ErrInternalServerError) {
+			c.JSON(503, map[string]string{"error": "try again later"})
+		}
+	})
+	app.POST("/jobs", H(func(c *Context, req typedRequest) (struct{}, error) {
+		return struct{}{}, errors.New("queue full")
+	}))
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/jobs", strings.NewReader(`{"name":"ann"}`)))
+	if w.Code != 503 || w.Body.String() != "{\"error\":\"try again later\"}\n" {
+		t.Errorf("Expected the error handler to choose the status, got %d %q", w.Code, w.Body.String())
+	}
+
+	// Without a response from the error handlers, the default handling
+	// answers with a body and content type
+	for body, status := range map[string]int{`{"name":`: 400, `{}`: 400, `{"name":"ann"}`: 500} {
+		app := New()
+		app.POST("/jobs", H(func(c *Context, req typedRequest) (struct{}, error) {
+			return struct{}{}, errors.New("queue full")
+		}))
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("POST", "/jobs", strings.NewReader(body)))
+		if w.Code != status || w.Header().Get("Content-Type") == "" || !strings.Contains(w.Body.String(), `"error"`) {
+			t.Errorf("Body %s: expected %d with an error body, got %d %q", body, status, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestTypedHandlerHTTPError(t *testing.T) {
+	app := New()
+	app.GET("/users/:id", H(func(c *Context, req struct{}) (typedResponse, error) {
+		return typedResponse{}, ErrNotFound
+	}))
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/users/1", nil))
+	if w.Code != 404 || !strings.Contains(w.Body.String(), `"Not Found"`) {
+		t.Errorf("Expected HTTPError status and body, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+type typedSignup struct {
+	Name string `json:"name"`
+}
+
+func (s typedSignup) Validate() error {
+	var errs ValidationErrors
+	if s.Name == "" {
+		errs.Add("name", "required", "")
+	}
+	return errs.Err()
+}
+
+func TestTypedHandlerValidationErrors(t *testing.T) {
+	app := New()
+	app.POST("/signup", H(func(c *Context, req typedSignup) (struct{}, error) {
+		return struct{}{}, nil
+	}))
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("POST", "/signup", strings.NewReader(`{}`)))
+	if w.Code != 422 || !strings.Contains(w.Body.String(), `"message":"name is required"`) {
+		t.Errorf("Expected 422 with field errors, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestTypedHandlerDocs(t *testing.T) {
+	app := New()
+	app.MountDocs("/docs")
+	Typed(app.Route("/orgs"), "POST", "/:org/users", func(c *Context, req typedRequest) (typedResponse, error) {
+		return typedResponse{}, nil
+	})
+	app.POST("/jobs", H(func(c *Context, req typedRequest) (typedResponse, error) {
+		return typedResponse{}, nil
+	}))
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/docs/openapi.json", nil))
+
+	var spec struct {
+		Paths map[string]map[string]struct {
+			Parameters []struct {
+				Name string `json:"name"`
+				In   string `json:"in"`
+			} `json:"parameters"`
+			RequestBody struct {
+				Content map[string]struct {
+					Schema map[string]interface{} `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+			Responses map[string]struct {
+				Content map[string]struct {
+					Schema map[string]interface{} `json:"schema"`
+				} `json:"content"`
+			} `json:"responses"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("Spec is not valid JSON: %v", err)
+	}
+
+	op := spec.Paths["/orgs/{org}/users"]["post"]
+	if len(op.Parameters) != 2 || op.Parameters[1].Name != "dry" || op.Parameters[1].In != "query" {
+		t.Errorf("Expected path and query parameters, got %+v", op.Parameters)
+	}
+	request := op.RequestBody.Content["application/json"].Schema["properties"].(map[string]interface{})
+	if len(request) != 1 || request["name"] == nil {
+		t.Errorf("Expected request schema with name, got %v", request)
+	}
+	response := op.Responses["200"].Content["application/json"].Schema["properties"].(map[string]interface{})
+	if len(response) != 3 || response["dry"].(map[string]interface{})["type"] != "boolean" {
+		t.Errorf("Expected response schema, got %v", response)
+	}
+	if content := spec.Paths["/jobs"]["post"].RequestBody.Content; len(content) != 0 {
+		t.Errorf("Routes not registered with Typed should not document types, got %v", content)
+	}
+}
@@ -0,0 +1,251 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains streaming multipart uploads to pluggable storage
+// backends.
+package goxpress
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Errors returned by StreamUpload for rejected files. They are HTTPErrors,
+// so passing them to c.Next produces the matching response.
+var (
+	ErrUploadTooLarge = NewHTTPError(http.StatusRequestEntityTooLarge, "uploaded file is too large")
+	ErrUploadType     = NewHTTPError(http.StatusUnsupportedMediaType, "uploaded file type is not allowed")
+	ErrTooManyUploads = NewHTTPError(http.StatusBadRequest, "too many uploaded files")
+)
+
+// Upload describes a file streamed by StreamUpload.
+type Upload struct {
+	Field       string `json:"field"`        // Form field name
+	Filename    string `json:"filename"`     // Base name of the file as sent by the client; untrusted
+	ContentType string `json:"content_type"` // MIME type sniffed from the content, not the client's claim
+
+	// Set once the file has been stored
+	Size     int64  `json:"size"`     // Number of bytes stored
+	Location string `json:"location"` // Location returned by the UploadSink, e.g. a path or object key
+}
+
+// UploadSink stores uploaded files as they arrive. goxpress provides
+// DiskSink; S3-compatible or Google Cloud Storage sinks are plugged in by
+// implementing UploadSink with the client library of your choice, keeping
+// their dependencies out of goxpress.
+//
+// Example:
+//
+//	// S3-compatible storage with the AWS SDK's streaming upload manager
+//	sink := goxpress.UploadSinkFunc(func(ctx context.Context, u goxpress.Upload, r io.Reader) (string, error) {
+//		key := "avatars/" + uuid.NewString()
+//		_, err := uploader.Upload(ctx, &s3.PutObjectInput{
+//			Bucket:      aws.String("uploads"),
+//			Key:         aws.String(key),
+//			Body:        r,
+//			ContentType: aws.String(u.ContentType),
+//		})
+//		return key, err
+//	})
+type UploadSink interface {
+	// Store reads the content of the file from r until io.EOF and
+	// returns its location. r fails with ErrUploadTooLarge once the file
+	// exceeds the size limit; Store should then discard what it stored
+	// and return the error.
+	Store(ctx context.Context, upload Upload, r io.Reader) (string, error)
+}
+
+// UploadSinkFunc adapts a function to the UploadSink interface.
+type UploadSinkFunc func(ctx context.Context, upload Upload, r io.Reader) (string, error)
+
+// Store calls f.
+func (f UploadSinkFunc) Store(ctx context.Context, upload Upload, r io.Reader) (string, error) {
+	return f(ctx, upload, r)
+}
+
+// DiskSink is an UploadSink writing files to a local directory under
+// random names that keep the extension of the client's file name. The
+// returned location is the path of the stored file.
+type DiskSink struct {
+	Dir string // Directory the files are created in; must exist
+}
+
+// Store implements UploadSink. Partially written files are removed when
+// reading or writing fails.
+func (s DiskSink) Store(ctx context.Context, upload Upload, r io.Reader) (string, error) {
+	ext := strings.ToLower(filepath.Ext(upload.Filename))
+	if strings.ContainsAny(ext, `/\`) || len(ext) > 16 {
+		ext = ""
+	}
+	f, err := os.CreateTemp(s.Dir, "upload-*"+ext)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// UploadConfig defines the checks StreamUpload applies to uploaded files.
+type UploadConfig struct {
+	// MaxFileSize is the maximum size in bytes of each file.
+	// If zero, files are not limited; use BodyLimit to cap the request.
+	MaxFileSize int64
+
+	// MaxFiles is the maximum number of files stored for the field.
+	// If zero, the number of files is not limited.
+	MaxFiles int
+
+	// AllowedTypes lists the accepted MIME types, such as "image/png",
+	// or type wildcards such as "image/*". Types are sniffed from the
+	// first bytes of each file. If empty, all types are accepted.
+	AllowedTypes []string
+}
+
+// StreamUpload reads the multipart request body part by part and passes
+// every file sent in the form field named field to sink as it arrives, so
+// files are never buffered whole in memory or on disk. Other parts are
+// skipped. It returns the stored files in order.
+//
+// Each file is checked against the optional UploadConfig before and while
+// it is stored. A rejected file stops the upload with ErrUploadTooLarge,
+// ErrUploadType or ErrTooManyUploads, and a 400 HTTPError wrapping
+// http.ErrMissingFile is returned if the field holds no file. Files stored before an error are returned
+// along with it, so they can be cleaned up.
+//
+// Example:
+//
+//	app.POST("/photos", func(c *goxpress.Context) {
+//		uploads, err := c.StreamUpload("photo", goxpress.DiskSink{Dir: "./uploads"}, goxpress.UploadConfig{
+//			MaxFileSize:  10 << 20,
+//			AllowedTypes: []string{"image/*"},
+//		})
+//		if err != nil {
+//			c.Next(err)
+//			return
+//		}
+//		c.JSON(201, map[string]interface{}{"stored": len(uploads)})
+//	})
+func (c *Context) StreamUpload(field string, sink UploadSink, opts ...UploadConfig) ([]Upload, error) {
+	var config UploadConfig
+	if len(opts) > 0 {
+		config = opts[0]
+	}
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, NewHTTPError(http.StatusBadRequest, "", WithInternal(err))
+	}
+
+	var uploads []Upload
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return uploads, NewHTTPError(http.StatusBadRequest, "", WithInternal(err))
+		}
+		if part.FormName() != field || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+		if config.MaxFiles > 0 && len(uploads) == config.MaxFiles {
+			part.Close()
+			return uploads, ErrTooManyUploads
+		}
+
+		upload, err := c.storeUpload(part.FormName(), part.FileName(), part, sink, config)
+		part.Close()
+		if err != nil {
+			return uploads, err
+		}
+		uploads = append(uploads, upload)
+	}
+
+	if len(uploads) == 0 {
+		return nil, NewHTTPError(http.StatusBadRequest, "no file uploaded", WithInternal(http.ErrMissingFile))
+	}
+	return uploads, nil
+}
+
+// storeUpload checks the type of a single file and streams it to sink.
+func (c *Context) storeUpload(field, filename string, r io.Reader, sink UploadSink, config UploadConfig) (Upload, error) {
+	// Sniff the type from the first bytes, which are then replayed
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return Upload{}, err
+	}
+	head = head[:n]
+
+	upload := Upload{
+		Field:       field,
+		Filename:    filepath.Base(strings.ReplaceAll(filename, `\`, "/")),
+		ContentType: http.DetectContentType(head),
+	}
+	if !uploadTypeAllowed(upload.ContentType, config.AllowedTypes) {
+		return Upload{}, ErrUploadType
+	}
+
+	body := &uploadReader{r: io.MultiReader(bytes.NewReader(head), r), limit: config.MaxFileSize}
+	location, err := sink.Store(c, upload, body)
+	if body.exceeded {
+		return Upload{}, ErrUploadTooLarge
+	}
+	if err != nil {
+		return Upload{}, err
+	}
+	upload.Size = body.n
+	upload.Location = location
+	return upload, nil
+}
+
+// uploadTypeAllowed reports whether contentType matches one of allowed.
+func uploadTypeAllowed(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	mediaType := strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	for _, pattern := range allowed {
+		if pattern == mediaType || pattern == "*/*" {
+			return true
+		}
+		if strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mediaType, pattern[:len(pattern)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// uploadReader counts the bytes of a file and fails once it exceeds limit.
+type uploadReader struct {
+	r        io.Reader
+	n        int64 // Bytes read so far
+	limit    int64 // Maximum size, 0 for none
+	exceeded bool
+}
+
+// Read implements io.Reader.
+func (u *uploadReader) Read(p []byte) (int, error) {
+	if u.exceeded {
+		return 0, ErrUploadTooLarge
+	}
+	n, err := u.r.Read(p)
+	u.n += int64(n)
+	if u.limit > 0 && u.n > u.limit {
+		u.exceeded = true
+		return 0, ErrUploadTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,142 @@
+package goxpress
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// multipartRequest builds a POST request with the given files, keyed by
+// field and file name, plus a plain form value.
+func multipartRequest(t *testing.T, files ...[3]string) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("title", "holiday")
+	for _, file := range files {
+		w, err := mw.CreateFormFile(file[0], file[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, file[2])
+	}
+	mw.Close()
+
+	req := httptest.NewRequest("POST", "/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestStreamUpload(t *testing.T) {
+	stored := make(map[string]string)
+	sink := UploadSinkFunc(func(ctx context.Context, u Upload, r io.Reader) (string, error) {
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return "", err
+		}
+		stored[u.Filename] = string(data)
+		return "mem/" + u.Filename, nil
+	})
+
+	var uploads []Upload
+	var uploadErr error
+	app := New()
+	app.POST("/upload", func(c *Context) {
+		uploads, uploadErr = c.StreamUpload("photo", sink)
+		if uploadErr != nil {
+			c.Next(uploadErr)
+		}
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, multipartRequest(t,
+		[3]string{"photo", "a.txt", "first file"},
+		[3]string{"other", "b.txt", "skipped"},
+		[3]string{"photo", `C:\photos\c.txt`, strings.Repeat("x", 2000)},
+	))
+
+	if w.Code != 200 || len(uploads) != 2 {
+		t.Fatalf("Expected 2 uploads, got %d %+v", w.Code, uploads)
+	}
+	if uploads[0] != (Upload{Field: "photo", Filename: "a.txt", ContentType: "text/plain; charset=utf-8", Size: 10, Location: "mem/a.txt"}) {
+		t.Errorf("Unexpected upload %+v", uploads[0])
+	}
+	if uploads[1].Filename != "c.txt" || uploads[1].Size != 2000 || len(stored["c.txt"]) != 2000 {
+		t.Errorf("Expected sanitized name and full content past the sniffed bytes, got %+v", uploads[1])
+	}
+	if _, ok := stored["b.txt"]; ok {
+		t.Error("Files of other fields should be skipped")
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, multipartRequest(t))
+	if w.Code != 400 || !errors.Is(uploadErr, http.ErrMissingFile) {
+		t.Errorf("Expected missing file error, got %d %v", w.Code, uploadErr)
+	}
+}
+
+func TestStreamUploadChecks(t *testing.T) {
+	dir := t.TempDir()
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 100)
+
+	var uploads []Upload
+	app := New()
+	app.POST("/upload", func(c *Context) {
+		var err error
+		uploads, err = c.StreamUpload("photo", DiskSink{Dir: dir}, UploadConfig{
+			MaxFileSize:  1000,
+			MaxFiles:     2,
+			AllowedTypes: []string{"image/*"},
+		})
+		if err != nil {
+			c.Next(err)
+			return
+		}
+		c.Status(201)
+	})
+
+	tests := []struct {
+		name  string
+		files [][3]string
+		code  int
+	}{
+		{"Stored", [][3]string{{"photo", "cat.png", png}}, 201},
+		{"WrongType", [][3]string{{"photo", "cat.png", "plain text"}}, 415},
+		{"TooLarge", [][3]string{{"photo", "big.png", png + strings.Repeat("\x00", 1000)}}, 413},
+		{"TooMany", [][3]string{{"photo", "1.png", png}, {"photo", "2.png", png}, {"photo", "3.png", png}}, 400},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, multipartRequest(t, test.files...))
+			if w.Code != test.code {
+				t.Errorf("Expected %d, got %d %q", test.code, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// Every stored file is on disk; the rejected one was removed
+	entries, _ := ioutil.ReadDir(dir)
+	if len(entries) != 3 {
+		t.Errorf("Expected 3 stored files, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) != ".png" || entry.Size() != int64(len(png)) {
+			t.Errorf("Unexpected stored file %s of %d bytes", entry.Name(), entry.Size())
+		}
+	}
+	if len(uploads) != 2 {
+		t.Errorf("Expected files stored before the error to be returned, got %+v", uploads)
+	}
+	if _, err := os.Stat(uploads[0].Location); err != nil {
+		t.Errorf("Expected location to be the stored path: %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains the structured validation errors returned by bound
+// values and their localized representation.
+package goxpress
+
+import (
+	"net/http"
+	"strings"
+)
+
+// FieldError describes a constraint violated by one field of a bound value.
+type FieldError struct {
+	Field   string `json:"field"`           // Field name as sent by the client, e.g. "email"
+	Rule    string `json:"rule"`            // Violated rule, e.g. "required" or "min"
+	Param   string `json:"param,omitempty"` // Rule parameter, e.g. "8" for a minimum length
+	Message string `json:"message"`         // Human-readable message
+}
+
+// Error implements the error interface.
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.message()
+}
+
+// message returns the message of e, defaulting to the English message of
+// its rule.
+func (e FieldError) message() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	template, ok := defaultValidationMessages[e.Rule]
+	if !ok {
+		template = "{field} is invalid"
+	}
+	return e.format(template)
+}
+
+// format replaces the {field} and {param} placeholders of template.
+func (e FieldError) format(template string) string {
+	return strings.NewReplacer("{field}", e.Field, "{param}", e.Param).Replace(template)
+}
+
+// defaultValidationMessages holds the messages of common rules, used for
+// field errors without a message.
+var defaultValidationMessages = map[string]string{
+	"required": "{field} is required",
+	"min":      "{field} must be at least {param}",
+	"max":      "{field} must be at most {param}",
+	"len":      "{field} must have length {param}",
+	"email":    "{field} must be a valid email address",
+	"url":      "{field} must be a valid URL",
+	"oneof":    "{field} must be one of {param}",
+	"pattern":  "{field} has an invalid format",
+}
+
+// ValidationErrors is the list of field errors found while validating a
+// bound value. Return it from Validator.Validate to report every invalid
+// field at once.
+//
+// When none of the error handlers writes a response, a ValidationErrors
+// found with errors.As in the request's error is answered with 422
+// Unprocessable Entity and a JSON body listing the field errors, with
+// messages localized by Localize:
+//
+//	{"error": "Unprocessable Entity", "errors": [
+//		{"field": "name", "rule": "required", "message": "name is required"}
+//	]}
+//
+// Example:
+//
+//	func (u User) Validate() error {
+//		var errs goxpress.ValidationErrors
+//		if u.Name == "" {
+//			errs.Add("name", "required", "")
+//		}
+//		if len(u.Password) < 8 {
+//			errs.Add("password", "min", "8")
+//		}
+//		return errs.Err()
+//	}
+type ValidationErrors []FieldError
+
+// Add appends an error for field violating rule with the given parameter.
+// Its message is the default message of the rule.
+func (v *ValidationErrors) Add(field, rule, param string) {
+	*v = append(*v, FieldError{Field: field, Rule: rule, Param: param})
+}
+
+// Err returns v as an error, or nil if v is empty, so Validate methods can
+// return it directly.
+func (v ValidationErrors) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+// Error implements the error interface, joining the field errors with "; ".
+func (v ValidationErrors) Error() string {
+	messages := make([]string, len(v))
+	for i, e := range v {
+		messages[i] = e.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Localize returns a copy of v with the messages translated for the
+// request. The translator set with Engine.SetValidationTranslator is used
+// if any; otherwise, with the I18n middleware, each message is looked up
+// in the catalogs under "validation." followed by the rule, such as
+// "validation.required". Catalog messages use the {field} and {param}
+// placeholders instead of format verbs:
+//
+//	{"validation.min": "{field} doit contenir au moins {param} caractères"}
+//
+// Field errors without a translation keep their message, or get the
+// default English message of their rule.
+func (v ValidationErrors) Localize(c *Context) ValidationErrors {
+	localized := make(ValidationErrors, len(v))
+	for i, e := range v {
+		if c.engine != nil && c.engine.validationTranslator != nil {
+			e.Message = c.engine.validationTranslator(c, e)
+		} else {
+			e.Message = translateFieldError(c, e)
+		}
+		localized[i] = e
+	}
+	return localized
+}
+
+// translateFieldError returns the message of e from the catalogs of the
+// I18n middleware, falling back to its own message.
+func translateFieldError(c *Context, e FieldError) string {
+	if l, ok := c.localizer(); ok {
+		if template, ok := l.translator.lookup(l.locale, "validation."+e.Rule); ok {
+			return e.format(template)
+		}
+	}
+	return e.message()
+}
+
+// ValidationTranslator returns the message of a field error for a request,
+// e.g. by looking it up in a translation library.
+type ValidationTranslator func(c *Context, e FieldError) string
+
+// SetValidationTranslator sets the function translating the messages of
+// ValidationErrors, replacing the lookup in the catalogs of the I18n
+// middleware. Passing nil restores the default.
+// Returns the Engine instance for method chaining.
+//
+// Example:
+//
+//	app.SetValidationTranslator(func(c *goxpress.Context, e goxpress.FieldError) string {
+//		return bundle.Localize(c.Locale(), "validation."+e.Rule, e.Field, e.Param)
+//	})
+func (e *Engine) SetValidationTranslator(translator ValidationTranslator) *Engine {
+	e.validationTranslator = translator
+	return e
+}
+
+// validationErrorBody returns the JSON representation of v sent with 422
+// Unprocessable Entity responses.
+func validationErrorBody(c *Context, v ValidationErrors) map[string]interface{} {
+	return map[string]interface{}{
+		"error":  http.StatusText(http.StatusUnprocessableEntity),
+		"errors": v.Localize(c),
+	}
+}
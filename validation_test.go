@@ -0,0 +1,86 @@
+package goxpress
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestValidationErrors(t *testing.T) {
+	var errs ValidationErrors
+	if errs.Err() != nil {
+		t.Error("Empty ValidationErrors should not be an error")
+	}
+
+	errs.Add("name", "required", "")
+	errs.Add("password", "min", "8")
+	errs = append(errs, FieldError{Field: "email", Rule: "taken", Message: "email is already registered"})
+	errs.Add("age", "adult", "")
+
+	want := "name: name is required; password: password must be at least 8; email: email is already registered; age: age is invalid"
+	if errs.Error() != want {
+		t.Errorf("Expected %q, got %q", want, errs.Error())
+	}
+
+	var target ValidationErrors
+	if !errors.As(fmt.Errorf("signup: %w", errs.Err()), &target) || len(target) != 4 {
+		t.Error("ValidationErrors should be found with errors.As")
+	}
+}
+
+func TestValidationErrorsResponse(t *testing.T) {
+	translator, err := LoadTranslations(fstest.MapFS{
+		"en.json": {Data: []byte(`{}`)},
+		"fr.json": {Data: []byte(`{"validation.required": "{field} est obligatoire"}`)},
+	}, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := New()
+	app.Use(I18n(I18nConfig{Translator: translator}))
+	app.POST("/users", func(c *Context) {
+		var errs ValidationErrors
+		errs.Add("name", "required", "")
+		errs.Add("password", "min", "8")
+		c.Next(errs)
+	})
+
+	req := httptest.NewRequest("POST", "/users", nil)
+	req.Header.Set("Accept-Language", "fr")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	var body struct {
+		Error  string       `json:"error"`
+		Errors []FieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || w.Code != 422 {
+		t.Fatalf("Expected 422 JSON response, got %d %q", w.Code, w.Body.String())
+	}
+	if body.Error != "Unprocessable Entity" || len(body.Errors) != 2 {
+		t.Fatalf("Unexpected body %+v", body)
+	}
+	if body.Errors[0] != (FieldError{Field: "name", Rule: "required", Message: "name est obligatoire"}) {
+		t.Errorf("Expected translated message, got %+v", body.Errors[0])
+	}
+	if body.Errors[1].Message != "password must be at least 8" || body.Errors[1].Param != "8" {
+		t.Errorf("Expected default message without translation, got %+v", body.Errors[1])
+	}
+
+	// A custom translator replaces the catalogs
+	app.SetValidationTranslator(func(c *Context, e FieldError) string {
+		return c.Locale() + ":" + e.Rule
+	})
+	req = httptest.NewRequest("POST", "/users", nil)
+	req.Header.Set("Accept", "text/plain")
+	req.Header.Set("Accept-Language", "fr")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != 422 || w.Body.String() != "name: fr:required; password: fr:min" {
+		t.Errorf("Expected plain text with custom messages, got %d %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,185 @@
+// Package goxpress provides a fast, intuitive web framework for Go inspired by Express.js.
+// This file contains middleware verifying the signatures of common webhook
+// providers.
+package goxpress
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultWebhookTolerance is the maximum age of signed webhook timestamps
+// when no tolerance is given.
+const defaultWebhookTolerance = 5 * time.Minute
+
+// GitHubWebhook returns a middleware that verifies the X-Hub-Signature-256
+// header of GitHub webhook deliveries against the webhook secret.
+// Requests with a missing or invalid signature are aborted with
+// 401 Unauthorized. The body is read with RawBody, so handlers can still
+// bind it. It panics if secret is empty, so a missing environment variable
+// doesn't accept signatures anyone can compute.
+//
+// Example:
+//
+//	app.POST("/webhooks/github", goxpress.GitHubWebhook(os.Getenv("GITHUB_WEBHOOK_SECRET")), onPush)
+func GitHubWebhook(secret string) HandlerFunc {
+	requireWebhookSecret(secret)
+	return func(c *Context) {
+		body, ok := webhookBody(c)
+		if !ok {
+			return
+		}
+
+		signature := c.Request.Header.Get("X-Hub-Signature-256")
+		if !strings.HasPrefix(signature, "sha256=") ||
+			!validHMAC(secret, body, strings.TrimPrefix(signature, "sha256=")) {
+			rejectWebhook(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// StripeWebhook returns a middleware that verifies the Stripe-Signature
+// header of Stripe webhook events against the endpoint's signing secret.
+// Requests with a missing or invalid signature, or signed longer than
+// tolerance ago (5 minutes if zero), are aborted with 401 Unauthorized,
+// which protects against replayed events. The body is read with RawBody,
+// so handlers can still bind it. It panics if secret is empty.
+//
+// Example:
+//
+//	app.POST("/webhooks/stripe", goxpress.StripeWebhook(os.Getenv("STRIPE_WEBHOOK_SECRET"), 0), onEvent)
+func StripeWebhook(secret string, tolerance time.Duration) HandlerFunc {
+	requireWebhookSecret(secret)
+	if tolerance <= 0 {
+		tolerance = defaultWebhookTolerance
+	}
+
+	return func(c *Context) {
+		body, ok := webhookBody(c)
+		if !ok {
+			return
+		}
+
+		// Stripe-Signature: t=1492774577,v1=5257a869...,v1=...
+		var timestamp string
+		var signatures []string
+		for _, part := range strings.Split(c.Request.Header.Get("Stripe-Signature"), ",") {
+			kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			switch kv[0] {
+			case "t":
+				timestamp = kv[1]
+			case "v1":
+				signatures = append(signatures, kv[1])
+			}
+		}
+
+		if !validWebhookTimestamp(timestamp, tolerance) {
+			rejectWebhook(c)
+			return
+		}
+		payload := append([]byte(timestamp+"."), body...)
+		for _, signature := range signatures {
+			if validHMAC(secret, payload, signature) {
+				c.Next()
+				return
+			}
+		}
+		rejectWebhook(c)
+	}
+}
+
+// SlackWebhook returns a middleware that verifies the X-Slack-Signature
+// and X-Slack-Request-Timestamp headers of Slack requests against the
+// app's signing secret. Requests with a missing or invalid signature, or
+// signed longer than tolerance ago (5 minutes if zero), are aborted with
+// 401 Unauthorized. The body is read with RawBody, so handlers can still
+// bind it. It panics if signingSecret is empty.
+//
+// Example:
+//
+//	app.POST("/slack/commands", goxpress.SlackWebhook(os.Getenv("SLACK_SIGNING_SECRET"), 0), onCommand)
+func SlackWebhook(signingSecret string, tolerance time.Duration) HandlerFunc {
+	requireWebhookSecret(signingSecret)
+	if tolerance <= 0 {
+		tolerance = defaultWebhookTolerance
+	}
+
+	return func(c *Context) {
+		body, ok := webhookBody(c)
+		if !ok {
+			return
+		}
+
+		timestamp := c.Request.Header.Get("X-Slack-Request-Timestamp")
+		signature := c.Request.Header.Get("X-Slack-Signature")
+		if !validWebhookTimestamp(timestamp, tolerance) || !strings.HasPrefix(signature, "v0=") {
+			rejectWebhook(c)
+			return
+		}
+
+		payload := append([]byte("v0:"+timestamp+":"), body...)
+		if !validHMAC(signingSecret, payload, strings.TrimPrefix(signature, "v0=")) {
+			rejectWebhook(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// webhookBody reads the request body for signature verification. If it
+// cannot be read, the request is aborted with 400 Bad Request.
+func webhookBody(c *Context) ([]byte, bool) {
+	body, err := c.RawBody()
+	if err != nil {
+		c.Abort()
+		c.String(http.StatusBadRequest, "Bad Request")
+		return nil, false
+	}
+	return body, true
+}
+
+// rejectWebhook aborts a request whose signature could not be verified.
+func rejectWebhook(c *Context) {
+	c.Abort()
+	c.String(http.StatusUnauthorized, "Unauthorized")
+}
+
+// requireWebhookSecret panics if secret is empty.
+func requireWebhookSecret(secret string) {
+	if secret == "" {
+		panic("goxpress: webhook secret must not be empty")
+	}
+}
+
+// validHMAC reports whether signature is the hex-encoded HMAC-SHA256 of
+// payload with secret, comparing in constant time.
+func validHMAC(secret string, payload []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// validWebhookTimestamp reports whether timestamp, in Unix seconds, lies
+// within tolerance of the current time.
+func validWebhookTimestamp(timestamp string, tolerance time.Duration) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	age := time.Since(time.Unix(seconds, 0))
+	return age <= tolerance && age >= -tolerance
+}
@@ -0,0 +1,133 @@
+package goxpress
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sign returns the hex-encoded HMAC-SHA256 of payload with secret.
+func sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookApp returns an Engine echoing the body of POST / behind verifier.
+func webhookApp(verifier HandlerFunc) *Engine {
+	app := New()
+	app.POST("/", verifier, func(c *Context) {
+		var event map[string]string
+		if err := c.BindJSON(&event); err != nil {
+			c.String(400, err.Error())
+			return
+		}
+		c.String(200, event["type"])
+	})
+	return app
+}
+
+func TestGitHubWebhook(t *testing.T) {
+	app := webhookApp(GitHubWebhook("secret"))
+	body := `{"type":"push"}`
+
+	tests := []struct {
+		signature string
+		code      int
+	}{
+		{"sha256=" + sign("secret", body), 200},
+		{"sha256=" + sign("other", body), 401},
+		{sign("secret", body), 401},
+		{"", 401},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("X-Hub-Signature-256", tt.signature)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("Signature %q: expected %d, got %d", tt.signature, tt.code, w.Code)
+		}
+		if tt.code == 200 && w.Body.String() != "push" {
+			t.Errorf("Expected handler to bind the verified body, got %q", w.Body.String())
+		}
+	}
+}
+
+func TestStripeWebhook(t *testing.T) {
+	app := webhookApp(StripeWebhook("whsec", 0))
+	body := `{"type":"invoice.paid"}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	old := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+
+	tests := []struct {
+		header string
+		code   int
+	}{
+		{"t=" + now + ",v1=" + sign("whsec", now+"."+body), 200},
+		{"t=" + now + ",v1=" + sign("rotated", now+"."+body) + ",v1=" + sign("whsec", now+"."+body), 200},
+		{"t=" + old + ",v1=" + sign("whsec", old+"."+body), 401},
+		{"t=" + now + ",v1=" + sign("whsec", body), 401},
+		{"v1=" + sign("whsec", now+"."+body), 401},
+	}
+	for i, tt := range tests {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Stripe-Signature", tt.header)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("Case %d: expected %d, got %d", i, tt.code, w.Code)
+		}
+	}
+}
+
+func TestSlackWebhook(t *testing.T) {
+	app := webhookApp(SlackWebhook("slack", time.Minute))
+	body := `{"type":"event_callback"}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	old := strconv.FormatInt(time.Now().Add(-2*time.Minute).Unix(), 10)
+
+	tests := []struct {
+		timestamp string
+		signature string
+		code      int
+	}{
+		{now, "v0=" + sign("slack", "v0:"+now+":"+body), 200},
+		{old, "v0=" + sign("slack", "v0:"+old+":"+body), 401},
+		{now, "v0=" + sign("slack", "v0:"+old+":"+body), 401},
+		{now, sign("slack", "v0:"+now+":"+body), 401},
+	}
+	for i, tt := range tests {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("X-Slack-Request-Timestamp", tt.timestamp)
+		req.Header.Set("X-Slack-Signature", tt.signature)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("Case %d: expected %d, got %d", i, tt.code, w.Code)
+		}
+	}
+}
+
+func TestWebhookEmptySecret(t *testing.T) {
+	constructors := map[string]func(){
+		"GitHub": func() { GitHubWebhook("") },
+		"Stripe": func() { StripeWebhook("", 0) },
+		"Slack":  func() { SlackWebhook("", 0) },
+	}
+	for name, construct := range constructors {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected a panic for an empty secret", name)
+				}
+			}()
+			construct()
+		}()
+	}
+}
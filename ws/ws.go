@@ -0,0 +1,390 @@
+// Package ws provides a WebSocket hub with rooms, broadcasting and
+// per-connection send queues, so chat and notification features don't
+// require building connection management from scratch.
+//
+// The package does not depend on a WebSocket library. Wrap the connection
+// of your choice in a type implementing Conn, e.g. with gorilla/websocket:
+//
+//	type wsConn struct{ *websocket.Conn }
+//
+//	func (c wsConn) ReadMessage() ([]byte, error) {
+//		_, data, err := c.Conn.ReadMessage()
+//		return data, err
+//	}
+//
+//	func (c wsConn) WriteMessage(data []byte) error {
+//		c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+//		return c.Conn.WriteMessage(websocket.TextMessage, data)
+//	}
+//
+// and hand upgraded connections to a Hub:
+//
+//	var hub *ws.Hub
+//	hub = ws.NewHub(ws.Config{
+//		OnMessage: func(c *ws.Client, data []byte) {
+//			hub.BroadcastTo(c.Get("room").(string), data)
+//		},
+//	})
+//	app.OnShutdown(hub.Shutdown)
+//
+//	app.GET("/rooms/:room", func(c *goxpress.Context) {
+//		conn, err := upgrader.Upgrade(c.Response, c.Request, nil)
+//		if err != nil {
+//			return
+//		}
+//		client, err := hub.Add(wsConn{conn})
+//		if err != nil {
+//			conn.Close()
+//			return
+//		}
+//		client.Set("room", c.Param("room"))
+//		client.Join(c.Param("room"))
+//		client.Listen()
+//	})
+package ws
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrClosed is returned when sending to a closed client or adding a
+	// client to a hub that has been shut down.
+	ErrClosed = errors.New("ws: connection closed")
+
+	// ErrQueueFull is returned by Client.Send when the client's send queue
+	// is full. The client is closed, as it cannot keep up.
+	ErrQueueFull = errors.New("ws: send queue full")
+)
+
+// Conn is the subset of a WebSocket connection used by the Hub.
+type Conn interface {
+	// ReadMessage blocks until the next message arrives and returns its
+	// payload. It returns an error once the connection is closed.
+	ReadMessage() ([]byte, error)
+
+	// WriteMessage sends a message. It is only called from one goroutine
+	// at a time.
+	WriteMessage(data []byte) error
+
+	// Close closes the connection, unblocking ReadMessage.
+	Close() error
+}
+
+// Config defines configuration options for a Hub.
+type Config struct {
+	// SendQueueSize is the number of outgoing messages buffered per client.
+	// If zero, defaults to 64.
+	SendQueueSize int
+
+	// OnMessage, if set, is called with every message received from a
+	// client, on the goroutine running Client.Listen.
+	OnMessage func(c *Client, data []byte)
+
+	// OnDisconnect, if set, is called once a client has been removed from
+	// the hub, with the error that ended its connection, if any.
+	OnDisconnect func(c *Client, err error)
+}
+
+// Hub tracks WebSocket clients and the rooms they joined. It is safe for
+// concurrent use.
+type Hub struct {
+	config Config
+
+	mu      sync.RWMutex
+	clients map[*Client]struct{}
+	rooms   map[string]map[*Client]struct{}
+	closed  bool
+
+	writers sync.WaitGroup // Running client writer goroutines
+}
+
+// NewHub creates an empty Hub.
+func NewHub(config Config) *Hub {
+	// Set defaults
+	if config.SendQueueSize <= 0 {
+		config.SendQueueSize = 64
+	}
+
+	return &Hub{
+		config:  config,
+		clients: make(map[*Client]struct{}),
+		rooms:   make(map[string]map[*Client]struct{}),
+	}
+}
+
+// Add registers conn with the hub and starts delivering the messages
+// queued for it. Call Listen on the returned client to receive messages.
+// Returns ErrClosed once the hub has been shut down.
+func (h *Hub) Add(conn Conn) (*Client, error) {
+	c := &Client{
+		hub:   h,
+		conn:  conn,
+		send:  make(chan []byte, h.config.SendQueueSize),
+		rooms: make(map[string]struct{}),
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil, ErrClosed
+	}
+	h.clients[c] = struct{}{}
+	h.writers.Add(1)
+	h.mu.Unlock()
+
+	go c.writeLoop()
+	return c, nil
+}
+
+// Broadcast queues data for every client of the hub.
+func (h *Hub) Broadcast(data []byte) {
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.mu.RUnlock()
+
+	for _, c := range clients {
+		c.Send(data)
+	}
+}
+
+// BroadcastTo queues data for every client in room.
+func (h *Hub) BroadcastTo(room string, data []byte) {
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.rooms[room]))
+	for c := range h.rooms[room] {
+		clients = append(clients, c)
+	}
+	h.mu.RUnlock()
+
+	for _, c := range clients {
+		c.Send(data)
+	}
+}
+
+// Len returns the number of clients of the hub.
+func (h *Hub) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// RoomLen returns the number of clients in room.
+func (h *Hub) RoomLen(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
+
+// Shutdown stops accepting clients and closes the existing ones once
+// their queued messages have been sent. If ctx is done first, the
+// remaining connections are closed immediately and ctx's error is
+// returned. Register it with Engine.OnShutdown, as http.Server does not
+// track WebSocket connections.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	clients := make([]*Client, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.mu.Unlock()
+
+	for _, c := range clients {
+		c.Close()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.writers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		for _, c := range clients {
+			c.conn.Close()
+		}
+		return ctx.Err()
+	}
+}
+
+// remove unregisters c from the hub and its rooms. It reports whether c
+// was still registered.
+func (h *Hub) remove(c *Client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.clients[c]; !ok {
+		return false
+	}
+	delete(h.clients, c)
+
+	c.mu.Lock()
+	for room := range c.rooms {
+		h.leave(c, room)
+	}
+	c.mu.Unlock()
+	return true
+}
+
+// leave removes c from room. The hub lock must be held.
+func (h *Hub) leave(c *Client, room string) {
+	members := h.rooms[room]
+	delete(members, c)
+	if len(members) == 0 {
+		delete(h.rooms, room)
+	}
+}
+
+// Client is a WebSocket connection registered with a Hub.
+type Client struct {
+	hub  *Hub
+	conn Conn
+	send chan []byte
+
+	mu     sync.Mutex // Guards the fields below
+	rooms  map[string]struct{}
+	values map[string]interface{}
+	closed bool
+}
+
+// Listen reads messages from the connection and passes them to the hub's
+// OnMessage callback until the connection fails or is closed. It then
+// removes the client from the hub and returns the error that ended the
+// connection.
+func (c *Client) Listen() error {
+	var err error
+	for {
+		var data []byte
+		if data, err = c.conn.ReadMessage(); err != nil {
+			break
+		}
+		if c.hub.config.OnMessage != nil {
+			c.hub.config.OnMessage(c, data)
+		}
+	}
+
+	c.Close()
+	if c.hub.remove(c) && c.hub.config.OnDisconnect != nil {
+		c.hub.config.OnDisconnect(c, err)
+	}
+	return err
+}
+
+// Send queues data for the client. If the queue is full, the client is
+// closed and ErrQueueFull is returned, so a slow client cannot hold up
+// broadcasts.
+func (c *Client) Send(data []byte) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	select {
+	case c.send <- data:
+		c.mu.Unlock()
+		return nil
+	default:
+	}
+	c.mu.Unlock()
+
+	c.Close()
+	return ErrQueueFull
+}
+
+// Join adds the client to room.
+func (c *Client) Join(room string) {
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+	if _, ok := c.hub.clients[c]; !ok {
+		return // Already removed
+	}
+
+	members, ok := c.hub.rooms[room]
+	if !ok {
+		members = make(map[*Client]struct{})
+		c.hub.rooms[room] = members
+	}
+	members[c] = struct{}{}
+
+	c.mu.Lock()
+	c.rooms[room] = struct{}{}
+	c.mu.Unlock()
+}
+
+// Leave removes the client from room.
+func (c *Client) Leave(room string) {
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+
+	c.hub.leave(c, room)
+
+	c.mu.Lock()
+	delete(c.rooms, room)
+	c.mu.Unlock()
+}
+
+// Rooms returns the rooms the client has joined.
+func (c *Client) Rooms() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rooms := make([]string, 0, len(c.rooms))
+	for room := range c.rooms {
+		rooms = append(rooms, room)
+	}
+	return rooms
+}
+
+// Set stores a value on the client, e.g. the authenticated user.
+func (c *Client) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.values == nil {
+		c.values = make(map[string]interface{})
+	}
+	c.values[key] = value
+}
+
+// Get returns a value stored with Set, or nil if there is none.
+func (c *Client) Get(key string) interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[key]
+}
+
+// Close stops accepting messages for the client. Messages already queued
+// are still sent before the connection is closed.
+func (c *Client) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// writeLoop sends queued messages until the queue is closed, then closes
+// the connection.
+func (c *Client) writeLoop() {
+	defer c.hub.writers.Done()
+	defer c.conn.Close()
+
+	for data := range c.send {
+		if err := c.conn.WriteMessage(data); err != nil {
+			c.Close()
+			for range c.send {
+				// Discard the rest of the queue
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeConn is an in-memory Conn. Messages pushed to in are read by the
+// hub; messages written by the hub are collected in out.
+type fakeConn struct {
+	in     chan []byte
+	closed chan struct{}
+	once   sync.Once
+
+	mu    sync.Mutex
+	out   []string
+	block chan struct{} // If set, writes wait until it is closed
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{in: make(chan []byte), closed: make(chan struct{})}
+}
+
+func (f *fakeConn) ReadMessage() ([]byte, error) {
+	select {
+	case data := <-f.in:
+		return data, nil
+	case <-f.closed:
+		return nil, errors.New("closed")
+	}
+}
+
+func (f *fakeConn) WriteMessage(data []byte) error {
+	if f.block != nil {
+		<-f.block
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.out = append(f.out, string(data))
+	return nil
+}
+
+func (f *fakeConn) Close() error {
+	f.once.Do(func() { close(f.closed) })
+	return nil
+}
+
+func (f *fakeConn) written() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.out...)
+}
+
+// waitFor polls cond until it holds or a second has passed.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHubRooms(t *testing.T) {
+	disconnected := make(chan *Client, 2)
+	var hub *Hub
+	hub = NewHub(Config{
+		OnMessage: func(c *Client, data []byte) {
+			hub.BroadcastTo(c.Get("room").(string), data)
+		},
+		OnDisconnect: func(c *Client, err error) {
+			disconnected <- c
+		},
+	})
+
+	alice, bob, carol := newFakeConn(), newFakeConn(), newFakeConn()
+	clients := map[*fakeConn]*Client{}
+	for conn, room := range map[*fakeConn]string{alice: "go", bob: "go", carol: "rust"} {
+		client, err := hub.Add(conn)
+		if err != nil {
+			t.Fatalf("Add should not return error: %v", err)
+		}
+		client.Set("room", room)
+		client.Join(room)
+		clients[conn] = client
+		go client.Listen()
+	}
+
+	if hub.Len() != 3 || hub.RoomLen("go") != 2 || hub.RoomLen("rust") != 1 {
+		t.Fatalf("Unexpected hub size %d, rooms go=%d rust=%d", hub.Len(), hub.RoomLen("go"), hub.RoomLen("rust"))
+	}
+
+	alice.in <- []byte("hello gophers")
+	waitFor(t, func() bool { return len(bob.written()) == 1 && len(alice.written()) == 1 })
+	if len(carol.written()) != 0 {
+		t.Errorf("Room messages should not reach other rooms, got %v", carol.written())
+	}
+
+	hub.Broadcast([]byte("maintenance"))
+	waitFor(t, func() bool { return len(carol.written()) == 1 })
+
+	bob.Close()
+	if c := <-disconnected; c != clients[bob] {
+		t.Error("Expected bob to disconnect")
+	}
+	if hub.Len() != 2 || hub.RoomLen("go") != 1 {
+		t.Errorf("Expected bob to be removed, got %d clients, %d in room", hub.Len(), hub.RoomLen("go"))
+	}
+}
+
+func TestClientQueueFull(t *testing.T) {
+	hub := NewHub(Config{SendQueueSize: 1})
+
+	conn := newFakeConn()
+	conn.block = make(chan struct{})
+	client, _ := hub.Add(conn)
+	go client.Listen()
+
+	// The first message is taken by the writer, the second fills the queue
+	client.Send([]byte("1"))
+	waitFor(t, func() bool { return len(client.send) == 0 })
+	client.Send([]byte("2"))
+	if err := client.Send([]byte("3")); err != ErrQueueFull {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+	if err := client.Send([]byte("4")); err != ErrClosed {
+		t.Errorf("Expected ErrClosed after overflow, got %v", err)
+	}
+
+	close(conn.block)
+	waitFor(t, func() bool { return hub.Len() == 0 })
+	if out := conn.written(); len(out) != 2 {
+		t.Errorf("Expected queued messages to be flushed, got %v", out)
+	}
+}
+
+func TestHubShutdown(t *testing.T) {
+	hub := NewHub(Config{})
+
+	conn := newFakeConn()
+	client, _ := hub.Add(conn)
+	go client.Listen()
+	client.Send([]byte("bye"))
+
+	if err := hub.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown should not return error: %v", err)
+	}
+	if out := conn.written(); len(out) != 1 || out[0] != "bye" {
+		t.Errorf("Expected queued message to be sent before closing, got %v", out)
+	}
+	select {
+	case <-conn.closed:
+	default:
+		t.Error("Expected connection to be closed")
+	}
+	if _, err := hub.Add(newFakeConn()); err != ErrClosed {
+		t.Errorf("Expected ErrClosed after shutdown, got %v", err)
+	}
+}